@@ -0,0 +1,6 @@
+#version 150 core
+
+out vec4 color;
+void main() {
+	color = vec4(1, 1, 1, 1);
+}
@@ -0,0 +1,85 @@
+// Package hellotriangle draws a white triangle on the screen.
+// It is an implementation of http://arcsynthesis.org/gltut/Basics/Tutorial%2001.html
+package hellotriangle
+
+import (
+	"embed"
+	"time"
+	"aqwari.net/exp/display"
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/shader"
+	"github.com/droyo/gltut/internal/tutorial"
+)
+
+func init() {
+	tutorial.Register(&tutorial.Tutorial{
+		Chapter: 1,
+		Section: 1,
+		Name:    "hello-triangle",
+		Title:   "Hello Triangle",
+		URL:     "http://arcsynthesis.org/gltut/Basics/Tutorial%2001.html",
+		Doc:     "Draws a white triangle on the screen.",
+		New:     func() tutorial.App { return new(scene) },
+		Shaders: shaders,
+	})
+}
+
+//go:generate go run github.com/droyo/gltut/cmd/glbind tutorial.vert tutorial.frag
+
+//go:embed tutorial.vert tutorial.frag
+var files embed.FS
+
+var shaders = shader.Local(files)
+
+type scene struct {
+	prog    *program
+	buffers []gfx.Buffer
+	vao     []gfx.VertexArray
+}
+
+func (s *scene) Init(ctx gfx.Context, width, height int) error {
+	ctx.ClearColor(0, 0, 0, 0)
+	
+	triPoints := []float32 {
+		0.75, 0.75, 0.0, 1.0,
+		0.75, -0.75, 0.0, 1.0,
+		-0.75, -0.75, 0.0, 1.0,
+	}
+	
+	prog, err := newProgram(ctx, shaders)
+	if err != nil {
+		return err
+	}
+	prog.Use()
+	s.prog = prog
+	
+	s.buffers = ctx.GenBuffers(1)
+	ctx.BindBuffer(gfx.ARRAY_BUFFER, s.buffers[0])
+	ctx.BufferData(gfx.ARRAY_BUFFER, triPoints, gfx.STATIC_DRAW)
+	
+	s.vao = ctx.GenVertexArrays(1)
+	ctx.BindVertexArray(s.vao[0])
+	
+	ctx.EnableVertexAttribArray(programPosition)
+	ctx.VertexAttribPointer(programPosition, 4, gfx.Float32, false, 0, 0)
+	return nil
+}
+
+func (s *scene) Resize(ctx gfx.Context, width, height int) {
+	ctx.Viewport(0, 0, width, height)
+}
+
+func (s *scene) Key(ctx gfx.Context, ev display.KeyPress) {}
+
+func (s *scene) Update(dt time.Duration) {}
+
+func (s *scene) Draw(ctx gfx.Context) {
+	ctx.Clear(gfx.COLOR_BUFFER_BIT)
+	ctx.DrawArrays(gfx.TRIANGLES, 0, 3)
+}
+
+func (s *scene) Close(ctx gfx.Context) {
+	ctx.DeleteVertexArrays(s.vao)
+	ctx.DeleteBuffers(s.buffers)
+	s.prog.Delete()
+}
@@ -0,0 +1,6 @@
+#version 150 core
+
+in vec4 position;
+void main() {
+	gl_Position = position;
+}
@@ -0,0 +1,6 @@
+#version 150
+
+out vec4 outColor;
+void main() {
+	outColor = vec4(1,1,1,1);
+}
@@ -0,0 +1,8 @@
+#version 150
+
+in vec2 position;
+uniform vec2 offset;
+
+void main() {
+	gl_Position = vec4(position + offset, 0, 1);
+}
@@ -0,0 +1,119 @@
+// Package movingthevertices loops a triangle around the screen by updating
+// a vertex buffer. It is an implementation of http://arcsynthesis.org/gltut/Positioning/Tutorial%2003.html
+package movingthevertices
+
+import (
+	"embed"
+	"time"
+	"math"
+	"aqwari.net/exp/display"
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/shader"
+	"github.com/droyo/gltut/internal/tutorial"
+)
+
+func init() {
+	tutorial.Register(&tutorial.Tutorial{
+		Chapter:  3,
+		Section:  1,
+		Name:     "moving-the-vertices",
+		Title:    "Moving Triangle",
+		URL:      "http://arcsynthesis.org/gltut/Positioning/Tutorial%2003.html",
+		Doc:      "Loops a triangle around the screen by updating a vertex buffer.",
+		New:      func() tutorial.App { return new(scene) },
+		Shaders:  shaders,
+		Animated: true,
+	})
+}
+
+//go:generate go run github.com/droyo/gltut/cmd/glbind tutorial.vert tutorial.frag
+
+//go:embed tutorial.vert tutorial.frag
+var files embed.FS
+
+var shaders = shader.Local(files)
+
+type scene struct {
+	prog       *program
+	buffers    []gfx.Buffer
+	vao        []gfx.VertexArray
+	vertexData []float32
+	elapsed    time.Duration
+}
+
+func (s *scene) Init(ctx gfx.Context, width, height int) error {
+	ctx.ClearColor(0, 0, 0, 0)
+	
+	vertexData := []float32 {
+		 0.0,    0.25, 0.0, 1.0,
+		 0.25, -0.366, 0.0, 1.0,
+		-0.25, -0.366, 0.0, 1.0,
+	}
+	
+	prog, err := newProgram(ctx, shaders)
+	if err != nil {
+		return err
+	}
+	prog.Use()
+	s.prog = prog
+	
+	s.buffers = ctx.GenBuffers(1)
+	ctx.BindBuffer(gfx.ARRAY_BUFFER, s.buffers[0])
+	ctx.BufferData(gfx.ARRAY_BUFFER, vertexData, gfx.STATIC_DRAW)
+	
+	s.vao = ctx.GenVertexArrays(1)
+	ctx.BindVertexArray(s.vao[0])
+	
+	ctx.EnableVertexAttribArray(programPosition)
+	ctx.VertexAttribPointer(programPosition, 4, gfx.Float32, false, 0, 0)
+	
+	s.vertexData = vertexData
+	return nil
+}
+
+func (s *scene) Resize(ctx gfx.Context, width, height int) {
+	ctx.Viewport(0, 0, width, height)
+}
+
+func (s *scene) Key(ctx gfx.Context, ev display.KeyPress) {}
+
+func (s *scene) Update(dt time.Duration) {
+	s.elapsed += dt
+}
+
+func (s *scene) Draw(ctx gfx.Context) {
+	ctx.BindBuffer(gfx.ARRAY_BUFFER, s.buffers[0])
+	ctx.BufferSubData(gfx.ARRAY_BUFFER, 0, translate(s.vertexData, s.elapsed))
+	ctx.Clear(gfx.COLOR_BUFFER_BIT)
+	ctx.DrawArrays(gfx.TRIANGLES, 0, 3)
+}
+
+func (s *scene) Close(ctx gfx.Context) {
+	ctx.DeleteVertexArrays(s.vao)
+	ctx.DeleteBuffers(s.buffers)
+	s.prog.Delete()
+}
+
+// translate returns a copy of points, moved along a circle according
+// to the elapsed time.
+func translate(points []float32, elapsed time.Duration) []float32 {
+	dx, dy := offset(elapsed)
+	moved := make([]float32, len(points))
+	copy(moved, points)
+	for i := 0 ; i < len(moved); i += 4 {
+		moved[i] += dx
+		moved[i+1] += dy
+	}
+	return moved
+}
+
+func offset(elapsed time.Duration) (dx float32, dy float32) {
+	π := float64(math.Pi)
+	period := time.Second * 2
+	scale := 2*π / period.Seconds()
+	pos := (elapsed % period).Seconds()
+	
+	dx = float32(math.Cos(pos * scale) / 2)
+	dy = float32(math.Sin(pos * scale) / 2)
+	return
+}
@@ -0,0 +1,15 @@
+#version 150
+
+out vec4 outColor;
+
+uniform float fragPeriod;
+uniform float time;
+
+const vec4 firstColor = vec4(1, 1, 1, 1);
+const vec4 secondColor = vec4(0, 1, 0, 1);
+
+void main() {
+	float cur = mod(time, fragPeriod);
+	float curLerp = cur / fragPeriod;
+	outColor = mix(firstColor, secondColor, curLerp);
+}
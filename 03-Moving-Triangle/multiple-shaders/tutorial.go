@@ -0,0 +1,93 @@
+// Package multipleshaders moves a triangle while cycling its color.
+// It is an implementation of http://arcsynthesis.org/gltut/Positioning/Tut03%20Multiple%20Shaders.html
+package multipleshaders
+
+import (
+	"embed"
+	"time"
+	"aqwari.net/exp/display"
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/shader"
+	"github.com/droyo/gltut/internal/tutorial"
+)
+
+func init() {
+	tutorial.Register(&tutorial.Tutorial{
+		Chapter:  3,
+		Section:  4,
+		Name:     "multiple-shaders",
+		Title:    "Multiple Shaders",
+		URL:      "http://arcsynthesis.org/gltut/Positioning/Tut03%20Multiple%20Shaders.html",
+		Doc:      "Moves a triangle while cycling its color.",
+		New:      func() tutorial.App { return new(scene) },
+		Shaders:  shaders,
+		Animated: true,
+	})
+}
+
+//go:generate go run github.com/droyo/gltut/cmd/glbind tutorial.vert tutorial.frag
+
+//go:embed tutorial.vert tutorial.frag
+var files embed.FS
+
+var shaders = shader.Local(files)
+
+type scene struct {
+	prog    *program
+	buffers []gfx.Buffer
+	vao     []gfx.VertexArray
+	elapsed time.Duration
+}
+
+func (s *scene) Init(ctx gfx.Context, width, height int) error {
+	ctx.ClearColor(0, 0, 0, 0)
+	
+	vertexData := []float32 {
+		 0.0,    0.25,
+		 0.25, -0.366,
+		-0.25, -0.366,
+	}
+	
+	prog, err := newProgram(ctx, shaders)
+	if err != nil {
+		return err
+	}
+	prog.Use()
+	s.prog = prog
+	
+	s.buffers = ctx.GenBuffers(1)
+	ctx.BindBuffer(gfx.ARRAY_BUFFER, s.buffers[0])
+	ctx.BufferData(gfx.ARRAY_BUFFER, vertexData, gfx.STATIC_DRAW)
+	
+	s.vao = ctx.GenVertexArrays(1)
+	ctx.BindVertexArray(s.vao[0])
+	
+	ctx.EnableVertexAttribArray(programPosition)
+	ctx.VertexAttribPointer(programPosition, 2, gfx.Float32, false, 0, 0)
+	
+	prog.SetPeriod(float32((time.Second * 4).Seconds()))
+	prog.SetFragPeriod(float32((time.Second * 2).Seconds()))
+	return nil
+}
+
+func (s *scene) Resize(ctx gfx.Context, width, height int) {
+	ctx.Viewport(0, 0, width, height)
+}
+
+func (s *scene) Key(ctx gfx.Context, ev display.KeyPress) {}
+
+func (s *scene) Update(dt time.Duration) {
+	s.elapsed += dt
+}
+
+func (s *scene) Draw(ctx gfx.Context) {
+	ctx.Clear(gfx.COLOR_BUFFER_BIT)
+	s.prog.SetTime(float32(s.elapsed.Seconds()))
+	ctx.DrawArrays(gfx.TRIANGLES, 0, 3)
+}
+
+func (s *scene) Close(ctx gfx.Context) {
+	ctx.DeleteVertexArrays(s.vao)
+	ctx.DeleteBuffers(s.buffers)
+	s.prog.Delete()
+}
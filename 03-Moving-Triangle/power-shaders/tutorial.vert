@@ -0,0 +1,12 @@
+#version 150
+
+in vec2 position;
+uniform float period;
+uniform float time;
+
+void main() {
+	float scale = 3.14159 * 2 / period;
+	float cur = mod(time, period);
+	vec2 offset = vec2(cos(cur * scale) / 2, sin(cur * scale) / 2);
+	gl_Position = vec4(position + offset, 0, 1);
+}
@@ -0,0 +1,192 @@
+// Package aspectratio displays a 3D prism, preserving the aspect ratio.
+// It is an implementation of http://arcsynthesis.org/gltut/Positioning/Tut04%20Aspect%20of%20the%20World.html
+package aspectratio
+
+import (
+	"embed"
+	"time"
+	"aqwari.net/exp/display"
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/mesh"
+	"github.com/droyo/gltut/internal/shader"
+	"github.com/droyo/gltut/internal/tutorial"
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+func init() {
+	tutorial.Register(&tutorial.Tutorial{
+		Chapter: 4,
+		Section: 4,
+		Name:    "aspect-ratio",
+		Title:   "Aspect Ratio",
+		URL:     "http://arcsynthesis.org/gltut/Positioning/Tut04%20Aspect%20of%20the%20World.html",
+		Doc:     "Displays a 3D prism, preserving the aspect ratio.",
+		New:     func() tutorial.App { return new(scene) },
+		Shaders: shaders,
+	})
+}
+
+//go:generate go run github.com/droyo/gltut/cmd/glbind tutorial.vert color.frag
+
+//go:embed tutorial.vert
+var files embed.FS
+
+var shaders = shader.Local(files)
+
+const (
+	zNear float32 = 1.0
+	zFar float32 = 3.0
+)
+
+type scene struct {
+	prog *program
+	mesh *mesh.Mesh
+	fovy float32
+}
+
+func (s *scene) Init(ctx gfx.Context, width, height int) error {
+	ctx.ClearColor(0, 0, 0, 0)
+	ctx.Enable(gfx.CULL_FACE)
+	ctx.CullFace(gfx.BACK)
+	ctx.FrontFace(gfx.CW)
+	
+	vertexData := []float32{
+		0.25, 0.25, -1.25, 1.0,
+		0.25, -0.25, -1.25, 1.0,
+		-0.25, 0.25, -1.25, 1.0,
+
+		0.25, -0.25, -1.25, 1.0,
+		-0.25, -0.25, -1.25, 1.0,
+		-0.25, 0.25, -1.25, 1.0,
+
+		0.25, 0.25, -2.75, 1.0,
+		-0.25, 0.25, -2.75, 1.0,
+		0.25, -0.25, -2.75, 1.0,
+
+		0.25, -0.25, -2.75, 1.0,
+		-0.25, 0.25, -2.75, 1.0,
+		-0.25, -0.25, -2.75, 1.0,
+
+		-0.25, 0.25, -1.25, 1.0,
+		-0.25, -0.25, -1.25, 1.0,
+		-0.25, -0.25, -2.75, 1.0,
+
+		-0.25, 0.25, -1.25, 1.0,
+		-0.25, -0.25, -2.75, 1.0,
+		-0.25, 0.25, -2.75, 1.0,
+
+		0.25, 0.25, -1.25, 1.0,
+		0.25, -0.25, -2.75, 1.0,
+		0.25, -0.25, -1.25, 1.0,
+
+		0.25, 0.25, -1.25, 1.0,
+		0.25, 0.25, -2.75, 1.0,
+		0.25, -0.25, -2.75, 1.0,
+
+		0.25, 0.25, -2.75, 1.0,
+		0.25, 0.25, -1.25, 1.0,
+		-0.25, 0.25, -1.25, 1.0,
+
+		0.25, 0.25, -2.75, 1.0,
+		-0.25, 0.25, -1.25, 1.0,
+		-0.25, 0.25, -2.75, 1.0,
+
+		0.25, -0.25, -2.75, 1.0,
+		-0.25, -0.25, -1.25, 1.0,
+		0.25, -0.25, -1.25, 1.0,
+
+		0.25, -0.25, -2.75, 1.0,
+		-0.25, -0.25, -2.75, 1.0,
+		-0.25, -0.25, -1.25, 1.0,
+
+		0.0, 0.0, 1.0, 1.0,
+		0.0, 0.0, 1.0, 1.0,
+		0.0, 0.0, 1.0, 1.0,
+
+		0.0, 0.0, 1.0, 1.0,
+		0.0, 0.0, 1.0, 1.0,
+		0.0, 0.0, 1.0, 1.0,
+
+		0.8, 0.8, 0.8, 1.0,
+		0.8, 0.8, 0.8, 1.0,
+		0.8, 0.8, 0.8, 1.0,
+
+		0.8, 0.8, 0.8, 1.0,
+		0.8, 0.8, 0.8, 1.0,
+		0.8, 0.8, 0.8, 1.0,
+
+		0.0, 1.0, 0.0, 1.0,
+		0.0, 1.0, 0.0, 1.0,
+		0.0, 1.0, 0.0, 1.0,
+
+		0.0, 1.0, 0.0, 1.0,
+		0.0, 1.0, 0.0, 1.0,
+		0.0, 1.0, 0.0, 1.0,
+
+		0.5, 0.5, 0.0, 1.0,
+		0.5, 0.5, 0.0, 1.0,
+		0.5, 0.5, 0.0, 1.0,
+
+		0.5, 0.5, 0.0, 1.0,
+		0.5, 0.5, 0.0, 1.0,
+		0.5, 0.5, 0.0, 1.0,
+
+		1.0, 0.0, 0.0, 1.0,
+		1.0, 0.0, 0.0, 1.0,
+		1.0, 0.0, 0.0, 1.0,
+
+		1.0, 0.0, 0.0, 1.0,
+		1.0, 0.0, 0.0, 1.0,
+		1.0, 0.0, 0.0, 1.0,
+
+		0.0, 1.0, 1.0, 1.0,
+		0.0, 1.0, 1.0, 1.0,
+		0.0, 1.0, 1.0, 1.0,
+
+		0.0, 1.0, 1.0, 1.0,
+		0.0, 1.0, 1.0, 1.0,
+		0.0, 1.0, 1.0, 1.0,
+	}
+	
+	prog, err := newProgram(ctx, shaders)
+	if err != nil {
+		return err
+	}
+	prog.Use()
+	s.prog = prog
+	
+	s.mesh, err = mesh.New(ctx, prog.Program, &mesh.Data{
+		Layout:    mesh.Sequential(36, mesh.Float("position", 4), mesh.Float("color", 4)),
+		Vertices:  vertexData,
+		Submeshes: []mesh.Submesh{{Mode: gfx.TRIANGLES, Count: 36}},
+	})
+	if err != nil {
+		prog.Delete()
+		return err
+	}
+	
+	s.fovy = vmath.Radians(90)
+	prog.SetOffset(1.5, 0.5)
+	s.Resize(ctx, width, height)
+	return nil
+}
+
+func (s *scene) Resize(ctx gfx.Context, width, height int) {
+	matrix := vmath.Perspective(s.fovy, float32(width) / float32(height), zNear, zFar)
+	s.prog.SetPerspectiveMatrix(matrix)
+	ctx.Viewport(0, 0, width, height)
+}
+
+func (s *scene) Key(ctx gfx.Context, ev display.KeyPress) {}
+
+func (s *scene) Update(dt time.Duration) {}
+
+func (s *scene) Draw(ctx gfx.Context) {
+	ctx.Clear(gfx.COLOR_BUFFER_BIT)
+	s.mesh.Draw()
+}
+
+func (s *scene) Close(ctx gfx.Context) {
+	s.mesh.Close()
+	s.prog.Delete()
+}
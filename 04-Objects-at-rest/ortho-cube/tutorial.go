@@ -0,0 +1,181 @@
+// Package orthocube displays a prism in 3D space without perspective projection.
+// It is an implementation of http://arcsynthesis.org/gltut/Positioning/Tutorial%2004.html
+package orthocube
+
+import (
+	"embed"
+	"time"
+	"aqwari.net/exp/display"
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/mesh"
+	"github.com/droyo/gltut/internal/shader"
+	"github.com/droyo/gltut/internal/tutorial"
+)
+
+func init() {
+	tutorial.Register(&tutorial.Tutorial{
+		Chapter: 4,
+		Section: 1,
+		Name:    "ortho-cube",
+		Title:   "Orthographic Cube",
+		URL:     "http://arcsynthesis.org/gltut/Positioning/Tutorial%2004.html",
+		Doc:     "Displays a prism in 3D space without perspective projection.",
+		New:     func() tutorial.App { return new(scene) },
+		Shaders: shaders,
+	})
+}
+
+//go:generate go run github.com/droyo/gltut/cmd/glbind tutorial.vert color.frag
+
+//go:embed tutorial.vert
+var files embed.FS
+
+var shaders = shader.Local(files)
+
+type scene struct {
+	prog    *program
+	mesh    *mesh.Mesh
+}
+
+func (s *scene) Init(ctx gfx.Context, width, height int) error {
+	ctx.ClearColor(0, 0, 0, 0)
+	ctx.Enable(gfx.CULL_FACE)
+	ctx.CullFace(gfx.BACK)
+	ctx.FrontFace(gfx.CW)
+	
+	vertexData := []float32{
+		0.25, 0.25, 0.75, 1.0,
+		0.25, -0.25, 0.75, 1.0,
+		-0.25, 0.25, 0.75, 1.0,
+
+		0.25, -0.25, 0.75, 1.0,
+		-0.25, -0.25, 0.75, 1.0,
+		-0.25, 0.25, 0.75, 1.0,
+
+		0.25, 0.25, -0.75, 1.0,
+		-0.25, 0.25, -0.75, 1.0,
+		0.25, -0.25, -0.75, 1.0,
+
+		0.25, -0.25, -0.75, 1.0,
+		-0.25, 0.25, -0.75, 1.0,
+		-0.25, -0.25, -0.75, 1.0,
+
+		-0.25, 0.25, 0.75, 1.0,
+		-0.25, -0.25, 0.75, 1.0,
+		-0.25, -0.25, -0.75, 1.0,
+
+		-0.25, 0.25, 0.75, 1.0,
+		-0.25, -0.25, -0.75, 1.0,
+		-0.25, 0.25, -0.75, 1.0,
+
+		0.25, 0.25, 0.75, 1.0,
+		0.25, -0.25, -0.75, 1.0,
+		0.25, -0.25, 0.75, 1.0,
+
+		0.25, 0.25, 0.75, 1.0,
+		0.25, 0.25, -0.75, 1.0,
+		0.25, -0.25, -0.75, 1.0,
+
+		0.25, 0.25, -0.75, 1.0,
+		0.25, 0.25, 0.75, 1.0,
+		-0.25, 0.25, 0.75, 1.0,
+
+		0.25, 0.25, -0.75, 1.0,
+		-0.25, 0.25, 0.75, 1.0,
+		-0.25, 0.25, -0.75, 1.0,
+
+		0.25, -0.25, -0.75, 1.0,
+		-0.25, -0.25, 0.75, 1.0,
+		0.25, -0.25, 0.75, 1.0,
+
+		0.25, -0.25, -0.75, 1.0,
+		-0.25, -0.25, -0.75, 1.0,
+		-0.25, -0.25, 0.75, 1.0,
+
+		0.0, 0.0, 1.0, 1.0,
+		0.0, 0.0, 1.0, 1.0,
+		0.0, 0.0, 1.0, 1.0,
+
+		0.0, 0.0, 1.0, 1.0,
+		0.0, 0.0, 1.0, 1.0,
+		0.0, 0.0, 1.0, 1.0,
+
+		0.8, 0.8, 0.8, 1.0,
+		0.8, 0.8, 0.8, 1.0,
+		0.8, 0.8, 0.8, 1.0,
+
+		0.8, 0.8, 0.8, 1.0,
+		0.8, 0.8, 0.8, 1.0,
+		0.8, 0.8, 0.8, 1.0,
+
+		0.0, 1.0, 0.0, 1.0,
+		0.0, 1.0, 0.0, 1.0,
+		0.0, 1.0, 0.0, 1.0,
+
+		0.0, 1.0, 0.0, 1.0,
+		0.0, 1.0, 0.0, 1.0,
+		0.0, 1.0, 0.0, 1.0,
+
+		0.5, 0.5, 0.0, 1.0,
+		0.5, 0.5, 0.0, 1.0,
+		0.5, 0.5, 0.0, 1.0,
+
+		0.5, 0.5, 0.0, 1.0,
+		0.5, 0.5, 0.0, 1.0,
+		0.5, 0.5, 0.0, 1.0,
+
+		1.0, 0.0, 0.0, 1.0,
+		1.0, 0.0, 0.0, 1.0,
+		1.0, 0.0, 0.0, 1.0,
+
+		1.0, 0.0, 0.0, 1.0,
+		1.0, 0.0, 0.0, 1.0,
+		1.0, 0.0, 0.0, 1.0,
+
+		0.0, 1.0, 1.0, 1.0,
+		0.0, 1.0, 1.0, 1.0,
+		0.0, 1.0, 1.0, 1.0,
+
+		0.0, 1.0, 1.0, 1.0,
+		0.0, 1.0, 1.0, 1.0,
+		0.0, 1.0, 1.0, 1.0,
+	}
+	
+	prog, err := newProgram(ctx, shaders)
+	if err != nil {
+		return err
+	}
+	prog.Use()
+	s.prog = prog
+	
+	s.mesh, err = mesh.New(ctx, prog.Program, &mesh.Data{
+		Layout:    mesh.Sequential(36, mesh.Float("position", 4), mesh.Float("color", 4)),
+		Vertices:  vertexData,
+		Submeshes: []mesh.Submesh{{Mode: gfx.TRIANGLES, Count: 36}},
+	})
+	if err != nil {
+		prog.Delete()
+		return err
+	}
+	
+	prog.SetOffset(0.5, 0.25)
+	return nil
+}
+
+func (s *scene) Resize(ctx gfx.Context, width, height int) {
+	ctx.Viewport(0, 0, width, height)
+}
+
+func (s *scene) Key(ctx gfx.Context, ev display.KeyPress) {}
+
+func (s *scene) Update(dt time.Duration) {}
+
+func (s *scene) Draw(ctx gfx.Context) {
+	ctx.Clear(gfx.COLOR_BUFFER_BIT)
+	s.mesh.Draw()
+}
+
+func (s *scene) Close(ctx gfx.Context) {
+	s.mesh.Close()
+	s.prog.Delete()
+}
@@ -0,0 +1,16 @@
+#version 150
+
+in vec4 position;
+in vec4 color;
+
+smooth out vec4 theColor;
+
+uniform vec3 offset;
+uniform mat4 perspectiveMatrix;
+
+void main()
+{
+	vec4 camera = position + vec4(offset, 0);
+	gl_Position = perspectiveMatrix * camera;
+	theColor = color;
+}
@@ -0,0 +1,198 @@
+// Package overlapnodepth displays two objects without depth buffering enabled.
+// It is an implementation of http://arcsynthesis.org/gltut/Positioning/Tutorial%2005.html
+package overlapnodepth
+
+import (
+	"embed"
+	"time"
+	"aqwari.net/exp/display"
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/mesh"
+	"github.com/droyo/gltut/internal/shader"
+	"github.com/droyo/gltut/internal/tutorial"
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+func init() {
+	tutorial.Register(&tutorial.Tutorial{
+		Chapter: 5,
+		Section: 1,
+		Name:    "overlap-no-depth",
+		Title:   "Overlap No Depth",
+		URL:     "http://arcsynthesis.org/gltut/Positioning/Tutorial%2005.html",
+		Doc:     "Displays two objects without depth buffering enabled.",
+		New:     func() tutorial.App { return new(scene) },
+		Shaders: shaders,
+	})
+}
+
+//go:generate go run github.com/droyo/gltut/cmd/glbind tutorial.vert color.frag
+
+//go:embed tutorial.vert
+var files embed.FS
+
+var shaders = shader.Local(files)
+
+const (
+	zNear float32 = 1.0
+	zFar float32 = 3.0
+)
+
+type scene struct {
+	prog *program
+	mesh *mesh.Mesh
+	fovy float32
+}
+
+func (s *scene) Init(ctx gfx.Context, width, height int) error {
+	ctx.ClearColor(0, 0, 0, 0)
+	ctx.Enable(gfx.CULL_FACE)
+	ctx.CullFace(gfx.BACK)
+	ctx.FrontFace(gfx.CW)
+	
+	const (
+		RightExtent = 0.8
+		LeftExtent = -RightExtent
+		TopExtent = 0.2
+		MiddleExtent = 0.0
+		BottomExtent = -TopExtent
+		FrontExtent = -1.25
+		RearExtent = -1.75		
+	)
+	
+	var (
+		Green = []float32{0.75, 0.75, 1.00, 1.00}
+		Blue  = []float32{0.00, 0.50, 0.00, 1.00}
+		Red   = []float32{1.00, 0.00, 0.00, 1.00}
+		Grey  = []float32{0.80, 0.80, 0.80, 0.80}
+		Brown = []float32{0.50, 0.50, 0.00, 1.00}
+	)
+	
+	vertexData := []float32{
+		LeftExtent, TopExtent, RearExtent,
+		LeftExtent, MiddleExtent, FrontExtent,
+		RightExtent, MiddleExtent, FrontExtent,
+		RightExtent, MiddleExtent, RearExtent,
+
+		LeftExtent, BottomExtent, RearExtent,
+		LeftExtent, MiddleExtent, FrontExtent,
+		RightExtent, MiddleExtent, FrontExtent,
+		RightExtent, BottomExtent, RearExtent,
+
+		LeftExtent, TopExtent, RearExtent,
+		LeftExtent, MiddleExtent, FrontExtent,
+		LeftExtent, BottomExtent, RearExtent,
+
+		RightExtent, TopExtent, RearExtent,
+		RightExtent, MiddleExtent, FrontExtent,
+		RightExtent, BottomExtent, RearExtent,
+
+		LeftExtent, BottomExtent, RearExtent,
+		LeftExtent, TopExtent, RearExtent,
+		RightExtent, TopExtent, RearExtent,
+		RightExtent, BottomExtent, RearExtent,
+
+		//Object 2 positions
+		TopExtent, RightExtent, RearExtent,
+		MiddleExtent, RightExtent, FrontExtent,
+		MiddleExtent, LeftExtent, FrontExtent,
+		TopExtent, LeftExtent, RearExtent,
+
+		BottomExtent, RightExtent, RearExtent,
+		MiddleExtent, RightExtent, FrontExtent,
+		MiddleExtent, LeftExtent, FrontExtent,
+		BottomExtent, LeftExtent, RearExtent,
+
+		TopExtent, RightExtent, RearExtent,
+		MiddleExtent, RightExtent, FrontExtent,
+		BottomExtent, RightExtent, RearExtent,
+
+		TopExtent, LeftExtent, RearExtent,
+		MiddleExtent, LeftExtent, FrontExtent,
+		BottomExtent, LeftExtent, RearExtent,
+
+		BottomExtent, RightExtent, RearExtent,
+		TopExtent, RightExtent, RearExtent,
+		TopExtent, LeftExtent, RearExtent,
+		BottomExtent, LeftExtent, RearExtent,
+	}
+	
+	// Object 1 colors
+	for _, col := range [...][]float32{Green, Blue, Red, Grey, Brown} {
+		for i := 0 ; i < 4 ; i++ {
+			vertexData = append(vertexData, col...)
+		}
+	}
+	
+	// Object 2 colors
+	for _, col := range [...][]float32{Red, Brown, Blue, Green, Grey} {
+		for i := 0 ; i < 4 ; i++ {
+			vertexData = append(vertexData, col...)
+		}
+	}
+	
+	indices := []uint16 {
+		0, 2, 1,
+		3, 2, 0,
+
+		4, 5, 6,
+		6, 7, 4,
+
+		8, 9, 10,
+		11, 13, 12,
+
+		14, 16, 15,
+		17, 16, 14,
+	}
+	
+	prog, err := newProgram(ctx, shaders)
+	if err != nil {
+		return err
+	}
+	prog.Use()
+	s.prog = prog
+	
+	// The second object's vertices follow the first's, in the
+	// same order, so both objects share the same indices.
+	s.mesh, err = mesh.New(ctx, prog.Program, &mesh.Data{
+		Layout:   mesh.Sequential(36, mesh.Float("position", 3), mesh.Float("color", 4)),
+		Vertices: vertexData,
+		Indices:  indices,
+		Submeshes: []mesh.Submesh{
+			{Name: "object1", Mode: gfx.TRIANGLES, Indexed: true, Count: len(indices)},
+			{Name: "object2", Mode: gfx.TRIANGLES, Indexed: true, Count: len(indices), BaseVertex: 36 / 2},
+		},
+	})
+	if err != nil {
+		prog.Delete()
+		return err
+	}
+	
+	s.fovy = vmath.Radians(90)
+	s.Resize(ctx, width, height)
+	return nil
+}
+
+func (s *scene) Resize(ctx gfx.Context, width, height int) {
+	matrix := vmath.Perspective(s.fovy, float32(width) / float32(height), zNear, zFar)
+	s.prog.SetPerspectiveMatrix(matrix)
+	ctx.Viewport(0, 0, width, height)
+}
+
+func (s *scene) Key(ctx gfx.Context, ev display.KeyPress) {}
+
+func (s *scene) Update(dt time.Duration) {}
+
+func (s *scene) Draw(ctx gfx.Context) {
+	ctx.Clear(gfx.COLOR_BUFFER_BIT)
+	s.prog.SetOffset(vmath.Vec3{0, 0, 0})
+	s.mesh.DrawSubmesh("object1")
+	
+	s.prog.SetOffset(vmath.Vec3{0, 0, -1})
+	s.mesh.DrawSubmesh("object2")
+}
+
+func (s *scene) Close(ctx gfx.Context) {
+	s.mesh.Close()
+	s.prog.Delete()
+}
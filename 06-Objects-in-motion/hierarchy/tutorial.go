@@ -0,0 +1,364 @@
+// Package hierarchy draws a robot arm whose parts are positioned
+// relative to each other with a matrix stack.
+// It is an implementation of http://arcsynthesis.org/gltut/Positioning/Tut06%20Fun%20with%20Matrices.html
+//
+// Keys:
+//
+//	A, D  turn the base
+//	W, S  raise and lower the upper arm
+//	R, F  raise and lower the lower arm
+//	T, G  raise and lower the wrist
+//	Z, X  roll the wrist
+//	Q, E  open and close the fingers
+//	Space print the current pose
+package hierarchy
+
+import (
+	"embed"
+	"fmt"
+	"log"
+	"math"
+	"time"
+	"aqwari.net/exp/display"
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/mesh"
+	"github.com/droyo/gltut/internal/shader"
+	"github.com/droyo/gltut/internal/tutorial"
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+func init() {
+	tutorial.Register(&tutorial.Tutorial{
+		Chapter: 6,
+		Section: 4,
+		Name:    "hierarchy",
+		Title:   "Hierarchy",
+		URL:     "http://arcsynthesis.org/gltut/Positioning/Tut06%20Fun%20with%20Matrices.html",
+		Doc:     "Moves the joints of a robot arm drawn with a matrix stack.",
+		New:     func() tutorial.App { return new(scene) },
+		Shaders: shaders,
+	})
+}
+
+//go:generate go run github.com/droyo/gltut/cmd/glbind tutorial.vert color.frag
+
+//go:embed tutorial.vert
+var files embed.FS
+
+var shaders = shader.Local(files)
+
+// Angles are in degrees, as in the original tutorial.
+const (
+	standardAngleIncrement = 11.25
+	smallAngleIncrement = 9
+)
+
+// A pose is the angle of every joint of the arm.
+type pose struct {
+	Base       float32
+	UpperArm   float32
+	LowerArm   float32
+	WristPitch float32
+	WristRoll  float32
+	FingerOpen float32
+}
+
+var initialPose = pose{
+	Base:       -45,
+	UpperArm:   -33.75,
+	LowerArm:   146.25,
+	WristPitch: 67.5,
+	WristRoll:  0,
+	FingerOpen: 180,
+}
+
+func (p pose) String() string {
+	return fmt.Sprintf("base %g, upper arm %g, lower arm %g, wrist pitch %g, wrist roll %g, finger open %g",
+		p.Base, p.UpperArm, p.LowerArm, p.WristPitch, p.WristRoll, p.FingerOpen)
+}
+
+func clamp(x, lo, hi float32) float32 {
+	return float32(math.Max(float64(lo), math.Min(float64(hi), float64(x))))
+}
+
+// wrap keeps an angle that can turn all the way around within
+// (-360, 360).
+func wrap(deg float32) float32 {
+	return float32(math.Mod(float64(deg), 360))
+}
+
+func (p *pose) AdjBase(d float32)       { p.Base = wrap(p.Base + d) }
+func (p *pose) AdjUpperArm(d float32)   { p.UpperArm = clamp(p.UpperArm + d, -90, 0) }
+func (p *pose) AdjLowerArm(d float32)   { p.LowerArm = clamp(p.LowerArm + d, 0, 146.25) }
+func (p *pose) AdjWristPitch(d float32) { p.WristPitch = clamp(p.WristPitch + d, 0, 90) }
+func (p *pose) AdjWristRoll(d float32)  { p.WristRoll = wrap(p.WristRoll + d) }
+func (p *pose) AdjFingerOpen(d float32) { p.FingerOpen = clamp(p.FingerOpen + d, 9, 180) }
+
+// The sizes and offsets of the parts of the arm. Every part is the
+// same cube, two units on a side, scaled to size.
+var (
+	posBase = vmath.Vec3{3, -5, -40}
+	posBaseLeft = vmath.Vec3{2, 0, 0}
+	posBaseRight = vmath.Vec3{-2, 0, 0}
+	posLowerArm = vmath.Vec3{0, 0, 8}
+	posWrist = vmath.Vec3{0, 0, 5}
+	posLeftFinger = vmath.Vec3{1, 0, 1}
+	posRightFinger = vmath.Vec3{-1, 0, 1}
+)
+
+const (
+	scaleBaseZ float32 = 3
+	sizeUpperArm float32 = 9
+	lenLowerArm float32 = 5
+	widthLowerArm float32 = 1.5
+	lenWrist float32 = 2
+	widthWrist float32 = 2
+	lenFinger float32 = 2
+	widthFinger float32 = 0.5
+	angLowerFinger float32 = 45
+)
+
+const (
+	zNear float32 = 1
+	zFar float32 = 100
+)
+
+type scene struct {
+	prog *program
+	mesh *mesh.Mesh
+	fovy float32
+	pose pose
+	keys tutorial.Keymap
+}
+
+func (s *scene) Init(ctx gfx.Context, width, height int) error {
+	ctx.ClearColor(0, 0, 0, 0)
+	ctx.ClearDepth(1)
+	ctx.Enable(gfx.CULL_FACE)
+	ctx.Enable(gfx.DEPTH_TEST)
+	ctx.DepthFunc(gfx.LEQUAL)
+	ctx.DepthMask(true)
+	ctx.DepthRange(0, 1)
+	ctx.CullFace(gfx.BACK)
+	ctx.FrontFace(gfx.CW)
+
+	var (
+		Green   = []float32{0, 1, 0, 1}
+		Blue    = []float32{0, 0, 1, 1}
+		Red     = []float32{1, 0, 0, 1}
+		Yellow  = []float32{1, 1, 0, 1}
+		Cyan    = []float32{0, 1, 1, 1}
+		Magenta = []float32{1, 0, 1, 1}
+	)
+
+	vertexData := []float32{
+		// Front
+		+1, +1, +1,
+		+1, -1, +1,
+		-1, -1, +1,
+		-1, +1, +1,
+
+		// Top
+		+1, +1, +1,
+		-1, +1, +1,
+		-1, +1, -1,
+		+1, +1, -1,
+
+		// Left
+		+1, +1, +1,
+		+1, +1, -1,
+		+1, -1, -1,
+		+1, -1, +1,
+
+		// Back
+		+1, +1, -1,
+		-1, +1, -1,
+		-1, -1, -1,
+		+1, -1, -1,
+
+		// Bottom
+		+1, -1, +1,
+		+1, -1, -1,
+		-1, -1, -1,
+		-1, -1, +1,
+
+		// Right
+		-1, +1, +1,
+		-1, -1, +1,
+		-1, -1, -1,
+		-1, +1, -1,
+	}
+
+	// One color per face
+	for _, col := range [...][]float32{Green, Blue, Red, Yellow, Cyan, Magenta} {
+		for i := 0; i < 4; i++ {
+			vertexData = append(vertexData, col...)
+		}
+	}
+
+	var indices []uint16
+	for face := uint16(0); face < 6; face++ {
+		v := face * 4
+		indices = append(indices, v, v + 1, v + 2, v + 2, v + 3, v)
+	}
+
+	prog, err := newProgram(ctx, shaders)
+	if err != nil {
+		return err
+	}
+	prog.Use()
+	s.prog = prog
+
+	s.mesh, err = mesh.New(ctx, prog.Program, &mesh.Data{
+		Layout:    mesh.Sequential(24, mesh.Float("position", 3), mesh.Float("color", 4)),
+		Vertices:  vertexData,
+		Indices:   indices,
+		Submeshes: []mesh.Submesh{{Mode: gfx.TRIANGLES, Indexed: true, Count: len(indices)}},
+	})
+	if err != nil {
+		prog.Delete()
+		return err
+	}
+
+	s.fovy = vmath.Radians(45)
+	s.pose = initialPose
+
+	p := &s.pose
+	s.keys = tutorial.Keymap{
+		display.KeyA: func() { p.AdjBase(standardAngleIncrement) },
+		display.KeyD: func() { p.AdjBase(-standardAngleIncrement) },
+		display.KeyW: func() { p.AdjUpperArm(-standardAngleIncrement) },
+		display.KeyS: func() { p.AdjUpperArm(standardAngleIncrement) },
+		display.KeyR: func() { p.AdjLowerArm(-standardAngleIncrement) },
+		display.KeyF: func() { p.AdjLowerArm(standardAngleIncrement) },
+		display.KeyT: func() { p.AdjWristPitch(-standardAngleIncrement) },
+		display.KeyG: func() { p.AdjWristPitch(standardAngleIncrement) },
+		display.KeyZ: func() { p.AdjWristRoll(standardAngleIncrement) },
+		display.KeyX: func() { p.AdjWristRoll(-standardAngleIncrement) },
+		display.KeyQ: func() { p.AdjFingerOpen(smallAngleIncrement) },
+		display.KeyE: func() { p.AdjFingerOpen(-smallAngleIncrement) },
+		display.KeySpace: func() { log.Print(p) },
+	}
+	s.Resize(ctx, width, height)
+	return nil
+}
+
+func (s *scene) Resize(ctx gfx.Context, width, height int) {
+	matrix := vmath.Perspective(s.fovy, float32(width) / float32(height), zNear, zFar)
+	s.prog.SetCameraToClipMatrix(matrix)
+	ctx.Viewport(0, 0, width, height)
+}
+
+func (s *scene) Key(ctx gfx.Context, ev display.KeyPress) {
+	s.keys.Handle(ev)
+}
+
+func (s *scene) Update(dt time.Duration) {}
+
+// drawCube draws the cube with the transform at the top of the stack.
+func (s *scene) drawCube(stack *vmath.MatrixStack) {
+	s.prog.SetModelToCameraMatrix(stack.Top())
+	s.mesh.Draw()
+}
+
+func (s *scene) Draw(ctx gfx.Context) {
+	ctx.Clear(gfx.COLOR_BUFFER_BIT | gfx.DEPTH_BUFFER_BIT)
+
+	var stack vmath.MatrixStack
+	stack.Translate(posBase)
+	stack.RotateY(vmath.Radians(s.pose.Base))
+
+	// Left base
+	stack.Push()
+	stack.Translate(posBaseLeft)
+	stack.Scale(vmath.Vec3{1, 1, scaleBaseZ})
+	s.drawCube(&stack)
+	stack.Pop()
+
+	// Right base
+	stack.Push()
+	stack.Translate(posBaseRight)
+	stack.Scale(vmath.Vec3{1, 1, scaleBaseZ})
+	s.drawCube(&stack)
+	stack.Pop()
+
+	s.drawUpperArm(&stack)
+}
+
+func (s *scene) drawUpperArm(stack *vmath.MatrixStack) {
+	stack.Push()
+	stack.RotateX(vmath.Radians(s.pose.UpperArm))
+
+	stack.Push()
+	stack.Translate(vmath.Vec3{0, 0, sizeUpperArm / 2 - 1})
+	stack.Scale(vmath.Vec3{1, 1, sizeUpperArm / 2})
+	s.drawCube(stack)
+	stack.Pop()
+
+	s.drawLowerArm(stack)
+	stack.Pop()
+}
+
+func (s *scene) drawLowerArm(stack *vmath.MatrixStack) {
+	stack.Push()
+	stack.Translate(posLowerArm)
+	stack.RotateX(vmath.Radians(s.pose.LowerArm))
+
+	stack.Push()
+	stack.Translate(vmath.Vec3{0, 0, lenLowerArm / 2})
+	stack.Scale(vmath.Vec3{widthLowerArm / 2, widthLowerArm / 2, lenLowerArm / 2})
+	s.drawCube(stack)
+	stack.Pop()
+
+	s.drawWrist(stack)
+	stack.Pop()
+}
+
+func (s *scene) drawWrist(stack *vmath.MatrixStack) {
+	stack.Push()
+	stack.Translate(posWrist)
+	stack.RotateZ(vmath.Radians(s.pose.WristRoll))
+	stack.RotateX(vmath.Radians(s.pose.WristPitch))
+
+	stack.Push()
+	stack.Scale(vmath.Vec3{widthWrist / 2, widthWrist / 2, lenWrist / 2})
+	s.drawCube(stack)
+	stack.Pop()
+
+	s.drawFinger(stack, posLeftFinger, 1)
+	s.drawFinger(stack, posRightFinger, -1)
+	stack.Pop()
+}
+
+// drawFinger draws one of the two fingers, which mirror each other:
+// side is 1 for the left finger and -1 for the right.
+func (s *scene) drawFinger(stack *vmath.MatrixStack, offset vmath.Vec3, side float32) {
+	stack.Push()
+	stack.Translate(offset)
+	stack.RotateY(vmath.Radians(side * s.pose.FingerOpen))
+
+	stack.Push()
+	stack.Translate(vmath.Vec3{0, 0, lenFinger / 2})
+	stack.Scale(vmath.Vec3{widthFinger / 2, widthFinger / 2, lenFinger / 2})
+	s.drawCube(stack)
+	stack.Pop()
+
+	// Lower finger
+	stack.Push()
+	stack.Translate(vmath.Vec3{0, 0, lenFinger})
+	stack.RotateY(vmath.Radians(-side * angLowerFinger))
+
+	stack.Push()
+	stack.Translate(vmath.Vec3{0, 0, lenFinger / 2})
+	stack.Scale(vmath.Vec3{widthFinger / 2, widthFinger / 2, lenFinger / 2})
+	s.drawCube(stack)
+	stack.Pop()
+
+	stack.Pop()
+	stack.Pop()
+}
+
+func (s *scene) Close(ctx gfx.Context) {
+	s.mesh.Close()
+	s.prog.Delete()
+}
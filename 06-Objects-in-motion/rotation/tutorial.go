@@ -0,0 +1,200 @@
+// Package rotation spins objects around with rotation matrices.
+// It is an implementation of http://arcsynthesis.org/gltut/Positioning/Tut06%20Rotation.html
+package rotation
+
+import (
+	"embed"
+	"time"
+	"math"
+	"aqwari.net/exp/display"
+	"github.com/droyo/gltut/internal/clock"
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/mesh"
+	"github.com/droyo/gltut/internal/shader"
+	"github.com/droyo/gltut/internal/tutorial"
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+func init() {
+	tutorial.Register(&tutorial.Tutorial{
+		Chapter:  6,
+		Section:  3,
+		Name:     "rotation",
+		Title:    "Rotation",
+		URL:      "http://arcsynthesis.org/gltut/Positioning/Tut06%20Rotation.html",
+		Doc:      "Spins objects around with rotation matrices.",
+		New:      func() tutorial.App { return new(scene) },
+		Shaders:  shaders,
+		Animated: true,
+	})
+}
+
+//go:generate go run github.com/droyo/gltut/cmd/glbind tutorial.vert color.frag
+
+//go:embed tutorial.vert
+var files embed.FS
+
+var shaders = shader.Local(files)
+
+// angle returns how far an object that turns once every period has
+// turned after elapsed, in radians.
+func angle(elapsed, period time.Duration) float32 {
+	return 2 * math.Pi * clock.Phase(elapsed, period)
+}
+
+// NullRotation leaves an object as it is.
+func NullRotation(elapsed time.Duration) vmath.Mat4 {
+	return vmath.Ident4()
+}
+
+// RotateX turns an object around the X axis every 3 seconds.
+func RotateX(elapsed time.Duration) vmath.Mat4 {
+	return vmath.RotateX(angle(elapsed, time.Second * 3))
+}
+
+// RotateY turns an object around the Y axis every 2 seconds.
+func RotateY(elapsed time.Duration) vmath.Mat4 {
+	return vmath.RotateY(angle(elapsed, time.Second * 2))
+}
+
+// RotateZ turns an object around the Z axis every 2 seconds.
+func RotateZ(elapsed time.Duration) vmath.Mat4 {
+	return vmath.RotateZ(angle(elapsed, time.Second * 2))
+}
+
+// RotateAxis turns an object around the diagonal (1, 1, 1) every
+// 2 seconds.
+func RotateAxis(elapsed time.Duration) vmath.Mat4 {
+	return vmath.Rotate(angle(elapsed, time.Second * 2), vmath.Vec3{1, 1, 1})
+}
+
+// An instance is one copy of the object in the scene.
+type instance struct {
+	rotate func(time.Duration) vmath.Mat4
+	offset vmath.Vec3
+}
+
+func (i instance) Matrix(elapsed time.Duration) vmath.Mat4 {
+	return vmath.Translate(i.offset).Mul(i.rotate(elapsed))
+}
+
+var instances = []instance{
+	{NullRotation, vmath.Vec3{0, 0, -25}},
+	{RotateX, vmath.Vec3{-5, -5, -25}},
+	{RotateY, vmath.Vec3{-5, 5, -25}},
+	{RotateZ, vmath.Vec3{5, 5, -25}},
+	{RotateAxis, vmath.Vec3{5, -5, -25}},
+}
+
+const (
+	zNear float32 = 1
+	zFar float32 = 61
+)
+
+type scene struct {
+	prog    *program
+	mesh    *mesh.Mesh
+	fovy    float32
+	elapsed time.Duration
+}
+
+func (s *scene) Init(ctx gfx.Context, width, height int) error {
+	ctx.ClearColor(0, 0, 0, 0)
+	ctx.ClearDepth(1)
+	ctx.Enable(gfx.CULL_FACE)
+	ctx.Enable(gfx.DEPTH_TEST)
+	ctx.DepthFunc(gfx.LEQUAL)
+	ctx.DepthMask(true)
+	ctx.DepthRange(0, 1)
+	ctx.CullFace(gfx.BACK)
+	ctx.FrontFace(gfx.CW)
+	
+	var (
+		Green = []float32{0.75, 0.75, 1.00, 1.00}
+		Blue  = []float32{0.00, 0.50, 0.00, 1.00}
+		Red   = []float32{1.00, 0.00, 0.00, 1.00}
+		Brown = []float32{0.50, 0.50, 0.00, 1.00}
+	)
+	
+	vertexData := []float32{
+		+1, +1, +1,
+		-1, -1, +1,
+		-1, +1, -1,
+		+1, -1, -1,
+		
+		-1, -1, -1,
+		+1, +1, -1,
+		+1, -1, +1,
+		-1, +1, +1,
+	}
+	
+	// Object 1 colors
+	for _, col := range [...][]float32{Green, Blue, Red, Brown} {
+		vertexData = append(vertexData, col...)
+	}
+	
+	// Object 2 colors
+	for _, col := range [...][]float32{Green, Blue, Red, Brown} {
+		vertexData = append(vertexData, col...)
+	}
+	
+	indices := []uint16 {
+		0, 1, 2,
+		1, 0, 3,
+		2, 3, 0,
+		3, 2, 1,
+
+		5, 4, 6,
+		4, 5, 7,
+		7, 6, 4,
+		6, 7, 5,
+	}
+	
+	prog, err := newProgram(ctx, shaders)
+	if err != nil {
+		return err
+	}
+	prog.Use()
+	s.prog = prog
+	
+	s.mesh, err = mesh.New(ctx, prog.Program, &mesh.Data{
+		Layout:    mesh.Sequential(8, mesh.Float("position", 3), mesh.Float("color", 4)),
+		Vertices:  vertexData,
+		Indices:   indices,
+		Submeshes: []mesh.Submesh{{Mode: gfx.TRIANGLES, Indexed: true, Count: len(indices)}},
+	})
+	if err != nil {
+		prog.Delete()
+		return err
+	}
+	
+	s.fovy = vmath.Radians(45)
+	s.Resize(ctx, width, height)
+	return nil
+}
+
+func (s *scene) Resize(ctx gfx.Context, width, height int) {
+	matrix := vmath.Perspective(s.fovy, float32(width) / float32(height), zNear, zFar)
+	s.prog.SetCameraToClipMatrix(matrix)
+	ctx.Viewport(0, 0, width, height)
+}
+
+func (s *scene) Key(ctx gfx.Context, ev display.KeyPress) {}
+
+func (s *scene) Update(dt time.Duration) {
+	s.elapsed += dt
+}
+
+func (s *scene) Draw(ctx gfx.Context) {
+	ctx.Clear(gfx.COLOR_BUFFER_BIT | gfx.DEPTH_BUFFER_BIT)
+	
+	for _, inst := range instances {
+		s.prog.SetModelToCameraMatrix(inst.Matrix(s.elapsed))
+		s.mesh.Draw()
+	}
+}
+
+func (s *scene) Close(ctx gfx.Context) {
+	s.mesh.Close()
+	s.prog.Delete()
+}
@@ -0,0 +1,190 @@
+// Package translation moves objects around the scene with translation matrices.
+// It is an implementation of http://arcsynthesis.org/gltut/Positioning/Tutorial%2006.html
+package translation
+
+import (
+	"embed"
+	"time"
+	"math"
+	"aqwari.net/exp/display"
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/mesh"
+	"github.com/droyo/gltut/internal/shader"
+	"github.com/droyo/gltut/internal/tutorial"
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+func init() {
+	tutorial.Register(&tutorial.Tutorial{
+		Chapter:  6,
+		Section:  1,
+		Name:     "translation",
+		Title:    "Translation",
+		URL:      "http://arcsynthesis.org/gltut/Positioning/Tutorial%2006.html",
+		Doc:      "Moves objects around the scene with translation matrices.",
+		New:      func() tutorial.App { return new(scene) },
+		Shaders:  shaders,
+		Animated: true,
+	})
+}
+
+//go:generate go run github.com/droyo/gltut/cmd/glbind tutorial.vert color.frag
+
+//go:embed tutorial.vert
+var files embed.FS
+
+var shaders = shader.Local(files)
+
+func UpdateOval(elapsed time.Duration) vmath.Mat4 {
+	π := float64(math.Pi)
+	period := time.Second * 3
+	scale := π * 2 / period.Seconds()
+	
+	pos := (elapsed % period).Seconds()
+	return vmath.Translate(vmath.Vec3{
+		float32(math.Cos(pos * scale) * 4),
+		float32(math.Sin(pos * scale) * 6),
+		-20,
+	})
+}
+
+func UpdateCircle(elapsed time.Duration) vmath.Mat4 {
+	π := float64(math.Pi)
+	period := time.Second * 12
+	scale := π * 2 / period.Seconds()
+	
+	pos := (elapsed % period).Seconds()
+	return vmath.Translate(vmath.Vec3{
+		float32(math.Cos(pos * scale) * 5),
+		-3.5,
+		float32(math.Sin(pos * scale) * 5 - 20),
+	})
+}
+
+const (
+	zNear float32 = 1
+	zFar float32 = 45
+)
+
+type scene struct {
+	prog    *program
+	mesh    *mesh.Mesh
+	fovy    float32
+	elapsed time.Duration
+}
+
+func (s *scene) Init(ctx gfx.Context, width, height int) error {
+	ctx.ClearColor(0, 0, 0, 0)
+	ctx.ClearDepth(1)
+	ctx.Enable(gfx.CULL_FACE)
+	ctx.Enable(gfx.DEPTH_TEST)
+	ctx.DepthFunc(gfx.LEQUAL)
+	ctx.DepthMask(true)
+	ctx.DepthRange(0, 1)
+	ctx.CullFace(gfx.BACK)
+	ctx.FrontFace(gfx.CW)
+	
+	const (
+		RightExtent = 0.8
+		LeftExtent = -RightExtent
+		TopExtent = 0.2
+		MiddleExtent = 0.0
+		BottomExtent = -TopExtent
+		FrontExtent = -1.25
+		RearExtent = -1.75		
+	)
+	
+	var (
+		Green = []float32{0.75, 0.75, 1.00, 1.00}
+		Blue  = []float32{0.00, 0.50, 0.00, 1.00}
+		Red   = []float32{1.00, 0.00, 0.00, 1.00}
+		Brown = []float32{0.50, 0.50, 0.00, 1.00}
+	)
+	
+	vertexData := []float32{
+		+1, +1, +1,
+		-1, -1, +1,
+		-1, +1, -1,
+		+1, -1, -1,
+		
+		-1, -1, -1,
+		+1, +1, -1,
+		+1, -1, +1,
+		-1, +1, +1,
+	}
+	
+	// Object 1 colors
+	for _, col := range [...][]float32{Green, Blue, Red, Brown} {
+		vertexData = append(vertexData, col...)
+	}
+	
+	// Object 2 colors
+	for _, col := range [...][]float32{Green, Blue, Red, Brown} {
+		vertexData = append(vertexData, col...)
+	}
+	
+	indices := []uint16 {
+		0, 1, 2,
+		1, 0, 3,
+		2, 3, 0,
+		3, 2, 1,
+
+		5, 4, 6,
+		4, 5, 7,
+		7, 6, 4,
+		6, 7, 5,
+	}
+	
+	prog, err := newProgram(ctx, shaders)
+	if err != nil {
+		return err
+	}
+	prog.Use()
+	s.prog = prog
+	
+	s.mesh, err = mesh.New(ctx, prog.Program, &mesh.Data{
+		Layout:    mesh.Sequential(8, mesh.Float("position", 3), mesh.Float("color", 4)),
+		Vertices:  vertexData,
+		Indices:   indices,
+		Submeshes: []mesh.Submesh{{Mode: gfx.TRIANGLES, Indexed: true, Count: len(indices)}},
+	})
+	if err != nil {
+		prog.Delete()
+		return err
+	}
+	
+	s.fovy = vmath.Radians(31.25)
+	s.Resize(ctx, width, height)
+	return nil
+}
+
+func (s *scene) Resize(ctx gfx.Context, width, height int) {
+	matrix := vmath.Perspective(s.fovy, float32(width) / float32(height), zNear, zFar)
+	s.prog.SetCameraToClipMatrix(matrix)
+	ctx.Viewport(0, 0, width, height)
+}
+
+func (s *scene) Key(ctx gfx.Context, ev display.KeyPress) {}
+
+func (s *scene) Update(dt time.Duration) {
+	s.elapsed += dt
+}
+
+func (s *scene) Draw(ctx gfx.Context) {
+	stationary := vmath.Translate(vmath.Vec3{0, 0, -20})
+	ctx.Clear(gfx.COLOR_BUFFER_BIT | gfx.DEPTH_BUFFER_BIT)
+	
+	s.prog.SetModelToCameraMatrix(stationary)
+	s.mesh.Draw()
+	
+	s.prog.SetModelToCameraMatrix(UpdateCircle(s.elapsed))
+	s.mesh.Draw()
+	
+	s.prog.SetModelToCameraMatrix(UpdateOval(s.elapsed))
+	s.mesh.Draw()
+}
+
+func (s *scene) Close(ctx gfx.Context) {
+	s.mesh.Close()
+	s.prog.Delete()
+}
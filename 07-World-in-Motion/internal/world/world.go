@@ -0,0 +1,341 @@
+// Package world builds the scene drawn by the chapter 7 tutorials,
+// a colonnade in a forest on a grassy plain, and the camera that
+// looks at it.
+//
+// Models are placed in world space; the tutorials differ only in
+// how they give their programs the camera's matrices.
+package world
+
+import (
+	"log"
+	"math"
+	"math/rand"
+
+	"aqwari.net/exp/display"
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/glutil"
+	"github.com/droyo/gltut/internal/mesh"
+	"github.com/droyo/gltut/internal/shape"
+	"github.com/droyo/gltut/internal/tutorial"
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+// A Camera looks at a target from a point on a sphere around it.
+// Angles are in degrees.
+type Camera struct {
+	Target vmath.Vec3
+	Radius float32 // distance from the target
+	Theta  float32 // angle around the Y axis, from the X axis
+	Phi    float32 // angle down from the Y axis
+}
+
+// InitialCamera is where the tutorials start the camera.
+var InitialCamera = Camera{
+	Target: vmath.Vec3{0, 0.4, 0},
+	Radius: 150,
+	Theta:  67.5,
+	Phi:    44,
+}
+
+// How far one key press moves the target, one pixel of mouse
+// movement turns the camera, and one click of the wheel zooms.
+const (
+	targetStep float32 = 4
+	orbitStep  float32 = 0.5
+	zoomStep   float32 = 5
+)
+
+// Position returns the position of the camera in world space.
+func (c *Camera) Position() vmath.Vec3 {
+	theta, phi := float64(vmath.Radians(c.Theta)), float64(vmath.Radians(c.Phi))
+	dir := vmath.Vec3{
+		float32(math.Sin(phi) * math.Cos(theta)),
+		float32(math.Cos(phi)),
+		float32(math.Sin(phi) * math.Sin(theta)),
+	}
+	return c.Target.Add(dir.Mul(c.Radius))
+}
+
+// Matrix returns the world-to-camera matrix.
+func (c *Camera) Matrix() vmath.Mat4 {
+	return vmath.LookAt(c.Position(), c.Target, vmath.Vec3{0, 1, 0})
+}
+
+// Orbit moves the camera around the target, keeping it above the
+// ground and off the Y axis, where the look-at matrix is undefined.
+func (c *Camera) Orbit(dtheta, dphi float32) {
+	c.Theta = float32(math.Mod(float64(c.Theta+dtheta), 360))
+	c.Phi = float32(math.Max(11.25, math.Min(89, float64(c.Phi+dphi))))
+}
+
+// Zoom moves the camera towards the target, but never closer than
+// 5 units.
+func (c *Camera) Zoom(d float32) {
+	c.Radius = float32(math.Max(5, float64(c.Radius-d)))
+}
+
+// Keymap returns the keys that move the target: W and S forwards and
+// backwards, A and D left and right, and Q and E up and down. Space
+// logs the target and camera positions.
+func (c *Camera) Keymap() tutorial.Keymap {
+	t := &c.Target
+	return tutorial.Keymap{
+		display.KeyW: func() { t[2] -= targetStep },
+		display.KeyS: func() { t[2] += targetStep },
+		display.KeyA: func() { t[0] -= targetStep },
+		display.KeyD: func() { t[0] += targetStep },
+		display.KeyQ: func() { t[1] += targetStep },
+		display.KeyE: func() { t[1] -= targetStep },
+		display.KeySpace: func() {
+			log.Printf("target %v, camera %v", c.Target, c.Position())
+		},
+	}
+}
+
+// Mouse moves the camera around the target when the mouse is
+// dragged with the left button, and zooms with the wheel.
+func (c *Camera) Mouse(m tutorial.Mouse) {
+	if m.Left && m.Dragging() {
+		c.Orbit(float32(m.DX)*orbitStep, -float32(m.DY)*orbitStep)
+	}
+	c.Zoom(float32(m.Wheel) * zoomStep)
+}
+
+// Every shape in the scene is a submesh of one mesh.Builder, so
+// that they share one set of buffers. There is no lighting yet, so
+// the builder shades each face by how much it faces this direction.
+var light = vmath.Vec3{0.5, 1, 0.3}.Normalize()
+
+// cube adds a unit cube centered on the origin.
+func cube(b *mesh.Builder, name string, color vmath.Vec3) {
+	b.Box(vmath.Vec3{}, vmath.Vec3{1, 1, 1}, color)
+	b.End(name)
+}
+
+// plane adds a unit square in the XZ plane, centered on the
+// origin, facing up.
+func plane(b *mesh.Builder, name string, color vmath.Vec3) {
+	up := vmath.Vec3{0, 1, 0}
+	b.Quad(
+		b.Vertex(vmath.Vec3{-0.5, 0, -0.5}, up, color),
+		b.Vertex(vmath.Vec3{0.5, 0, -0.5}, up, color),
+		b.Vertex(vmath.Vec3{0.5, 0, 0.5}, up, color),
+		b.Vertex(vmath.Vec3{-0.5, 0, 0.5}, up, color))
+	b.End(name)
+}
+
+// segments is how many slices the round shapes are cut into.
+const segments = 24
+
+// cylinder adds a cylinder one unit high and one unit across,
+// centered on the origin, standing on the Y axis.
+func cylinder(b *mesh.Builder, name string, color vmath.Vec3) {
+	shape.Cylinder(0.5, 1, segments, 1).Build(b, vmath.Ident4(), color)
+	b.End(name)
+}
+
+// cone adds a cone one unit high and one unit across, with its
+// base on the XZ plane and its tip on the Y axis.
+func cone(b *mesh.Builder, name string, color vmath.Vec3) {
+	shape.Cone(0.5, 1, segments, 1).Build(b, vmath.Translate(vmath.Vec3{0, 0.5, 0}), color)
+	b.End(name)
+}
+
+var (
+	groundColor = vmath.Vec3{0.302, 0.416, 0.0589}
+	trunkColor  = vmath.Vec3{0.694, 0.4, 0.106}
+	leafColor   = vmath.Vec3{0, 0.6, 0.1}
+	stoneColor  = vmath.Vec3{0.9, 0.9, 0.85}
+	white       = vmath.Vec3{1, 1, 1}
+)
+
+// A tree is a trunk with a cone of leaves on top.
+type tree struct {
+	x, z        float32
+	trunkHeight float32
+	coneHeight  float32
+}
+
+// The colonnade stands at templePos, and is templeWidth wide along
+// X and templeLength long along Z.
+var templePos = vmath.Vec3{20, 0, -10}
+
+const (
+	templeWidth      float32 = 14
+	templeLength     float32 = 20
+	templeBaseHeight float32 = 1
+	templeTopHeight  float32 = 2
+	columnHeight     float32 = 5
+	columnBaseHeight float32 = 0.25
+)
+
+// forest places the trees around the colonnade. The positions are
+// random, but the same every time.
+var forest = func() []tree {
+	var trees []tree
+	r := rand.New(rand.NewSource(7))
+	for len(trees) < 80 {
+		t := tree{
+			x:           r.Float32()*90 - 45,
+			z:           r.Float32()*90 - 45,
+			trunkHeight: 1 + r.Float32()*2,
+			coneHeight:  3 + r.Float32()*3,
+		}
+		near := func(x, z, dx, dz float32) bool {
+			return math.Abs(float64(t.x-x)) < float64(dx) && math.Abs(float64(t.z-z)) < float64(dz)
+		}
+		clearing := near(0, 0, 6, 6)
+		temple := near(templePos[0], templePos[2], templeWidth/2+3, templeLength/2+3)
+		if !clearing && !temple {
+			trees = append(trees, t)
+		}
+	}
+	return trees
+}()
+
+// A Scene holds the meshes of the world, as named submeshes of one
+// mesh.
+type Scene struct {
+	mesh *mesh.Mesh
+}
+
+// New uploads the meshes of the world, feeding their positions and
+// colors to the "position" and "color" inputs of prog. The scene
+// can be drawn with any program whose inputs have the same
+// locations.
+func New(ctx gfx.Context, prog *glutil.Program) (*Scene, error) {
+	b := &mesh.Builder{Light: light}
+	plane(b, "ground", groundColor)
+	cylinder(b, "trunk", trunkColor)
+	cone(b, "leaves", leafColor)
+	cylinder(b, "column", stoneColor)
+	cube(b, "stone", stoneColor)
+	cube(b, "marker", white)
+	m, err := mesh.New(ctx, prog, b.Data())
+	if err != nil {
+		return nil, err
+	}
+	return &Scene{mesh: m}, nil
+}
+
+// Draw draws the ground, the forest and the colonnade, calling
+// model with the model-to-world matrix of each mesh before drawing
+// it.
+func (s *Scene) Draw(model func(vmath.Mat4)) {
+	d := &drawer{mesh: s.mesh, model: model}
+	stack := &d.stack
+
+	stack.Push()
+	stack.Scale(vmath.Vec3{100, 1, 100})
+	d.draw("ground")
+	stack.Pop()
+
+	for _, t := range forest {
+		stack.Push()
+		stack.Translate(vmath.Vec3{t.x, 0, t.z})
+		d.tree(t)
+		stack.Pop()
+	}
+
+	stack.Push()
+	stack.Translate(templePos)
+	d.temple()
+	stack.Pop()
+}
+
+// DrawMarker draws a unit white cube centered on the origin, to
+// show where the camera is looking.
+func (s *Scene) DrawMarker() {
+	s.mesh.DrawSubmesh("marker")
+}
+
+// Close deletes the scene's buffers and vertex arrays.
+func (s *Scene) Close() {
+	s.mesh.Close()
+}
+
+// A drawer draws the submeshes of a scene with the transform at the
+// top of its stack, which maps them into world space.
+type drawer struct {
+	mesh  *mesh.Mesh
+	model func(vmath.Mat4)
+	stack vmath.MatrixStack
+}
+
+func (d *drawer) draw(submesh string) {
+	d.model(d.stack.Top())
+	d.mesh.DrawSubmesh(submesh)
+}
+
+func (d *drawer) tree(t tree) {
+	stack := &d.stack
+	stack.Push()
+	stack.Translate(vmath.Vec3{0, t.trunkHeight / 2, 0})
+	stack.Scale(vmath.Vec3{1, t.trunkHeight, 1})
+	d.draw("trunk")
+	stack.Pop()
+
+	stack.Push()
+	stack.Translate(vmath.Vec3{0, t.trunkHeight, 0})
+	stack.Scale(vmath.Vec3{3, t.coneHeight, 3})
+	d.draw("leaves")
+	stack.Pop()
+}
+
+// temple draws a roofed colonnade on a raised floor.
+func (d *drawer) temple() {
+	stack := &d.stack
+	// Floor
+	stack.Push()
+	stack.Translate(vmath.Vec3{0, templeBaseHeight / 2, 0})
+	stack.Scale(vmath.Vec3{templeWidth, templeBaseHeight, templeLength})
+	d.draw("stone")
+	stack.Pop()
+
+	// Roof
+	stack.Push()
+	stack.Translate(vmath.Vec3{0, templeBaseHeight + columnHeight + templeTopHeight/2, 0})
+	stack.Scale(vmath.Vec3{templeWidth, templeTopHeight, templeLength})
+	d.draw("stone")
+	stack.Pop()
+
+	column := func(x, z float32) {
+		stack.Push()
+		stack.Translate(vmath.Vec3{x, templeBaseHeight, z})
+		d.column()
+		stack.Pop()
+	}
+	// Front and back
+	for x := -templeWidth/2 + 1; x <= templeWidth/2-1; x += 2 {
+		column(x, templeLength/2-1)
+		column(x, -templeLength/2+1)
+	}
+	// Sides, leaving out the corners
+	for z := -templeLength/2 + 3; z <= templeLength/2-3; z += 2 {
+		column(templeWidth/2-1, z)
+		column(-templeWidth/2+1, z)
+	}
+}
+
+// column draws a column standing on the origin, with a square block
+// at its foot and head.
+func (d *drawer) column() {
+	stack := &d.stack
+	stack.Push()
+	stack.Translate(vmath.Vec3{0, columnBaseHeight / 2, 0})
+	stack.Scale(vmath.Vec3{1, columnBaseHeight, 1})
+	d.draw("stone")
+	stack.Pop()
+
+	stack.Push()
+	stack.Translate(vmath.Vec3{0, columnHeight - columnBaseHeight/2, 0})
+	stack.Scale(vmath.Vec3{1, columnBaseHeight, 1})
+	d.draw("stone")
+	stack.Pop()
+
+	stack.Push()
+	stack.Translate(vmath.Vec3{0, columnHeight / 2, 0})
+	stack.Scale(vmath.Vec3{0.8, columnHeight - 2*columnBaseHeight, 0.8})
+	d.draw("column")
+	stack.Pop()
+}
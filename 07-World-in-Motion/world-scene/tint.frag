@@ -0,0 +1,10 @@
+#version 150
+
+smooth in vec4 theColor;
+out vec4 outColor;
+
+uniform vec4 baseColor;
+
+void main() {
+	outColor = theColor * baseColor;
+}
@@ -0,0 +1,191 @@
+// Package worldwithubo draws the world of the world-scene tutorial,
+// giving every program the camera's matrices through one uniform
+// buffer.
+// It is an implementation of http://arcsynthesis.org/gltut/Positioning/Tut07%20Shared%20Uniforms.html
+//
+// The camera orbits a target point at a distance, with its position
+// given in spherical coordinates around the target. Drag with the
+// left mouse button to move the camera around the target, and turn
+// the wheel to move it closer or further away.
+//
+// Keys:
+//
+//	W, S  move the target forwards and backwards
+//	A, D  move the target left and right
+//	Q, E  move the target up and down
+//	Space print the target and camera positions
+package worldwithubo
+
+import (
+	"embed"
+	"log"
+	"time"
+	"aqwari.net/exp/display"
+	"github.com/droyo/gltut/07-World-in-Motion/internal/world"
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/glutil"
+	"github.com/droyo/gltut/internal/shader"
+	"github.com/droyo/gltut/internal/tutorial"
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+func init() {
+	tutorial.Register(&tutorial.Tutorial{
+		Chapter: 7,
+		Section: 2,
+		Name:    "world-with-ubo",
+		Title:   "World With UBO",
+		URL:     "http://arcsynthesis.org/gltut/Positioning/Tut07%20Shared%20Uniforms.html",
+		Doc:     "Shares the camera matrices between programs in a uniform buffer.",
+		New:     func() tutorial.App { return new(scene) },
+		Shaders: shaders,
+	})
+}
+
+//go:generate go run github.com/droyo/gltut/cmd/glbind -type colorProgram tutorial.vert color.frag
+//go:generate go run github.com/droyo/gltut/cmd/glbind -type tintProgram tutorial.vert tint.frag
+
+// Every program reads the camera from the GlobalMatrices block in
+// tutorial.vert, so that moving the camera or resizing the window is
+// one upload that all of them see. tint.frag colors an object by its
+// vertex colors times a uniform color, so one white mesh can be drawn
+// in any color.
+//
+//go:embed tutorial.vert tint.frag
+var files embed.FS
+
+var shaders = shader.Local(files)
+
+var markerColor = [4]float32{1, 0.2, 0.2, 1}
+
+const (
+	zNear float32 = 1
+	zFar float32 = 1000
+)
+
+// globalMatrices is the contents of the GlobalMatrices uniform
+// block.
+type globalMatrices struct {
+	CameraToClip  vmath.Mat4
+	WorldToCamera vmath.Mat4
+}
+
+// globalMatricesBinding is the uniform buffer binding point of the
+// GlobalMatrices block.
+const globalMatricesBinding = 0
+
+type scene struct {
+	color    *colorProgram
+	tint     *tintProgram
+	world    *world.Scene
+	matrices globalMatrices
+	globals  *glutil.UniformBuffer
+	fovy     float32
+
+	camera world.Camera
+	keys   tutorial.Keymap
+}
+
+func (s *scene) Init(ctx gfx.Context, width, height int) (err error) {
+	// Close releases whatever was created before a failure, since
+	// the runner does not call Close when Init fails.
+	defer func() {
+		if err != nil {
+			s.Close(ctx)
+		}
+	}()
+
+	ctx.ClearColor(0, 0, 0, 0)
+	ctx.ClearDepth(1)
+	ctx.Enable(gfx.CULL_FACE)
+	ctx.Enable(gfx.DEPTH_TEST)
+	ctx.DepthFunc(gfx.LEQUAL)
+	ctx.DepthMask(true)
+	ctx.DepthRange(0, 1)
+	ctx.CullFace(gfx.BACK)
+	ctx.FrontFace(gfx.CW)
+
+	if s.color, err = newColorProgram(ctx, shaders); err != nil {
+		return err
+	}
+	if s.tint, err = newTintProgram(ctx, shaders); err != nil {
+		return err
+	}
+
+	globals, err := glutil.NewUniformBuffer(ctx, globalMatricesBinding, &s.matrices)
+	if err != nil {
+		return err
+	}
+	s.globals = globals
+
+	for _, p := range []*glutil.Program{s.color.Program, s.tint.Program} {
+		if err := s.globals.Bind(p, "GlobalMatrices"); err != nil {
+			return err
+		}
+	}
+
+	// Both programs share the vertex shader, so glbind gives their
+	// inputs the same locations, and one vertex array feeds either.
+	if s.world, err = world.New(ctx, s.color.Program); err != nil {
+		return err
+	}
+
+	s.fovy = vmath.Radians(45)
+	s.camera = world.InitialCamera
+	s.keys = s.camera.Keymap()
+	s.Resize(ctx, width, height)
+	return nil
+}
+
+func (s *scene) Resize(ctx gfx.Context, width, height int) {
+	s.matrices.CameraToClip = vmath.Perspective(s.fovy, float32(width) / float32(height), zNear, zFar)
+	if err := s.globals.Update(&s.matrices); err != nil {
+		log.Printf("world-with-ubo: %v", err)
+	}
+	ctx.Viewport(0, 0, width, height)
+}
+
+func (s *scene) Key(ctx gfx.Context, ev display.KeyPress) {
+	s.keys.Handle(ev)
+}
+
+func (s *scene) Mouse(ctx gfx.Context, m tutorial.Mouse) {
+	s.camera.Mouse(m)
+}
+
+func (s *scene) Update(dt time.Duration) {}
+
+func (s *scene) Draw(ctx gfx.Context) {
+	ctx.Clear(gfx.COLOR_BUFFER_BIT | gfx.DEPTH_BUFFER_BIT)
+
+	// Only the camera has changed since the last frame, so only
+	// its matrix is uploaded, once for every program.
+	s.matrices.WorldToCamera = s.camera.Matrix()
+	if err := s.globals.Update(&s.matrices); err != nil {
+		log.Printf("world-with-ubo: %v", err)
+		return
+	}
+
+	s.color.Use()
+	s.world.Draw(s.color.SetModelToWorldMatrix)
+
+	s.tint.Use()
+	s.tint.SetBaseColor(markerColor)
+	s.tint.SetModelToWorldMatrix(vmath.Translate(s.camera.Target))
+	s.world.DrawMarker()
+}
+
+func (s *scene) Close(ctx gfx.Context) {
+	if s.color != nil {
+		s.color.Delete()
+	}
+	if s.tint != nil {
+		s.tint.Delete()
+	}
+	if s.world != nil {
+		s.world.Close()
+	}
+	if s.globals != nil {
+		s.globals.Delete()
+	}
+}
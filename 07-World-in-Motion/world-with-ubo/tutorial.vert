@@ -0,0 +1,22 @@
+#version 150
+
+in vec4 position;
+in vec4 color;
+
+smooth out vec4 theColor;
+
+layout(std140) uniform GlobalMatrices
+{
+	mat4 cameraToClipMatrix;
+	mat4 worldToCameraMatrix;
+};
+
+uniform mat4 modelToWorldMatrix;
+
+void main()
+{
+	vec4 worldPos = modelToWorldMatrix * position;
+	vec4 cameraPos = worldToCameraMatrix * worldPos;
+	gl_Position = cameraToClipMatrix * cameraPos;
+	theColor = color;
+}
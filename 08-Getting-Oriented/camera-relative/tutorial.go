@@ -0,0 +1,265 @@
+// Package camerarelative keeps the ship's orientation in a
+// quaternion, and turns it around the axes of the ship, the world
+// or the camera.
+// It is an implementation of http://arcsynthesis.org/gltut/Positioning/Tut08%20Camera%20Relative%20Orientation.html
+//
+// Each key press makes a small rotation around one axis. In model
+// mode the axes are the ship's own, so W always pitches the nose up,
+// like a pilot's controls. In world mode they are the axes of the
+// world, and in camera mode they are the axes of the screen, so W
+// always tips the ship away from the viewer. The rotation is
+// composed with the orientation on the right for model-relative
+// rotations and on the left for the others; no sequence of presses
+// can lock the ship up as the gimbal did.
+//
+// Drag with the left mouse button to move the camera around the
+// ship, and turn the wheel to move it closer or further away.
+//
+// Keys:
+//
+//	W, S  pitch: rotate around the X axis
+//	A, D  yaw: rotate around the Y axis
+//	Q, E  roll: rotate around the Z axis
+//	Space switch between model, world and camera axes
+//	R     reset the orientation and print it
+package camerarelative
+
+import (
+	"embed"
+	"fmt"
+	"math"
+	"time"
+	"aqwari.net/exp/display"
+	"github.com/droyo/gltut/08-Getting-Oriented/internal/model"
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/mesh"
+	"github.com/droyo/gltut/internal/shader"
+	"github.com/droyo/gltut/internal/tutorial"
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+func init() {
+	tutorial.Register(&tutorial.Tutorial{
+		Chapter: 8,
+		Section: 2,
+		Name:    "camera-relative",
+		Title:   "Camera Relative Orientation",
+		URL:     "http://arcsynthesis.org/gltut/Positioning/Tut08%20Camera%20Relative%20Orientation.html",
+		Doc:     "Turns a ship around its own, the world's or the camera's axes.",
+		New:     func() tutorial.App { return new(scene) },
+		Shaders: shaders,
+	})
+}
+
+//go:generate go run github.com/droyo/gltut/cmd/glbind tutorial.vert color.frag
+
+//go:embed tutorial.vert
+var files embed.FS
+
+var shaders = shader.Local(files)
+
+const smallAngleIncrement = 9
+
+// A mode is the frame of reference of the rotation keys.
+type mode int
+
+const (
+	modelRelative mode = iota
+	worldRelative
+	cameraRelative
+	numModes
+)
+
+func (m mode) String() string {
+	switch m {
+	case modelRelative:
+		return "model relative"
+	case worldRelative:
+		return "world relative"
+	case cameraRelative:
+		return "camera relative"
+	}
+	return fmt.Sprintf("mode(%d)", int(m))
+}
+
+// A camera looks at a target from a point on a sphere around it.
+// Angles are in degrees.
+type camera struct {
+	Target vmath.Vec3
+	Radius float32 // distance from the target
+	Theta  float32 // angle around the Y axis, from the X axis
+	Phi    float32 // angle down from the Y axis
+}
+
+// Position returns the position of the camera in world space.
+func (c *camera) Position() vmath.Vec3 {
+	theta, phi := float64(vmath.Radians(c.Theta)), float64(vmath.Radians(c.Phi))
+	dir := vmath.Vec3{
+		float32(math.Sin(phi) * math.Cos(theta)),
+		float32(math.Cos(phi)),
+		float32(math.Sin(phi) * math.Sin(theta)),
+	}
+	return c.Target.Add(dir.Mul(c.Radius))
+}
+
+// Matrix returns the world-to-camera matrix.
+func (c *camera) Matrix() vmath.Mat4 {
+	return vmath.LookAt(c.Position(), c.Target, vmath.Vec3{0, 1, 0})
+}
+
+// Orbit moves the camera around the target, keeping it off the Y
+// axis, where the look-at matrix is undefined.
+func (c *camera) Orbit(dtheta, dphi float32) {
+	c.Theta = float32(math.Mod(float64(c.Theta + dtheta), 360))
+	c.Phi = float32(math.Max(11.25, math.Min(168.75, float64(c.Phi + dphi))))
+}
+
+// Zoom moves the camera towards the target, but never closer than
+// 10 units.
+func (c *camera) Zoom(d float32) {
+	c.Radius = float32(math.Max(10, float64(c.Radius - d)))
+}
+
+var initialCamera = camera{
+	Radius: 25,
+	Theta:  75,
+	Phi:    60,
+}
+
+const (
+	orbitStep float32 = 0.5
+	zoomStep float32 = 2
+)
+
+// The ship hovers over the middle of the ground.
+const groundHeight float32 = -6
+
+const (
+	zNear float32 = 1
+	zFar float32 = 600
+)
+
+type scene struct {
+	prog *program
+	fovy float32
+
+	ship, ground *mesh.Mesh
+	camera       camera
+	orientation  vmath.Quat
+	mode         mode
+	keys         tutorial.Keymap
+}
+
+func (s *scene) Init(ctx gfx.Context, width, height int) error {
+	ctx.ClearColor(0, 0, 0, 0)
+	ctx.ClearDepth(1)
+	ctx.Enable(gfx.CULL_FACE)
+	ctx.Enable(gfx.DEPTH_TEST)
+	ctx.DepthFunc(gfx.LEQUAL)
+	ctx.DepthMask(true)
+	ctx.DepthRange(0, 1)
+	ctx.CullFace(gfx.BACK)
+	ctx.FrontFace(gfx.CW)
+
+	prog, err := newProgram(ctx, shaders)
+	if err != nil {
+		return err
+	}
+	prog.Use()
+	s.prog = prog
+
+	if s.ship, err = model.Ship(ctx, prog.Program); err != nil {
+		s.Close(ctx)
+		return err
+	}
+	if s.ground, err = model.Ground(ctx, prog.Program, 60, 12); err != nil {
+		s.Close(ctx)
+		return err
+	}
+
+	s.fovy = vmath.Radians(45)
+	s.camera = initialCamera
+	s.orientation = vmath.IdentQuat()
+
+	x, y, z := vmath.Vec3{1, 0, 0}, vmath.Vec3{0, 1, 0}, vmath.Vec3{0, 0, 1}
+	s.keys = tutorial.Keymap{
+		display.KeyW: func() { s.rotate(x, smallAngleIncrement) },
+		display.KeyS: func() { s.rotate(x, -smallAngleIncrement) },
+		display.KeyA: func() { s.rotate(y, smallAngleIncrement) },
+		display.KeyD: func() { s.rotate(y, -smallAngleIncrement) },
+		display.KeyQ: func() { s.rotate(z, smallAngleIncrement) },
+		display.KeyE: func() { s.rotate(z, -smallAngleIncrement) },
+		display.KeySpace: func() {
+			s.mode = (s.mode + 1) % numModes
+			fmt.Println(s.mode)
+		},
+		display.KeyR: func() {
+			fmt.Printf("orientation %+v\n", s.orientation)
+			s.orientation = vmath.IdentQuat()
+		},
+	}
+	s.Resize(ctx, width, height)
+	return nil
+}
+
+// rotate turns the ship by deg degrees around axis, which is in the
+// frame of reference of the current mode.
+func (s *scene) rotate(axis vmath.Vec3, deg float32) {
+	angle := vmath.Radians(deg)
+	switch s.mode {
+	case modelRelative:
+		// Applied before the orientation, so the axis is in
+		// model space.
+		s.orientation = s.orientation.Mul(vmath.AxisAngle(angle, axis))
+	case worldRelative:
+		s.orientation = vmath.AxisAngle(angle, axis).Mul(s.orientation)
+	case cameraRelative:
+		// The camera's rotation is orthonormal, so its transpose
+		// takes the axis from camera space back into world space.
+		toWorld := s.camera.Matrix().Mat3().Transpose()
+		s.orientation = vmath.AxisAngle(angle, toWorld.MulVec(axis)).Mul(s.orientation)
+	}
+	// Keep rounding errors from building up into a scale.
+	s.orientation = s.orientation.Normalize()
+}
+
+func (s *scene) Resize(ctx gfx.Context, width, height int) {
+	matrix := vmath.Perspective(s.fovy, float32(width) / float32(height), zNear, zFar)
+	s.prog.SetCameraToClipMatrix(matrix)
+	ctx.Viewport(0, 0, width, height)
+}
+
+func (s *scene) Key(ctx gfx.Context, ev display.KeyPress) {
+	s.keys.Handle(ev)
+}
+
+func (s *scene) Mouse(ctx gfx.Context, m tutorial.Mouse) {
+	if m.Left && m.Dragging() {
+		s.camera.Orbit(float32(m.DX) * orbitStep, -float32(m.DY) * orbitStep)
+	}
+	s.camera.Zoom(float32(m.Wheel) * zoomStep)
+}
+
+func (s *scene) Update(dt time.Duration) {}
+
+func (s *scene) Draw(ctx gfx.Context) {
+	ctx.Clear(gfx.COLOR_BUFFER_BIT | gfx.DEPTH_BUFFER_BIT)
+
+	stack := vmath.NewMatrixStack(s.camera.Matrix())
+
+	stack.Push()
+	stack.Translate(vmath.Vec3{0, groundHeight, 0})
+	s.prog.SetModelToCameraMatrix(stack.Top())
+	s.ground.Draw()
+	stack.Pop()
+
+	stack.Mul(s.orientation.Mat4())
+	s.prog.SetModelToCameraMatrix(stack.Top())
+	s.ship.Draw()
+}
+
+func (s *scene) Close(ctx gfx.Context) {
+	s.ship.Close()
+	s.ground.Close()
+	s.prog.Delete()
+}
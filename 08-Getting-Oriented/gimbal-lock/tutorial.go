@@ -0,0 +1,207 @@
+// Package gimballock turns a ship with three nested Euler angles,
+// drawn as the rings of a gimbal, to show how two of the rings can
+// line up and lose a degree of freedom.
+// It is an implementation of http://arcsynthesis.org/gltut/Positioning/Tutorial%2008.html
+//
+// The outer ring turns around the X axis, the middle ring around the
+// Y axis within it, and the inner ring, which holds the ship, around
+// the Z axis within that. Turn the middle ring a quarter turn and
+// the outer and inner rings turn around the same axis: no key will
+// pitch the ship up or down any more.
+//
+// Keys:
+//
+//	W, S  turn the outer (X) ring
+//	A, D  turn the middle (Y) ring
+//	Q, E  turn the inner (Z) ring
+//	Space show or hide the rings
+//	R     reset the angles and print them
+package gimballock
+
+import (
+	"embed"
+	"fmt"
+	"math"
+	"time"
+	"aqwari.net/exp/display"
+	"github.com/droyo/gltut/08-Getting-Oriented/internal/model"
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/mesh"
+	"github.com/droyo/gltut/internal/shader"
+	"github.com/droyo/gltut/internal/tutorial"
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+func init() {
+	tutorial.Register(&tutorial.Tutorial{
+		Chapter: 8,
+		Section: 1,
+		Name:    "gimbal-lock",
+		Title:   "Gimbal Lock",
+		URL:     "http://arcsynthesis.org/gltut/Positioning/Tutorial%2008.html",
+		Doc:     "Turns a ship in a gimbal, one Euler angle per ring.",
+		New:     func() tutorial.App { return new(scene) },
+		Shaders: shaders,
+	})
+}
+
+//go:generate go run github.com/droyo/gltut/cmd/glbind tutorial.vert color.frag
+
+//go:embed tutorial.vert
+var files embed.FS
+
+var shaders = shader.Local(files)
+
+const smallAngleIncrement = 9
+
+// angles are the angles of the three rings, in degrees.
+type angles struct {
+	X, Y, Z float32
+}
+
+func wrap(deg float32) float32 {
+	return float32(math.Mod(float64(deg), 360))
+}
+
+// A gimbal ring lies in the XY plane and pivots around X. These
+// turn it to pivot around each axis, lying in the plane that holds
+// the pivots of the next ring in.
+var ringOrientation = [3]vmath.Mat4{
+	vmath.Ident4(),
+	// X to Y, Y to Z: the middle ring lies in the YZ plane
+	vmath.Rotate(vmath.Radians(120), vmath.Vec3{1, 1, 1}),
+	// X to Z, Y to X: the inner ring lies in the ZX plane
+	vmath.Rotate(vmath.Radians(240), vmath.Vec3{1, 1, 1}),
+}
+
+var (
+	ringColors = [3]vmath.Vec3{{1, 0.2, 0.2}, {0.2, 1, 0.2}, {0.2, 0.4, 1}}
+	ringRadius = [3]float32{13, 11, 9}
+)
+
+const (
+	zNear float32 = 1
+	zFar float32 = 600
+)
+
+// The gimbal is seen from above and to one side, so that all three
+// rings can be told apart.
+var worldToCamera = vmath.Translate(vmath.Vec3{0, 0, -45}).
+	Mul(vmath.RotateX(vmath.Radians(25))).
+	Mul(vmath.RotateY(vmath.Radians(-35)))
+
+type scene struct {
+	prog *program
+	fovy float32
+
+	ship      *mesh.Mesh
+	rings     [3]*mesh.Mesh
+	angles    angles
+	showRings bool
+	keys      tutorial.Keymap
+}
+
+func (s *scene) Init(ctx gfx.Context, width, height int) error {
+	ctx.ClearColor(0, 0, 0, 0)
+	ctx.ClearDepth(1)
+	ctx.Enable(gfx.CULL_FACE)
+	ctx.Enable(gfx.DEPTH_TEST)
+	ctx.DepthFunc(gfx.LEQUAL)
+	ctx.DepthMask(true)
+	ctx.DepthRange(0, 1)
+	ctx.CullFace(gfx.BACK)
+	ctx.FrontFace(gfx.CW)
+
+	prog, err := newProgram(ctx, shaders)
+	if err != nil {
+		return err
+	}
+	prog.Use()
+	s.prog = prog
+
+	if s.ship, err = model.Ship(ctx, prog.Program); err != nil {
+		s.Close(ctx)
+		return err
+	}
+	for i := range s.rings {
+		reach := float32(2)
+		if i == 0 {
+			reach = 3
+		}
+		s.rings[i], err = model.Gimbal(ctx, prog.Program, ringRadius[i], reach, ringColors[i])
+		if err != nil {
+			s.Close(ctx)
+			return err
+		}
+	}
+
+	s.fovy = vmath.Radians(45)
+	s.showRings = true
+
+	a := &s.angles
+	s.keys = tutorial.Keymap{
+		display.KeyW: func() { a.X = wrap(a.X + smallAngleIncrement) },
+		display.KeyS: func() { a.X = wrap(a.X - smallAngleIncrement) },
+		display.KeyA: func() { a.Y = wrap(a.Y + smallAngleIncrement) },
+		display.KeyD: func() { a.Y = wrap(a.Y - smallAngleIncrement) },
+		display.KeyQ: func() { a.Z = wrap(a.Z + smallAngleIncrement) },
+		display.KeyE: func() { a.Z = wrap(a.Z - smallAngleIncrement) },
+		display.KeySpace: func() { s.showRings = !s.showRings },
+		display.KeyR: func() {
+			fmt.Printf("x %g, y %g, z %g\n", a.X, a.Y, a.Z)
+			*a = angles{}
+		},
+	}
+	s.Resize(ctx, width, height)
+	return nil
+}
+
+func (s *scene) Resize(ctx gfx.Context, width, height int) {
+	matrix := vmath.Perspective(s.fovy, float32(width) / float32(height), zNear, zFar)
+	s.prog.SetCameraToClipMatrix(matrix)
+	ctx.Viewport(0, 0, width, height)
+}
+
+func (s *scene) Key(ctx gfx.Context, ev display.KeyPress) {
+	s.keys.Handle(ev)
+}
+
+func (s *scene) Update(dt time.Duration) {}
+
+func (s *scene) draw(ctx gfx.Context, m vmath.Mat4, o *mesh.Mesh) {
+	s.prog.SetModelToCameraMatrix(m)
+	o.Draw()
+}
+
+// drawRing draws ring i, which turns around axis i, in the frame of
+// the ring outside it.
+func (s *scene) drawRing(ctx gfx.Context, stack *vmath.MatrixStack, i int) {
+	if !s.showRings {
+		return
+	}
+	s.draw(ctx, stack.Top().Mul(ringOrientation[i]), s.rings[i])
+}
+
+func (s *scene) Draw(ctx gfx.Context) {
+	ctx.Clear(gfx.COLOR_BUFFER_BIT | gfx.DEPTH_BUFFER_BIT)
+
+	// Each ring turns within the one outside it, so each angle is
+	// applied on top of the previous ones.
+	stack := vmath.NewMatrixStack(worldToCamera)
+	stack.RotateX(vmath.Radians(s.angles.X))
+	s.drawRing(ctx, stack, 0)
+	stack.RotateY(vmath.Radians(s.angles.Y))
+	s.drawRing(ctx, stack, 1)
+	stack.RotateZ(vmath.Radians(s.angles.Z))
+	s.drawRing(ctx, stack, 2)
+
+	s.draw(ctx, stack.Top(), s.ship)
+}
+
+func (s *scene) Close(ctx gfx.Context) {
+	s.ship.Close()
+	for _, r := range s.rings {
+		r.Close()
+	}
+	s.prog.Delete()
+}
@@ -0,0 +1,107 @@
+// Package model builds the objects drawn by the chapter 8
+// tutorials: a small spaceship, the rings of a gimbal, and a
+// checkered ground to turn the ship against.
+//
+// The ship's nose points along -Z, its tail fin along +Y and its
+// right wing along +X. The wings carry navigation lights, red on the
+// left and green on the right, so that its orientation is never
+// ambiguous.
+package model
+
+import (
+	"math"
+
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/glutil"
+	"github.com/droyo/gltut/internal/mesh"
+	"github.com/droyo/gltut/internal/shape"
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+// There is no lighting yet, so the builders shade each face by
+// how much it faces this direction.
+var light = vmath.Vec3{0.3, 1, 0.5}.Normalize()
+
+func newBuilder() *mesh.Builder {
+	return &mesh.Builder{Light: light}
+}
+
+// pyramid adds to b the sides of a pyramid on a rectangular base
+// at z = base, with its tip at (0, 0, tip). The base is left open.
+func pyramid(b *mesh.Builder, base, tip, width, height float32, color vmath.Vec3) {
+	w, h := width/2, height/2
+	corners := []vmath.Vec3{{-w, -h, base}, {w, -h, base}, {w, h, base}, {-w, h, base}}
+	apex := vmath.Vec3{0, 0, tip}
+	for i, p := range corners {
+		q := corners[(i+1)%4]
+		n := q.Sub(p).Cross(apex.Sub(p)).Normalize()
+		mid := p.Add(q).Mul(0.5)
+		if n.Dot(mid.Sub(vmath.Vec3{0, 0, base})) < 0 {
+			n = n.Neg()
+		}
+		b.Tri(b.Vertex(p, n, color), b.Vertex(q, n, color), b.Vertex(apex, n, color))
+	}
+}
+
+var (
+	hullColor   = vmath.Vec3{0.7, 0.7, 0.75}
+	noseColor   = vmath.Vec3{0.9, 0.6, 0.1}
+	wingColor   = vmath.Vec3{0.3, 0.4, 0.8}
+	finColor    = vmath.Vec3{0.9, 0.85, 0.2}
+	canopyColor = vmath.Vec3{0.2, 0.8, 0.9}
+	portColor   = vmath.Vec3{1, 0.1, 0.1}
+	starColor   = vmath.Vec3{0.1, 1, 0.1}
+
+	groundColors = [2]vmath.Vec3{{0.3, 0.3, 0.3}, {0.15, 0.15, 0.15}}
+)
+
+// Ship uploads the ship, which is 8 units from wing tip to wing
+// tip and about 9 units from nose to tail, centered on the origin.
+func Ship(ctx gfx.Context, prog *glutil.Program) (*mesh.Mesh, error) {
+	b := newBuilder()
+	b.Box(vmath.Vec3{0, 0, 0.5}, vmath.Vec3{1.2, 1, 6}, hullColor)
+	pyramid(b, -2.5, -4.5, 1.2, 1, noseColor)
+	b.Box(vmath.Vec3{0, 0.6, -1}, vmath.Vec3{0.8, 0.3, 1.5}, canopyColor)
+	b.Box(vmath.Vec3{0, 0, 1.5}, vmath.Vec3{7, 0.2, 2}, wingColor)
+	b.Box(vmath.Vec3{-3.75, 0, 1.5}, vmath.Vec3{0.5, 0.4, 2}, portColor)
+	b.Box(vmath.Vec3{3.75, 0, 1.5}, vmath.Vec3{0.5, 0.4, 2}, starColor)
+	b.Box(vmath.Vec3{0, 1.25, 3}, vmath.Vec3{0.2, 1.5, 1}, finColor)
+	return mesh.New(ctx, prog, b.Data())
+}
+
+// Ground uploads a checkerboard of n by n squares, size units
+// across, in the XZ plane and facing up.
+func Ground(ctx gfx.Context, prog *glutil.Program, size float32, n int) (*mesh.Mesh, error) {
+	b := newBuilder()
+	up := vmath.Vec3{0, 1, 0}
+	step := size / float32(n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			color := groundColors[(i+j)%2]
+			x, z := -size/2+float32(i)*step, -size/2+float32(j)*step
+			b.Quad(
+				b.Vertex(vmath.Vec3{x, 0, z}, up, color),
+				b.Vertex(vmath.Vec3{x + step, 0, z}, up, color),
+				b.Vertex(vmath.Vec3{x + step, 0, z + step}, up, color),
+				b.Vertex(vmath.Vec3{x, 0, z + step}, up, color))
+		}
+	}
+	return mesh.New(ctx, prog, b.Data())
+}
+
+const ringSegments, tubeSegments = 48, 8
+
+// Gimbal uploads a gimbal ring of the given radius, lying in the XY
+// plane and pivoting around the X axis. Pins at either end of the
+// pivot stick out by reach, to meet the next ring outward.
+func Gimbal(ctx gfx.Context, prog *glutil.Program, radius, reach float32, color vmath.Vec3) (*mesh.Mesh, error) {
+	const tube = 0.4
+	b := newBuilder()
+	// The torus stands around Y; turn it to stand around Z.
+	ring := vmath.RotateX(math.Pi / 2)
+	shape.Torus(radius, tube, ringSegments, tubeSegments).Build(b, ring, color)
+	pin := vmath.Vec3{reach + tube, 2 * tube, 2 * tube}
+	b.Box(vmath.Vec3{radius + reach/2, 0, 0}, pin, color)
+	b.Box(vmath.Vec3{-radius - reach/2, 0, 0}, pin, color)
+	return mesh.New(ctx, prog, b.Data())
+}
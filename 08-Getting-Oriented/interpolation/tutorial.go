@@ -0,0 +1,151 @@
+// Package interpolation turns two ships through the same sequence
+// of orientations, one interpolating linearly between quaternions
+// and the other spherically.
+// It is an implementation of http://arcsynthesis.org/gltut/Positioning/Tut08%20Interpolation.html
+//
+// The ship on the left blends the quaternions linearly and
+// normalizes the result; the ship on the right uses slerp. Both
+// follow the same path and arrive at the same time, but the left
+// ship starts and ends each turn slowly and hurries through the
+// middle, while the right one turns at a constant rate. The
+// difference grows with the angle between orientations.
+//
+// Keys:
+//
+//	Space start again from the first orientation
+package interpolation
+
+import (
+	"embed"
+	"time"
+	"aqwari.net/exp/display"
+	"github.com/droyo/gltut/08-Getting-Oriented/internal/model"
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/mesh"
+	"github.com/droyo/gltut/internal/shader"
+	"github.com/droyo/gltut/internal/tutorial"
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+func init() {
+	tutorial.Register(&tutorial.Tutorial{
+		Chapter:  8,
+		Section:  3,
+		Name:     "interpolation",
+		Title:    "Interpolation",
+		URL:      "http://arcsynthesis.org/gltut/Positioning/Tut08%20Interpolation.html",
+		Doc:      "Compares linear and spherical interpolation of orientations.",
+		New:      func() tutorial.App { return new(scene) },
+		Shaders:  shaders,
+		Animated: true,
+	})
+}
+
+//go:generate go run github.com/droyo/gltut/cmd/glbind tutorial.vert color.frag
+
+//go:embed tutorial.vert
+var files embed.FS
+
+var shaders = shader.Local(files)
+
+// The ships turn from each orientation to the next, and from the
+// last back to the first. Neighbors are far apart, so that the
+// two methods visibly disagree.
+var orientations = []vmath.Quat{
+	vmath.IdentQuat(),
+	vmath.AxisAngle(vmath.Radians(170), vmath.Vec3{0, 1, 0}),
+	vmath.AxisAngle(vmath.Radians(120), vmath.Vec3{1, 0, 1}),
+	vmath.AxisAngle(vmath.Radians(-150), vmath.Vec3{1, 1, 0}),
+	vmath.AxisAngle(vmath.Radians(90), vmath.Vec3{0, 0, 1}),
+}
+
+// legTime is how long each turn takes.
+const legTime = 2 * time.Second
+
+// orientation returns the orientation at time elapsed, blending
+// neighboring orientations with interp.
+func orientation(elapsed time.Duration, interp func(q, p vmath.Quat, t float32) vmath.Quat) vmath.Quat {
+	leg := int(elapsed / legTime) % len(orientations)
+	t := float32(elapsed % legTime) / float32(legTime)
+	from, to := orientations[leg], orientations[(leg + 1) % len(orientations)]
+	return interp(from, to, t)
+}
+
+// The ships are side by side, seen from a little above.
+var (
+	lerpPos = vmath.Vec3{-7, 0, 0}
+	slerpPos = vmath.Vec3{7, 0, 0}
+	worldToCamera = vmath.Translate(vmath.Vec3{0, 0, -35}).Mul(vmath.RotateX(vmath.Radians(20)))
+)
+
+const (
+	zNear float32 = 1
+	zFar float32 = 600
+)
+
+type scene struct {
+	prog    *program
+	fovy    float32
+	ship    *mesh.Mesh
+	elapsed time.Duration
+}
+
+func (s *scene) Init(ctx gfx.Context, width, height int) error {
+	ctx.ClearColor(0, 0, 0, 0)
+	ctx.ClearDepth(1)
+	ctx.Enable(gfx.CULL_FACE)
+	ctx.Enable(gfx.DEPTH_TEST)
+	ctx.DepthFunc(gfx.LEQUAL)
+	ctx.DepthMask(true)
+	ctx.DepthRange(0, 1)
+	ctx.CullFace(gfx.BACK)
+	ctx.FrontFace(gfx.CW)
+
+	prog, err := newProgram(ctx, shaders)
+	if err != nil {
+		return err
+	}
+	prog.Use()
+	s.prog = prog
+	if s.ship, err = model.Ship(ctx, prog.Program); err != nil {
+		prog.Delete()
+		return err
+	}
+
+	s.fovy = vmath.Radians(45)
+	s.Resize(ctx, width, height)
+	return nil
+}
+
+func (s *scene) Resize(ctx gfx.Context, width, height int) {
+	matrix := vmath.Perspective(s.fovy, float32(width) / float32(height), zNear, zFar)
+	s.prog.SetCameraToClipMatrix(matrix)
+	ctx.Viewport(0, 0, width, height)
+}
+
+func (s *scene) Key(ctx gfx.Context, ev display.KeyPress) {
+	if ev.Code == display.KeySpace && ev.Down {
+		s.elapsed = 0
+	}
+}
+
+func (s *scene) Update(dt time.Duration) {
+	s.elapsed += dt
+}
+
+func (s *scene) drawShip(ctx gfx.Context, pos vmath.Vec3, q vmath.Quat) {
+	m := worldToCamera.Mul(vmath.Translate(pos)).Mul(q.Mat4())
+	s.prog.SetModelToCameraMatrix(m)
+	s.ship.Draw()
+}
+
+func (s *scene) Draw(ctx gfx.Context) {
+	ctx.Clear(gfx.COLOR_BUFFER_BIT | gfx.DEPTH_BUFFER_BIT)
+	s.drawShip(ctx, lerpPos, orientation(s.elapsed, vmath.Quat.Lerp))
+	s.drawShip(ctx, slerpPos, orientation(s.elapsed, vmath.Quat.Slerp))
+}
+
+func (s *scene) Close(ctx gfx.Context) {
+	s.ship.Close()
+	s.prog.Delete()
+}
@@ -0,0 +1,420 @@
+// Command glbind generates typed Go bindings for the attributes and
+// uniforms of a GLSL program, so that a uniform renamed in a shader
+// breaks the Go build instead of being silently ignored.
+//
+// Usage:
+//
+//	glbind [-type name] [-o file] shader ...
+//
+// glbind reads the shader files the way glutil.Builder.Files does:
+// from the current directory, or else from internal/shader/common,
+// with the files they include. For the default -type of program, it
+// writes a Go file declaring
+//
+//   - a gfx.Attrib constant for each vertex shader input, such as
+//     programPosition for "in vec4 position", giving its location;
+//   - a type program, embedding *glutil.Program, with a Set method
+//     for each uniform outside a uniform block, such as
+//     SetPerspectiveMatrix(m vmath.Mat4) for "uniform mat4
+//     perspectiveMatrix";
+//   - a function newProgram(ctx gfx.Context, fsys fs.FS) that links
+//     the shaders from fsys, binds each input to its location and
+//     looks up the uniforms.
+//
+// Inputs with a layout(location = N) qualifier keep their location;
+// the rest are numbered in the order they are declared. Uniforms of
+// type float, vec2, vec3, vec4 and mat4 are supported. Uniform blocks
+// are left to package std140.
+//
+// The output file is named after the type, as in program_glsl.go,
+// unless -o says otherwise. glbind is meant to be run by go generate,
+// from a line such as
+//
+//	//go:generate go run github.com/droyo/gltut/cmd/glbind tutorial.vert color.frag
+//
+// in the package that uses the shaders; the package name is taken
+// from the GOPACKAGE variable that go generate sets.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/build"
+	"go/format"
+	"go/token"
+	"io/fs"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/droyo/gltut/internal/glsl"
+	"github.com/droyo/gltut/internal/glutil"
+	"github.com/droyo/gltut/internal/shader"
+)
+
+var (
+	typeName = flag.String("type", "program", "name of the generated Go type")
+	output   = flag.String("o", "", "output file; default is the type name in lower case, with _glsl.go")
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: glbind [-type name] [-o file] shader ...")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("glbind: ")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+	}
+	if !token.IsIdentifier(*typeName) {
+		log.Fatalf("bad type name %q", *typeName)
+	}
+	pkg := os.Getenv("GOPACKAGE")
+	if pkg == "" {
+		p, err := build.ImportDir(".", 0)
+		if err != nil {
+			log.Fatalf("cannot find the package name: %v", err)
+		}
+		pkg = p.Name
+	}
+
+	prog, err := read(os.DirFS("."), flag.Args())
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := generate(pkg, *typeName, os.Args[1:], prog)
+	if err != nil {
+		log.Fatal(err)
+	}
+	name := *output
+	if name == "" {
+		name = strings.ToLower(*typeName) + "_glsl.go"
+	}
+	if err := os.WriteFile(name, src, 0666); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// A program is the interface of a program, as declared by its
+// shaders.
+type program struct {
+	files    []string
+	inputs   []input
+	uniforms []uniform
+}
+
+// An input is a vertex shader input.
+type input struct {
+	name  string
+	loc   int
+	fixed bool // set by a layout qualifier
+}
+
+// A uniform is a uniform outside a uniform block.
+type uniform struct {
+	name string
+	typ  glsl.Type
+}
+
+// read reads the named shaders from fsys and collects their vertex
+// shader inputs and uniforms.
+func read(fsys fs.FS, names []string) (*program, error) {
+	prog := &program{files: names}
+	seen := make(map[string]glsl.Type)
+	var inputs []*glsl.VarDecl
+	for _, name := range names {
+		stage, ok := glutil.StageOf(name)
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown shader stage; want .vert, .geom or .frag", name)
+		}
+		src, err := shader.Preprocess(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		file, err := glsl.Parse(src.Text)
+		if err != nil {
+			return nil, where(src, err)
+		}
+		for _, d := range file.Decls {
+			v, ok := d.(*glsl.VarDecl)
+			if !ok {
+				continue
+			}
+			switch {
+			case v.Qual.Storage == "in" && stage == glutil.Vertex:
+				inputs = append(inputs, v)
+			case v.Qual.Storage == "uniform":
+				for _, x := range v.Vars {
+					t := v.Type
+					if x.ArrayLen > 0 {
+						t.ArrayLen = x.ArrayLen
+					}
+					if prev, ok := seen[x.Name]; ok {
+						if prev != t {
+							return nil, posError(src, x.Pos, "uniform %s is declared as both %s and %s", x.Name, typeString(prev), typeString(t))
+						}
+						continue
+					}
+					if _, ok := setters[t.Name]; !ok || t.ArrayLen > 0 {
+						return nil, posError(src, x.Pos, "uniform %s %s: no Go setter for %[1]s", typeString(t), x.Name)
+					}
+					seen[x.Name] = t
+					prog.uniforms = append(prog.uniforms, uniform{x.Name, t})
+				}
+			}
+		}
+	}
+	var err error
+	prog.inputs, err = locate(inputs)
+	return prog, err
+}
+
+// locate assigns a location to each vertex shader input: the one in
+// its layout qualifier, or else the lowest free ones, in order.
+func locate(decls []*glsl.VarDecl) ([]input, error) {
+	var inputs []input
+	var slots []int
+	used := make(map[int]string)
+	for _, d := range decls {
+		for _, x := range d.Vars {
+			n := columns(d.Type.Name)
+			if x.ArrayLen > 0 {
+				n *= x.ArrayLen
+			} else if d.Type.ArrayLen > 0 {
+				n *= d.Type.ArrayLen
+			}
+			in := input{name: x.Name, loc: -1}
+			if loc, ok := d.Qual.Layout["location"]; ok && loc >= 0 {
+				in.loc, in.fixed = loc, true
+				for i := loc; i < loc+n; i++ {
+					if prev, ok := used[i]; ok {
+						return nil, fmt.Errorf("inputs %s and %s share location %d", prev, x.Name, i)
+					}
+					used[i] = x.Name
+				}
+			}
+			inputs = append(inputs, in)
+			slots = append(slots, n)
+		}
+	}
+	for i := range inputs {
+		in := &inputs[i]
+		if in.fixed {
+			continue
+		}
+	search:
+		for loc := 0; ; loc++ {
+			for j := loc; j < loc+slots[i]; j++ {
+				if _, ok := used[j]; ok {
+					continue search
+				}
+			}
+			in.loc = loc
+			break
+		}
+		for j := in.loc; j < in.loc+slots[i]; j++ {
+			used[j] = in.name
+		}
+	}
+	return inputs, nil
+}
+
+// columns returns the number of locations taken by an input of the
+// named type: one per column of a matrix, and one for anything else.
+func columns(name string) int {
+	if strings.HasPrefix(name, "mat") && len(name) > 3 {
+		if n, err := strconv.Atoi(name[3:4]); err == nil {
+			return n
+		}
+	}
+	return 1
+}
+
+func typeString(t glsl.Type) string {
+	if t.ArrayLen > 0 {
+		return fmt.Sprintf("%s[%d]", t.Name, t.ArrayLen)
+	}
+	return t.Name
+}
+
+// where rewrites the position of a parse error to the file it is in.
+func where(src *shader.Source, err error) error {
+	if e, ok := err.(*glsl.Error); ok {
+		return posError(src, e.Pos, "%s", e.Msg)
+	}
+	return err
+}
+
+func posError(src *shader.Source, pos glsl.Pos, format string, args ...interface{}) error {
+	file := "?"
+	if pos.Source >= 0 && pos.Source < len(src.Files) {
+		file = src.Files[pos.Source]
+	}
+	return fmt.Errorf("%s:%d: %s", file, pos.Line, fmt.Sprintf(format, args...))
+}
+
+// A setter is the Go side of a uniform type: the parameters of its
+// Set method, and the call in its body, given the location.
+type setter struct {
+	params string
+	call   string
+}
+
+var setters = map[string]setter{
+	"float": {"v float32", "p.ctx.Uniformf(%s, v)"},
+	"vec2":  {"x, y float32", "p.ctx.Uniformf(%s, x, y)"},
+	"vec3":  {"v vmath.Vec3", "p.ctx.Uniformf(%s, v[:]...)"},
+	"vec4":  {"v vmath.Vec4", "p.ctx.Uniformf(%s, v[:]...)"},
+	"mat4":  {"m vmath.Mat4", "p.ctx.UniformMatrix4fv(%s, false, m.ColumnMajor())"},
+}
+
+// title returns name with its first letter in upper case.
+func title(name string) string {
+	r, n := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(r)) + name[n:]
+}
+
+// field returns the name of the struct field holding the location
+// of the named uniform.
+func field(name string) string {
+	if token.IsKeyword(name) {
+		return name + "_"
+	}
+	return name
+}
+
+// comment writes a doc comment, wrapped to fit in 70 columns.
+func comment(buf *bytes.Buffer, format string, args ...interface{}) {
+	line := "//"
+	for _, word := range strings.Fields(fmt.Sprintf(format, args...)) {
+		if len(line)+1+len(word) > 70 && line != "//" {
+			fmt.Fprintln(buf, line)
+			line = "//"
+		}
+		line += " " + word
+	}
+	fmt.Fprintln(buf, line)
+}
+
+// generate returns the Go source for the bindings of prog, noting in
+// its header that glbind was run with args.
+func generate(pkg, typ string, args []string, prog *program) ([]byte, error) {
+	var buf bytes.Buffer
+	p := func(format string, args ...interface{}) { fmt.Fprintf(&buf, format+"\n", args...) }
+
+	files := prog.files[0]
+	if n := len(prog.files); n > 1 {
+		files = strings.Join(prog.files[:n-1], ", ") + " and " + prog.files[n-1]
+	}
+	quoted := make([]string, len(prog.files))
+	for i, f := range prog.files {
+		quoted[i] = strconv.Quote(f)
+	}
+	useVmath := false
+	for _, u := range prog.uniforms {
+		useVmath = useVmath || strings.Contains(setters[u.typ.Name].params, "vmath.")
+	}
+
+	p("// Code generated by glbind %s; DO NOT EDIT.", strings.Join(args, " "))
+	p("")
+	p("package %s", pkg)
+	p("")
+	p("import (")
+	p("%q", "io/fs")
+	p("")
+	p("%q", "github.com/droyo/gltut/internal/gfx")
+	p("%q", "github.com/droyo/gltut/internal/glutil")
+	if useVmath {
+		p("%q", "github.com/droyo/gltut/internal/vmath")
+	}
+	p(")")
+
+	names := make(map[string]string)
+	claim := func(name, what string) error {
+		if prev, ok := names[name]; ok {
+			return fmt.Errorf("%s and %s are both bound to %s", prev, what, name)
+		}
+		names[name] = what
+		return nil
+	}
+	if len(prog.inputs) > 0 {
+		p("")
+		p("// Locations of the vertex shader inputs of %s.", typ)
+		p("const (")
+		for _, in := range prog.inputs {
+			name := typ + title(in.name)
+			if err := claim(name, "input "+in.name); err != nil {
+				return nil, err
+			}
+			p("%s gfx.Attrib = %d", name, in.loc)
+		}
+		p(")")
+	}
+
+	p("")
+	comment(&buf, "%s is the program linked from %s. Its Set methods set uniforms of the current program; call Use first.", typ, files)
+	p("type %s struct {", typ)
+	p("*glutil.Program")
+	p("ctx gfx.Context")
+	if len(prog.uniforms) > 0 {
+		p("loc struct {")
+		for _, u := range prog.uniforms {
+			p("%s gfx.Uniform", field(u.name))
+		}
+		p("}")
+	}
+	p("}")
+
+	p("")
+	comment(&buf, "new%s links %s, read from fsys, into a %s.", title(typ), files, typ)
+	p("func new%s(ctx gfx.Context, fsys fs.FS) (*%s, error) {", title(typ), typ)
+	p("prog, err := glutil.NewProgram(ctx).")
+	p("Files(fsys, %s).", strings.Join(quoted, ", "))
+	for _, in := range prog.inputs {
+		if !in.fixed {
+			p("BindAttrib(%q, %s).", in.name, typ+title(in.name))
+		}
+	}
+	p("Link()")
+	p("if err != nil {")
+	p("return nil, err")
+	p("}")
+	p("p := &%s{Program: prog, ctx: ctx}", typ)
+	if len(prog.uniforms) > 0 {
+		p("// Uniforms the driver optimized away have location -1,")
+		p("// which OpenGL ignores.")
+		for _, u := range prog.uniforms {
+			p("p.loc.%s, _ = ctx.GetUniformLocation(prog.ID, %q)", field(u.name), u.name)
+		}
+	}
+	p("return p, nil")
+	p("}")
+
+	for _, u := range prog.uniforms {
+		name := "Set" + title(u.name)
+		if err := claim(name, "uniform "+u.name); err != nil {
+			return nil, err
+		}
+		s := setters[u.typ.Name]
+		p("")
+		p("// %s sets uniform %s %s.", name, u.typ.Name, u.name)
+		p("func (p *%s) %s(%s) {", typ, name, s.params)
+		p(s.call, "p.loc."+field(u.name))
+		p("}")
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting output: %v", err)
+	}
+	return src, nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const directive = "//go:generate go run github.com/droyo/gltut/cmd/glbind "
+
+// TestGenerated runs every glbind directive in the repository, and
+// checks that its output matches the checked-in file, so that a
+// shader edited without running go generate fails the tests.
+func TestGenerated(t *testing.T) {
+	root := filepath.Join("..", "..")
+	n := 0
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		s := bufio.NewScanner(bytes.NewReader(data))
+		for s.Scan() {
+			if line := s.Text(); strings.HasPrefix(line, directive) {
+				checkGenerated(t, path, strings.Fields(strings.TrimPrefix(line, directive)))
+				n++
+			}
+		}
+		return s.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n == 0 {
+		t.Error("found no glbind directives")
+	}
+}
+
+// checkGenerated runs glbind with args in the directory of the Go
+// file path, and compares its output with the file it would write.
+func checkGenerated(t *testing.T, path string, args []string) {
+	t.Helper()
+	flags := flag.NewFlagSet("glbind", flag.ContinueOnError)
+	typ := flags.String("type", "program", "")
+	out := flags.String("o", "", "")
+	if err := flags.Parse(args); err != nil {
+		t.Errorf("%s: %v", path, err)
+		return
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.PackageClauseOnly)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	dir := filepath.Dir(path)
+	prog, err := read(os.DirFS(dir), flags.Args())
+	if err != nil {
+		t.Errorf("%s: %v", dir, err)
+		return
+	}
+	src, err := generate(f.Name.Name, *typ, args, prog)
+	if err != nil {
+		t.Errorf("%s: %v", dir, err)
+		return
+	}
+	name := *out
+	if name == "" {
+		name = strings.ToLower(*typ) + "_glsl.go"
+	}
+	name = filepath.Join(dir, name)
+	want, err := os.ReadFile(name)
+	if err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(src, want) {
+		t.Errorf("%s is out of date; run go generate in %s", name, dir)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.vert": {Data: []byte(`#version 150
+#include "m.glsl"
+layout(location = 0) in vec4 pos;
+in mat4 model;
+in vec2 uv;
+uniform vec3 type;
+void main() { gl_Position = pos; }
+`)},
+		"m.glsl": {Data: []byte("uniform mat4 cameraToClip;\nlayout(std140) uniform B { mat4 x; };\n")},
+		"b.frag": {Data: []byte("#version 150\nuniform vec3 type;\nuniform float k;\nout vec4 o;\nvoid main() { o = vec4(k); }\n")},
+	}
+	args := []string{"-type", "thing", "a.vert", "b.frag", "color.frag"}
+	prog, err := read(fsys, args[2:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := generate("x", "thing", args, prog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		want string
+		in   bool
+	}{
+		{"fixed input keeps its location", "thingPos   gfx.Attrib = 0", true},
+		// A mat4 takes four locations.
+		{"inputs fill free locations", "thingModel gfx.Attrib = 1", true},
+		{"inputs after a matrix", "thingUv    gfx.Attrib = 5", true},
+		{"bound input", `BindAttrib("uv", thingUv)`, true},
+		{"fixed input is not bound", `BindAttrib("pos"`, false},
+		{"keyword field renamed", "type_        gfx.Uniform", true},
+		{"uniform in two shaders", "SetType(v vmath.Vec3)", true},
+		{"float uniform", "SetK(v float32)", true},
+		{"included uniform", "SetCameraToClipMatrix", false},
+		{"included uniform", "SetCameraToClip(m vmath.Mat4)", true},
+		{"block member", "SetX", false},
+		{"header", "// Code generated by glbind -type thing a.vert b.frag color.frag; DO NOT EDIT.", true},
+		{"file list", "a.vert, b.frag and color.frag", true},
+	}
+	for _, tt := range tests {
+		if strings.Contains(string(src), tt.want) != tt.in {
+			t.Errorf("%s: output contains %q is %v, want %v", tt.name, tt.want, !tt.in, tt.in)
+		}
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.vert":       {Data: []byte("#version 150\nin vec4 pos;\nuniform vec3 type;\nvoid main() {}\n")},
+		"sampler.frag": {Data: []byte("#version 150\n\nuniform sampler2D tex;\nvoid main() {}\n")},
+		"array.frag":   {Data: []byte("#version 150\nuniform vec4 lights[2];\nvoid main() {}\n")},
+		"clash.frag":   {Data: []byte("#version 150\nuniform vec2 type;\nvoid main() {}\n")},
+		"syntax.frag":  {Data: []byte("#version 150\nvoid main() { x = ; }\n")},
+		"shared.vert":  {Data: []byte("#version 150\nlayout(location = 1) in mat2 m;\nlayout(location = 2) in vec4 p;\nvoid main() {}\n")},
+	}
+	tests := []struct {
+		files []string
+		want  string
+	}{
+		{[]string{"a.vert", "sampler.frag"}, "sampler.frag:3: uniform sampler2D tex: no Go setter for sampler2D"},
+		{[]string{"a.vert", "array.frag"}, "array.frag:2: uniform vec4[2] lights: no Go setter for vec4[2]"},
+		{[]string{"a.vert", "clash.frag"}, "clash.frag:2: uniform type is declared as both vec3 and vec2"},
+		{[]string{"a.vert", "syntax.frag"}, "syntax.frag:2: syntax error, unexpected ';'"},
+		{[]string{"shared.vert"}, "inputs m and p share location 2"},
+		{[]string{"a.txt"}, "a.txt: unknown shader stage; want .vert, .geom or .frag"},
+		{[]string{"none.vert"}, "shader: open none.vert: file does not exist"},
+	}
+	for _, tt := range tests {
+		_, err := read(fsys, tt.files)
+		if err == nil {
+			t.Errorf("%v: no error", tt.files)
+		} else if err.Error() != tt.want {
+			t.Errorf("%v: error %q, want %q", tt.files, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Command glcheck lists the attributes and uniforms of the programs
+// that tutorials link, and checks that the tutorials' Go code agrees
+// with them.
+//
+// Usage:
+//
+//	glcheck [-size WxH] [-t elapsed] [chapter/name ...]
+//
+// Each named tutorial, or every tutorial if none are named, draws a
+// frame with the software renderer, as glrender does. For every
+// program the tutorial links, glcheck prints its active attributes
+// and uniforms, with their types, array sizes and locations; members
+// of uniform blocks have no location. Then it prints the mistakes
+// found by package check, such as looking up a uniform the program
+// does not have, or setting a vec2 with three values, and exits with
+// status 1 if there were any.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/gfx/check"
+	"github.com/droyo/gltut/internal/gfx/soft"
+	"github.com/droyo/gltut/internal/glutil"
+	"github.com/droyo/gltut/internal/tutorial"
+	_ "github.com/droyo/gltut/internal/tutorial/all"
+)
+
+var (
+	size    = flag.String("size", "500x500", "framebuffer size, as WxH")
+	elapsed = flag.Duration("t", 0, "time since the tutorial started")
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: glcheck [-size WxH] [-t elapsed] [chapter/name ...]")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("glcheck: ")
+	flag.Usage = usage
+	flag.Parse()
+
+	var width, height int
+	if _, err := fmt.Sscanf(*size, "%dx%d", &width, &height); err != nil || width <= 0 || height <= 0 {
+		log.Fatalf("bad size %q", *size)
+	}
+
+	var list []*tutorial.Tutorial
+	if flag.NArg() == 0 {
+		list = tutorial.All()
+	}
+	for _, id := range flag.Args() {
+		t, err := tutorial.Lookup(id)
+		if err != nil {
+			log.Fatal(err)
+		}
+		list = append(list, t)
+	}
+
+	failed := false
+	for _, t := range list {
+		if err := inspect(os.Stdout, t, width, height, *elapsed); err != nil {
+			log.Print(err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// inspect draws a frame of t, printing the interface of every
+// program it links to w, and returns the mistakes it makes.
+func inspect(w io.Writer, t *tutorial.Tutorial, width, height int, elapsed time.Duration) error {
+	ctx := soft.New(width, height)
+	checked := check.New(ctx)
+	checked.Linked = func(p gfx.Program, in *glutil.Interface) {
+		fmt.Fprintf(w, "%s: program %d\n", t.ID(), p)
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		for _, v := range in.Attribs {
+			row(tw, "in", v)
+		}
+		for _, v := range in.Uniforms {
+			row(tw, "uniform", v)
+		}
+		tw.Flush()
+	}
+	if err := t.Render(checked, width, height, elapsed); err != nil {
+		return err
+	}
+	for _, err := range []error{checked.Err(), ctx.Err()} {
+		if err != nil {
+			return fmt.Errorf("%s: %v", t.ID(), err)
+		}
+	}
+	return nil
+}
+
+func row(w io.Writer, kind string, v glutil.Variable) {
+	name := strings.TrimSuffix(v.Name, "[0]")
+	if name != v.Name || v.Size > 1 {
+		name = fmt.Sprintf("%s[%d]", name, v.Size)
+	}
+	loc := "-"
+	if v.Location >= 0 {
+		loc = fmt.Sprintf("location %d", v.Location)
+	}
+	fmt.Fprintf(w, "\t%s\t%s\t%s\t%s\n", kind, v.Type, name, loc)
+}
@@ -0,0 +1,90 @@
+// Command glgif records an animated tutorial as an animated GIF.
+//
+// Usage:
+//
+//	glgif [-size WxH] [-n frames] [-step duration] [-o file] chapter/name
+//
+// The frames are drawn with the software renderer, with the
+// animation advancing by a fixed step between frames, so the same
+// flags always produce the same file. The GIF shows each frame for
+// one step, rounded to the nearest hundredth of a second; steps that
+// are a multiple of 10ms play back at the right speed.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/gif"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/droyo/gltut/internal/capture"
+	"github.com/droyo/gltut/internal/tutorial"
+	_ "github.com/droyo/gltut/internal/tutorial/all"
+)
+
+var (
+	size   = flag.String("size", "250x250", "frame size, as WxH")
+	frames = flag.Int("n", 100, "number of frames")
+	step   = flag.Duration("step", 20*time.Millisecond, "animation time between frames")
+	output = flag.String("o", "", "output file (default chapter-name.gif)")
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: glgif [-size WxH] [-n frames] [-step duration] [-o file] chapter/name")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("glgif: ")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() != 1 || *frames <= 0 || *step <= 0 {
+		usage()
+	}
+
+	var width, height int
+	if _, err := fmt.Sscanf(*size, "%dx%d", &width, &height); err != nil || width <= 0 || height <= 0 {
+		log.Fatalf("bad size %q", *size)
+	}
+	t, err := tutorial.Lookup(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !t.Animated {
+		log.Printf("warning: %s is not animated; every frame will be the same", t.ID())
+	}
+	name := *output
+	if name == "" {
+		name = strings.Replace(t.ID(), "/", "-", -1) + ".gif"
+	}
+
+	var list []*image.RGBA
+	err = t.Frames(width, height, *frames, *step, func(i int, img *image.RGBA) error {
+		list = append(list, img)
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := write(name, capture.GIF(list, *step)); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func write(name string, g *gif.GIF) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := gif.EncodeAll(f, g); err != nil {
+		f.Close()
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	return f.Close()
+}
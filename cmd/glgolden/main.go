@@ -0,0 +1,74 @@
+// Command glgolden checks every tutorial against golden images.
+//
+// Usage:
+//
+//	glgolden [-dir dir] [-tolerance n] [-update] [chapter/name ...]
+//
+// Each tutorial is drawn with the software renderer at a fixed size
+// and a fixed time since it started, and compared against
+// dir/chapter-name.png. When a tutorial does not match, glgolden
+// writes dir/chapter-name.diff.png, which shows the new frame, the
+// golden image and the differing pixels side by side, and exits
+// with a non-zero status.
+//
+// With -update, glgolden writes the new frames as the golden images
+// instead of comparing them. Review the changed images before
+// checking them in.
+//
+// The same check runs as a test of package internal/golden; use
+// "go test ./internal/golden -update" to update the images from
+// there.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/droyo/gltut/internal/golden"
+	"github.com/droyo/gltut/internal/tutorial"
+	_ "github.com/droyo/gltut/internal/tutorial/all"
+)
+
+var (
+	dir       = flag.String("dir", "testdata/golden", "directory holding the golden images")
+	tolerance = flag.Int("tolerance", golden.Tolerance, "largest allowed difference in a color channel, from 0 to 255")
+	update    = flag.Bool("update", false, "overwrite the golden images with the current output")
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: glgolden [-dir dir] [-tolerance n] [-update] [chapter/name ...]")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("glgolden: ")
+	flag.Usage = usage
+	flag.Parse()
+
+	var list []*tutorial.Tutorial
+	if flag.NArg() == 0 {
+		list = tutorial.All()
+	}
+	for _, id := range flag.Args() {
+		t, err := tutorial.Lookup(id)
+		if err != nil {
+			log.Fatal(err)
+		}
+		list = append(list, t)
+	}
+
+	failed := 0
+	for _, t := range list {
+		if err := golden.Check(t, *dir, *tolerance, *update); err != nil {
+			log.Printf("FAIL %s: %v", t.ID(), err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		log.Fatalf("%d of %d tutorials failed", failed, len(list))
+	}
+}
@@ -0,0 +1,89 @@
+// Command glrender draws tutorials to PNG files with the software
+// renderer. It does not need a window, an X server or a GPU.
+//
+// Usage:
+//
+//	glrender [-size WxH] [-t elapsed] [-o dir] [chapter/name ...]
+//
+// Each named tutorial, or every tutorial if none are named, is drawn
+// as it appears once the elapsed time has passed, and written to
+// dir/chapter-name.png.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/droyo/gltut/internal/tutorial"
+	_ "github.com/droyo/gltut/internal/tutorial/all"
+)
+
+var (
+	size    = flag.String("size", "500x500", "framebuffer size, as WxH")
+	elapsed = flag.Duration("t", 0, "time since the tutorial started")
+	outDir  = flag.String("o", ".", "directory to write images to")
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: glrender [-size WxH] [-t elapsed] [-o dir] [chapter/name ...]")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("glrender: ")
+	flag.Usage = usage
+	flag.Parse()
+
+	var width, height int
+	if _, err := fmt.Sscanf(*size, "%dx%d", &width, &height); err != nil || width <= 0 || height <= 0 {
+		log.Fatalf("bad size %q", *size)
+	}
+
+	var list []*tutorial.Tutorial
+	if flag.NArg() == 0 {
+		list = tutorial.All()
+	}
+	for _, id := range flag.Args() {
+		t, err := tutorial.Lookup(id)
+		if err != nil {
+			log.Fatal(err)
+		}
+		list = append(list, t)
+	}
+
+	failed := false
+	for _, t := range list {
+		if err := render(t, width, height, *elapsed); err != nil {
+			log.Print(err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func render(t *tutorial.Tutorial, width, height int, elapsed time.Duration) error {
+	img, err := t.Image(width, height, elapsed)
+	if err != nil {
+		return err
+	}
+	name := filepath.Join(*outDir, strings.Replace(t.ID(), "/", "-", -1)+".png")
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,179 @@
+// Command gltut lists and runs the tutorials.
+//
+// Usage:
+//
+//	gltut list
+//	gltut [-step duration] [-capture-dir dir] [-capture-depth] [-capture-frames n] [-reload=false] [-check=false] run [chapter/name]
+//
+// The run command opens a window and runs the named tutorial, or the
+// first tutorial if no name is given. While a tutorial is running,
+// Page Down and Page Up switch to the next and previous tutorial in
+// the same window, and Escape quits.
+//
+// Animations follow the wall clock. With -step, they instead advance
+// by the given duration every frame, so that every run draws the same
+// frames. P pauses an animation, the period key steps through a
+// paused animation one frame at a time, minus and equals halve and
+// double its speed, and 0 restores the normal speed.
+//
+// Pressing C saves the next frame as a timestamped PNG image in the
+// -capture-dir directory, along with an image of the depth buffer if
+// -capture-depth is set. With -capture-frames, the first n frames of
+// every tutorial are saved as a numbered sequence.
+//
+// Tutorials read their shaders from the .vert and .frag files in
+// their source directories, when those are on disk, and fall back to
+// the copies built into the binary. While a tutorial runs, its shader
+// files are watched, and when one is saved the tutorial is rebuilt
+// with the new shaders, in the state it was in. If a shader fails to
+// compile or link, its info log is printed and the tutorial keeps
+// running with the shaders that worked. -reload=false turns this off.
+//
+// Calls that disagree with a program's attributes and uniforms, such
+// as looking up a uniform the shaders do not have, or setting a vec2
+// with three values, are logged as they happen, once each.
+// -check=false turns this off.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"aqwari.net/exp/display"
+	"aqwari.net/exp/gl"
+	"github.com/droyo/gltut/internal/clock"
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/gfx/check"
+	"github.com/droyo/gltut/internal/gfx/hw"
+	"github.com/droyo/gltut/internal/tutorial"
+	_ "github.com/droyo/gltut/internal/tutorial/all"
+)
+
+var config = display.Config{
+	"Title":          "gltut",
+	"Geometry":       "500x500",
+	"OpenGL Version": "3.2",
+}
+
+var (
+	step          = flag.Duration("step", 0, "advance animations by a fixed `duration` every frame")
+	captureDir    = flag.String("capture-dir", ".", "directory to save captured frames in")
+	captureDepth  = flag.Bool("capture-depth", false, "save the depth buffer with every captured frame")
+	captureFrames = flag.Int("capture-frames", 0, "save the first `n` frames of every tutorial")
+	reload        = flag.Bool("reload", true, "rebuild tutorials when their shader files change")
+	checkBindings = flag.Bool("check", true, "log calls that disagree with the shaders' attributes and uniforms")
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: gltut list")
+	fmt.Fprintln(os.Stderr, "       gltut [-step duration] [-capture-dir dir] [-capture-depth] [-capture-frames n] [-reload=false] [-check=false] run [chapter/name]")
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("gltut: ")
+	flag.Usage = usage
+	flag.Parse()
+
+	switch flag.Arg(0) {
+	case "list":
+		list(os.Stdout)
+	case "run":
+		if flag.NArg() > 2 {
+			usage()
+		}
+		if err := run(flag.Arg(1)); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		usage()
+	}
+}
+
+func list(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for _, t := range tutorial.All() {
+		fmt.Fprintf(tw, "%s\t%s\n", t.ID(), t.Title)
+		fmt.Fprintf(tw, "\t%s\n", t.Doc)
+		fmt.Fprintf(tw, "\t%s\n", t.URL)
+	}
+	tw.Flush()
+}
+
+// run runs tutorials until the user quits. It returns errors rather
+// than exiting, so that the window is closed on the way out.
+func run(id string) error {
+	all := tutorial.All()
+	if len(all) == 0 {
+		return errors.New("no tutorials registered")
+	}
+	cur := 0
+	if id != "" {
+		t, err := tutorial.Lookup(id)
+		if err != nil {
+			return err
+		}
+		for i := range all {
+			if all[i] == t {
+				cur = i
+			}
+		}
+	}
+
+	win, err := display.Open(config)
+	if err != nil {
+		return err
+	}
+	defer win.Close()
+	if err := gl.Init(config["OpenGL Version"]); err != nil {
+		return err
+	}
+
+	var ctx gfx.Context = hw.Context{}
+	if *checkBindings {
+		ctx = check.New(ctx)
+	}
+	w := &tutorial.Window{
+		Window:  win,
+		Context: ctx,
+		Capture: tutorial.Capture{
+			Dir:    *captureDir,
+			Depth:  *captureDepth,
+			Frames: *captureFrames,
+		},
+		Reload: *reload,
+	}
+	fmt.Sscanf(config["Geometry"], "%dx%d", &w.Size.Width, &w.Size.Height)
+	for {
+		t := all[cur]
+		log.Printf("%s: %s\n\t%s\n\t%s", t.ID(), t.Title, t.Doc, t.URL)
+		tutorial.Reset(w.Context, w.Size.Width, w.Size.Height)
+		w.Clock = newClock()
+		a, err := t.Run(w)
+		if err != nil {
+			return err
+		}
+		switch a {
+		case tutorial.Quit:
+			return nil
+		case tutorial.Next:
+			cur = (cur + 1) % len(all)
+		case tutorial.Prev:
+			cur = (cur + len(all) - 1) % len(all)
+		}
+	}
+}
+
+// newClock returns the clock for a tutorial that is about to start.
+func newClock() *clock.Control {
+	if *step > 0 {
+		return clock.NewControl(clock.NewFixed(*step))
+	}
+	return clock.NewControl(clock.NewReal())
+}
@@ -0,0 +1,83 @@
+// Package capture reads back the framebuffer and saves it as PNG
+// images, for screenshots and frame dumps.
+package capture
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/droyo/gltut/internal/gfx"
+)
+
+// Color reads the color buffer of ctx. OpenGL returns the bottom row
+// first, so the rows are flipped to give an image the right way up.
+func Color(ctx gfx.Context, width, height int) *image.RGBA {
+	buf := make([]byte, 4*width*height)
+	ctx.ReadPixels(0, 0, width, height, gfx.RGBA, gfx.Uint8, buf)
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		copy(img.Pix[y*img.Stride:], buf[(height-1-y)*4*width:(height-y)*4*width])
+	}
+	return img
+}
+
+// Depth reads the depth buffer of ctx as a grey image, the right way
+// up, in which black is the near plane and white the far plane.
+func Depth(ctx gfx.Context, width, height int) *image.Gray16 {
+	buf := make([]float32, width*height)
+	ctx.ReadPixels(0, 0, width, height, gfx.DEPTH_COMPONENT, gfx.Float32, buf)
+	img := image.NewGray16(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			d := buf[(height-1-y)*width+x]
+			img.SetGray16(x, y, color.Gray16{uint16(clamp01(d)*0xffff + 0.5)})
+		}
+	}
+	return img
+}
+
+func clamp01(x float32) float32 {
+	switch {
+	case x < 0:
+		return 0
+	case x > 1:
+		return 1
+	}
+	return x
+}
+
+// Name returns a file name for a capture of the tutorial with the
+// given ID, such as "05-depth-clamping-20150102-150405.000.png".
+// The suffix, if any, is added before the extension.
+func Name(id string, t time.Time, suffix string) string {
+	return fmt.Sprintf("%s-%s%s.png", strings.Replace(id, "/", "-", -1),
+		t.Format("20060102-150405.000"), suffix)
+}
+
+// FrameName returns a file name for frame n of a sequence captured
+// from the tutorial with the given ID, such as
+// "05-depth-clamping-0007.png".
+func FrameName(id string, n int, suffix string) string {
+	return fmt.Sprintf("%s-%04d%s.png", strings.Replace(id, "/", "-", -1), n, suffix)
+}
+
+// Save writes img to dir/name as a PNG image, and returns the path
+// of the file.
+func Save(dir, name string, img image.Image) (string, error) {
+	path := filepath.Join(dir, name)
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return "", fmt.Errorf("%s: %v", path, err)
+	}
+	return path, f.Close()
+}
@@ -0,0 +1,136 @@
+package capture
+
+import (
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/gfx/soft"
+)
+
+// bottomHalf returns a 2x4 soft context with its bottom half, in
+// OpenGL's coordinates, drawn in red at a depth of 0.25. The top
+// half is left blue, at the far plane.
+func bottomHalf(t *testing.T) *soft.Context {
+	t.Helper()
+	c := soft.New(2, 4)
+	p := c.CreateProgram()
+	for _, s := range []struct {
+		typ gfx.Enum
+		src string
+	}{
+		{gfx.VERTEX_SHADER, "#version 330\nlayout(location = 0) in vec4 position;\nvoid main() { gl_Position = position; }\n"},
+		{gfx.FRAGMENT_SHADER, "#version 330\nout vec4 color;\nvoid main() { color = vec4(1.0, 0.0, 0.0, 1.0); }\n"},
+	} {
+		sh := c.CreateShader(s.typ)
+		c.ShaderSource(sh, []byte(s.src))
+		if err := c.CompileShader(sh); err != nil {
+			t.Fatal(err)
+		}
+		c.AttachShader(p, sh)
+	}
+	if err := c.LinkProgram(p); err != nil {
+		t.Fatal(err)
+	}
+	c.UseProgram(p)
+	b := c.GenBuffers(1)[0]
+	c.BindBuffer(gfx.ARRAY_BUFFER, b)
+	c.BufferData(gfx.ARRAY_BUFFER, []float32{
+		-1, -1, -0.5, 1, 1, -1, -0.5, 1, 1, 0, -0.5, 1,
+		-1, -1, -0.5, 1, 1, 0, -0.5, 1, -1, 0, -0.5, 1,
+	}, gfx.STATIC_DRAW)
+	c.EnableVertexAttribArray(0)
+	c.VertexAttribPointer(0, 4, gfx.Float32, false, 0, 0)
+	c.Enable(gfx.DEPTH_TEST)
+	c.ClearColor(0, 0, 1, 1)
+	c.Clear(gfx.COLOR_BUFFER_BIT | gfx.DEPTH_BUFFER_BIT)
+	c.DrawArrays(gfx.TRIANGLES, 0, 6)
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestColor(t *testing.T) {
+	img := Color(bottomHalf(t), 2, 4)
+	for y := 0; y < 4; y++ {
+		want := [3]uint8{0, 0, 255}
+		if y >= 2 {
+			want = [3]uint8{255, 0, 0}
+		}
+		for x := 0; x < 2; x++ {
+			c := img.RGBAAt(x, y)
+			if got := [3]uint8{c.R, c.G, c.B}; got != want {
+				t.Errorf("pixel %d,%d is %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestDepth(t *testing.T) {
+	img := Depth(bottomHalf(t), 2, 4)
+	for y := 0; y < 4; y++ {
+		want := uint16(0xffff)
+		if y >= 2 {
+			want = 0x4000
+		}
+		for x := 0; x < 2; x++ {
+			if got := img.Gray16At(x, y).Y; got != want {
+				t.Errorf("depth at %d,%d is %#x, want %#x", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	at := time.Date(2015, 1, 2, 15, 4, 5, 6e6, time.UTC)
+	tests := []struct {
+		got, want string
+	}{
+		{Name("05/depth-clamping", at, ""), "05-depth-clamping-20150102-150405.006.png"},
+		{Name("05/depth-clamping", at, "-depth"), "05-depth-clamping-20150102-150405.006-depth.png"},
+		{FrameName("05/depth-clamping", 7, ""), "05-depth-clamping-0007.png"},
+		{FrameName("05/depth-clamping", 12345, "-depth"), "05-depth-clamping-12345-depth.png"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+}
+
+func TestSave(t *testing.T) {
+	dir := t.TempDir()
+	img := Color(bottomHalf(t), 2, 4)
+	path, err := Save(dir, FrameName("05/depth-clamping", 0, ""), img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "05-depth-clamping-0000.png"); path != want {
+		t.Errorf("saved to %s, want %s", path, want)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	saved, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 2; x++ {
+			r0, g0, b0, _ := saved.At(x, y).RGBA()
+			r1, g1, b1, _ := img.At(x, y).RGBA()
+			if r0 != r1 || g0 != g1 || b0 != b1 {
+				t.Errorf("saved pixel %d,%d differs", x, y)
+			}
+		}
+	}
+	if _, err := Save(filepath.Join(dir, "missing"), "x.png", img); err == nil {
+		t.Error("saving to a missing directory did not fail")
+	}
+}
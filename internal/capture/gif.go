@@ -0,0 +1,190 @@
+package capture
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"sort"
+	"time"
+)
+
+// GIF encodes frames as an animated GIF that loops forever, showing
+// each frame for the given delay. GIF delays are counted in
+// hundredths of a second, so delay is rounded to the nearest
+// hundredth, with a minimum of two, since most viewers slow down
+// faster animations. All frames share one palette of up to 256
+// colors, chosen by Quantize. Transparent pixels are drawn over
+// black.
+func GIF(frames []*image.RGBA, delay time.Duration) *gif.GIF {
+	d := int((delay + 5*time.Millisecond) / (10 * time.Millisecond))
+	if d < 2 {
+		d = 2
+	}
+	opaque := make([]*image.RGBA, len(frames))
+	for i, f := range frames {
+		opaque[i] = image.NewRGBA(f.Bounds())
+		draw.Draw(opaque[i], f.Bounds(), image.Black, image.ZP, draw.Src)
+		draw.Draw(opaque[i], f.Bounds(), f, f.Bounds().Min, draw.Over)
+	}
+	pal := Quantize(opaque, 256)
+	g := &gif.GIF{LoopCount: 0}
+	for _, f := range opaque {
+		p := image.NewPaletted(f.Bounds(), pal)
+		draw.Draw(p, f.Bounds(), f, f.Bounds().Min, draw.Src)
+		g.Image = append(g.Image, p)
+		g.Delay = append(g.Delay, d)
+	}
+	return g
+}
+
+// A colorBox is a box in RGB space holding some of the colors of an
+// image, for median cut quantization.
+type colorBox struct {
+	colors []histEntry
+	count  int
+}
+
+type histEntry struct {
+	c     [3]uint8 // bucket, 5 bits per channel
+	count int
+	sum   [3]int // sum of the colors in the bucket
+}
+
+// Quantize chooses a palette of at most n colors for images, using
+// the median cut algorithm over a histogram of their pixels. Colors
+// are bucketed to 5 bits per channel, and each palette entry is the
+// mean of the pixels it stands for, so images with few colors, like
+// most of the tutorials, are reproduced exactly.
+func Quantize(images []*image.RGBA, n int) color.Palette {
+	hist := make(map[[3]uint8]*histEntry)
+	for _, img := range images {
+		b := img.Bounds()
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			row := img.Pix[img.PixOffset(b.Min.X, y):img.PixOffset(b.Max.X, y)]
+			for i := 0; i < len(row); i += 4 {
+				k := [3]uint8{row[i] >> 3, row[i+1] >> 3, row[i+2] >> 3}
+				e := hist[k]
+				if e == nil {
+					e = &histEntry{c: k}
+					hist[k] = e
+				}
+				e.count++
+				for ch := range e.sum {
+					e.sum[ch] += int(row[i+ch])
+				}
+			}
+		}
+	}
+	all := &colorBox{}
+	for _, e := range hist {
+		all.colors = append(all.colors, *e)
+		all.count += e.count
+	}
+	// map iteration order is random; sort so the palette is the
+	// same every time.
+	sort.Slice(all.colors, func(i, j int) bool {
+		a, b := all.colors[i].c, all.colors[j].c
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		if a[1] != b[1] {
+			return a[1] < b[1]
+		}
+		return a[2] < b[2]
+	})
+	boxes := []*colorBox{all}
+	for len(boxes) < n {
+		i := widest(boxes)
+		if i < 0 {
+			break
+		}
+		a, b := split(boxes[i])
+		boxes[i] = a
+		boxes = append(boxes, b)
+	}
+	pal := make(color.Palette, 0, len(boxes))
+	for _, box := range boxes {
+		if box.count > 0 {
+			pal = append(pal, box.average())
+		}
+	}
+	return pal
+}
+
+// widest returns the index of the box that most needs splitting, or
+// -1 if no box can be split.
+func widest(boxes []*colorBox) int {
+	best, bestScore := -1, 0
+	for i, box := range boxes {
+		if len(box.colors) < 2 {
+			continue
+		}
+		_, r := box.longestAxis()
+		if score := r * box.count; score > bestScore || best < 0 {
+			best, bestScore = i, score
+		}
+	}
+	return best
+}
+
+// longestAxis returns the channel along which the colors in the box
+// vary the most, and their range along it.
+func (box *colorBox) longestAxis() (axis, span int) {
+	for ch := 0; ch < 3; ch++ {
+		lo, hi := 255, 0
+		for _, e := range box.colors {
+			v := int(e.c[ch])
+			if v < lo {
+				lo = v
+			}
+			if v > hi {
+				hi = v
+			}
+		}
+		if hi-lo > span || ch == 0 {
+			axis, span = ch, hi-lo
+		}
+	}
+	return axis, span
+}
+
+// split divides box at the median pixel along its longest axis.
+func split(box *colorBox) (*colorBox, *colorBox) {
+	axis, _ := box.longestAxis()
+	sort.SliceStable(box.colors, func(i, j int) bool {
+		return box.colors[i].c[axis] < box.colors[j].c[axis]
+	})
+	half, sum, cut := box.count/2, 0, 1
+	for i, e := range box.colors[:len(box.colors)-1] {
+		sum += e.count
+		cut = i + 1
+		if sum >= half {
+			break
+		}
+	}
+	a := &colorBox{colors: box.colors[:cut]}
+	b := &colorBox{colors: box.colors[cut:]}
+	for _, e := range a.colors {
+		a.count += e.count
+	}
+	b.count = box.count - a.count
+	return a, b
+}
+
+// average returns the mean color of the pixels in the box.
+func (box *colorBox) average() color.Color {
+	var sum [3]int
+	for _, e := range box.colors {
+		for ch := range sum {
+			sum[ch] += e.sum[ch]
+		}
+	}
+	n := box.count
+	return color.RGBA{
+		uint8((sum[0] + n/2) / n),
+		uint8((sum[1] + n/2) / n),
+		uint8((sum[2] + n/2) / n),
+		255,
+	}
+}
@@ -0,0 +1,110 @@
+package capture
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+)
+
+func solid(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+func TestGIFDelay(t *testing.T) {
+	tests := []struct {
+		delay time.Duration
+		want  int
+	}{
+		{time.Second / 10, 10},
+		{time.Second / 30, 3},
+		{44 * time.Millisecond, 4},
+		{45 * time.Millisecond, 5},
+		{time.Second / 60, 2},
+		{0, 2},
+	}
+	frames := []*image.RGBA{solid(2, 2, color.RGBA{255, 0, 0, 255}), solid(2, 2, color.RGBA{0, 255, 0, 255})}
+	for _, tt := range tests {
+		g := GIF(frames, tt.delay)
+		if len(g.Image) != 2 || len(g.Delay) != 2 {
+			t.Fatalf("%v: %d images and %d delays, want 2", tt.delay, len(g.Image), len(g.Delay))
+		}
+		for _, d := range g.Delay {
+			if d != tt.want {
+				t.Errorf("delay %v gave %d hundredths, want %d", tt.delay, d, tt.want)
+			}
+		}
+		if g.LoopCount != 0 {
+			t.Errorf("loop count %d, want 0 (forever)", g.LoopCount)
+		}
+	}
+}
+
+func TestGIFPalette(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	green := color.RGBA{0, 200, 10, 255}
+	a := solid(4, 4, red)
+	b := solid(4, 4, green)
+	// A transparent pixel is drawn over black.
+	b.SetRGBA(3, 3, color.RGBA{})
+	g := GIF([]*image.RGBA{a, b}, time.Second/10)
+	pal := g.Image[0].Palette
+	if len(pal) != 3 {
+		t.Fatalf("palette has %d colors, want 3: %v", len(pal), pal)
+	}
+	for i, img := range g.Image {
+		if !sameColors(img.Palette, pal) {
+			t.Errorf("frame %d has its own palette", i)
+		}
+	}
+	check := func(frame, x, y int, want color.RGBA) {
+		t.Helper()
+		if got := color.RGBAModel.Convert(g.Image[frame].At(x, y)); got != want {
+			t.Errorf("frame %d pixel %d,%d is %v, want %v", frame, x, y, got, want)
+		}
+	}
+	check(0, 0, 0, red)
+	check(1, 0, 0, green)
+	check(1, 3, 3, color.RGBA{0, 0, 0, 255})
+}
+
+func TestQuantize(t *testing.T) {
+	// A gradient of 64 greys needs more than 4 colors; each palette
+	// entry is the mean of the greys it stands for.
+	img := image.NewRGBA(image.Rect(0, 0, 64, 1))
+	for x := 0; x < 64; x++ {
+		img.SetRGBA(x, 0, color.RGBA{uint8(4 * x), uint8(4 * x), uint8(4 * x), 255})
+	}
+	pal := Quantize([]*image.RGBA{img}, 4)
+	if len(pal) != 4 {
+		t.Fatalf("palette has %d colors, want 4: %v", len(pal), pal)
+	}
+	for _, c := range pal {
+		r, g, b, _ := c.RGBA()
+		if r != g || g != b {
+			t.Errorf("palette color %v is not grey", c)
+		}
+	}
+	if !sameColors(Quantize([]*image.RGBA{img}, 4), pal) {
+		t.Error("Quantize is not deterministic")
+	}
+	if pal := Quantize([]*image.RGBA{solid(3, 3, color.RGBA{10, 20, 30, 255})}, 256); len(pal) != 1 {
+		t.Errorf("one color gave a palette of %d", len(pal))
+	}
+}
+
+func sameColors(a, b color.Palette) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,152 @@
+// Package clock provides the time source for animated tutorials.
+// A tutorial draws each frame at the time reported by its Clock,
+// rather than reading the wall clock itself, so a frame can be
+// reproduced exactly and an animation can be paused, stepped or
+// slowed down.
+package clock
+
+import "time"
+
+// A Clock reports the time since an animation started.
+type Clock interface {
+	// Now returns the current animation time.
+	Now() time.Duration
+
+	// Tick is called once after every frame is drawn.
+	Tick()
+}
+
+// Real follows the wall clock.
+type Real struct {
+	start time.Time
+}
+
+// NewReal returns a Real clock that starts at zero.
+func NewReal() *Real {
+	return &Real{start: time.Now()}
+}
+
+func (c *Real) Now() time.Duration { return time.Since(c.start) }
+func (c *Real) Tick()              {}
+
+// Fixed advances by Step every frame, however long the frame took
+// to draw.
+type Fixed struct {
+	Step time.Duration
+	t    time.Duration
+}
+
+// NewFixed returns a Fixed clock that starts at zero and advances
+// by step every frame.
+func NewFixed(step time.Duration) *Fixed {
+	return &Fixed{Step: step}
+}
+
+func (c *Fixed) Now() time.Duration { return c.t }
+func (c *Fixed) Tick()              { c.t += c.Step }
+
+// Manual only changes when it is told to.
+type Manual struct {
+	t time.Duration
+}
+
+// NewManual returns a Manual clock stopped at t.
+func NewManual(t time.Duration) *Manual {
+	return &Manual{t: t}
+}
+
+func (c *Manual) Now() time.Duration      { return c.t }
+func (c *Manual) Tick()                   {}
+func (c *Manual) Set(t time.Duration)     { c.t = t }
+func (c *Manual) Advance(d time.Duration) { c.t += d }
+
+// A Control wraps a Clock and lets the user pause, single-step and
+// change the speed of the animation while it runs. Changes take
+// effect from the current time, so the animation does not jump.
+type Control struct {
+	src    Clock
+	base   time.Duration // animation time at the last change
+	last   time.Duration // src time at the last change
+	scale  float64
+	paused bool
+
+	// FrameStep is how far Step advances a paused animation.
+	FrameStep time.Duration
+}
+
+// NewControl returns a Control for src, running at normal speed.
+func NewControl(src Clock) *Control {
+	return &Control{
+		src:       src,
+		last:      src.Now(),
+		scale:     1,
+		FrameStep: time.Second / 60,
+	}
+}
+
+func (c *Control) Now() time.Duration {
+	if c.paused {
+		return c.base
+	}
+	return c.base + time.Duration(float64(c.src.Now()-c.last)*c.scale)
+}
+
+func (c *Control) Tick() { c.src.Tick() }
+
+// rebase starts a new segment of the animation at the current time.
+func (c *Control) rebase() {
+	c.base = c.Now()
+	c.last = c.src.Now()
+}
+
+// Paused reports whether the animation is paused.
+func (c *Control) Paused() bool { return c.paused }
+
+// SetPaused pauses or resumes the animation.
+func (c *Control) SetPaused(paused bool) {
+	c.rebase()
+	c.paused = paused
+}
+
+// Step advances a paused animation by one FrameStep. It pauses the
+// animation if it is running.
+func (c *Control) Step() {
+	c.SetPaused(true)
+	c.base += c.FrameStep
+}
+
+// Scale returns the speed of the animation relative to its source.
+func (c *Control) Scale() float64 { return c.scale }
+
+// SetScale sets the speed of the animation relative to its source.
+// A scale of 0.5 runs at half speed. Negative scales are treated
+// as zero.
+func (c *Control) SetScale(scale float64) {
+	if scale < 0 {
+		scale = 0
+	}
+	c.rebase()
+	c.scale = scale
+}
+
+// Phase returns how far t is through the current cycle of an
+// animation that repeats every period, from 0 up to but not
+// including 1.
+func Phase(t, period time.Duration) float32 {
+	p := t % period
+	if p < 0 {
+		p += period
+	}
+	return float32(float64(p) / float64(period))
+}
+
+// PingPong is like Phase, but goes from 0 to 1 in the first half of
+// the cycle and back to 0 in the second, for use as a lerp factor
+// that does not jump when the cycle repeats.
+func PingPong(t, period time.Duration) float32 {
+	x := 2 * Phase(t, period)
+	if x > 1 {
+		x = 2 - x
+	}
+	return x
+}
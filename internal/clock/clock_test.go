@@ -0,0 +1,79 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPhase(t *testing.T) {
+	const period = 4 * time.Second
+	tests := []struct {
+		t    time.Duration
+		want float32
+	}{
+		{0, 0},
+		{time.Second, 0.25},
+		{2 * time.Second, 0.5},
+		{period, 0},
+		{period + time.Second, 0.25},
+		{-time.Second, 0.75},
+		{-period, 0},
+		{-period - 3*time.Second, 0.25},
+	}
+	for _, tt := range tests {
+		if got := Phase(tt.t, period); got != tt.want {
+			t.Errorf("Phase(%v, %v) = %v, want %v", tt.t, period, got, tt.want)
+		}
+	}
+}
+
+func TestPingPong(t *testing.T) {
+	const period = 4 * time.Second
+	tests := []struct {
+		t    time.Duration
+		want float32
+	}{
+		{0, 0},
+		{period / 4, 0.5},
+		{period / 2, 1},
+		{3 * period / 4, 0.5},
+		{period, 0},
+		{-period / 4, 0.5},
+	}
+	for _, tt := range tests {
+		if got := PingPong(tt.t, period); got != tt.want {
+			t.Errorf("PingPong(%v, %v) = %v, want %v", tt.t, period, got, tt.want)
+		}
+	}
+}
+
+func TestControl(t *testing.T) {
+	const step = 10 * time.Millisecond
+	c := NewControl(NewFixed(step))
+	tick := func(n int) {
+		for i := 0; i < n; i++ {
+			c.Tick()
+		}
+	}
+
+	tick(10)
+	if got, want := c.Now(), 100*time.Millisecond; got != want {
+		t.Fatalf("after 10 ticks, Now = %v, want %v", got, want)
+	}
+	c.SetScale(0.5)
+	tick(10)
+	if got, want := c.Now(), 150*time.Millisecond; got != want {
+		t.Fatalf("after 10 ticks at half speed, Now = %v, want %v", got, want)
+	}
+	c.SetPaused(true)
+	tick(1)
+	c.Step()
+	if got, want := c.Now(), 150*time.Millisecond+c.FrameStep; got != want {
+		t.Fatalf("after Step, Now = %v, want %v", got, want)
+	}
+	c.SetPaused(false)
+	tick(1)
+	if got, want := c.Now(), 155*time.Millisecond+c.FrameStep; got != want {
+		t.Fatalf("after resuming, Now = %v, want %v", got, want)
+	}
+}
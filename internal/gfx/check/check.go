@@ -0,0 +1,148 @@
+// Package check wraps a gfx.Context to catch Go code that disagrees
+// with the shaders it drives: looking up an attribute or uniform
+// that a program does not have, or setting a uniform with the wrong
+// number or kind of values, such as three floats for a vec2. OpenGL
+// lets most of these mistakes pass without a word, and the shader
+// reads zeros instead.
+package check
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/glutil"
+)
+
+// A Context passes every call on to the context it wraps, and
+// records the mistakes it sees, each one once.
+type Context struct {
+	gfx.Context
+
+	// Linked, if not nil, is called with the interface of every
+	// program that links.
+	Linked func(p gfx.Program, in *glutil.Interface)
+
+	programs map[gfx.Program]*program
+	current  gfx.Program
+	seen     map[string]bool
+	errs     []string
+}
+
+// A program is a linked program and the uniform locations handed
+// out for it.
+type program struct {
+	in       *glutil.Interface
+	uniforms map[gfx.Uniform]glutil.Variable
+}
+
+// New returns a Context that wraps ctx.
+func New(ctx gfx.Context) *Context {
+	return &Context{
+		Context:  ctx,
+		programs: make(map[gfx.Program]*program),
+		seen:     make(map[string]bool),
+	}
+}
+
+// Err returns the mistakes recorded since the last call to Err, one
+// per line, and clears them.
+func (c *Context) Err() error {
+	if len(c.errs) == 0 {
+		return nil
+	}
+	err := errors.New(strings.Join(c.errs, "\n"))
+	c.errs = nil
+	return err
+}
+
+func (c *Context) errorf(format string, args ...interface{}) {
+	msg := fmt.Sprintf("check: "+format, args...)
+	if !c.seen[msg] {
+		c.seen[msg] = true
+		c.errs = append(c.errs, msg)
+	}
+}
+
+func (c *Context) LinkProgram(p gfx.Program) error {
+	if err := c.Context.LinkProgram(p); err != nil {
+		delete(c.programs, p)
+		return err
+	}
+	in := glutil.Inspect(c.Context, p)
+	c.programs[p] = &program{in: in, uniforms: make(map[gfx.Uniform]glutil.Variable)}
+	if c.Linked != nil {
+		c.Linked(p, in)
+	}
+	return nil
+}
+
+func (c *Context) DeleteProgram(p gfx.Program) {
+	delete(c.programs, p)
+	c.Context.DeleteProgram(p)
+}
+
+func (c *Context) UseProgram(p gfx.Program) {
+	c.current = p
+	c.Context.UseProgram(p)
+}
+
+func (c *Context) GetAttribLocation(p gfx.Program, name string) (gfx.Attrib, error) {
+	a, err := c.Context.GetAttribLocation(p, name)
+	if prog, ok := c.programs[p]; ok {
+		if _, ok := prog.in.Attrib(name); !ok {
+			c.errorf("program %d has no active attribute %q", p, name)
+		}
+	}
+	return a, err
+}
+
+func (c *Context) GetUniformLocation(p gfx.Program, name string) (gfx.Uniform, error) {
+	u, err := c.Context.GetUniformLocation(p, name)
+	prog, ok := c.programs[p]
+	if !ok {
+		return u, err
+	}
+	v, ok := prog.in.Uniform(name)
+	switch {
+	case !ok:
+		c.errorf("program %d has no active uniform %q", p, name)
+	case err != nil:
+		c.errorf("program %d: uniform %q has no location; it may be in a uniform block", p, name)
+	default:
+		prog.uniforms[u] = v
+	}
+	return u, err
+}
+
+// uniform returns the variable at location u of the current
+// program, if it was looked up with GetUniformLocation.
+func (c *Context) uniform(u gfx.Uniform) (glutil.Variable, bool) {
+	prog, ok := c.programs[c.current]
+	if !ok {
+		return glutil.Variable{}, false
+	}
+	v, ok := prog.uniforms[u]
+	return v, ok
+}
+
+func (c *Context) Uniformf(u gfx.Uniform, v ...float32) {
+	if x, ok := c.uniform(u); ok {
+		t := x.Type
+		kind := t.Kind()
+		if t.IsMatrix() || (kind != gfx.FLOAT && kind != gfx.BOOL) || len(v) != t.Components() {
+			c.errorf("Uniformf: %d floats for uniform %s of type %s", len(v), x.Name, t)
+		}
+	}
+	c.Context.Uniformf(u, v...)
+}
+
+func (c *Context) UniformMatrix4fv(u gfx.Uniform, transpose bool, m []float32) {
+	if x, ok := c.uniform(u); ok {
+		if x.Type != glutil.Type(gfx.FLOAT_MAT4) || len(m) == 0 || len(m)%16 != 0 || len(m)/16 > x.Size {
+			c.errorf("UniformMatrix4fv: %d floats for uniform %s of type %s", len(m), x.Name, x.Type)
+		}
+	}
+	c.Context.UniformMatrix4fv(u, transpose, m)
+}
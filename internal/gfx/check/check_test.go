@@ -0,0 +1,162 @@
+package check
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/gfx/soft"
+	"github.com/droyo/gltut/internal/glutil"
+)
+
+const vertexShader = `#version 150
+in vec4 position;
+uniform vec2 offset;
+uniform mat4 bones[3];
+layout(std140) uniform Camera { mat4 cameraToClip; };
+void main() {
+	gl_Position = cameraToClip * bones[1] * (position + vec4(offset, 0, 0));
+}
+`
+
+const fragmentShader = `#version 150
+out vec4 color;
+uniform vec4 tint;
+uniform int mode;
+uniform bool on;
+void main() { color = on && mode > 0 ? tint : vec4(0); }
+`
+
+// link links the test program with a checking context around a
+// soft one, and returns both.
+func link(t *testing.T) (*Context, *glutil.Program) {
+	c := New(soft.New(1, 1))
+	p, err := glutil.NewProgram(c).Vertex([]byte(vertexShader)).Fragment([]byte(fragmentShader)).Link()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Use()
+	return c, p
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		attribs, uniforms []string
+		want              []string
+	}{
+		{[]string{"position"}, []string{"offset", "bones", "bones[2]", "tint", "mode", "on"}, nil},
+		{[]string{"colour"}, []string{"tnit", "cameraToClip"}, []string{
+			`check: program 1 has no active attribute "colour"`,
+			`check: program 1 has no active uniform "tnit"`,
+			`check: program 1: uniform "cameraToClip" has no location; it may be in a uniform block`,
+		}},
+		// An array has no element past its end.
+		{nil, []string{"bones[3]"}, []string{`check: program 1 has no active uniform "bones[3]"`}},
+	}
+	for _, tt := range tests {
+		c, p := link(t)
+		for _, name := range tt.attribs {
+			c.GetAttribLocation(p.ID, name)
+		}
+		for _, name := range tt.uniforms {
+			c.GetUniformLocation(p.ID, name)
+		}
+		checkErr(t, strings.Join(append(tt.attribs, tt.uniforms...), ", "), c.Err(), tt.want)
+	}
+}
+
+func TestUniforms(t *testing.T) {
+	tests := []struct {
+		name string
+		set  func(c *Context, u map[string]gfx.Uniform)
+		want []string
+	}{
+		{"matching", func(c *Context, u map[string]gfx.Uniform) {
+			c.Uniformf(u["offset"], 1, 2)
+			c.Uniformf(u["tint"], 1, 0, 0, 1)
+			c.Uniformf(u["on"], 1)
+			c.UniformMatrix4fv(u["bones"], false, make([]float32, 48))
+			c.UniformMatrix4fv(u["bones[1]"], false, make([]float32, 32))
+		}, nil},
+		{"too many values", func(c *Context, u map[string]gfx.Uniform) {
+			c.Uniformf(u["offset"], 1, 2, 3)
+		}, []string{"check: Uniformf: 3 floats for uniform offset of type vec2"}},
+		{"too few values", func(c *Context, u map[string]gfx.Uniform) {
+			c.Uniformf(u["tint"], 1, 0, 0)
+		}, []string{"check: Uniformf: 3 floats for uniform tint of type vec4"}},
+		{"floats for an int", func(c *Context, u map[string]gfx.Uniform) {
+			c.Uniformf(u["mode"], 1)
+		}, []string{"check: Uniformf: 1 floats for uniform mode of type int"}},
+		{"floats for a matrix", func(c *Context, u map[string]gfx.Uniform) {
+			c.Uniformf(u["bones"], 1)
+		}, []string{"check: Uniformf: 1 floats for uniform bones[0] of type mat4"}},
+		{"matrix for a vector", func(c *Context, u map[string]gfx.Uniform) {
+			c.UniformMatrix4fv(u["offset"], false, make([]float32, 16))
+		}, []string{"check: UniformMatrix4fv: 16 floats for uniform offset of type vec2"}},
+		{"past the end of an array", func(c *Context, u map[string]gfx.Uniform) {
+			c.UniformMatrix4fv(u["bones[1]"], false, make([]float32, 48))
+		}, []string{"check: UniformMatrix4fv: 48 floats for uniform bones[1] of type mat4"}},
+		{"partial matrix", func(c *Context, u map[string]gfx.Uniform) {
+			c.UniformMatrix4fv(u["bones"], false, make([]float32, 9))
+		}, []string{"check: UniformMatrix4fv: 9 floats for uniform bones[0] of type mat4"}},
+		// Each mistake is reported once.
+		{"repeated", func(c *Context, u map[string]gfx.Uniform) {
+			c.Uniformf(u["offset"], 1, 2, 3)
+			c.Uniformf(u["offset"], 1, 2, 3)
+		}, []string{"check: Uniformf: 3 floats for uniform offset of type vec2"}},
+		// Locations are only known for the program they were
+		// looked up in.
+		{"no program", func(c *Context, u map[string]gfx.Uniform) {
+			c.UseProgram(0)
+			c.Uniformf(u["offset"], 1, 2, 3)
+		}, nil},
+	}
+	for _, tt := range tests {
+		c, p := link(t)
+		u := make(map[string]gfx.Uniform)
+		for _, name := range []string{"offset", "bones", "bones[1]", "tint", "mode", "on"} {
+			u[name], _ = c.GetUniformLocation(p.ID, name)
+		}
+		if err := c.Err(); err != nil {
+			t.Fatal(err)
+		}
+		tt.set(c, u)
+		checkErr(t, tt.name, c.Err(), tt.want)
+		if err := c.Err(); err != nil {
+			t.Errorf("%s: second Err returned %v, want nil", tt.name, err)
+		}
+	}
+}
+
+func TestLinked(t *testing.T) {
+	c := New(soft.New(1, 1))
+	var linked []gfx.Program
+	c.Linked = func(p gfx.Program, in *glutil.Interface) {
+		if _, ok := in.Uniform("tint"); !ok {
+			t.Errorf("program %d: interface %+v has no tint", p, in)
+		}
+		linked = append(linked, p)
+	}
+	p, err := glutil.NewProgram(c).Vertex([]byte(vertexShader)).Fragment([]byte(fragmentShader)).Link()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = glutil.NewProgram(c).Vertex([]byte(vertexShader)).Fragment([]byte("#version 150\nvoid main() { bogus; }\n")).Link()
+	if err == nil {
+		t.Fatal("linked a broken program")
+	}
+	if len(linked) != 1 || linked[0] != p.ID {
+		t.Errorf("Linked called for %v, want only %d", linked, p.ID)
+	}
+}
+
+func checkErr(t *testing.T, name string, err error, want []string) {
+	t.Helper()
+	var got []string
+	if err != nil {
+		got = strings.Split(err.Error(), "\n")
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("%s: reported\n\t%s\nwant\n\t%s", name, strings.Join(got, "\n\t"), strings.Join(want, "\n\t"))
+	}
+}
@@ -0,0 +1,199 @@
+// Package gfx defines the subset of OpenGL used by the tutorials
+// as an interface, so that a tutorial can draw to a window through
+// the hardware driver or to an image with the software renderer.
+//
+// The methods of Context mirror the functions of the same name in
+// aqwari.net/exp/gl, and the constants have the same values as
+// their OpenGL counterparts.
+package gfx
+
+type (
+	Enum        uint32 // an OpenGL enumerant, such as TRIANGLES
+	Type        uint32 // the type of a component in a buffer
+	Buffer      uint32
+	VertexArray uint32
+	Program     uint32
+	Shader      uint32
+	Attrib      uint32
+	Uniform     int32
+
+	// UniformBlock is the index of a uniform block in a program.
+	UniformBlock uint32
+)
+
+const (
+	COLOR_BUFFER_BIT Enum = 0x4000
+	DEPTH_BUFFER_BIT Enum = 0x0100
+
+	CULL_FACE   Enum = 0x0B44
+	DEPTH_TEST  Enum = 0x0B71
+	DEPTH_CLAMP Enum = 0x864F
+
+	FRONT          Enum = 0x0404
+	BACK           Enum = 0x0405
+	FRONT_AND_BACK Enum = 0x0408
+	CW             Enum = 0x0900
+	CCW            Enum = 0x0901
+
+	NEVER    Enum = 0x0200
+	LESS     Enum = 0x0201
+	EQUAL    Enum = 0x0202
+	LEQUAL   Enum = 0x0203
+	GREATER  Enum = 0x0204
+	NOTEQUAL Enum = 0x0205
+	GEQUAL   Enum = 0x0206
+	ALWAYS   Enum = 0x0207
+
+	ARRAY_BUFFER         Enum = 0x8892
+	ELEMENT_ARRAY_BUFFER Enum = 0x8893
+	UNIFORM_BUFFER       Enum = 0x8A11
+	STREAM_DRAW          Enum = 0x88E0
+	STATIC_DRAW          Enum = 0x88E4
+	DYNAMIC_DRAW         Enum = 0x88E8
+
+	POINTS         Enum = 0x0000
+	LINES          Enum = 0x0001
+	LINE_LOOP      Enum = 0x0002
+	LINE_STRIP     Enum = 0x0003
+	TRIANGLES      Enum = 0x0004
+	TRIANGLE_STRIP Enum = 0x0005
+	TRIANGLE_FAN   Enum = 0x0006
+
+	UNIFORM_BLOCK_DATA_SIZE Enum = 0x8A40
+	ACTIVE_UNIFORMS         Enum = 0x8B86
+	ACTIVE_ATTRIBUTES       Enum = 0x8B89
+
+	RGBA            Enum = 0x1908
+	DEPTH_COMPONENT Enum = 0x1902
+
+	FRAGMENT_SHADER Enum = 0x8B30
+	VERTEX_SHADER   Enum = 0x8B31
+	GEOMETRY_SHADER Enum = 0x8DD9
+)
+
+// The types of active attributes and uniforms, as reported by
+// GetActiveAttrib and GetActiveUniform.
+const (
+	FLOAT             Enum = 0x1406
+	FLOAT_VEC2        Enum = 0x8B50
+	FLOAT_VEC3        Enum = 0x8B51
+	FLOAT_VEC4        Enum = 0x8B52
+	INT               Enum = 0x1404
+	INT_VEC2          Enum = 0x8B53
+	INT_VEC3          Enum = 0x8B54
+	INT_VEC4          Enum = 0x8B55
+	UNSIGNED_INT      Enum = 0x1405
+	UNSIGNED_INT_VEC2 Enum = 0x8DC6
+	UNSIGNED_INT_VEC3 Enum = 0x8DC7
+	UNSIGNED_INT_VEC4 Enum = 0x8DC8
+	BOOL              Enum = 0x8B56
+	BOOL_VEC2         Enum = 0x8B57
+	BOOL_VEC3         Enum = 0x8B58
+	BOOL_VEC4         Enum = 0x8B59
+	FLOAT_MAT2        Enum = 0x8B5A
+	FLOAT_MAT3        Enum = 0x8B5B
+	FLOAT_MAT4        Enum = 0x8B5C
+	FLOAT_MAT2x3      Enum = 0x8B65
+	FLOAT_MAT2x4      Enum = 0x8B66
+	FLOAT_MAT3x2      Enum = 0x8B67
+	FLOAT_MAT3x4      Enum = 0x8B68
+	FLOAT_MAT4x2      Enum = 0x8B69
+	FLOAT_MAT4x3      Enum = 0x8B6A
+	SAMPLER_2D        Enum = 0x8B5E
+)
+
+const (
+	Int8    Type = 0x1400
+	Uint8   Type = 0x1401
+	Int16   Type = 0x1402
+	Uint16  Type = 0x1403
+	Int32   Type = 0x1404
+	Uint32  Type = 0x1405
+	Float32 Type = 0x1406
+)
+
+// Size returns the size of t in bytes.
+func (t Type) Size() int {
+	switch t {
+	case Int8, Uint8:
+		return 1
+	case Int16, Uint16:
+		return 2
+	case Int32, Uint32, Float32:
+		return 4
+	}
+	return 0
+}
+
+// A Context is an OpenGL rendering context.
+type Context interface {
+	ClearColor(r, g, b, a float32)
+	ClearDepth(d float64)
+	Clear(mask Enum)
+	Enable(cap Enum)
+	Disable(cap Enum)
+	IsEnabled(cap Enum) bool
+	CullFace(mode Enum)
+	FrontFace(mode Enum)
+	DepthFunc(fn Enum)
+	DepthMask(on bool)
+	DepthRange(near, far float64)
+	Viewport(x, y, width, height int)
+
+	CreateShader(typ Enum) Shader
+	DeleteShader(s Shader)
+	ShaderSource(s Shader, src []byte)
+	CompileShader(s Shader) error
+	CreateProgram() Program
+	DeleteProgram(p Program)
+	AttachShader(p Program, s Shader)
+	DetachShader(p Program, s Shader)
+
+	// BindAttribLocation sets the location of the vertex shader
+	// input with the given name when p is next linked. It does not
+	// override a layout(location = N) qualifier.
+	BindAttribLocation(p Program, a Attrib, name string)
+	LinkProgram(p Program) error
+	UseProgram(p Program)
+
+	// GetProgramiv returns a parameter of a linked program, such
+	// as ACTIVE_ATTRIBUTES or ACTIVE_UNIFORMS. GetActiveAttrib and
+	// GetActiveUniform describe the active attribute or uniform at
+	// an index below that count: its name, its type, such as
+	// FLOAT_VEC3, and its number of array elements.
+	GetProgramiv(p Program, pname Enum) int
+	GetActiveAttrib(p Program, index int) (name string, size int, typ Enum)
+	GetActiveUniform(p Program, index int) (name string, size int, typ Enum)
+
+	GetAttribLocation(p Program, name string) (Attrib, error)
+	GetUniformLocation(p Program, name string) (Uniform, error)
+	Uniformf(u Uniform, v ...float32)
+	UniformMatrix4fv(u Uniform, transpose bool, m []float32)
+	GetUniformBlockIndex(p Program, name string) (UniformBlock, error)
+	UniformBlockBinding(p Program, block UniformBlock, binding int)
+	GetActiveUniformBlockiv(p Program, block UniformBlock, pname Enum) int
+
+	GenBuffers(n int) []Buffer
+	DeleteBuffers(b []Buffer)
+	BindBuffer(target Enum, b Buffer)
+	BindBufferBase(target Enum, index int, b Buffer)
+	BindBufferRange(target Enum, index int, b Buffer, offset, size int)
+	BufferData(target Enum, data interface{}, usage Enum) error
+	BufferSubData(target Enum, offset int, data interface{}) error
+
+	GenVertexArrays(n int) []VertexArray
+	DeleteVertexArrays(a []VertexArray)
+	BindVertexArray(a VertexArray)
+	EnableVertexAttribArray(a Attrib)
+	DisableVertexAttribArray(a Attrib)
+	VertexAttribPointer(a Attrib, size int, typ Type, normalized bool, stride int, offset uintptr)
+
+	DrawArrays(mode Enum, first, count int)
+	DrawElements(mode Enum, count int, typ Type, offset uintptr)
+	DrawElementsBaseVertex(mode Enum, count int, typ Type, offset uintptr, base int)
+
+	// ReadPixels copies a block of the framebuffer into dst, bottom
+	// row first. The format and type must be RGBA and Uint8, with dst
+	// a []byte, or DEPTH_COMPONENT and Float32, with dst a []float32.
+	ReadPixels(x, y, width, height int, format Enum, typ Type, dst interface{})
+}
@@ -0,0 +1,171 @@
+// Package hw implements gfx.Context with the OpenGL driver, through
+// aqwari.net/exp/gl. The context must be initialized with gl.Init
+// before use.
+package hw
+
+import (
+	"aqwari.net/exp/gl"
+	"github.com/droyo/gltut/internal/gfx"
+)
+
+// Context draws with the current OpenGL context.
+type Context struct{}
+
+var _ gfx.Context = Context{}
+
+func (Context) ClearColor(r, g, b, a float32) { gl.ClearColor(r, g, b, a) }
+func (Context) ClearDepth(d float64)          { gl.ClearDepth(d) }
+func (Context) Clear(mask gfx.Enum)           { gl.Clear(gl.Enum(mask)) }
+func (Context) Enable(cap gfx.Enum)           { gl.Enable(gl.Enum(cap)) }
+func (Context) Disable(cap gfx.Enum)          { gl.Disable(gl.Enum(cap)) }
+func (Context) IsEnabled(cap gfx.Enum) bool   { return gl.IsEnabled(gl.Enum(cap)) }
+func (Context) CullFace(mode gfx.Enum)        { gl.CullFace(gl.Enum(mode)) }
+func (Context) FrontFace(mode gfx.Enum)       { gl.FrontFace(gl.Enum(mode)) }
+func (Context) DepthFunc(fn gfx.Enum)         { gl.DepthFunc(gl.Enum(fn)) }
+func (Context) DepthMask(on bool)             { gl.DepthMask(on) }
+func (Context) DepthRange(near, far float64)  { gl.DepthRange(near, far) }
+func (Context) Viewport(x, y, w, h int)       { gl.Viewport(x, y, w, h) }
+
+func (Context) CreateShader(typ gfx.Enum) gfx.Shader {
+	return gfx.Shader(gl.CreateShader(gl.Enum(typ)))
+}
+func (Context) DeleteShader(s gfx.Shader)             { gl.DeleteShader(gl.Shader(s)) }
+func (Context) ShaderSource(s gfx.Shader, src []byte) { gl.ShaderSource(gl.Shader(s), src) }
+func (Context) CompileShader(s gfx.Shader) error      { return gl.CompileShader(gl.Shader(s)) }
+func (Context) CreateProgram() gfx.Program            { return gfx.Program(gl.CreateProgram()) }
+func (Context) DeleteProgram(p gfx.Program)           { gl.DeleteProgram(gl.Program(p)) }
+func (Context) AttachShader(p gfx.Program, s gfx.Shader) {
+	gl.AttachShader(gl.Program(p), gl.Shader(s))
+}
+func (Context) DetachShader(p gfx.Program, s gfx.Shader) {
+	gl.DetachShader(gl.Program(p), gl.Shader(s))
+}
+func (Context) BindAttribLocation(p gfx.Program, a gfx.Attrib, name string) {
+	gl.BindAttribLocation(gl.Program(p), gl.Attrib(a), name)
+}
+func (Context) LinkProgram(p gfx.Program) error { return gl.LinkProgram(gl.Program(p)) }
+func (Context) UseProgram(p gfx.Program)        { gl.UseProgram(gl.Program(p)) }
+
+func (Context) GetProgramiv(p gfx.Program, pname gfx.Enum) int {
+	return gl.GetProgramiv(gl.Program(p), gl.Enum(pname))
+}
+
+func (Context) GetActiveAttrib(p gfx.Program, index int) (string, int, gfx.Enum) {
+	name, size, typ := gl.GetActiveAttrib(gl.Program(p), index)
+	return name, size, gfx.Enum(typ)
+}
+
+func (Context) GetActiveUniform(p gfx.Program, index int) (string, int, gfx.Enum) {
+	name, size, typ := gl.GetActiveUniform(gl.Program(p), index)
+	return name, size, gfx.Enum(typ)
+}
+
+func (Context) GetAttribLocation(p gfx.Program, name string) (gfx.Attrib, error) {
+	a, err := gl.GetAttribLocation(gl.Program(p), name)
+	return gfx.Attrib(a), err
+}
+
+func (Context) GetUniformLocation(p gfx.Program, name string) (gfx.Uniform, error) {
+	u, err := gl.GetUniformLocation(gl.Program(p), name)
+	return gfx.Uniform(u), err
+}
+
+func (Context) Uniformf(u gfx.Uniform, v ...float32) { gl.Uniformf(gl.Uniform(u), v...) }
+func (Context) UniformMatrix4fv(u gfx.Uniform, transpose bool, m []float32) {
+	gl.UniformMatrix4fv(gl.Uniform(u), transpose, m)
+}
+
+func (Context) GetUniformBlockIndex(p gfx.Program, name string) (gfx.UniformBlock, error) {
+	b, err := gl.GetUniformBlockIndex(gl.Program(p), name)
+	return gfx.UniformBlock(b), err
+}
+
+func (Context) UniformBlockBinding(p gfx.Program, block gfx.UniformBlock, binding int) {
+	gl.UniformBlockBinding(gl.Program(p), gl.UniformBlock(block), binding)
+}
+
+func (Context) GetActiveUniformBlockiv(p gfx.Program, block gfx.UniformBlock, pname gfx.Enum) int {
+	return gl.GetActiveUniformBlockiv(gl.Program(p), gl.UniformBlock(block), gl.Enum(pname))
+}
+
+func (Context) GenBuffers(n int) []gfx.Buffer {
+	var b []gfx.Buffer
+	for _, x := range gl.GenBuffers(n) {
+		b = append(b, gfx.Buffer(x))
+	}
+	return b
+}
+
+func (Context) DeleteBuffers(b []gfx.Buffer) {
+	if len(b) == 0 {
+		return
+	}
+	var x []gl.Buffer
+	for _, v := range b {
+		x = append(x, gl.Buffer(v))
+	}
+	gl.DeleteBuffers(x)
+}
+
+func (Context) BindBuffer(target gfx.Enum, b gfx.Buffer) {
+	gl.BindBuffer(gl.Enum(target), gl.Buffer(b))
+}
+
+func (Context) BindBufferBase(target gfx.Enum, index int, b gfx.Buffer) {
+	gl.BindBufferBase(gl.Enum(target), index, gl.Buffer(b))
+}
+
+func (Context) BindBufferRange(target gfx.Enum, index int, b gfx.Buffer, offset, size int) {
+	gl.BindBufferRange(gl.Enum(target), index, gl.Buffer(b), offset, size)
+}
+
+func (Context) BufferData(target gfx.Enum, data interface{}, usage gfx.Enum) error {
+	return gl.BufferData(gl.Enum(target), data, gl.Enum(usage))
+}
+
+func (Context) BufferSubData(target gfx.Enum, offset int, data interface{}) error {
+	return gl.BufferSubData(gl.Enum(target), offset, data)
+}
+
+func (Context) GenVertexArrays(n int) []gfx.VertexArray {
+	var a []gfx.VertexArray
+	for _, x := range gl.GenVertexArrays(n) {
+		a = append(a, gfx.VertexArray(x))
+	}
+	return a
+}
+
+func (Context) DeleteVertexArrays(a []gfx.VertexArray) {
+	if len(a) == 0 {
+		return
+	}
+	var x []gl.VertexArray
+	for _, v := range a {
+		x = append(x, gl.VertexArray(v))
+	}
+	gl.DeleteVertexArrays(x)
+}
+
+func (Context) BindVertexArray(a gfx.VertexArray)     { gl.BindVertexArray(gl.VertexArray(a)) }
+func (Context) EnableVertexAttribArray(a gfx.Attrib)  { gl.EnableVertexAttribArray(gl.Attrib(a)) }
+func (Context) DisableVertexAttribArray(a gfx.Attrib) { gl.DisableVertexAttribArray(gl.Attrib(a)) }
+
+func (Context) VertexAttribPointer(a gfx.Attrib, size int, typ gfx.Type, normalized bool, stride int, offset uintptr) {
+	gl.VertexAttribPointer(gl.Attrib(a), size, gl.Type(typ), normalized, stride, offset)
+}
+
+func (Context) DrawArrays(mode gfx.Enum, first, count int) {
+	gl.DrawArrays(gl.Enum(mode), first, count)
+}
+
+func (Context) DrawElements(mode gfx.Enum, count int, typ gfx.Type, offset uintptr) {
+	gl.DrawElements(gl.Enum(mode), count, gl.Type(typ), offset)
+}
+
+func (Context) DrawElementsBaseVertex(mode gfx.Enum, count int, typ gfx.Type, offset uintptr, base int) {
+	gl.DrawElementsBaseVertex(gl.Enum(mode), count, gl.Type(typ), offset, base)
+}
+
+func (Context) ReadPixels(x, y, width, height int, format gfx.Enum, typ gfx.Type, dst interface{}) {
+	gl.ReadPixels(x, y, width, height, gl.Enum(format), gl.Type(typ), dst)
+}
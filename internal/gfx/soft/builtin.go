@@ -0,0 +1,478 @@
+package soft
+
+import (
+	"math"
+
+	"github.com/droyo/gltut/internal/glsl"
+)
+
+// A builtinFunc checks the argument types of a call to a built-in
+// function, and returns the result type and implementation. It
+// returns a nil implementation if the arguments do not match.
+type builtinFunc func(c *compiler, pos glsl.Pos, args []typ) (typ, func(a []value) value)
+
+var builtinFuncs map[string]builtinFunc
+
+func init() {
+	builtinFuncs = map[string]builtinFunc{
+		"radians":     float1(func(x float64) float64 { return x * math.Pi / 180 }),
+		"degrees":     float1(func(x float64) float64 { return x * 180 / math.Pi }),
+		"sin":         float1(math.Sin),
+		"cos":         float1(math.Cos),
+		"tan":         float1(math.Tan),
+		"asin":        float1(math.Asin),
+		"acos":        float1(math.Acos),
+		"exp":         float1(math.Exp),
+		"log":         float1(math.Log),
+		"exp2":        float1(math.Exp2),
+		"log2":        float1(math.Log2),
+		"sqrt":        float1(math.Sqrt),
+		"inversesqrt": float1(func(x float64) float64 { return 1 / math.Sqrt(x) }),
+		"floor":       float1(math.Floor),
+		"ceil":        float1(math.Ceil),
+		"trunc":       float1(math.Trunc),
+		"round":       float1(math.Round),
+		"fract":       float1(func(x float64) float64 { return x - math.Floor(x) }),
+		"abs":         gen1(math.Abs),
+		"sign": gen1(func(x float64) float64 {
+			switch {
+			case x > 0:
+				return 1
+			case x < 0:
+				return -1
+			}
+			return 0
+		}),
+		"atan":       atan,
+		"pow":        float2(math.Pow),
+		"mod":        float2(func(x, y float64) float64 { return x - y*math.Floor(x/y) }),
+		"min":        gen2(math.Min),
+		"max":        gen2(math.Max),
+		"step":       step,
+		"clamp":      clamp,
+		"mix":        mix,
+		"smoothstep": smoothstep,
+
+		"length":    length,
+		"distance":  distance,
+		"dot":       dot,
+		"cross":     cross,
+		"normalize": normalize,
+		"reflect":   reflect,
+
+		"transpose":   transpose,
+		"determinant": determinant,
+		"inverse":     inverse,
+
+		"lessThan":         compare(func(a, b float32) bool { return a < b }),
+		"lessThanEqual":    compare(func(a, b float32) bool { return a <= b }),
+		"greaterThan":      compare(func(a, b float32) bool { return a > b }),
+		"greaterThanEqual": compare(func(a, b float32) bool { return a >= b }),
+		"equal":            compare(func(a, b float32) bool { return a == b }),
+		"notEqual":         compare(func(a, b float32) bool { return a != b }),
+		"any":              reduce(false),
+		"all":              reduce(true),
+		"not":              not,
+	}
+}
+
+// genType reports whether t is a numeric scalar or vector.
+func genType(t typ) bool {
+	return t.numeric() && t.arr == 0 && t.c == 1
+}
+
+func asFloat(t typ) typ {
+	t.b = tFloat
+	return t
+}
+
+// float1 is a function of one float scalar or vector, applied to
+// each component.
+func float1(fn func(float64) float64) builtinFunc {
+	return func(c *compiler, pos glsl.Pos, args []typ) (typ, func([]value) value) {
+		if len(args) != 1 || !genType(args[0]) {
+			return typ{}, nil
+		}
+		n := args[0].n
+		return asFloat(args[0]), func(a []value) value {
+			var r value
+			for i := 0; i < n; i++ {
+				r[i] = float32(fn(float64(a[0][i])))
+			}
+			return r
+		}
+	}
+}
+
+// gen1 is like float1, but preserves integer types.
+func gen1(fn func(float64) float64) builtinFunc {
+	return func(c *compiler, pos glsl.Pos, args []typ) (typ, func([]value) value) {
+		t, impl := float1(fn)(c, pos, args)
+		if impl != nil {
+			t.b = args[0].b
+		}
+		return t, impl
+	}
+}
+
+// float2 is a function of two floats, applied to each component.
+// The second argument may be a scalar.
+func float2(fn func(x, y float64) float64) builtinFunc {
+	return func(c *compiler, pos glsl.Pos, args []typ) (typ, func([]value) value) {
+		if len(args) != 2 || !genType(args[0]) || !genType(args[1]) {
+			return typ{}, nil
+		}
+		if args[1].n != 1 && args[1].n != args[0].n {
+			return typ{}, nil
+		}
+		n, s := args[0].n, args[1].n == 1
+		return asFloat(args[0]), func(a []value) value {
+			var r value
+			for i := 0; i < n; i++ {
+				y := a[1][0]
+				if !s {
+					y = a[1][i]
+				}
+				r[i] = float32(fn(float64(a[0][i]), float64(y)))
+			}
+			return r
+		}
+	}
+}
+
+// gen2 is like float2, but preserves integer types.
+func gen2(fn func(x, y float64) float64) builtinFunc {
+	return func(c *compiler, pos glsl.Pos, args []typ) (typ, func([]value) value) {
+		t, impl := float2(fn)(c, pos, args)
+		if impl != nil && args[0].b == tInt && args[1].b == tInt {
+			t.b = tInt
+		}
+		return t, impl
+	}
+}
+
+func atan(c *compiler, pos glsl.Pos, args []typ) (typ, func([]value) value) {
+	if len(args) == 1 {
+		return float1(math.Atan)(c, pos, args)
+	}
+	if len(args) != 2 || args[0].n != args[1].n {
+		return typ{}, nil
+	}
+	return float2(math.Atan2)(c, pos, args)
+}
+
+func step(c *compiler, pos glsl.Pos, args []typ) (typ, func([]value) value) {
+	if len(args) != 2 {
+		return typ{}, nil
+	}
+	// step(edge, x): the edge may be a scalar.
+	t, impl := float2(func(x, edge float64) float64 {
+		if x < edge {
+			return 0
+		}
+		return 1
+	})(c, pos, []typ{args[1], args[0]})
+	if impl == nil {
+		return t, nil
+	}
+	return t, func(a []value) value { return impl([]value{a[1], a[0]}) }
+}
+
+// ternary checks the arguments of a function of three genTypes,
+// whose last arguments may be scalars if scalar is true.
+func ternary(args []typ, scalar ...bool) (n int, broadcast [3]bool, ok bool) {
+	if len(args) != 3 {
+		return 0, broadcast, false
+	}
+	n = args[0].n
+	for i, t := range args {
+		if !genType(t) {
+			return 0, broadcast, false
+		}
+		if t.n != n {
+			if t.n != 1 || !scalar[i] {
+				return 0, broadcast, false
+			}
+			broadcast[i] = true
+		}
+	}
+	return n, broadcast, true
+}
+
+func arg(a []value, broadcast [3]bool, j, i int) float32 {
+	if broadcast[j] {
+		return a[j][0]
+	}
+	return a[j][i]
+}
+
+func clamp(c *compiler, pos glsl.Pos, args []typ) (typ, func([]value) value) {
+	n, b, ok := ternary(args, false, true, true)
+	if !ok {
+		return typ{}, nil
+	}
+	t := asFloat(args[0])
+	if args[0].b == tInt && args[1].b == tInt && args[2].b == tInt {
+		t.b = tInt
+	}
+	return t, func(a []value) value {
+		var r value
+		for i := 0; i < n; i++ {
+			x, lo, hi := a[0][i], arg(a, b, 1, i), arg(a, b, 2, i)
+			r[i] = float32(math.Min(math.Max(float64(x), float64(lo)), float64(hi)))
+		}
+		return r
+	}
+}
+
+func mix(c *compiler, pos glsl.Pos, args []typ) (typ, func([]value) value) {
+	n, b, ok := ternary(args, false, false, true)
+	if !ok {
+		return typ{}, nil
+	}
+	return asFloat(args[0]), func(a []value) value {
+		var r value
+		for i := 0; i < n; i++ {
+			t := arg(a, b, 2, i)
+			r[i] = a[0][i]*(1-t) + a[1][i]*t
+		}
+		return r
+	}
+}
+
+func smoothstep(c *compiler, pos glsl.Pos, args []typ) (typ, func([]value) value) {
+	if len(args) != 3 {
+		return typ{}, nil
+	}
+	// smoothstep(edge0, edge1, x): the edges may be scalars.
+	n, b, ok := ternary([]typ{args[2], args[0], args[1]}, false, true, true)
+	if !ok {
+		return typ{}, nil
+	}
+	return asFloat(args[2]), func(a []value) value {
+		var r value
+		v := []value{a[2], a[0], a[1]}
+		for i := 0; i < n; i++ {
+			e0, e1 := arg(v, b, 1, i), arg(v, b, 2, i)
+			t := (v[0][i] - e0) / (e1 - e0)
+			t = float32(math.Min(math.Max(float64(t), 0), 1))
+			r[i] = t * t * (3 - 2*t)
+		}
+		return r
+	}
+}
+
+func vdot(a, b value, n int) float32 {
+	var s float32
+	for i := 0; i < n; i++ {
+		s += a[i] * b[i]
+	}
+	return s
+}
+
+func length(c *compiler, pos glsl.Pos, args []typ) (typ, func([]value) value) {
+	if len(args) != 1 || !genType(args[0]) {
+		return typ{}, nil
+	}
+	n := args[0].n
+	return floatType, func(a []value) value {
+		return value{float32(math.Sqrt(float64(vdot(a[0], a[0], n))))}
+	}
+}
+
+func distance(c *compiler, pos glsl.Pos, args []typ) (typ, func([]value) value) {
+	if len(args) != 2 || !genType(args[0]) || args[0].n != args[1].n || !genType(args[1]) {
+		return typ{}, nil
+	}
+	n := args[0].n
+	return floatType, func(a []value) value {
+		var d value
+		for i := 0; i < n; i++ {
+			d[i] = a[0][i] - a[1][i]
+		}
+		return value{float32(math.Sqrt(float64(vdot(d, d, n))))}
+	}
+}
+
+func dot(c *compiler, pos glsl.Pos, args []typ) (typ, func([]value) value) {
+	if len(args) != 2 || !genType(args[0]) || args[0].n != args[1].n || !genType(args[1]) {
+		return typ{}, nil
+	}
+	n := args[0].n
+	return floatType, func(a []value) value {
+		return value{vdot(a[0], a[1], n)}
+	}
+}
+
+func cross(c *compiler, pos glsl.Pos, args []typ) (typ, func([]value) value) {
+	v3 := vecType(tFloat, 3)
+	if len(args) != 2 || !assignable(v3, args[0]) || !assignable(v3, args[1]) {
+		return typ{}, nil
+	}
+	return v3, func(a []value) value {
+		x, y := a[0], a[1]
+		return value{
+			x[1]*y[2] - x[2]*y[1],
+			x[2]*y[0] - x[0]*y[2],
+			x[0]*y[1] - x[1]*y[0],
+		}
+	}
+}
+
+func normalize(c *compiler, pos glsl.Pos, args []typ) (typ, func([]value) value) {
+	if len(args) != 1 || !genType(args[0]) {
+		return typ{}, nil
+	}
+	n := args[0].n
+	return asFloat(args[0]), func(a []value) value {
+		var r value
+		l := float32(math.Sqrt(float64(vdot(a[0], a[0], n))))
+		for i := 0; i < n; i++ {
+			r[i] = a[0][i] / l
+		}
+		return r
+	}
+}
+
+func reflect(c *compiler, pos glsl.Pos, args []typ) (typ, func([]value) value) {
+	if len(args) != 2 || !genType(args[0]) || args[0].n != args[1].n || !genType(args[1]) {
+		return typ{}, nil
+	}
+	n := args[0].n
+	return asFloat(args[0]), func(a []value) value {
+		var r value
+		d := 2 * vdot(a[0], a[1], n)
+		for i := 0; i < n; i++ {
+			r[i] = a[0][i] - d*a[1][i]
+		}
+		return r
+	}
+}
+
+func transpose(c *compiler, pos glsl.Pos, args []typ) (typ, func([]value) value) {
+	if len(args) != 1 || !args[0].isMatrix() {
+		return typ{}, nil
+	}
+	t := args[0]
+	return typ{b: tFloat, n: t.c, c: t.n}, func(a []value) value {
+		var r value
+		for j := 0; j < t.c; j++ {
+			for i := 0; i < t.n; i++ {
+				r[i*t.c+j] = a[0][j*t.n+i]
+			}
+		}
+		return r
+	}
+}
+
+func square(args []typ) bool {
+	return len(args) == 1 && args[0].isMatrix() && args[0].n == args[0].c
+}
+
+// minor returns the determinant of the n-1 x n-1 submatrix of the
+// n x n matrix m that excludes row r and column c.
+func minor(m value, n, r, c int) float64 {
+	var sub value
+	k := 0
+	for j := 0; j < n; j++ {
+		for i := 0; i < n; i++ {
+			if i != r && j != c {
+				sub[k] = m[j*n+i]
+				k++
+			}
+		}
+	}
+	return det(sub, n-1)
+}
+
+func det(m value, n int) float64 {
+	switch n {
+	case 1:
+		return float64(m[0])
+	case 2:
+		return float64(m[0])*float64(m[3]) - float64(m[2])*float64(m[1])
+	}
+	var d float64
+	for j := 0; j < n; j++ {
+		d += math.Pow(-1, float64(j)) * float64(m[j*n]) * minor(m, n, 0, j)
+	}
+	return d
+}
+
+func determinant(c *compiler, pos glsl.Pos, args []typ) (typ, func([]value) value) {
+	if !square(args) {
+		return typ{}, nil
+	}
+	n := args[0].n
+	return floatType, func(a []value) value {
+		return value{float32(det(a[0], n))}
+	}
+}
+
+func inverse(c *compiler, pos glsl.Pos, args []typ) (typ, func([]value) value) {
+	if !square(args) {
+		return typ{}, nil
+	}
+	n := args[0].n
+	return args[0], func(a []value) value {
+		var r value
+		d := det(a[0], n)
+		for j := 0; j < n; j++ {
+			for i := 0; i < n; i++ {
+				// the inverse is the transposed cofactor matrix over
+				// the determinant
+				cof := math.Pow(-1, float64(i+j)) * minor(a[0], n, j, i)
+				r[j*n+i] = float32(cof / d)
+			}
+		}
+		return r
+	}
+}
+
+func compare(fn func(a, b float32) bool) builtinFunc {
+	return func(c *compiler, pos glsl.Pos, args []typ) (typ, func([]value) value) {
+		if len(args) != 2 || !args[0].isVector() || args[0] != args[1] {
+			return typ{}, nil
+		}
+		n := args[0].n
+		return vecType(tBool, n), func(a []value) value {
+			var r value
+			for i := 0; i < n; i++ {
+				r[i] = b2f(fn(a[0][i], a[1][i]))
+			}
+			return r
+		}
+	}
+}
+
+// reduce implements any and all.
+func reduce(all bool) builtinFunc {
+	return func(c *compiler, pos glsl.Pos, args []typ) (typ, func([]value) value) {
+		if len(args) != 1 || !args[0].isVector() || args[0].b != tBool {
+			return typ{}, nil
+		}
+		n := args[0].n
+		return boolType, func(a []value) value {
+			for i := 0; i < n; i++ {
+				if (a[0][i] != 0) != all {
+					return value{b2f(!all)}
+				}
+			}
+			return value{b2f(all)}
+		}
+	}
+}
+
+func not(c *compiler, pos glsl.Pos, args []typ) (typ, func([]value) value) {
+	if len(args) != 1 || !args[0].isVector() || args[0].b != tBool {
+		return typ{}, nil
+	}
+	n := args[0].n
+	return args[0], func(a []value) value {
+		var r value
+		for i := 0; i < n; i++ {
+			r[i] = 1 - a[0][i]
+		}
+		return r
+	}
+}
@@ -0,0 +1,1423 @@
+package soft
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/glsl"
+)
+
+// Shaders are compiled from their syntax tree to a tree of closures.
+// Every variable lives in one of two []float32 slices: globals,
+// which include the shader's inputs, outputs and uniforms, and the
+// locals of the function being executed.
+type frame struct {
+	g   []float32
+	l   []float32
+	ret value
+}
+
+type (
+	exprFn func(f *frame) value
+	stmtFn func(f *frame) flow
+)
+
+// flow is the result of executing a statement.
+type flow int
+
+const (
+	flowNext flow = iota
+	flowBreak
+	flowContinue
+	flowReturn
+	flowDiscard
+)
+
+// A symbol is a variable.
+type symbol struct {
+	name     string
+	t        typ
+	global   bool
+	off      int
+	storage  string // "in", "out", "uniform", "const" or ""
+	block    string // the uniform block the symbol belongs to, if any
+	interp   string
+	layout   map[string]int
+	readOnly bool
+	builtin  bool
+}
+
+type function struct {
+	name   string
+	ret    typ
+	params []*symbol
+	quals  []string
+	body   stmtFn
+	frame  int
+	pos    glsl.Pos
+}
+
+// A uniformBlock is a uniform block declared by a shader. Its
+// members are globals like any other uniform, filled from the
+// bound uniform buffer before each draw.
+type uniformBlock struct {
+	name    string
+	members []*symbol
+}
+
+// A shader is a compiled shader stage.
+type shader struct {
+	stage  gfx.Enum
+	vars   []*symbol // globals, in declaration order
+	blocks []*uniformBlock
+	gsize  int
+	init   []stmtFn
+	main   *function
+}
+
+func (s *shader) lookup(name string) *symbol {
+	for _, v := range s.vars {
+		if v.name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// run executes the shader's main function. The frame's globals must
+// already hold the shader's inputs.
+func (s *shader) run(f *frame) flow {
+	for _, fn := range s.init {
+		fn(f)
+	}
+	return s.main.body(f)
+}
+
+func (s *shader) newFrame() *frame {
+	return &frame{g: make([]float32, s.gsize), l: make([]float32, s.main.frame)}
+}
+
+type compiler struct {
+	stage     gfx.Enum
+	sh        *shader
+	globals   map[string]*symbol
+	instances map[string]bool // instance names of uniform blocks
+	funcs     map[string][]*function
+	called    map[*function]glsl.Pos
+	scopes    []map[string]*symbol
+	fn        *function
+	loff      int
+	loops     int
+}
+
+var builtinVars = map[gfx.Enum][]symbol{
+	gfx.VERTEX_SHADER: {
+		{name: "gl_Position", t: vec4Type, storage: "out"},
+		{name: "gl_PointSize", t: floatType, storage: "out"},
+		{name: "gl_VertexID", t: intType, storage: "in", readOnly: true},
+		{name: "gl_InstanceID", t: intType, storage: "in", readOnly: true},
+	},
+	gfx.FRAGMENT_SHADER: {
+		{name: "gl_FragCoord", t: vec4Type, storage: "in", readOnly: true},
+		{name: "gl_FrontFacing", t: boolType, storage: "in", readOnly: true},
+		{name: "gl_FragDepth", t: floatType, storage: "out"},
+	},
+}
+
+// compileShader parses and compiles the source of a shader stage.
+// Errors are reported in the same format as a driver's info log.
+func compileShader(stage gfx.Enum, src []byte) (sh *shader, err error) {
+	file, err := glsl.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*glsl.Error)
+			if !ok {
+				panic(r)
+			}
+			sh, err = nil, e
+		}
+	}()
+	c := &compiler{
+		stage:     stage,
+		sh:        &shader{stage: stage},
+		globals:   make(map[string]*symbol),
+		instances: make(map[string]bool),
+		funcs:     make(map[string][]*function),
+		called:    make(map[*function]glsl.Pos),
+	}
+	if _, ok := builtinVars[stage]; !ok {
+		return nil, fmt.Errorf("0:0(0): error: %s shaders are not supported by the software renderer", stageName(stage))
+	}
+	for _, b := range builtinVars[stage] {
+		b := b
+		b.builtin = true
+		c.global(&b)
+	}
+	for _, d := range file.Decls {
+		switch d := d.(type) {
+		case *glsl.VarDecl:
+			c.globalDecl(d)
+		case *glsl.BlockDecl:
+			c.blockDecl(d)
+		case *glsl.FuncDecl:
+			c.funcDecl(d)
+		}
+	}
+	for fn, pos := range c.called {
+		if fn.body == nil {
+			c.fail(pos, "no function with name '%s' is defined", fn.name)
+		}
+	}
+	for _, fn := range c.funcs["main"] {
+		if len(fn.params) == 0 && fn.ret == voidType && fn.body != nil {
+			c.sh.main = fn
+		}
+	}
+	if c.sh.main == nil {
+		c.fail(glsl.Pos{Line: 1}, "no definition of main()")
+	}
+	return c.sh, nil
+}
+
+func stageName(stage gfx.Enum) string {
+	switch stage {
+	case gfx.VERTEX_SHADER:
+		return "vertex"
+	case gfx.FRAGMENT_SHADER:
+		return "fragment"
+	case gfx.GEOMETRY_SHADER:
+		return "geometry"
+	}
+	return fmt.Sprintf("0x%x", uint32(stage))
+}
+
+// errors are propagated by panicking with a *glsl.Error, and
+// recovered in compileShader.
+func (c *compiler) fail(pos glsl.Pos, format string, args ...interface{}) {
+	panic(&glsl.Error{Pos: pos, Msg: fmt.Sprintf(format, args...)})
+}
+
+func (c *compiler) global(s *symbol) {
+	s.global = true
+	s.off = c.sh.gsize
+	c.sh.gsize += s.t.size()
+	c.globals[s.name] = s
+	c.sh.vars = append(c.sh.vars, s)
+}
+
+func (c *compiler) varType(pos glsl.Pos, t glsl.Type, arrayLen int) typ {
+	vt, ok := parseType(t)
+	if !ok {
+		c.fail(pos, "unknown type '%s'", t.Name)
+	}
+	vt.arr = arrayLen
+	if vt.b == tVoid {
+		c.fail(pos, "variables cannot be declared as void")
+	}
+	return vt
+}
+
+func (c *compiler) globalDecl(d *glsl.VarDecl) {
+	switch d.Qual.Storage {
+	case "in", "out":
+		if d.Qual.Interp != "" && d.Qual.Storage == "in" && c.stage == gfx.VERTEX_SHADER {
+			c.fail(d.Pos, "interpolation qualifiers cannot be used on vertex shader inputs")
+		}
+	}
+	for _, v := range d.Vars {
+		if old, ok := c.globals[v.Name]; ok && !old.builtin {
+			c.fail(v.Pos, "'%s' redeclared", v.Name)
+		} else if ok {
+			c.fail(v.Pos, "redeclaration of built-in variable '%s'", v.Name)
+		}
+		s := &symbol{
+			name:    v.Name,
+			t:       c.varType(v.Pos, d.Type, v.ArrayLen),
+			storage: d.Qual.Storage,
+			interp:  d.Qual.Interp,
+			layout:  d.Qual.Layout,
+		}
+		switch s.storage {
+		case "in", "uniform", "const":
+			s.readOnly = true
+		}
+		if s.storage == "const" && v.Init == nil {
+			c.fail(v.Pos, "const variable '%s' must be initialized", v.Name)
+		}
+		if v.Init != nil {
+			if s.storage == "in" || s.storage == "uniform" {
+				c.fail(v.Pos, "cannot initialize %s variable '%s'", s.storage, v.Name)
+			}
+			c.sh.init = append(c.sh.init, c.initializer(v, s))
+		}
+		c.global(s)
+	}
+}
+
+// blockDecl declares the members of a uniform block as globals.
+// Members of a block with an instance name are declared as
+// "instance.member", and found by ref. Every block is laid out by
+// the std140 rules, whatever its layout qualifier says, since the
+// software renderer has no other layout to offer.
+func (c *compiler) blockDecl(d *glsl.BlockDecl) {
+	if d.Qual.Storage != "uniform" {
+		c.fail(d.Pos, "%s interface blocks are not supported", d.Qual.Storage)
+	}
+	if _, ok := d.Qual.Layout["row_major"]; ok {
+		c.fail(d.Pos, "row_major uniform blocks are not supported")
+	}
+	for _, b := range c.sh.blocks {
+		if b.name == d.Name {
+			c.fail(d.Pos, "uniform block '%s' redeclared", d.Name)
+		}
+	}
+	if d.Instance != "" {
+		if _, ok := c.globals[d.Instance]; ok || c.instances[d.Instance] {
+			c.fail(d.Pos, "'%s' redeclared", d.Instance)
+		}
+		c.instances[d.Instance] = true
+	}
+	b := &uniformBlock{name: d.Name}
+	for _, m := range d.Members {
+		if _, ok := m.Qual.Layout["row_major"]; ok {
+			c.fail(m.Pos, "row_major uniform block members are not supported")
+		}
+		if m.Qual.Storage != "" && m.Qual.Storage != "uniform" || m.Qual.Interp != "" {
+			c.fail(m.Pos, "invalid qualifiers on uniform block member")
+		}
+		for _, v := range m.Vars {
+			name := v.Name
+			if d.Instance != "" {
+				name = d.Instance + "." + v.Name
+			}
+			if _, ok := c.globals[name]; ok {
+				c.fail(v.Pos, "'%s' redeclared", v.Name)
+			}
+			if v.Init != nil {
+				c.fail(v.Pos, "cannot initialize uniform block member '%s'", v.Name)
+			}
+			s := &symbol{
+				name:     name,
+				t:        c.varType(v.Pos, m.Type, v.ArrayLen),
+				storage:  "uniform",
+				block:    d.Name,
+				readOnly: true,
+			}
+			if s.t.b == tSampler {
+				c.fail(v.Pos, "samplers cannot be uniform block members")
+			}
+			c.global(s)
+			b.members = append(b.members, s)
+		}
+	}
+	c.sh.blocks = append(c.sh.blocks, b)
+}
+
+// initializer compiles the initialization of the variable s. The
+// variable is not yet in scope.
+func (c *compiler) initializer(v *glsl.Var, s *symbol) stmtFn {
+	t, x := c.expr(v.Init)
+	if !assignable(s.t, t) {
+		c.fail(v.Pos, "initializer of type %s cannot be assigned to variable of type %s", t, s.t)
+	}
+	n := s.t.size()
+	return func(f *frame) flow {
+		v := x(f)
+		mem := f.l
+		if s.global {
+			mem = f.g
+		}
+		copy(mem[s.off:s.off+n], v[:n])
+		return flowNext
+	}
+}
+
+// assignable reports whether a value of type src can be stored in
+// a variable of type dst. Integers are implicitly converted to
+// floats.
+func assignable(dst, src typ) bool {
+	if dst.arr > 0 || src.arr > 0 {
+		return false
+	}
+	if dst == src {
+		return true
+	}
+	return dst.b == tFloat && src.b == tInt && dst.n == src.n && dst.c == src.c
+}
+
+func (c *compiler) funcDecl(d *glsl.FuncDecl) {
+	if c.fn != nil {
+		c.fail(d.Pos, "functions cannot be nested")
+	}
+	ret, ok := parseType(d.Ret)
+	if !ok || ret.arr > 0 {
+		c.fail(d.Pos, "invalid return type %s", d.Ret.Name)
+	}
+	var params []typ
+	for _, p := range d.Params {
+		pt := c.varType(p.Pos, p.Type, p.Type.ArrayLen)
+		if pt.arr > 0 {
+			c.fail(p.Pos, "array parameters are not supported")
+		}
+		params = append(params, pt)
+	}
+	var fn *function
+	for _, f := range c.funcs[d.Name] {
+		if sameParams(f, params) {
+			fn = f
+		}
+	}
+	if fn == nil {
+		fn = &function{name: d.Name, ret: ret, pos: d.Pos}
+		c.funcs[d.Name] = append(c.funcs[d.Name], fn)
+	} else if fn.ret != ret {
+		c.fail(d.Pos, "function '%s' redeclared with a different return type", d.Name)
+	}
+	if d.Body == nil {
+		if fn.params == nil {
+			for i, p := range d.Params {
+				fn.params = append(fn.params, &symbol{name: p.Name, t: params[i]})
+				fn.quals = append(fn.quals, p.Qual)
+			}
+		}
+		return
+	}
+	if fn.body != nil {
+		c.fail(d.Pos, "function '%s' redefined", d.Name)
+	}
+	c.fn, c.loff, c.loops = fn, 0, 0
+	c.scopes = []map[string]*symbol{{}}
+	fn.params, fn.quals = nil, nil
+	for i, p := range d.Params {
+		s := c.local(p.Pos, p.Name, params[i])
+		s.readOnly = false
+		fn.params = append(fn.params, s)
+		fn.quals = append(fn.quals, p.Qual)
+	}
+	body := c.block(d.Body, false)
+	fn.body = body
+	c.fn, c.scopes = nil, nil
+}
+
+func sameParams(fn *function, params []typ) bool {
+	if len(fn.params) != len(params) {
+		return false
+	}
+	for i, p := range fn.params {
+		if p.t != params[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// local allocates a local variable in the innermost scope.
+func (c *compiler) local(pos glsl.Pos, name string, t typ) *symbol {
+	scope := c.scopes[len(c.scopes)-1]
+	if _, ok := scope[name]; ok && name != "" {
+		c.fail(pos, "'%s' redeclared in this scope", name)
+	}
+	s := &symbol{name: name, t: t, off: c.loff}
+	c.loff += t.size()
+	if c.loff > c.fn.frame {
+		c.fn.frame = c.loff
+	}
+	if name != "" {
+		scope[name] = s
+	}
+	return s
+}
+
+func (c *compiler) lookup(pos glsl.Pos, name string) *symbol {
+	for i := len(c.scopes) - 1; i >= 0; i-- {
+		if s, ok := c.scopes[i][name]; ok {
+			return s
+		}
+	}
+	if s, ok := c.globals[name]; ok {
+		return s
+	}
+	c.fail(pos, "'%s' undeclared", name)
+	return nil
+}
+
+// shadowed reports whether name is declared in a local scope.
+func (c *compiler) shadowed(name string) bool {
+	for _, scope := range c.scopes {
+		if _, ok := scope[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// block compiles a block statement. Function bodies share a scope
+// with the function's parameters.
+func (c *compiler) block(b *glsl.BlockStmt, scope bool) stmtFn {
+	if scope {
+		c.scopes = append(c.scopes, map[string]*symbol{})
+		loff := c.loff
+		defer func() {
+			c.scopes = c.scopes[:len(c.scopes)-1]
+			c.loff = loff
+		}()
+	}
+	var stmts []stmtFn
+	for _, s := range b.Stmts {
+		if fn := c.stmt(s); fn != nil {
+			stmts = append(stmts, fn)
+		}
+	}
+	return func(f *frame) flow {
+		for _, s := range stmts {
+			if fl := s(f); fl != flowNext {
+				return fl
+			}
+		}
+		return flowNext
+	}
+}
+
+func (c *compiler) stmt(s glsl.Stmt) stmtFn {
+	switch s := s.(type) {
+	case nil:
+		return nil
+	case *glsl.BlockStmt:
+		return c.block(s, true)
+	case *glsl.DeclStmt:
+		return c.localDecl(s.Decl)
+	case *glsl.ExprStmt:
+		_, x := c.expr(s.X)
+		return func(f *frame) flow {
+			x(f)
+			return flowNext
+		}
+	case *glsl.IfStmt:
+		cond := c.cond(s.Cond)
+		then := c.scoped(s.Then)
+		els := c.scoped(s.Else)
+		return func(f *frame) flow {
+			if cond(f)[0] != 0 {
+				return then(f)
+			}
+			return els(f)
+		}
+	case *glsl.ForStmt:
+		return c.loop(s)
+	case *glsl.ReturnStmt:
+		ret := c.fn.ret
+		if s.Result == nil {
+			if ret != voidType {
+				c.fail(s.Pos, "function '%s' must return a value of type %s", c.fn.name, ret)
+			}
+			return func(*frame) flow { return flowReturn }
+		}
+		t, x := c.expr(s.Result)
+		if !assignable(ret, t) {
+			c.fail(s.Pos, "cannot return a value of type %s from function '%s' of type %s", t, c.fn.name, ret)
+		}
+		return func(f *frame) flow {
+			f.ret = x(f)
+			return flowReturn
+		}
+	case *glsl.BranchStmt:
+		switch s.Tok {
+		case "discard":
+			if c.stage != gfx.FRAGMENT_SHADER {
+				c.fail(s.Pos, "discard may only be used in fragment shaders")
+			}
+			return func(*frame) flow { return flowDiscard }
+		case "break":
+			if c.loops == 0 {
+				c.fail(s.Pos, "break may only appear in a loop")
+			}
+			return func(*frame) flow { return flowBreak }
+		default:
+			if c.loops == 0 {
+				c.fail(s.Pos, "continue may only appear in a loop")
+			}
+			return func(*frame) flow { return flowContinue }
+		}
+	}
+	panic(fmt.Sprintf("unexpected statement %T", s))
+}
+
+// scoped compiles a statement in a new scope, and never returns nil.
+func (c *compiler) scoped(s glsl.Stmt) stmtFn {
+	c.scopes = append(c.scopes, map[string]*symbol{})
+	loff := c.loff
+	fn := c.stmt(s)
+	c.scopes = c.scopes[:len(c.scopes)-1]
+	c.loff = loff
+	if fn == nil {
+		return func(*frame) flow { return flowNext }
+	}
+	return fn
+}
+
+func (c *compiler) cond(e glsl.Expr) exprFn {
+	t, x := c.expr(e)
+	if t != boolType {
+		c.fail(e.Position(), "condition must be a scalar boolean, not %s", t)
+	}
+	return x
+}
+
+func (c *compiler) loop(s *glsl.ForStmt) stmtFn {
+	c.scopes = append(c.scopes, map[string]*symbol{})
+	loff := c.loff
+	defer func() {
+		c.scopes = c.scopes[:len(c.scopes)-1]
+		c.loff = loff
+	}()
+	init := c.stmt(s.Init)
+	var cond, post exprFn
+	if s.Cond != nil {
+		cond = c.cond(s.Cond)
+	}
+	if s.Post != nil {
+		_, post = c.expr(s.Post)
+	}
+	c.loops++
+	body := c.scoped(s.Body)
+	c.loops--
+	return func(f *frame) flow {
+		if init != nil {
+			init(f)
+		}
+		for cond == nil || cond(f)[0] != 0 {
+			switch body(f) {
+			case flowBreak:
+				return flowNext
+			case flowReturn:
+				return flowReturn
+			case flowDiscard:
+				return flowDiscard
+			}
+			if post != nil {
+				post(f)
+			}
+		}
+		return flowNext
+	}
+}
+
+func (c *compiler) localDecl(d *glsl.VarDecl) stmtFn {
+	if d.Qual.Storage != "" && d.Qual.Storage != "const" {
+		c.fail(d.Pos, "%s variables must be declared at global scope", d.Qual.Storage)
+	}
+	var stmts []stmtFn
+	for _, v := range d.Vars {
+		t := c.varType(v.Pos, d.Type, v.ArrayLen)
+		var init stmtFn
+		if v.Init != nil {
+			// the variable is not in scope in its own initializer
+			tmp := &symbol{name: v.Name, t: t, off: c.loff}
+			init = c.initializer(v, tmp)
+		} else if d.Qual.Storage == "const" {
+			c.fail(v.Pos, "const variable '%s' must be initialized", v.Name)
+		}
+		s := c.local(v.Pos, v.Name, t)
+		s.readOnly = d.Qual.Storage == "const"
+		if init == nil {
+			// Uninitialized variables are undefined in GLSL; zero
+			// them so that rendering is deterministic.
+			off, n := s.off, t.size()
+			init = func(f *frame) flow {
+				for i := off; i < off+n; i++ {
+					f.l[i] = 0
+				}
+				return flowNext
+			}
+		}
+		stmts = append(stmts, init)
+	}
+	return func(f *frame) flow {
+		for _, s := range stmts {
+			s(f)
+		}
+		return flowNext
+	}
+}
+
+func constant(v value) exprFn {
+	return func(*frame) value { return v }
+}
+
+func (c *compiler) expr(e glsl.Expr) (typ, exprFn) {
+	switch e := e.(type) {
+	case *glsl.LitExpr:
+		return c.literal(e)
+	case *glsl.IdentExpr, *glsl.FieldExpr, *glsl.IndexExpr:
+		if r, ok := c.ref(e); ok {
+			if r.t.arr > 0 {
+				c.fail(e.Position(), "arrays cannot be used as values")
+			}
+			return r.t, r.read()
+		}
+		return c.selector(e)
+	case *glsl.UnaryExpr:
+		return c.unary(e)
+	case *glsl.PostfixExpr:
+		r := c.lvalue(e.X)
+		if !r.t.numeric() || r.t.isMatrix() {
+			c.fail(e.Pos, "operand of %s must be an integer or float scalar or vector", e.Op)
+		}
+		d := float32(1)
+		if e.Op == "--" {
+			d = -1
+		}
+		read, n := r.read(), r.t.size()
+		return r.t, func(f *frame) value {
+			old := read(f)
+			v := old
+			for i := 0; i < n; i++ {
+				v[i] += d
+			}
+			r.write(f, v)
+			return old
+		}
+	case *glsl.BinaryExpr:
+		return c.binary(e)
+	case *glsl.CondExpr:
+		cond := c.cond(e.Cond)
+		tx, x := c.expr(e.X)
+		ty, y := c.expr(e.Y)
+		t := tx
+		switch {
+		case tx == ty:
+		case assignable(tx, ty):
+		case assignable(ty, tx):
+			t = ty
+		default:
+			c.fail(e.Pos, "second and third operands of ?: must have the same type, not %s and %s", tx, ty)
+		}
+		return t, func(f *frame) value {
+			if cond(f)[0] != 0 {
+				return x(f)
+			}
+			return y(f)
+		}
+	case *glsl.CallExpr:
+		return c.call(e)
+	}
+	panic(fmt.Sprintf("unexpected expression %T", e))
+}
+
+func (c *compiler) literal(e *glsl.LitExpr) (typ, exprFn) {
+	var v value
+	switch e.Kind {
+	case glsl.IntLit:
+		n, err := strconv.ParseInt(e.Value, 0, 64)
+		if err != nil {
+			c.fail(e.Pos, "invalid integer constant %s", e.Value)
+		}
+		v[0] = float32(n)
+		return intType, constant(v)
+	case glsl.FloatLit:
+		x, err := strconv.ParseFloat(e.Value, 32)
+		if err != nil {
+			c.fail(e.Pos, "invalid float constant %s", e.Value)
+		}
+		v[0] = float32(x)
+		return floatType, constant(v)
+	}
+	v[0] = b2f(e.Value == "true")
+	return boolType, constant(v)
+}
+
+// A ref locates a variable, or some components of one, so that it
+// can be read and assigned to.
+type ref struct {
+	t     typ
+	sym   *symbol
+	loc   func(f *frame) ([]float32, int)
+	comps []int // swizzled components; nil if contiguous
+}
+
+func (r ref) read() exprFn {
+	n, loc, comps := r.t.size(), r.loc, r.comps
+	if comps == nil {
+		return func(f *frame) value {
+			var v value
+			m, off := loc(f)
+			copy(v[:n], m[off:off+n])
+			return v
+		}
+	}
+	return func(f *frame) value {
+		var v value
+		m, off := loc(f)
+		for i, c := range comps {
+			v[i] = m[off+c]
+		}
+		return v
+	}
+}
+
+func (r ref) write(f *frame, v value) {
+	m, off := r.loc(f)
+	if r.comps == nil {
+		copy(m[off:off+r.t.size()], v[:])
+		return
+	}
+	for i, c := range r.comps {
+		m[off+c] = v[i]
+	}
+}
+
+// ref resolves an expression that names a variable, or part of a
+// variable. It returns false if e does not refer to storage, such
+// as a swizzle of a function's result.
+func (c *compiler) ref(e glsl.Expr) (ref, bool) {
+	switch e := e.(type) {
+	case *glsl.IdentExpr:
+		s := c.lookup(e.Pos, e.Name)
+		off := s.off
+		r := ref{t: s.t, sym: s}
+		if s.global {
+			r.loc = func(f *frame) ([]float32, int) { return f.g, off }
+		} else {
+			r.loc = func(f *frame) ([]float32, int) { return f.l, off }
+		}
+		return r, true
+	case *glsl.FieldExpr:
+		if id, ok := e.X.(*glsl.IdentExpr); ok && c.instances[id.Name] && !c.shadowed(id.Name) {
+			name := id.Name + "." + e.Name
+			if _, ok := c.globals[name]; !ok {
+				c.fail(e.Pos, "no member named '%s' in uniform block instance '%s'", e.Name, id.Name)
+			}
+			return c.ref(&glsl.IdentExpr{Pos: e.Pos, Name: name})
+		}
+		base, ok := c.ref(e.X)
+		if !ok {
+			return base, false
+		}
+		sw := c.swizzle(e.Pos, base.t, e.Name)
+		comps := sw
+		if base.comps != nil {
+			comps = make([]int, len(sw))
+			for i, k := range sw {
+				comps[i] = base.comps[k]
+			}
+		}
+		return ref{t: swizzleType(base.t, len(sw)), sym: base.sym, loc: base.loc, comps: comps}, true
+	case *glsl.IndexExpr:
+		base, ok := c.ref(e.X)
+		if !ok || base.comps != nil {
+			return base, false
+		}
+		et, stride, n := c.indexType(e.Pos, base.t)
+		it, idx := c.expr(e.Index)
+		if it != intType {
+			c.fail(e.Index.Position(), "array index must be an integer scalar, not %s", it)
+		}
+		loc := base.loc
+		return ref{t: et, sym: base.sym, loc: func(f *frame) ([]float32, int) {
+			m, off := loc(f)
+			return m, off + stride*clampIndex(idx(f)[0], n)
+		}}, true
+	}
+	return ref{}, false
+}
+
+// lvalue resolves an expression that is assigned to.
+func (c *compiler) lvalue(e glsl.Expr) ref {
+	r, ok := c.ref(e)
+	if !ok {
+		c.fail(e.Position(), "invalid lvalue in assignment")
+	}
+	if r.sym.readOnly {
+		c.fail(e.Position(), "assignment to read-only variable '%s'", r.sym.name)
+	}
+	if r.t.arr > 0 {
+		c.fail(e.Position(), "arrays cannot be assigned to")
+	}
+	seen := make(map[int]bool)
+	for _, k := range r.comps {
+		if seen[k] {
+			c.fail(e.Position(), "component used twice in the swizzle of an lvalue")
+		}
+		seen[k] = true
+	}
+	return r
+}
+
+// Indexing outside the bounds of an array is undefined; clamp
+// the index rather than crash.
+func clampIndex(x float32, n int) int {
+	i := int(x)
+	if i < 0 {
+		return 0
+	}
+	if i >= n {
+		return n - 1
+	}
+	return i
+}
+
+// indexType returns the type of an element of t, its size, and the
+// number of elements.
+func (c *compiler) indexType(pos glsl.Pos, t typ) (typ, int, int) {
+	switch {
+	case t.arr > 0:
+		return t.elem(), t.elem().size(), t.arr
+	case t.isMatrix():
+		return t.column(), t.n, t.c
+	case t.isVector():
+		return typ{b: t.b, n: 1, c: 1}, 1, t.n
+	}
+	c.fail(pos, "cannot index a value of type %s", t)
+	return t, 0, 0
+}
+
+var swizzleSets = []string{"xyzw", "rgba", "stpq"}
+
+func (c *compiler) swizzle(pos glsl.Pos, t typ, name string) []int {
+	if t.arr > 0 || t.isMatrix() || t.b == tVoid || t.b == tSampler {
+		c.fail(pos, "cannot select '%s' from a value of type %s", name, t)
+	}
+	if len(name) > 4 {
+		c.fail(pos, "invalid swizzle '%s'", name)
+	}
+	var comps []int
+	for _, set := range swizzleSets {
+		comps = comps[:0]
+		for _, r := range name {
+			i := indexRune(set, r)
+			if i < 0 {
+				break
+			}
+			comps = append(comps, i)
+		}
+		if len(comps) == len(name) {
+			break
+		}
+	}
+	if len(comps) != len(name) {
+		c.fail(pos, "invalid swizzle '%s'", name)
+	}
+	for _, k := range comps {
+		if k >= t.n {
+			c.fail(pos, "swizzle '%s' selects a component beyond the end of a %s", name, t)
+		}
+	}
+	return comps
+}
+
+func indexRune(s string, r rune) int {
+	for i, x := range s {
+		if x == r {
+			return i
+		}
+	}
+	return -1
+}
+
+func swizzleType(t typ, n int) typ {
+	return typ{b: t.b, n: n, c: 1}
+}
+
+// selector compiles a swizzle or index applied to a value that is not
+// stored in a variable.
+func (c *compiler) selector(e glsl.Expr) (typ, exprFn) {
+	switch e := e.(type) {
+	case *glsl.FieldExpr:
+		t, x := c.expr(e.X)
+		comps := c.swizzle(e.Pos, t, e.Name)
+		return swizzleType(t, len(comps)), func(f *frame) value {
+			var v value
+			a := x(f)
+			for i, k := range comps {
+				v[i] = a[k]
+			}
+			return v
+		}
+	case *glsl.IndexExpr:
+		t, x := c.expr(e.X)
+		et, stride, n := c.indexType(e.Pos, t)
+		it, idx := c.expr(e.Index)
+		if it != intType {
+			c.fail(e.Index.Position(), "array index must be an integer scalar, not %s", it)
+		}
+		return et, func(f *frame) value {
+			var v value
+			a := x(f)
+			off := stride * clampIndex(idx(f)[0], n)
+			copy(v[:stride], a[off:off+stride])
+			return v
+		}
+	}
+	panic("unreachable")
+}
+
+func (c *compiler) unary(e *glsl.UnaryExpr) (typ, exprFn) {
+	switch e.Op {
+	case "++", "--":
+		r := c.lvalue(e.X)
+		if !r.t.numeric() || r.t.isMatrix() {
+			c.fail(e.Pos, "operand of %s must be an integer or float scalar or vector", e.Op)
+		}
+		d := float32(1)
+		if e.Op == "--" {
+			d = -1
+		}
+		read, n := r.read(), r.t.size()
+		return r.t, func(f *frame) value {
+			v := read(f)
+			for i := 0; i < n; i++ {
+				v[i] += d
+			}
+			r.write(f, v)
+			return v
+		}
+	}
+	t, x := c.expr(e.X)
+	switch e.Op {
+	case "+":
+		if !t.numeric() {
+			c.fail(e.Pos, "operand of unary + must be numeric, not %s", t)
+		}
+		return t, x
+	case "-":
+		if !t.numeric() {
+			c.fail(e.Pos, "operand of unary - must be numeric, not %s", t)
+		}
+		n := t.size()
+		return t, func(f *frame) value {
+			v := x(f)
+			for i := 0; i < n; i++ {
+				v[i] = -v[i]
+			}
+			return v
+		}
+	case "!":
+		if t != boolType {
+			c.fail(e.Pos, "operand of ! must be a scalar boolean, not %s", t)
+		}
+		return t, func(f *frame) value {
+			v := x(f)
+			v[0] = 1 - v[0]
+			return v
+		}
+	}
+	c.fail(e.Pos, "operator %s is not supported", e.Op)
+	return t, nil
+}
+
+func (c *compiler) binary(e *glsl.BinaryExpr) (typ, exprFn) {
+	switch e.Op {
+	case "=":
+		r := c.lvalue(e.X)
+		t, y := c.expr(e.Y)
+		if !assignable(r.t, t) {
+			c.fail(e.Pos, "value of type %s cannot be assigned to variable of type %s", t, r.t)
+		}
+		return r.t, func(f *frame) value {
+			v := y(f)
+			r.write(f, v)
+			return v
+		}
+	case "+=", "-=", "*=", "/=", "%=":
+		r := c.lvalue(e.X)
+		ty, y := c.expr(e.Y)
+		t, op := c.arith(e.Pos, e.Op[:1], r.t, ty)
+		if !assignable(r.t, t) {
+			c.fail(e.Pos, "result of type %s cannot be assigned to variable of type %s", t, r.t)
+		}
+		read := r.read()
+		return r.t, func(f *frame) value {
+			v := op(read(f), y(f))
+			r.write(f, v)
+			return v
+		}
+	case "<<=", ">>=", "&=", "|=", "^=":
+		c.fail(e.Pos, "operator %s is not supported", e.Op)
+	case ",":
+		_, x := c.expr(e.X)
+		t, y := c.expr(e.Y)
+		return t, func(f *frame) value {
+			x(f)
+			return y(f)
+		}
+	}
+	tx, x := c.expr(e.X)
+	ty, y := c.expr(e.Y)
+	switch e.Op {
+	case "&&", "||", "^^":
+		if tx != boolType || ty != boolType {
+			c.fail(e.Pos, "operands of %s must be scalar booleans", e.Op)
+		}
+		switch e.Op {
+		case "&&":
+			return boolType, func(f *frame) value {
+				return value{b2f(x(f)[0] != 0 && y(f)[0] != 0)}
+			}
+		case "||":
+			return boolType, func(f *frame) value {
+				return value{b2f(x(f)[0] != 0 || y(f)[0] != 0)}
+			}
+		}
+		return boolType, func(f *frame) value {
+			return value{b2f((x(f)[0] != 0) != (y(f)[0] != 0))}
+		}
+	case "==", "!=":
+		if !assignable(tx, ty) && !assignable(ty, tx) {
+			c.fail(e.Pos, "operands of %s must have the same type, not %s and %s", e.Op, tx, ty)
+		}
+		n, want := tx.size(), e.Op == "=="
+		return boolType, func(f *frame) value {
+			a, b := x(f), y(f)
+			eq := true
+			for i := 0; i < n; i++ {
+				eq = eq && a[i] == b[i]
+			}
+			return value{b2f(eq == want)}
+		}
+	case "<", ">", "<=", ">=":
+		if !tx.isScalar() || !ty.isScalar() || !tx.numeric() || !ty.numeric() {
+			c.fail(e.Pos, "operands of relational operators must be numeric scalars, not %s and %s", tx, ty)
+		}
+		cmp := map[string]func(a, b float32) bool{
+			"<":  func(a, b float32) bool { return a < b },
+			">":  func(a, b float32) bool { return a > b },
+			"<=": func(a, b float32) bool { return a <= b },
+			">=": func(a, b float32) bool { return a >= b },
+		}[e.Op]
+		return boolType, func(f *frame) value {
+			return value{b2f(cmp(x(f)[0], y(f)[0]))}
+		}
+	case "+", "-", "*", "/", "%":
+		t, op := c.arith(e.Pos, e.Op, tx, ty)
+		return t, func(f *frame) value { return op(x(f), y(f)) }
+	}
+	c.fail(e.Pos, "operator %s is not supported", e.Op)
+	return tx, nil
+}
+
+// arith returns the result type and implementation of an arithmetic
+// operator applied to values of type a and b.
+func (c *compiler) arith(pos glsl.Pos, op string, a, b typ) (typ, func(x, y value) value) {
+	if !a.numeric() || !b.numeric() || a.arr > 0 || b.arr > 0 {
+		c.fail(pos, "operands of %s must be numeric, not %s and %s", op, a, b)
+	}
+	if op == "*" && (a.isMatrix() || b.isMatrix()) && !a.isScalar() && !b.isScalar() {
+		return c.matMul(pos, a, b)
+	}
+	rt := a
+	if a.isScalar() {
+		rt = b
+	}
+	if !a.isScalar() && !b.isScalar() && (a.n != b.n || a.c != b.c) {
+		c.fail(pos, "operands of %s must have the same size, not %s and %s", op, a, b)
+	}
+	integer := a.b == tInt && b.b == tInt
+	if integer {
+		rt.b = tInt
+	} else {
+		rt.b = tFloat
+	}
+	if op == "%" && !integer {
+		c.fail(pos, "operands of %% must be integers, not %s and %s", a, b)
+	}
+	var fn func(x, y float32) float32
+	switch op {
+	case "+":
+		fn = func(x, y float32) float32 { return x + y }
+	case "-":
+		fn = func(x, y float32) float32 { return x - y }
+	case "*":
+		fn = func(x, y float32) float32 { return x * y }
+	case "/":
+		if integer {
+			fn = func(x, y float32) float32 {
+				if y == 0 {
+					return 0
+				}
+				return float32(int32(x) / int32(y))
+			}
+		} else {
+			fn = func(x, y float32) float32 { return x / y }
+		}
+	case "%":
+		fn = func(x, y float32) float32 {
+			if y == 0 {
+				return 0
+			}
+			return float32(int32(x) % int32(y))
+		}
+	}
+	n, sa, sb := rt.size(), a.isScalar(), b.isScalar()
+	return rt, func(x, y value) value {
+		var r value
+		for i := 0; i < n; i++ {
+			p, q := x[0], y[0]
+			if !sa {
+				p = x[i]
+			}
+			if !sb {
+				q = y[i]
+			}
+			r[i] = fn(p, q)
+		}
+		return r
+	}
+}
+
+// matMul implements the linear algebraic product of a matrix with a
+// matrix or vector.
+func (c *compiler) matMul(pos glsl.Pos, a, b typ) (typ, func(x, y value) value) {
+	if a.b != tFloat {
+		a.b = tFloat
+	}
+	switch {
+	case a.isMatrix() && b.isMatrix():
+		if a.c != b.n {
+			c.fail(pos, "cannot multiply %s by %s", a, b)
+		}
+		rt := typ{b: tFloat, n: a.n, c: b.c}
+		return rt, func(x, y value) value {
+			var r value
+			for j := 0; j < b.c; j++ {
+				for i := 0; i < a.n; i++ {
+					var s float32
+					for k := 0; k < a.c; k++ {
+						s += x[k*a.n+i] * y[j*b.n+k]
+					}
+					r[j*a.n+i] = s
+				}
+			}
+			return r
+		}
+	case a.isMatrix():
+		if a.c != b.n {
+			c.fail(pos, "cannot multiply %s by %s", a, b)
+		}
+		return vecType(tFloat, a.n), func(x, y value) value {
+			var r value
+			for i := 0; i < a.n; i++ {
+				var s float32
+				for k := 0; k < a.c; k++ {
+					s += x[k*a.n+i] * y[k]
+				}
+				r[i] = s
+			}
+			return r
+		}
+	}
+	if a.n != b.n {
+		c.fail(pos, "cannot multiply %s by %s", a, b)
+	}
+	return vecType(tFloat, b.c), func(x, y value) value {
+		var r value
+		for j := 0; j < b.c; j++ {
+			var s float32
+			for k := 0; k < b.n; k++ {
+				s += x[k] * y[j*b.n+k]
+			}
+			r[j] = s
+		}
+		return r
+	}
+}
+
+func (c *compiler) call(e *glsl.CallExpr) (typ, exprFn) {
+	if glsl.IsType(e.Func) {
+		t, _ := parseType(glsl.Type{Name: e.Func})
+		return c.construct(e, t)
+	}
+	var types []typ
+	var args []exprFn
+	for _, a := range e.Args {
+		t, x := c.expr(a)
+		types = append(types, t)
+		args = append(args, x)
+	}
+	if fns, ok := c.funcs[e.Func]; ok {
+		return c.callUser(e, fns, types, args)
+	}
+	b, ok := builtinFuncs[e.Func]
+	if !ok {
+		c.fail(e.Pos, "no function with name '%s'", e.Func)
+	}
+	t, impl := b(c, e.Pos, types)
+	if impl == nil {
+		c.fail(e.Pos, "no matching function for call to %s%s", e.Func, typeList(types))
+	}
+	return t, func(f *frame) value {
+		var buf [3]value
+		a := buf[:len(args)]
+		for i, x := range args {
+			a[i] = x(f)
+		}
+		return impl(a)
+	}
+}
+
+func typeList(types []typ) string {
+	s := "("
+	for i, t := range types {
+		if i > 0 {
+			s += ", "
+		}
+		s += t.String()
+	}
+	return s + ")"
+}
+
+func (c *compiler) callUser(e *glsl.CallExpr, fns []*function, types []typ, args []exprFn) (typ, exprFn) {
+	var fn *function
+	for _, f := range fns {
+		if len(f.params) != len(types) {
+			continue
+		}
+		match := true
+		for i, p := range f.params {
+			match = match && assignable(p.t, types[i])
+		}
+		if match && (fn == nil || sameParams(f, types)) {
+			fn = f
+		}
+	}
+	if fn == nil {
+		c.fail(e.Pos, "no matching function for call to %s%s", e.Func, typeList(types))
+	}
+	if fn == c.fn {
+		c.fail(e.Pos, "recursive call to function '%s'", fn.name)
+	}
+	if _, ok := c.called[fn]; !ok {
+		c.called[fn] = e.Pos
+	}
+	// out and inout arguments are copied back after the call.
+	outs := make([]*ref, len(args))
+	for i, q := range fn.quals {
+		if q == "out" || q == "inout" {
+			r := c.lvalue(e.Args[i])
+			if r.t != fn.params[i].t {
+				c.fail(e.Args[i].Position(), "%s argument must have type %s, not %s", q, fn.params[i].t, r.t)
+			}
+			outs[i] = &r
+		}
+	}
+	return fn.ret, func(f *frame) value {
+		// The parameters' offsets are only known once the function
+		// has been compiled, so they are read at call time.
+		nf := frame{g: f.g, l: make([]float32, fn.frame)}
+		for i, x := range args {
+			p := fn.params[i]
+			v := x(f)
+			copy(nf.l[p.off:p.off+p.t.size()], v[:])
+		}
+		fn.body(&nf)
+		for i, r := range outs {
+			if r != nil {
+				p := fn.params[i]
+				var v value
+				copy(v[:], nf.l[p.off:p.off+p.t.size()])
+				r.write(f, v)
+			}
+		}
+		return nf.ret
+	}
+}
+
+// construct compiles a constructor call, such as vec4(v.xyz, 1).
+func (c *compiler) construct(e *glsl.CallExpr, t typ) (typ, exprFn) {
+	if t.b == tVoid || t.b == tSampler {
+		c.fail(e.Pos, "cannot construct a value of type %s", t)
+	}
+	if len(e.Args) == 0 {
+		c.fail(e.Pos, "too few arguments to constructor of %s", t)
+	}
+	type part struct {
+		x exprFn
+		n int
+	}
+	var parts []part
+	var types []typ
+	total := 0
+	for _, a := range e.Args {
+		at, x := c.expr(a)
+		if at.arr > 0 || at.b == tVoid || at.b == tSampler {
+			c.fail(a.Position(), "invalid argument of type %s to constructor of %s", at, t)
+		}
+		parts = append(parts, part{x, at.size()})
+		types = append(types, at)
+		total += at.size()
+	}
+	conv := converter(t.b)
+	n := t.size()
+	switch {
+	case len(parts) == 1 && types[0].isScalar() && !t.isScalar():
+		// splat a scalar, or fill the diagonal of a matrix
+		x := parts[0].x
+		if t.isMatrix() {
+			return t, func(f *frame) value {
+				var v value
+				s := conv(x(f)[0])
+				for i := 0; i < t.c && i < t.n; i++ {
+					v[i*t.n+i] = s
+				}
+				return v
+			}
+		}
+		return t, func(f *frame) value {
+			var v value
+			s := conv(x(f)[0])
+			for i := 0; i < n; i++ {
+				v[i] = s
+			}
+			return v
+		}
+	case len(parts) == 1 && types[0].isMatrix() && t.isMatrix():
+		src, x := types[0], parts[0].x
+		return t, func(f *frame) value {
+			var v value
+			a := x(f)
+			for j := 0; j < t.c; j++ {
+				for i := 0; i < t.n; i++ {
+					switch {
+					case j < src.c && i < src.n:
+						v[j*t.n+i] = a[j*src.n+i]
+					case i == j:
+						v[j*t.n+i] = 1
+					}
+				}
+			}
+			return v
+		}
+	}
+	if total < n {
+		c.fail(e.Pos, "too few components to construct %s", t)
+	}
+	if total-parts[len(parts)-1].n >= n {
+		c.fail(e.Pos, "too many arguments to constructor of %s", t)
+	}
+	return t, func(f *frame) value {
+		var v value
+		k := 0
+		for _, p := range parts {
+			a := p.x(f)
+			for i := 0; i < p.n && k < n; i++ {
+				v[k] = conv(a[i])
+				k++
+			}
+		}
+		return v
+	}
+}
+
+func converter(b basic) func(float32) float32 {
+	switch b {
+	case tInt:
+		return func(x float32) float32 { return float32(math.Trunc(float64(x))) }
+	case tBool:
+		return func(x float32) float32 { return b2f(x != 0) }
+	}
+	return func(x float32) float32 { return x }
+}
@@ -0,0 +1,167 @@
+package soft
+
+import (
+	"testing"
+
+	"github.com/droyo/gltut/internal/gfx"
+)
+
+// fullScreen is a triangle covering the whole window.
+var fullScreen = []float32{-1, -1, 0, 1, 3, -1, 0, 1, -1, 3, 0, 1}
+
+func TestInterpreter(t *testing.T) {
+	tests := []struct {
+		name string
+		body string    // body of the fragment shader's main
+		want [][4]byte // pixels of a 2x1 window, left to right
+	}{
+		{
+			name: "swizzle write",
+			body: `vec4 v = vec4(0.0, 0.0, 0.0, 1.0);
+				v.zx = vec2(0.25, 0.5);
+				color = v;`,
+			want: [][4]byte{{128, 0, 64, 255}, {128, 0, 64, 255}},
+		},
+		{
+			name: "swizzle of swizzle",
+			body: `vec4 v = vec4(0.25, 0.5, 0.75, 1.0);
+				color = vec4(v.wzyx.yx, 0.0, 1.0);`,
+			want: [][4]byte{{191, 255, 0, 255}, {191, 255, 0, 255}},
+		},
+		{
+			// Matrices are column-major: m*v sums the columns,
+			// v*m takes dot products with them.
+			name: "matrix times vector",
+			body: `mat2 m = mat2(1.0, 2.0, 3.0, 4.0);
+				color = vec4(m * vec2(1.0) / 8.0, 0.0, 1.0);`,
+			want: [][4]byte{{128, 191, 0, 255}, {128, 191, 0, 255}},
+		},
+		{
+			name: "vector times matrix",
+			body: `mat2 m = mat2(1.0, 2.0, 3.0, 4.0);
+				color = vec4(vec2(1.0) * m / 8.0, 0.0, 1.0);`,
+			want: [][4]byte{{96, 223, 0, 255}, {96, 223, 0, 255}},
+		},
+		{
+			name: "loop with break and continue",
+			body: `int s = 0;
+				for (int i = 0; i < 10; i++) {
+					if (i == 2) continue;
+					if (i == 5) break;
+					s += 1;
+				}
+				int w = 0;
+				while (true) {
+					if (++w >= 3) break;
+				}
+				color = vec4(float(s) / 8.0, float(w) / 4.0, 0.0, 1.0);`,
+			want: [][4]byte{{128, 191, 0, 255}, {128, 191, 0, 255}},
+		},
+		{
+			name: "discard",
+			body: `if (gl_FragCoord.x < 1.0) discard;
+				color = vec4(1.0);`,
+			want: [][4]byte{{0, 0, 0, 0}, {255, 255, 255, 255}},
+		},
+		{
+			name: "overloads",
+			body: `color = vec4(f(1.0), f(vec2(1.0)), f(1), max(vec2(0.0, 0.5), 0.25).x);`,
+			want: [][4]byte{{64, 128, 191, 64}, {64, 128, 191, 64}},
+		},
+	}
+	for _, tt := range tests {
+		c := New(2, 1)
+		fs := `#version 330
+out vec4 color;
+float f(float x) { return 0.25; }
+float f(vec2 x) { return 0.5; }
+float f(int x) { return 0.75; }
+void main() {
+	` + tt.body + `
+}
+`
+		build(t, c, passVS, fs)
+		positions(c, fullScreen...)
+		c.DrawArrays(gfx.TRIANGLES, 0, 3)
+		if err := c.Err(); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		img := c.Image()
+		for x, want := range tt.want {
+			px := img.RGBAAt(x, 0)
+			got := [4]byte{px.R, px.G, px.B, px.A}
+			for i := range got {
+				if d := int(got[i]) - int(want[i]); d < -1 || d > 1 {
+					t.Errorf("%s: pixel %d is %v, want %v", tt.name, x, got, want)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		stage gfx.Enum
+		src   string
+		want  string
+	}{
+		{
+			name:  "undeclared identifier",
+			stage: gfx.FRAGMENT_SHADER,
+			src:   "#version 330\nout vec4 color;\nvoid main() {\n\tcolor = tint;\n}\n",
+			want:  "0:4(10): error: 'tint' undeclared",
+		},
+		{
+			name:  "type mismatch",
+			stage: gfx.FRAGMENT_SHADER,
+			src:   "#version 330\nout vec4 color;\nvoid main() {\n\tcolor = vec3(1.0);\n}\n",
+			want:  "0:4(8): error: value of type vec3 cannot be assigned to variable of type vec4",
+		},
+		{
+			name:  "syntax error",
+			stage: gfx.FRAGMENT_SHADER,
+			src:   "#version 330\nout vec4 color;\nvoid main() {\n\tcolor = vec4(1.0;\n}\n",
+			want:  "0:4(18): error: syntax error, unexpected ';', expecting ')'",
+		},
+		{
+			name:  "no main",
+			stage: gfx.VERTEX_SHADER,
+			src:   "#version 330\nvoid f() {}\n",
+			want:  "0:1(0): error: no definition of main()",
+		},
+		{
+			name:  "break outside a loop",
+			stage: gfx.VERTEX_SHADER,
+			src:   "#version 330\nvoid main() {\n\tbreak;\n}\n",
+			want:  "0:3(2): error: break may only appear in a loop",
+		},
+		{
+			name:  "discard in a vertex shader",
+			stage: gfx.VERTEX_SHADER,
+			src:   "#version 330\nvoid main() {\n\tdiscard;\n}\n",
+			want:  "0:3(2): error: discard may only be used in fragment shaders",
+		},
+		{
+			name:  "unknown function",
+			stage: gfx.VERTEX_SHADER,
+			src:   "#version 330\nvoid main() {\n\tgl_Position = g(1.0);\n}\n",
+			want:  "0:3(16): error: no function with name 'g'",
+		},
+	}
+	for _, tt := range tests {
+		c := New(1, 1)
+		sh := c.CreateShader(tt.stage)
+		c.ShaderSource(sh, []byte(tt.src))
+		err := c.CompileShader(sh)
+		if err == nil {
+			t.Errorf("%s: compiled without error", tt.name)
+			continue
+		}
+		if err.Error() != tt.want {
+			t.Errorf("%s: error %q, want %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,415 @@
+package soft
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/std140"
+)
+
+type attribute struct {
+	sym *symbol
+	loc int
+}
+
+// A uniformLoc is a uniform location. Every element of a uniform
+// array has its own location.
+type uniformLoc struct {
+	name  string
+	t     typ // the type of one element
+	count int // number of array elements from here to the end
+	syms  [2]*symbol
+	off   int // offset of the element within the variable
+}
+
+// A linkedBlock is a uniform block of a linked program. Members
+// declared in both stages are filled in both frames.
+type linkedBlock struct {
+	name    string
+	layout  *std140.Type
+	members []blockMember
+	binding int
+}
+
+type blockMember struct {
+	syms [2]*symbol
+	t    *std140.Type
+	off  int // offset within the block, in bytes
+}
+
+type varying struct {
+	out, in *symbol
+	interp  string
+	off     int
+}
+
+// linked is a linked program. Each stage keeps a frame for the
+// lifetime of the program, which holds the values of uniforms
+// between draw calls.
+type linked struct {
+	vs, fs    *shader
+	frames    [2]*frame
+	attribs   []attribute
+	locations []uniformLoc
+	blocks    []linkedBlock
+	varyings  []varying
+	nvary     int
+	color     *symbol
+
+	position, vertexID                *symbol
+	fragCoord, frontFacing, fragDepth *symbol
+}
+
+func linkError(format string, args ...interface{}) error {
+	return fmt.Errorf("error: "+format, args...)
+}
+
+// link links the stages of a program. bindings holds the locations
+// given to vertex shader inputs with BindAttribLocation.
+func link(stages []*shader, bindings map[string]int) (*linked, error) {
+	var vs, fs *shader
+	for _, s := range stages {
+		switch s.stage {
+		case gfx.VERTEX_SHADER:
+			if vs != nil {
+				return nil, linkError("more than one vertex shader is attached")
+			}
+			vs = s
+		case gfx.FRAGMENT_SHADER:
+			if fs != nil {
+				return nil, linkError("more than one fragment shader is attached")
+			}
+			fs = s
+		}
+	}
+	if vs == nil {
+		return nil, linkError("no vertex shader is attached")
+	}
+	if fs == nil {
+		return nil, linkError("no fragment shader is attached")
+	}
+	l := &linked{
+		vs:          vs,
+		fs:          fs,
+		frames:      [2]*frame{vs.newFrame(), fs.newFrame()},
+		position:    vs.lookup("gl_Position"),
+		vertexID:    vs.lookup("gl_VertexID"),
+		fragCoord:   fs.lookup("gl_FragCoord"),
+		frontFacing: fs.lookup("gl_FrontFacing"),
+		fragDepth:   fs.lookup("gl_FragDepth"),
+	}
+	if err := l.linkAttribs(bindings); err != nil {
+		return nil, err
+	}
+	if err := l.linkVaryings(); err != nil {
+		return nil, err
+	}
+	if err := l.linkUniforms(); err != nil {
+		return nil, err
+	}
+	if err := l.linkBlocks(); err != nil {
+		return nil, err
+	}
+	for _, v := range fs.vars {
+		if v.storage != "out" || v.builtin {
+			continue
+		}
+		if loc, ok := v.layout["location"]; (ok && loc == 0) || l.color == nil {
+			l.color = v
+		}
+	}
+	return l, nil
+}
+
+// linkAttribs assigns a location to each vertex shader input,
+// honoring layout(location = N) qualifiers, and then the locations
+// bound by name.
+func (l *linked) linkAttribs(bindings map[string]int) error {
+	used := make(map[int]bool)
+	var unassigned []*symbol
+	for _, v := range l.vs.vars {
+		if v.storage != "in" || v.builtin {
+			continue
+		}
+		if v.t.arr > 0 || v.t.isMatrix() || v.t.b == tBool {
+			return linkError("vertex shader input `%s' of type %s is not supported", v.name, v.t)
+		}
+		loc, ok := v.layout["location"]
+		if !ok || loc < 0 {
+			loc, ok = bindings[v.name]
+		}
+		if !ok {
+			unassigned = append(unassigned, v)
+			continue
+		}
+		if loc >= maxAttribs || used[loc] {
+			return linkError("invalid location %d for vertex shader input `%s'", loc, v.name)
+		}
+		used[loc] = true
+		l.attribs = append(l.attribs, attribute{v, loc})
+	}
+	loc := 0
+	for _, v := range unassigned {
+		for used[loc] {
+			loc++
+		}
+		if loc >= maxAttribs {
+			return linkError("too many vertex shader inputs")
+		}
+		used[loc] = true
+		l.attribs = append(l.attribs, attribute{v, loc})
+	}
+	return nil
+}
+
+// linkVaryings matches the fragment shader's inputs with the vertex
+// shader's outputs.
+func (l *linked) linkVaryings() error {
+	for _, in := range l.fs.vars {
+		if in.storage != "in" || in.builtin {
+			continue
+		}
+		out := l.vs.lookup(in.name)
+		if out == nil || out.storage != "out" || out.builtin {
+			return linkError("fragment shader input `%s' has no matching output in the vertex shader", in.name)
+		}
+		if out.t != in.t {
+			return linkError("`%s' is declared as %s in the vertex shader and %s in the fragment shader",
+				in.name, out.t, in.t)
+		}
+		interp := in.interp
+		if interp == "" {
+			interp = out.interp
+		}
+		if interp == "" {
+			interp = "smooth"
+		}
+		if in.t.b != tFloat && interp != "flat" {
+			return linkError("integer fragment shader input `%s' must be qualified with flat", in.name)
+		}
+		l.varyings = append(l.varyings, varying{out, in, interp, l.nvary})
+		l.nvary += in.t.size()
+	}
+	return nil
+}
+
+func (l *linked) linkUniforms() error {
+	var names []string
+	syms := make(map[string]*[2]*symbol)
+	for i, s := range []*shader{l.vs, l.fs} {
+		for _, v := range s.vars {
+			if v.storage != "uniform" || v.t.b == tSampler || v.block != "" {
+				continue
+			}
+			p, ok := syms[v.name]
+			if !ok {
+				p = new([2]*symbol)
+				syms[v.name] = p
+				names = append(names, v.name)
+			} else if p[0].t != v.t {
+				return linkError("uniform `%s' is declared as %s in the vertex shader and %s in the fragment shader",
+					v.name, p[0].t, v.t)
+			}
+			p[i] = v
+		}
+	}
+	for _, name := range names {
+		p := syms[name]
+		var t typ
+		if p[0] != nil {
+			t = p[0].t
+		} else {
+			t = p[1].t
+		}
+		n := t.arr
+		if n == 0 {
+			n = 1
+		}
+		et := t.elem()
+		for i := 0; i < n; i++ {
+			loc := uniformLoc{name: name, t: et, count: n - i, syms: *p, off: i * et.size()}
+			if t.arr > 0 {
+				loc.name = fmt.Sprintf("%s[%d]", name, i)
+			}
+			l.locations = append(l.locations, loc)
+		}
+	}
+	return nil
+}
+
+// linkBlocks matches the uniform blocks of the two stages by name.
+// A block declared in both stages must have the same members.
+func (l *linked) linkBlocks() error {
+	for i, s := range []*shader{l.vs, l.fs} {
+	blocks:
+		for _, b := range s.blocks {
+			for j := range l.blocks {
+				lb := &l.blocks[j]
+				if lb.name != b.name {
+					continue
+				}
+				if len(lb.members) != len(b.members) {
+					return linkError("uniform block `%s' differs between the vertex and fragment shaders", b.name)
+				}
+				for k, m := range b.members {
+					old := lb.members[k].syms[0]
+					if memberName(old) != memberName(m) || old.t != m.t {
+						return linkError("uniform block `%s' differs between the vertex and fragment shaders", b.name)
+					}
+					lb.members[k].syms[i] = m
+				}
+				continue blocks
+			}
+			lb := linkedBlock{name: b.name}
+			var fields []std140.Field
+			for _, m := range b.members {
+				var bm blockMember
+				bm.syms[i] = m
+				bm.t = layoutType(m.t)
+				lb.members = append(lb.members, bm)
+				fields = append(fields, std140.Field{Name: memberName(m), Type: bm.t})
+			}
+			lb.layout = std140.NewStruct(fields...)
+			for k := range lb.members {
+				lb.members[k].off = lb.layout.Fields[k].Offset
+			}
+			l.blocks = append(l.blocks, lb)
+		}
+	}
+	return nil
+}
+
+// memberName returns the name of a block member without the
+// block's instance name, which may differ between stages.
+func memberName(s *symbol) string {
+	return s.name[strings.LastIndex(s.name, ".")+1:]
+}
+
+// layoutType returns the std140 type of a uniform block member.
+func layoutType(t typ) *std140.Type {
+	var st *std140.Type
+	s := std140.Float
+	switch t.b {
+	case tInt:
+		s = std140.Int
+	case tBool:
+		s = std140.Bool
+	}
+	switch {
+	case t.c > 1:
+		st = std140.NewMatrix(t.c, t.n)
+	case t.n > 1:
+		st = std140.NewVector(s, t.n)
+	default:
+		st = std140.NewScalar(s)
+	}
+	if t.arr > 0 {
+		st = std140.NewArray(st, t.arr)
+	}
+	return st
+}
+
+// loadBlock copies the contents of a uniform buffer into the
+// members of a block. data holds at least b.layout.Size() bytes.
+func (l *linked) loadBlock(b *linkedBlock, data []byte) {
+	for _, m := range b.members {
+		var vals []float32
+		m.t.Components(m.off, func(off int, s std140.Scalar) {
+			bits := binary.LittleEndian.Uint32(data[off:])
+			var v float32
+			switch s {
+			case std140.Float:
+				v = math.Float32frombits(bits)
+			case std140.Int:
+				v = float32(int32(bits))
+			case std140.Uint:
+				v = float32(bits)
+			case std140.Bool:
+				if bits != 0 {
+					v = 1
+				}
+			}
+			vals = append(vals, v)
+		})
+		for i, s := range m.syms {
+			if s != nil {
+				copy(l.frames[i].g[s.off:s.off+s.t.size()], vals)
+			}
+		}
+	}
+}
+
+// An active is an active attribute or uniform of a linked program,
+// as reported by GetActiveAttrib and GetActiveUniform. Arrays are
+// named by their first element.
+type active struct {
+	name string
+	size int
+	t    gfx.Enum
+}
+
+func newActive(name string, t typ) active {
+	if t.arr > 0 {
+		return active{name + "[0]", t.arr, t.enum()}
+	}
+	return active{name, 1, t.enum()}
+}
+
+func (l *linked) activeAttribs() []active {
+	var list []active
+	for _, a := range l.attribs {
+		list = append(list, newActive(a.sym.name, a.sym.t))
+	}
+	return list
+}
+
+// activeUniforms lists the uniforms outside of blocks, then the
+// members of each block. Members of a block with an instance name
+// are named as Block.member, as in OpenGL.
+func (l *linked) activeUniforms() []active {
+	var list []active
+	for _, loc := range l.locations {
+		if loc.off == 0 {
+			s := loc.syms[0]
+			if s == nil {
+				s = loc.syms[1]
+			}
+			list = append(list, newActive(s.name, s.t))
+		}
+	}
+	for _, b := range l.blocks {
+		for _, m := range b.members {
+			s := m.syms[0]
+			if s == nil {
+				s = m.syms[1]
+			}
+			name := memberName(s)
+			if strings.Contains(s.name, ".") {
+				name = b.name + "." + name
+			}
+			list = append(list, newActive(name, s.t))
+		}
+	}
+	return list
+}
+
+func (l *linked) uniformLocation(name string) (int, bool) {
+	for i, loc := range l.locations {
+		// "a" is the same location as "a[0]"
+		if loc.name == name || (loc.name == name+"[0]" && !strings.Contains(name, "[")) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (l *linked) setUniform(loc *uniformLoc, v []float32) {
+	for i, s := range loc.syms {
+		if s != nil {
+			copy(l.frames[i].g[s.off+loc.off:s.off+loc.off+loc.t.size()], v)
+		}
+	}
+}
@@ -0,0 +1,444 @@
+package soft
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/droyo/gltut/internal/gfx"
+)
+
+// A vertex is the output of the vertex shader: a position in clip
+// coordinates and the values of the varyings.
+type vertex struct {
+	pos  [4]float32
+	vary []float32
+}
+
+func (c *Context) DrawArrays(mode gfx.Enum, first, count int) {
+	idx := make([]int, count)
+	for i := range idx {
+		idx[i] = first + i
+	}
+	c.draw("DrawArrays", mode, idx)
+}
+
+func (c *Context) DrawElements(mode gfx.Enum, count int, typ gfx.Type, offset uintptr) {
+	c.DrawElementsBaseVertex(mode, count, typ, offset, 0)
+}
+
+func (c *Context) DrawElementsBaseVertex(mode gfx.Enum, count int, typ gfx.Type, offset uintptr, base int) {
+	buf := c.bound("DrawElements", gfx.ELEMENT_ARRAY_BUFFER)
+	if buf == nil {
+		return
+	}
+	size := typ.Size()
+	if typ != gfx.Uint8 && typ != gfx.Uint16 && typ != gfx.Uint32 {
+		c.errorf("DrawElements: invalid index type 0x%x", uint32(typ))
+		return
+	}
+	if int(offset)+count*size > len(buf.data) {
+		c.errorf("DrawElements: %d indices at offset %d overflow the element buffer", count, offset)
+		return
+	}
+	idx := make([]int, count)
+	for i := range idx {
+		p := buf.data[int(offset)+i*size:]
+		switch typ {
+		case gfx.Uint8:
+			idx[i] = int(p[0])
+		case gfx.Uint16:
+			idx[i] = int(binary.LittleEndian.Uint16(p))
+		case gfx.Uint32:
+			idx[i] = int(binary.LittleEndian.Uint32(p))
+		}
+		idx[i] += base
+	}
+	c.draw("DrawElements", mode, idx)
+}
+
+func (c *Context) draw(fn string, mode gfx.Enum, idx []int) {
+	l := c.linkedProgram(fn, c.current)
+	if l == nil || !c.loadBlocks(fn, l) {
+		return
+	}
+	var tris [][3]int
+	switch mode {
+	case gfx.TRIANGLES:
+		for i := 0; i+2 < len(idx); i += 3 {
+			tris = append(tris, [3]int{i, i + 1, i + 2})
+		}
+	case gfx.TRIANGLE_STRIP:
+		// every other triangle is reversed, so that all of them
+		// have the same winding
+		for i := 2; i < len(idx); i++ {
+			if i%2 == 0 {
+				tris = append(tris, [3]int{i - 2, i - 1, i})
+			} else {
+				tris = append(tris, [3]int{i - 1, i - 2, i})
+			}
+		}
+	case gfx.TRIANGLE_FAN:
+		for i := 2; i < len(idx); i++ {
+			tris = append(tris, [3]int{0, i - 1, i})
+		}
+	default:
+		c.errorf("%s: unsupported primitive mode 0x%x", fn, uint32(mode))
+		return
+	}
+	// Shade each distinct vertex once.
+	shaded := make(map[int]*vertex)
+	verts := make([]*vertex, len(idx))
+	for i, n := range idx {
+		v, ok := shaded[n]
+		if !ok {
+			if v = c.shadeVertex(fn, l, n); v == nil {
+				return
+			}
+			shaded[n] = v
+		}
+		verts[i] = v
+	}
+	for _, t := range tris {
+		c.triangle(l, verts[t[0]], verts[t[1]], verts[t[2]])
+	}
+}
+
+// shadeVertex fetches the attributes of vertex n and runs the vertex
+// shader.
+func (c *Context) shadeVertex(fn string, l *linked, n int) *vertex {
+	f := l.frames[0]
+	va := c.vaos[c.vao]
+	for _, a := range l.attribs {
+		v := [4]float32{0, 0, 0, 1}
+		p := va.attribs[a.loc]
+		if p.enabled {
+			if !c.fetch(fn, &p, n, v[:]) {
+				return nil
+			}
+		}
+		copy(f.g[a.sym.off:a.sym.off+a.sym.t.size()], v[:])
+	}
+	f.g[l.vertexID.off] = float32(n)
+	l.vs.run(f)
+	out := &vertex{vary: make([]float32, l.nvary)}
+	copy(out.pos[:], f.g[l.position.off:])
+	for _, v := range l.varyings {
+		copy(out.vary[v.off:v.off+v.in.t.size()], f.g[v.out.off:])
+	}
+	return out
+}
+
+// fetch reads the components of vertex n from an attribute array
+// into v, converting them to floating point.
+func (c *Context) fetch(fn string, p *attribPointer, n int, v []float32) bool {
+	buf, ok := c.buffers[p.buf]
+	if !ok {
+		c.errorf("%s: buffer %d of an attribute array was deleted", fn, p.buf)
+		return false
+	}
+	size := p.typ.Size()
+	start := int(p.offset) + n*p.stride
+	if n < 0 || start+p.size*size > len(buf.data) {
+		c.errorf("%s: vertex %d is outside of buffer %d", fn, n, p.buf)
+		return false
+	}
+	b := buf.data[start:]
+	for i := 0; i < p.size; i++ {
+		v[i] = component(b[i*size:], p.typ, p.normalized)
+	}
+	return true
+}
+
+func component(b []byte, typ gfx.Type, normalized bool) float32 {
+	var x, max float64
+	switch typ {
+	case gfx.Float32:
+		return math.Float32frombits(binary.LittleEndian.Uint32(b))
+	case gfx.Int8:
+		x, max = float64(int8(b[0])), math.MaxInt8
+	case gfx.Uint8:
+		x, max = float64(b[0]), math.MaxUint8
+	case gfx.Int16:
+		x, max = float64(int16(binary.LittleEndian.Uint16(b))), math.MaxInt16
+	case gfx.Uint16:
+		x, max = float64(binary.LittleEndian.Uint16(b)), math.MaxUint16
+	case gfx.Int32:
+		x, max = float64(int32(binary.LittleEndian.Uint32(b))), math.MaxInt32
+	case gfx.Uint32:
+		x, max = float64(binary.LittleEndian.Uint32(b)), math.MaxUint32
+	}
+	if normalized {
+		return float32(math.Max(x/max, -1))
+	}
+	return float32(x)
+}
+
+// Clip planes, as coefficients of (x, y, z, w). A vertex is inside a
+// plane if the dot product is not negative.
+var (
+	wPlane      = [4]float32{0, 0, 0, 1}
+	sidePlanes  = [][4]float32{{1, 0, 0, 1}, {-1, 0, 0, 1}, {0, 1, 0, 1}, {0, -1, 0, 1}}
+	depthPlanes = [][4]float32{{0, 0, 1, 1}, {0, 0, -1, 1}}
+)
+
+// Vertices must stay in front of the eye even when depth clamping
+// disables the near plane, so that the perspective divide is safe.
+const minW = 1e-5
+
+func planeDist(p [4]float32, v *vertex) float32 {
+	d := p[0]*v.pos[0] + p[1]*v.pos[1] + p[2]*v.pos[2] + p[3]*v.pos[3]
+	if p == wPlane {
+		d -= minW
+	}
+	return d
+}
+
+func lerpVertex(a, b *vertex, t float32) *vertex {
+	v := &vertex{vary: make([]float32, len(a.vary))}
+	for i := range v.pos {
+		v.pos[i] = a.pos[i] + t*(b.pos[i]-a.pos[i])
+	}
+	for i := range v.vary {
+		v.vary[i] = a.vary[i] + t*(b.vary[i]-a.vary[i])
+	}
+	return v
+}
+
+// clip clips a polygon against the view volume, using the
+// Sutherland-Hodgman algorithm.
+func (c *Context) clip(poly []*vertex) []*vertex {
+	planes := append([][4]float32{wPlane}, sidePlanes...)
+	if !c.caps[gfx.DEPTH_CLAMP] {
+		planes = append(planes, depthPlanes...)
+	}
+	for _, p := range planes {
+		inside := true
+		for _, v := range poly {
+			inside = inside && planeDist(p, v) >= 0
+		}
+		if inside {
+			continue
+		}
+		var out []*vertex
+		for i, a := range poly {
+			b := poly[(i+1)%len(poly)]
+			da, db := planeDist(p, a), planeDist(p, b)
+			if da >= 0 {
+				out = append(out, a)
+			}
+			if (da >= 0) != (db >= 0) {
+				out = append(out, lerpVertex(a, b, da/(da-db)))
+			}
+		}
+		poly = out
+		if len(poly) < 3 {
+			return nil
+		}
+	}
+	return poly
+}
+
+// A screenVertex is a vertex in window coordinates.
+type screenVertex struct {
+	x, y, z float32
+	invW    float32
+	vary    []float32
+}
+
+func (c *Context) toScreen(v *vertex) screenVertex {
+	invW := 1 / v.pos[3]
+	vx, vy := float32(c.viewport[0]), float32(c.viewport[1])
+	vw, vh := float32(c.viewport[2]), float32(c.viewport[3])
+	n, f := float32(c.near), float32(c.far)
+	return screenVertex{
+		x:    (v.pos[0]*invW+1)*vw/2 + vx,
+		y:    (v.pos[1]*invW+1)*vh/2 + vy,
+		z:    ((f-n)*v.pos[2]*invW + f + n) / 2,
+		invW: invW,
+		vary: v.vary,
+	}
+}
+
+func (c *Context) triangle(l *linked, a, b, p *vertex) {
+	poly := c.clip([]*vertex{a, b, p})
+	if poly == nil {
+		return
+	}
+	sv := make([]screenVertex, len(poly))
+	for i, v := range poly {
+		sv[i] = c.toScreen(v)
+	}
+	// Clipping preserves the winding of the polygon, so it is
+	// culled as a whole.
+	var area float32
+	for i := range sv {
+		j := (i + 1) % len(sv)
+		area += sv[i].x*sv[j].y - sv[j].x*sv[i].y
+	}
+	if area == 0 {
+		return
+	}
+	front := (area > 0) == (c.frontFace == gfx.CCW)
+	if c.caps[gfx.CULL_FACE] {
+		switch c.cullFace {
+		case gfx.FRONT_AND_BACK:
+			return
+		case gfx.FRONT:
+			if front {
+				return
+			}
+		default:
+			if !front {
+				return
+			}
+		}
+	}
+	for i := 2; i < len(sv); i++ {
+		// Flat varyings take their value from the last vertex of the
+		// original triangle, the provoking vertex.
+		c.raster(l, &sv[0], &sv[i-1], &sv[i], area > 0, front, p.vary)
+	}
+}
+
+func edge(a, b *screenVertex, x, y float32) float32 {
+	return (b.x-a.x)*(y-a.y) - (b.y-a.y)*(x-a.x)
+}
+
+// topLeft reports whether the edge from a to b of a counter-clockwise
+// triangle is a top or left edge. Pixel centers exactly on an edge
+// are only drawn for top and left edges, so that pixels on an edge
+// shared by two triangles are drawn once.
+func topLeft(a, b *screenVertex) bool {
+	return b.y < a.y || (a.y == b.y && b.x < a.x)
+}
+
+func (c *Context) raster(l *linked, v0, v1, v2 *screenVertex, ccw, front bool, flat []float32) {
+	if !ccw {
+		v1, v2 = v2, v1
+	}
+	area := edge(v0, v1, v2.x, v2.y)
+	if area <= 0 {
+		return
+	}
+	minX := int(math.Floor(float64(min3(v0.x, v1.x, v2.x))))
+	maxX := int(math.Ceil(float64(max3(v0.x, v1.x, v2.x))))
+	minY := int(math.Floor(float64(min3(v0.y, v1.y, v2.y))))
+	maxY := int(math.Ceil(float64(max3(v0.y, v1.y, v2.y))))
+	minX, minY = maxInt(minX, 0), maxInt(minY, 0)
+	maxX, maxY = minInt(maxX, c.width-1), minInt(maxY, c.height-1)
+
+	tl0, tl1, tl2 := topLeft(v1, v2), topLeft(v2, v0), topLeft(v0, v1)
+	f := l.frames[1]
+	for y := minY; y <= maxY; y++ {
+		py := float32(y) + 0.5
+		for x := minX; x <= maxX; x++ {
+			px := float32(x) + 0.5
+			e0, e1, e2 := edge(v1, v2, px, py), edge(v2, v0, px, py), edge(v0, v1, px, py)
+			if e0 < 0 || e1 < 0 || e2 < 0 ||
+				(e0 == 0 && !tl0) || (e1 == 0 && !tl1) || (e2 == 0 && !tl2) {
+				continue
+			}
+			b0, b1, b2 := e0/area, e1/area, e2/area
+			c.fragment(l, f, x, y, [3]*screenVertex{v0, v1, v2}, [3]float32{b0, b1, b2}, front, flat)
+		}
+	}
+}
+
+// fragment shades and writes the fragment at (x, y), whose
+// barycentric coordinates within the triangle tri are b.
+func (c *Context) fragment(l *linked, f *frame, x, y int, tri [3]*screenVertex, b [3]float32, front bool, flat []float32) {
+	z := b[0]*tri[0].z + b[1]*tri[1].z + b[2]*tri[2].z
+	invW := b[0]*tri[0].invW + b[1]*tri[1].invW + b[2]*tri[2].invW
+	// perspective-correct weights
+	var p [3]float32
+	for i := range p {
+		p[i] = b[i] * tri[i].invW / invW
+	}
+	for _, v := range l.varyings {
+		if v.interp == "flat" {
+			copy(f.g[v.in.off:v.in.off+v.in.t.size()], flat[v.off:])
+			continue
+		}
+		w := p
+		if v.interp == "noperspective" {
+			w = b
+		}
+		for k := 0; k < v.in.t.size(); k++ {
+			i := v.off + k
+			f.g[v.in.off+k] = w[0]*tri[0].vary[i] + w[1]*tri[1].vary[i] + w[2]*tri[2].vary[i]
+		}
+	}
+	copy(f.g[l.fragCoord.off:], []float32{float32(x) + 0.5, float32(y) + 0.5, z, invW})
+	f.g[l.frontFacing.off] = b2f(front)
+	f.g[l.fragDepth.off] = float32(math.NaN())
+	if l.fs.run(f) == flowDiscard {
+		return
+	}
+	if d := f.g[l.fragDepth.off]; d == d {
+		z = d
+	}
+	if c.caps[gfx.DEPTH_CLAMP] {
+		lo, hi := float32(math.Min(c.near, c.far)), float32(math.Max(c.near, c.far))
+		z = float32(math.Min(math.Max(float64(z), float64(lo)), float64(hi)))
+	}
+	i := y*c.width + x
+	if c.caps[gfx.DEPTH_TEST] {
+		if !depthPass(c.depthFunc, z, c.depth[i]) {
+			return
+		}
+		if c.depthMask {
+			c.depth[i] = z
+		}
+	}
+	if l.color == nil {
+		return
+	}
+	out := f.g[l.color.off:]
+	px := c.color[4*i : 4*i+4]
+	px[3] = 255
+	for k := 0; k < l.color.t.n && k < 4; k++ {
+		px[k] = unorm8(out[k])
+	}
+}
+
+func depthPass(fn gfx.Enum, z, d float32) bool {
+	switch fn {
+	case gfx.NEVER:
+		return false
+	case gfx.LESS:
+		return z < d
+	case gfx.EQUAL:
+		return z == d
+	case gfx.LEQUAL:
+		return z <= d
+	case gfx.GREATER:
+		return z > d
+	case gfx.NOTEQUAL:
+		return z != d
+	case gfx.GEQUAL:
+		return z >= d
+	}
+	return true
+}
+
+func min3(a, b, c float32) float32 {
+	return float32(math.Min(float64(a), math.Min(float64(b), float64(c))))
+}
+
+func max3(a, b, c float32) float32 {
+	return float32(math.Max(float64(a), math.Max(float64(b), float64(c))))
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
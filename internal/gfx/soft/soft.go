@@ -0,0 +1,788 @@
+// Package soft implements gfx.Context with a software rasterizer,
+// so that tutorials can be drawn without a window or an OpenGL
+// driver. Shaders are compiled from GLSL by an interpreter for the
+// subset of the language parsed by package glsl.
+//
+// The renderer follows the OpenGL 3.2 core specification for the
+// features in gfx.Context: clipping, face culling, perspective-correct
+// interpolation, the depth test and depth clamping. It is meant to be
+// deterministic, not fast.
+package soft
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/droyo/gltut/internal/gfx"
+)
+
+const (
+	maxAttribs         = 16
+	maxUniformBindings = 36
+)
+
+type shaderObject struct {
+	typ      gfx.Enum
+	src      []byte
+	compiled *shader
+}
+
+type program struct {
+	shaders  []gfx.Shader
+	bindings map[string]int // from BindAttribLocation
+	linked   *linked
+}
+
+type buffer struct {
+	data []byte
+}
+
+// A bufferRange is a range of a buffer bound to an indexed target.
+// A size of 0 means the whole buffer.
+type bufferRange struct {
+	buf          gfx.Buffer
+	offset, size int
+}
+
+type attribPointer struct {
+	enabled    bool
+	buf        gfx.Buffer
+	size       int
+	typ        gfx.Type
+	normalized bool
+	stride     int
+	offset     uintptr
+}
+
+type vertexArray struct {
+	elements gfx.Buffer
+	attribs  [maxAttribs]attribPointer
+}
+
+// A Context renders to an in-memory framebuffer with a color and a
+// depth buffer. The zero value is not usable; call New.
+type Context struct {
+	width, height int
+	color         []byte    // RGBA, bottom row first
+	depth         []float32 // bottom row first
+
+	clearColor [4]float32
+	clearDepth float32
+	caps       map[gfx.Enum]bool
+	cullFace   gfx.Enum
+	frontFace  gfx.Enum
+	depthFunc  gfx.Enum
+	depthMask  bool
+	near, far  float64
+	viewport   [4]int
+
+	nextName    uint32
+	shaders     map[gfx.Shader]*shaderObject
+	programs    map[gfx.Program]*program
+	buffers     map[gfx.Buffer]*buffer
+	vaos        map[gfx.VertexArray]*vertexArray
+	arrayBuffer gfx.Buffer
+	vao         gfx.VertexArray
+	current     gfx.Program
+
+	uniformBuffer   gfx.Buffer
+	uniformBindings [maxUniformBindings]bufferRange
+
+	err error
+}
+
+var _ gfx.Context = (*Context)(nil)
+
+// New returns a Context with a framebuffer of the given size, in
+// the default OpenGL state.
+func New(width, height int) *Context {
+	c := &Context{
+		width:     width,
+		height:    height,
+		color:     make([]byte, 4*width*height),
+		depth:     make([]float32, width*height),
+		caps:      make(map[gfx.Enum]bool),
+		cullFace:  gfx.BACK,
+		frontFace: gfx.CCW,
+		depthFunc: gfx.LESS,
+		depthMask: true,
+		far:       1,
+		viewport:  [4]int{0, 0, width, height},
+
+		clearDepth: 1,
+		shaders:    make(map[gfx.Shader]*shaderObject),
+		programs:   make(map[gfx.Program]*program),
+		buffers:    make(map[gfx.Buffer]*buffer),
+		// vertex array 0 holds the vertex state when no vertex
+		// array object is bound.
+		vaos: map[gfx.VertexArray]*vertexArray{0: new(vertexArray)},
+	}
+	for i := range c.depth {
+		c.depth[i] = 1
+	}
+	return c
+}
+
+// Image returns a copy of the color buffer. Unlike OpenGL, whose
+// rows start at the bottom of the window, the first row of the image
+// is the top of the framebuffer.
+func (c *Context) Image() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, c.width, c.height))
+	stride := 4 * c.width
+	for y := 0; y < c.height; y++ {
+		row := c.color[(c.height-1-y)*stride : (c.height-y)*stride]
+		copy(img.Pix[y*img.Stride:], row)
+	}
+	return img
+}
+
+// Depth returns the value of the depth buffer at (x, y), where y
+// counts from the top of the framebuffer, as in Image.
+func (c *Context) Depth(x, y int) float32 {
+	return c.depth[(c.height-1-y)*c.width+x]
+}
+
+// Err returns the first error recorded since the last call to Err,
+// and clears it. Like glGetError, it reports misuse of the API,
+// such as drawing without a program or with a missing buffer.
+func (c *Context) Err() error {
+	err := c.err
+	c.err = nil
+	return err
+}
+
+func (c *Context) errorf(format string, args ...interface{}) {
+	if c.err == nil {
+		c.err = fmt.Errorf("soft: "+format, args...)
+	}
+}
+
+func (c *Context) name() uint32 {
+	c.nextName++
+	return c.nextName
+}
+
+func (c *Context) ClearColor(r, g, b, a float32) { c.clearColor = [4]float32{r, g, b, a} }
+func (c *Context) ClearDepth(d float64)          { c.clearDepth = float32(clamp01(d)) }
+func (c *Context) Enable(cap gfx.Enum)           { c.caps[cap] = true }
+func (c *Context) Disable(cap gfx.Enum)          { c.caps[cap] = false }
+func (c *Context) IsEnabled(cap gfx.Enum) bool   { return c.caps[cap] }
+func (c *Context) CullFace(mode gfx.Enum)        { c.cullFace = mode }
+func (c *Context) FrontFace(mode gfx.Enum)       { c.frontFace = mode }
+func (c *Context) DepthFunc(fn gfx.Enum)         { c.depthFunc = fn }
+func (c *Context) DepthMask(on bool)             { c.depthMask = on }
+func (c *Context) Viewport(x, y, w, h int)       { c.viewport = [4]int{x, y, w, h} }
+
+func (c *Context) DepthRange(near, far float64) {
+	c.near, c.far = clamp01(near), clamp01(far)
+}
+
+func clamp01(x float64) float64 {
+	switch {
+	case x < 0:
+		return 0
+	case x > 1:
+		return 1
+	}
+	return x
+}
+
+func (c *Context) Clear(mask gfx.Enum) {
+	if mask&gfx.COLOR_BUFFER_BIT != 0 {
+		var px [4]byte
+		for i, v := range c.clearColor {
+			px[i] = unorm8(v)
+		}
+		for i := 0; i < len(c.color); i += 4 {
+			copy(c.color[i:i+4], px[:])
+		}
+	}
+	// As in OpenGL, the depth mask applies to glClear.
+	if mask&gfx.DEPTH_BUFFER_BIT != 0 && c.depthMask {
+		for i := range c.depth {
+			c.depth[i] = c.clearDepth
+		}
+	}
+}
+
+func (c *Context) ReadPixels(x, y, width, height int, format gfx.Enum, typ gfx.Type, dst interface{}) {
+	switch dst := dst.(type) {
+	case []byte:
+		if format != gfx.RGBA || typ != gfx.Uint8 {
+			c.errorf("ReadPixels: cannot read format %#x, type %#x into []byte", format, typ)
+			return
+		}
+		if len(dst) < 4*width*height {
+			c.errorf("ReadPixels: dst holds %d bytes, need %d", len(dst), 4*width*height)
+			return
+		}
+		c.readPixels(x, y, width, height, func(src, dst0 int) {
+			copy(dst[4*dst0:4*dst0+4], c.color[4*src:4*src+4])
+		})
+	case []float32:
+		if format != gfx.DEPTH_COMPONENT || typ != gfx.Float32 {
+			c.errorf("ReadPixels: cannot read format %#x, type %#x into []float32", format, typ)
+			return
+		}
+		if len(dst) < width*height {
+			c.errorf("ReadPixels: dst holds %d values, need %d", len(dst), width*height)
+			return
+		}
+		c.readPixels(x, y, width, height, func(src, dst0 int) {
+			dst[dst0] = c.depth[src]
+		})
+	default:
+		c.errorf("ReadPixels: unsupported destination %T", dst)
+	}
+}
+
+// readPixels calls fn with the index of every pixel of the block
+// that lies within the framebuffer, and its index in the block.
+func (c *Context) readPixels(x, y, width, height int, fn func(src, dst int)) {
+	for j := 0; j < height; j++ {
+		if y+j < 0 || y+j >= c.height {
+			continue
+		}
+		for i := 0; i < width; i++ {
+			if x+i < 0 || x+i >= c.width {
+				continue
+			}
+			fn((y+j)*c.width+x+i, j*width+i)
+		}
+	}
+}
+
+func unorm8(v float32) byte {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 1:
+		return 255
+	}
+	return byte(v*255 + 0.5)
+}
+
+func (c *Context) CreateShader(typ gfx.Enum) gfx.Shader {
+	s := gfx.Shader(c.name())
+	c.shaders[s] = &shaderObject{typ: typ}
+	return s
+}
+
+func (c *Context) DeleteShader(s gfx.Shader) { delete(c.shaders, s) }
+
+func (c *Context) ShaderSource(s gfx.Shader, src []byte) {
+	obj, ok := c.shaders[s]
+	if !ok {
+		c.errorf("ShaderSource: no shader %d", s)
+		return
+	}
+	obj.src = append([]byte(nil), src...)
+}
+
+func (c *Context) CompileShader(s gfx.Shader) error {
+	obj, ok := c.shaders[s]
+	if !ok {
+		c.errorf("CompileShader: no shader %d", s)
+		return fmt.Errorf("no shader %d", s)
+	}
+	sh, err := compileShader(obj.typ, obj.src)
+	obj.compiled = sh
+	return err
+}
+
+func (c *Context) CreateProgram() gfx.Program {
+	p := gfx.Program(c.name())
+	c.programs[p] = new(program)
+	return p
+}
+
+func (c *Context) DeleteProgram(p gfx.Program) {
+	delete(c.programs, p)
+	if c.current == p {
+		c.current = 0
+	}
+}
+
+func (c *Context) AttachShader(p gfx.Program, s gfx.Shader) {
+	prog, ok := c.programs[p]
+	if !ok {
+		c.errorf("AttachShader: no program %d", p)
+		return
+	}
+	prog.shaders = append(prog.shaders, s)
+}
+
+func (c *Context) DetachShader(p gfx.Program, s gfx.Shader) {
+	prog, ok := c.programs[p]
+	if !ok {
+		c.errorf("DetachShader: no program %d", p)
+		return
+	}
+	for i, x := range prog.shaders {
+		if x == s {
+			prog.shaders = append(prog.shaders[:i], prog.shaders[i+1:]...)
+			return
+		}
+	}
+}
+
+func (c *Context) BindAttribLocation(p gfx.Program, a gfx.Attrib, name string) {
+	prog, ok := c.programs[p]
+	switch {
+	case !ok:
+		c.errorf("BindAttribLocation: no program %d", p)
+	case a >= maxAttribs:
+		c.errorf("BindAttribLocation: attribute %d out of range", a)
+	case strings.HasPrefix(name, "gl_"):
+		c.errorf("BindAttribLocation: cannot bind built-in %s", name)
+	default:
+		if prog.bindings == nil {
+			prog.bindings = make(map[string]int)
+		}
+		prog.bindings[name] = int(a)
+	}
+}
+
+func (c *Context) LinkProgram(p gfx.Program) error {
+	prog, ok := c.programs[p]
+	if !ok {
+		c.errorf("LinkProgram: no program %d", p)
+		return fmt.Errorf("no program %d", p)
+	}
+	var stages []*shader
+	for _, s := range prog.shaders {
+		obj, ok := c.shaders[s]
+		if !ok || obj.compiled == nil {
+			return fmt.Errorf("error: shader %d is not compiled", s)
+		}
+		stages = append(stages, obj.compiled)
+	}
+	l, err := link(stages, prog.bindings)
+	prog.linked = l
+	return err
+}
+
+func (c *Context) UseProgram(p gfx.Program) {
+	if _, ok := c.programs[p]; !ok && p != 0 {
+		c.errorf("UseProgram: no program %d", p)
+		return
+	}
+	c.current = p
+}
+
+func (c *Context) linkedProgram(fn string, p gfx.Program) *linked {
+	prog, ok := c.programs[p]
+	if !ok || prog.linked == nil {
+		c.errorf("%s: program %d is not linked", fn, p)
+		return nil
+	}
+	return prog.linked
+}
+
+func (c *Context) GetProgramiv(p gfx.Program, pname gfx.Enum) int {
+	l := c.linkedProgram("GetProgramiv", p)
+	if l == nil {
+		return 0
+	}
+	switch pname {
+	case gfx.ACTIVE_ATTRIBUTES:
+		return len(l.activeAttribs())
+	case gfx.ACTIVE_UNIFORMS:
+		return len(l.activeUniforms())
+	}
+	c.errorf("GetProgramiv: unsupported parameter 0x%x", uint32(pname))
+	return 0
+}
+
+func (c *Context) GetActiveAttrib(p gfx.Program, index int) (string, int, gfx.Enum) {
+	return c.active("GetActiveAttrib", p, index, (*linked).activeAttribs)
+}
+
+func (c *Context) GetActiveUniform(p gfx.Program, index int) (string, int, gfx.Enum) {
+	return c.active("GetActiveUniform", p, index, (*linked).activeUniforms)
+}
+
+func (c *Context) active(fn string, p gfx.Program, index int, list func(*linked) []active) (string, int, gfx.Enum) {
+	l := c.linkedProgram(fn, p)
+	if l == nil {
+		return "", 0, 0
+	}
+	all := list(l)
+	if index < 0 || index >= len(all) {
+		c.errorf("%s: no index %d in program %d", fn, index, p)
+		return "", 0, 0
+	}
+	a := all[index]
+	return a.name, a.size, a.t
+}
+
+func (c *Context) GetAttribLocation(p gfx.Program, name string) (gfx.Attrib, error) {
+	l := c.linkedProgram("GetAttribLocation", p)
+	if l == nil {
+		return 0, fmt.Errorf("program %d is not linked", p)
+	}
+	for _, a := range l.attribs {
+		if a.sym.name == name {
+			return gfx.Attrib(a.loc), nil
+		}
+	}
+	return 0, fmt.Errorf("no attribute %q in program %d", name, p)
+}
+
+func (c *Context) GetUniformLocation(p gfx.Program, name string) (gfx.Uniform, error) {
+	l := c.linkedProgram("GetUniformLocation", p)
+	if l == nil {
+		return -1, fmt.Errorf("program %d is not linked", p)
+	}
+	if loc, ok := l.uniformLocation(name); ok {
+		return gfx.Uniform(loc), nil
+	}
+	return -1, fmt.Errorf("no uniform %q in program %d", name, p)
+}
+
+func (c *Context) GetUniformBlockIndex(p gfx.Program, name string) (gfx.UniformBlock, error) {
+	l := c.linkedProgram("GetUniformBlockIndex", p)
+	if l == nil {
+		return 0, fmt.Errorf("program %d is not linked", p)
+	}
+	for i, b := range l.blocks {
+		if b.name == name {
+			return gfx.UniformBlock(i), nil
+		}
+	}
+	return 0, fmt.Errorf("no uniform block %q in program %d", name, p)
+}
+
+// linkedBlock returns the uniform block of program p at index block.
+func (c *Context) linkedBlock(fn string, p gfx.Program, block gfx.UniformBlock) *linkedBlock {
+	l := c.linkedProgram(fn, p)
+	if l == nil {
+		return nil
+	}
+	if int(block) >= len(l.blocks) {
+		c.errorf("%s: no uniform block %d in program %d", fn, block, p)
+		return nil
+	}
+	return &l.blocks[block]
+}
+
+func (c *Context) UniformBlockBinding(p gfx.Program, block gfx.UniformBlock, binding int) {
+	b := c.linkedBlock("UniformBlockBinding", p, block)
+	if b == nil {
+		return
+	}
+	if binding < 0 || binding >= maxUniformBindings {
+		c.errorf("UniformBlockBinding: invalid binding point %d", binding)
+		return
+	}
+	b.binding = binding
+}
+
+func (c *Context) GetActiveUniformBlockiv(p gfx.Program, block gfx.UniformBlock, pname gfx.Enum) int {
+	b := c.linkedBlock("GetActiveUniformBlockiv", p, block)
+	if b == nil {
+		return 0
+	}
+	switch pname {
+	case gfx.UNIFORM_BLOCK_DATA_SIZE:
+		return b.layout.Size()
+	}
+	c.errorf("GetActiveUniformBlockiv: unsupported parameter 0x%x", uint32(pname))
+	return 0
+}
+
+// loadBlocks fills the uniform blocks of l from the buffers bound
+// to their binding points. It reports false if a block has no
+// buffer, or a buffer is too small.
+func (c *Context) loadBlocks(fn string, l *linked) bool {
+	for i := range l.blocks {
+		b := &l.blocks[i]
+		r := c.uniformBindings[b.binding]
+		buf, ok := c.buffers[r.buf]
+		if !ok {
+			c.errorf("%s: no buffer bound to uniform block %s (binding %d)", fn, b.name, b.binding)
+			return false
+		}
+		end := len(buf.data)
+		if r.size > 0 && r.offset+r.size < end {
+			end = r.offset + r.size
+		}
+		if end-r.offset < b.layout.Size() {
+			c.errorf("%s: uniform block %s needs %d bytes; binding %d has %d",
+				fn, b.name, b.layout.Size(), b.binding, end-r.offset)
+			return false
+		}
+		l.loadBlock(b, buf.data[r.offset:end])
+	}
+	return true
+}
+
+// uniform returns the location u of the current program.
+func (c *Context) uniform(fn string, u gfx.Uniform) (*linked, *uniformLoc) {
+	if u == -1 {
+		// like OpenGL, silently ignore location -1
+		return nil, nil
+	}
+	l := c.linkedProgram(fn, c.current)
+	if l == nil {
+		return nil, nil
+	}
+	if u < 0 || int(u) >= len(l.locations) {
+		c.errorf("%s: invalid uniform location %d", fn, u)
+		return nil, nil
+	}
+	return l, &l.locations[u]
+}
+
+func (c *Context) Uniformf(u gfx.Uniform, v ...float32) {
+	l, loc := c.uniform("Uniformf", u)
+	if loc == nil {
+		return
+	}
+	if loc.t.isMatrix() || len(v) != loc.t.n {
+		c.errorf("Uniformf: %d values for uniform %s of type %s", len(v), loc.name, loc.t)
+		return
+	}
+	l.setUniform(loc, v)
+}
+
+func (c *Context) UniformMatrix4fv(u gfx.Uniform, transpose bool, m []float32) {
+	l, loc := c.uniform("UniformMatrix4fv", u)
+	if loc == nil {
+		return
+	}
+	if loc.t.n != 4 || loc.t.c != 4 || len(m)%16 != 0 || len(m) == 0 {
+		c.errorf("UniformMatrix4fv: %d values for uniform %s of type %s", len(m), loc.name, loc.t)
+		return
+	}
+	// consecutive matrices fill consecutive elements of an array
+	for i := 0; i < len(m)/16 && i < loc.count; i++ {
+		var v [16]float32
+		copy(v[:], m[16*i:])
+		if transpose {
+			for r := 0; r < 4; r++ {
+				for c := 0; c < 4; c++ {
+					v[c*4+r] = m[16*i+r*4+c]
+				}
+			}
+		}
+		l.setUniform(&l.locations[int(u)+i], v[:])
+	}
+}
+
+func (c *Context) GenBuffers(n int) []gfx.Buffer {
+	b := make([]gfx.Buffer, n)
+	for i := range b {
+		b[i] = gfx.Buffer(c.name())
+		c.buffers[b[i]] = new(buffer)
+	}
+	return b
+}
+
+func (c *Context) DeleteBuffers(b []gfx.Buffer) {
+	for _, x := range b {
+		delete(c.buffers, x)
+		if c.arrayBuffer == x {
+			c.arrayBuffer = 0
+		}
+		if c.uniformBuffer == x {
+			c.uniformBuffer = 0
+		}
+		for i := range c.uniformBindings {
+			if c.uniformBindings[i].buf == x {
+				c.uniformBindings[i] = bufferRange{}
+			}
+		}
+		if va := c.vaos[c.vao]; va.elements == x {
+			va.elements = 0
+		}
+	}
+}
+
+func (c *Context) BindBuffer(target gfx.Enum, b gfx.Buffer) {
+	if _, ok := c.buffers[b]; !ok && b != 0 {
+		c.errorf("BindBuffer: no buffer %d", b)
+		return
+	}
+	switch target {
+	case gfx.ARRAY_BUFFER:
+		c.arrayBuffer = b
+	case gfx.ELEMENT_ARRAY_BUFFER:
+		// the element array binding is part of the vertex array state
+		c.vaos[c.vao].elements = b
+	case gfx.UNIFORM_BUFFER:
+		c.uniformBuffer = b
+	default:
+		c.errorf("BindBuffer: unsupported target 0x%x", uint32(target))
+	}
+}
+
+func (c *Context) BindBufferBase(target gfx.Enum, index int, b gfx.Buffer) {
+	c.BindBufferRange(target, index, b, 0, 0)
+}
+
+// BindBufferRange binds a range of b to a uniform buffer binding
+// point. Like OpenGL, it also binds b to the generic target. The
+// range is checked against the size of the buffer at draw time,
+// since the buffer may not have storage yet.
+func (c *Context) BindBufferRange(target gfx.Enum, index int, b gfx.Buffer, offset, size int) {
+	if target != gfx.UNIFORM_BUFFER {
+		c.errorf("BindBufferRange: unsupported target 0x%x", uint32(target))
+		return
+	}
+	if index < 0 || index >= maxUniformBindings {
+		c.errorf("BindBufferRange: invalid binding point %d", index)
+		return
+	}
+	if offset < 0 || size < 0 {
+		c.errorf("BindBufferRange: invalid range [%d, %d)", offset, offset+size)
+		return
+	}
+	if _, ok := c.buffers[b]; !ok && b != 0 {
+		c.errorf("BindBufferRange: no buffer %d", b)
+		return
+	}
+	c.uniformBuffer = b
+	c.uniformBindings[index] = bufferRange{b, offset, size}
+}
+
+func (c *Context) bound(fn string, target gfx.Enum) *buffer {
+	var b gfx.Buffer
+	switch target {
+	case gfx.ARRAY_BUFFER:
+		b = c.arrayBuffer
+	case gfx.ELEMENT_ARRAY_BUFFER:
+		b = c.vaos[c.vao].elements
+	case gfx.UNIFORM_BUFFER:
+		b = c.uniformBuffer
+	}
+	buf, ok := c.buffers[b]
+	if !ok {
+		c.errorf("%s: no buffer bound to target 0x%x", fn, uint32(target))
+		return nil
+	}
+	return buf
+}
+
+// bufferBytes converts a slice of fixed-size values, or a pointer to
+// a fixed-size value, to its little-endian representation.
+func bufferBytes(data interface{}) ([]byte, error) {
+	if b, ok := data.([]byte); ok {
+		return append([]byte(nil), b...), nil
+	}
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, data); err != nil {
+		return nil, fmt.Errorf("soft: cannot store %T in a buffer: %v", data, err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *Context) BufferData(target gfx.Enum, data interface{}, usage gfx.Enum) error {
+	buf := c.bound("BufferData", target)
+	if buf == nil {
+		return c.Err()
+	}
+	b, err := bufferBytes(data)
+	if err != nil {
+		return err
+	}
+	buf.data = b
+	return nil
+}
+
+func (c *Context) BufferSubData(target gfx.Enum, offset int, data interface{}) error {
+	buf := c.bound("BufferSubData", target)
+	if buf == nil {
+		return c.Err()
+	}
+	b, err := bufferBytes(data)
+	if err != nil {
+		return err
+	}
+	if offset < 0 || offset+len(b) > len(buf.data) {
+		return fmt.Errorf("soft: BufferSubData: range [%d, %d) is outside buffer of %d bytes",
+			offset, offset+len(b), len(buf.data))
+	}
+	copy(buf.data[offset:], b)
+	return nil
+}
+
+func (c *Context) GenVertexArrays(n int) []gfx.VertexArray {
+	a := make([]gfx.VertexArray, n)
+	for i := range a {
+		a[i] = gfx.VertexArray(c.name())
+		c.vaos[a[i]] = new(vertexArray)
+	}
+	return a
+}
+
+func (c *Context) DeleteVertexArrays(a []gfx.VertexArray) {
+	for _, x := range a {
+		if x == 0 {
+			continue
+		}
+		delete(c.vaos, x)
+		if c.vao == x {
+			c.vao = 0
+		}
+	}
+}
+
+func (c *Context) BindVertexArray(a gfx.VertexArray) {
+	if _, ok := c.vaos[a]; !ok {
+		c.errorf("BindVertexArray: no vertex array %d", a)
+		return
+	}
+	c.vao = a
+}
+
+func (c *Context) attrib(fn string, a gfx.Attrib) *attribPointer {
+	if a >= maxAttribs {
+		c.errorf("%s: attribute %d out of range", fn, a)
+		return nil
+	}
+	return &c.vaos[c.vao].attribs[a]
+}
+
+func (c *Context) EnableVertexAttribArray(a gfx.Attrib) {
+	if p := c.attrib("EnableVertexAttribArray", a); p != nil {
+		p.enabled = true
+	}
+}
+
+func (c *Context) DisableVertexAttribArray(a gfx.Attrib) {
+	if p := c.attrib("DisableVertexAttribArray", a); p != nil {
+		p.enabled = false
+	}
+}
+
+func (c *Context) VertexAttribPointer(a gfx.Attrib, size int, typ gfx.Type, normalized bool, stride int, offset uintptr) {
+	p := c.attrib("VertexAttribPointer", a)
+	if p == nil {
+		return
+	}
+	if size < 1 || size > 4 || typ.Size() == 0 {
+		c.errorf("VertexAttribPointer: invalid size %d or type 0x%x", size, uint32(typ))
+		return
+	}
+	if c.arrayBuffer == 0 {
+		c.errorf("VertexAttribPointer: no buffer bound to ARRAY_BUFFER")
+		return
+	}
+	if stride == 0 {
+		stride = size * typ.Size()
+	}
+	// the pointer captures the buffer bound at the time of the call
+	*p = attribPointer{
+		enabled:    p.enabled,
+		buf:        c.arrayBuffer,
+		size:       size,
+		typ:        typ,
+		normalized: normalized,
+		stride:     stride,
+		offset:     offset,
+	}
+}
@@ -0,0 +1,266 @@
+package soft
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/droyo/gltut/internal/gfx"
+)
+
+const (
+	passVS = `#version 330
+layout(location = 0) in vec4 position;
+void main() { gl_Position = position; }
+`
+	tintFS = `#version 330
+uniform vec4 tint;
+out vec4 color;
+void main() { color = tint; }
+`
+)
+
+// build compiles and links a program and makes it current.
+func build(t *testing.T, c *Context, vs, fs string) gfx.Program {
+	t.Helper()
+	p := c.CreateProgram()
+	for _, s := range []struct {
+		typ gfx.Enum
+		src string
+	}{{gfx.VERTEX_SHADER, vs}, {gfx.FRAGMENT_SHADER, fs}} {
+		sh := c.CreateShader(s.typ)
+		c.ShaderSource(sh, []byte(s.src))
+		if err := c.CompileShader(sh); err != nil {
+			t.Fatalf("compiling %s shader: %v", stageName(s.typ), err)
+		}
+		c.AttachShader(p, sh)
+	}
+	if err := c.LinkProgram(p); err != nil {
+		t.Fatalf("linking: %v", err)
+	}
+	c.UseProgram(p)
+	return p
+}
+
+// positions uploads vertex positions with four components each, to
+// feed attribute 0.
+func positions(c *Context, v ...float32) {
+	b := c.GenBuffers(1)[0]
+	c.BindBuffer(gfx.ARRAY_BUFFER, b)
+	c.BufferData(gfx.ARRAY_BUFFER, v, gfx.STATIC_DRAW)
+	c.EnableVertexAttribArray(0)
+	c.VertexAttribPointer(0, 4, gfx.Float32, false, 0, 0)
+}
+
+// setTint sets the tint uniform of p.
+func setTint(t *testing.T, c *Context, p gfx.Program, r, g, b, a float32) {
+	t.Helper()
+	u, err := c.GetUniformLocation(p, "tint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Uniformf(u, r, g, b, a)
+}
+
+// coverage returns the rows of the framebuffer from the top, with
+// '#' for pixels with some red and '.' for the others.
+func coverage(c *Context) []string {
+	img := c.Image()
+	var rows []string
+	for y := 0; y < c.height; y++ {
+		row := make([]byte, c.width)
+		for x := range row {
+			row[x] = '.'
+			if img.RGBAAt(x, y).R != 0 {
+				row[x] = '#'
+			}
+		}
+		rows = append(rows, string(row))
+	}
+	return rows
+}
+
+func checkCoverage(t *testing.T, name string, c *Context, want ...string) {
+	t.Helper()
+	if got := coverage(c); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("%s: drew\n\t%q\nwant\n\t%q", name, got, want)
+	}
+	if err := c.Err(); err != nil {
+		t.Errorf("%s: %v", name, err)
+	}
+}
+
+func TestClipping(t *testing.T) {
+	full := []string{"########", "########", "########", "########", "########", "########", "########", "########"}
+	tests := []struct {
+		name  string
+		clamp bool
+		tri   []float32
+		want  []string
+	}{
+		{
+			// z crosses the near plane, z = -w, a third of
+			// the way from the bottom edge to the top vertex.
+			name: "near plane",
+			tri:  []float32{-1, -1, 0, 1, 3, -1, 0, 1, -1, 3, -3, 1},
+			want: []string{"........", "........", "........", "########", "########", "########", "########", "########"},
+		},
+		{
+			name: "beyond far plane",
+			tri:  []float32{-1, -1, 2, 1, 3, -1, 2, 1, -1, 3, 2, 1},
+			want: []string{"........", "........", "........", "........", "........", "........", "........", "........"},
+		},
+		{
+			name:  "depth clamp",
+			clamp: true,
+			tri:   []float32{-1, -1, 2, 1, 3, -1, 2, 1, -1, 3, -3, 1},
+			want:  full,
+		},
+		{
+			// The top vertex is behind the eye. Dividing by
+			// its w would flip it below the window; clipping
+			// at w = 0 leaves a wedge that widens upwards.
+			name: "behind the eye",
+			tri:  []float32{-1, -1, 0, 1, 1, -1, 0, 1, 0, 3, 0, -1},
+			want: full,
+		},
+	}
+	for _, tt := range tests {
+		c := New(8, 8)
+		p := build(t, c, passVS, tintFS)
+		setTint(t, c, p, 1, 0, 0, 1)
+		if tt.clamp {
+			c.Enable(gfx.DEPTH_CLAMP)
+		}
+		positions(c, tt.tri...)
+		c.DrawArrays(gfx.TRIANGLES, 0, 3)
+		checkCoverage(t, tt.name, c, tt.want...)
+	}
+}
+
+func TestCulling(t *testing.T) {
+	// Each triangle covers the bottom left half of the window.
+	ccw := []float32{-1, -1, 0, 1, 3, -1, 0, 1, -1, 3, 0, 1}
+	cw := []float32{-1, -1, 0, 1, -1, 3, 0, 1, 3, -1, 0, 1}
+	tests := []struct {
+		name      string
+		cull      bool
+		mode      gfx.Enum
+		frontFace gfx.Enum
+		tri       []float32
+		drawn     bool
+	}{
+		{"no culling, ccw", false, gfx.BACK, gfx.CCW, ccw, true},
+		{"no culling, cw", false, gfx.BACK, gfx.CCW, cw, true},
+		{"cull back, ccw front", true, gfx.BACK, gfx.CCW, ccw, true},
+		{"cull back, cw back", true, gfx.BACK, gfx.CCW, cw, false},
+		{"cull back, cw front", true, gfx.BACK, gfx.CW, cw, true},
+		{"cull back, ccw back", true, gfx.BACK, gfx.CW, ccw, false},
+		{"cull front, ccw front", true, gfx.FRONT, gfx.CCW, ccw, false},
+		{"cull front, cw back", true, gfx.FRONT, gfx.CCW, cw, true},
+		{"cull both", true, gfx.FRONT_AND_BACK, gfx.CCW, ccw, false},
+	}
+	for _, tt := range tests {
+		c := New(2, 2)
+		p := build(t, c, passVS, tintFS)
+		setTint(t, c, p, 1, 0, 0, 1)
+		if tt.cull {
+			c.Enable(gfx.CULL_FACE)
+		}
+		c.CullFace(tt.mode)
+		c.FrontFace(tt.frontFace)
+		positions(c, tt.tri...)
+		c.DrawArrays(gfx.TRIANGLES, 0, 3)
+		want := []string{"..", ".."}
+		if tt.drawn {
+			want = []string{"##", "##"}
+		}
+		checkCoverage(t, tt.name, c, want...)
+	}
+}
+
+func TestDepth(t *testing.T) {
+	// A full-window triangle at each depth.
+	at := func(z float32) []float32 {
+		return []float32{-1, -1, z, 1, 3, -1, z, 1, -1, 3, z, 1}
+	}
+	type layer struct {
+		z       float32
+		r, g, b float32
+	}
+	tests := []struct {
+		name   string
+		test   bool
+		fn     gfx.Enum
+		mask   bool
+		layers []layer
+		color  [3]byte
+		depth  float32
+	}{
+		{"test off draws in order", false, gfx.LESS, true,
+			[]layer{{0, 1, 0, 0}, {0.5, 0, 1, 0}}, [3]byte{0, 255, 0}, 1},
+		{"less keeps the nearer", true, gfx.LESS, true,
+			[]layer{{0, 1, 0, 0}, {0.5, 0, 1, 0}}, [3]byte{255, 0, 0}, 0.5},
+		{"less draws the nearer", true, gfx.LESS, true,
+			[]layer{{0.5, 1, 0, 0}, {0, 0, 1, 0}}, [3]byte{0, 255, 0}, 0.5},
+		{"less rejects equal", true, gfx.LESS, true,
+			[]layer{{0, 1, 0, 0}, {0, 0, 1, 0}}, [3]byte{255, 0, 0}, 0.5},
+		{"lequal accepts equal", true, gfx.LEQUAL, true,
+			[]layer{{0, 1, 0, 0}, {0, 0, 1, 0}}, [3]byte{0, 255, 0}, 0.5},
+		{"greater", true, gfx.GREATER, true,
+			[]layer{{0, 1, 0, 0}, {0.5, 0, 1, 0}}, [3]byte{0, 0, 0}, 1},
+		{"mask off leaves depth", true, gfx.LESS, false,
+			[]layer{{0.5, 1, 0, 0}, {0, 0, 1, 0}, {0.2, 0, 0, 1}}, [3]byte{0, 0, 255}, 1},
+	}
+	for _, tt := range tests {
+		c := New(1, 1)
+		p := build(t, c, passVS, tintFS)
+		if tt.test {
+			c.Enable(gfx.DEPTH_TEST)
+		}
+		c.DepthFunc(tt.fn)
+		c.DepthMask(tt.mask)
+		for _, l := range tt.layers {
+			setTint(t, c, p, l.r, l.g, l.b, 1)
+			positions(c, at(l.z)...)
+			c.DrawArrays(gfx.TRIANGLES, 0, 3)
+		}
+		px := c.Image().RGBAAt(0, 0)
+		if got := [3]byte{px.R, px.G, px.B}; got != tt.color {
+			t.Errorf("%s: color %v, want %v", tt.name, got, tt.color)
+		}
+		if got := c.Depth(0, 0); got != tt.depth {
+			t.Errorf("%s: depth %v, want %v", tt.name, got, tt.depth)
+		}
+		if err := c.Err(); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+	}
+}
+
+func TestDrawElementsBaseVertex(t *testing.T) {
+	c := New(4, 4)
+	p := build(t, c, passVS, tintFS)
+	setTint(t, c, p, 1, 0, 0, 1)
+	// Two off-screen vertices, then a quad covering the left half
+	// of the window.
+	positions(c,
+		5, 5, 0, 1,
+		6, 6, 0, 1,
+		-1, -1, 0, 1,
+		0, -1, 0, 1,
+		0, 1, 0, 1,
+		-1, 1, 0, 1)
+	b := c.GenBuffers(1)[0]
+	c.BindBuffer(gfx.ELEMENT_ARRAY_BUFFER, b)
+	// The first index is padding, skipped by the offset; the rest
+	// count from the third vertex.
+	c.BufferData(gfx.ELEMENT_ARRAY_BUFFER, []uint16{9, 0, 1, 2, 0, 2, 3}, gfx.STATIC_DRAW)
+	c.DrawElementsBaseVertex(gfx.TRIANGLES, 6, gfx.Uint16, 2, 2)
+	checkCoverage(t, "base vertex 2, offset 2", c, "##..", "##..", "##..", "##..")
+
+	c.Clear(gfx.COLOR_BUFFER_BIT)
+	c.DrawElementsBaseVertex(gfx.TRIANGLES, 6, gfx.Uint16, 4, 0)
+	if err := c.Err(); err == nil {
+		t.Error("reading past the end of the element buffer was not reported")
+	}
+}
@@ -0,0 +1,153 @@
+package soft
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/glsl"
+)
+
+// Every GLSL value is stored as float32 components; integers are
+// exact up to 2^24, which is plenty for shader arithmetic, and
+// booleans are 0 or 1.
+type basic uint8
+
+const (
+	tFloat basic = iota
+	tInt
+	tBool
+	tVoid
+	tSampler
+)
+
+// typ is the type of a GLSL expression. Scalars have n = c = 1,
+// vectors have c = 1, and matrices have c columns of n rows.
+type typ struct {
+	b   basic
+	n   int
+	c   int
+	arr int // array length, 0 for non-arrays
+}
+
+var (
+	voidType  = typ{b: tVoid}
+	floatType = typ{b: tFloat, n: 1, c: 1}
+	intType   = typ{b: tInt, n: 1, c: 1}
+	boolType  = typ{b: tBool, n: 1, c: 1}
+	vec4Type  = typ{b: tFloat, n: 4, c: 1}
+)
+
+func vecType(b basic, n int) typ { return typ{b: b, n: n, c: 1} }
+
+// size returns the number of components in a value of type t.
+func (t typ) size() int {
+	s := t.n * t.c
+	if t.arr > 0 {
+		s *= t.arr
+	}
+	return s
+}
+
+func (t typ) elem() typ {
+	t.arr = 0
+	return t
+}
+
+func (t typ) isScalar() bool { return t.arr == 0 && t.n == 1 && t.c == 1 && t.b != tVoid }
+func (t typ) isVector() bool { return t.arr == 0 && t.n > 1 && t.c == 1 }
+func (t typ) isMatrix() bool { return t.arr == 0 && t.c > 1 }
+func (t typ) numeric() bool  { return t.b == tFloat || t.b == tInt }
+
+// column returns the type of one column of a matrix.
+func (t typ) column() typ { return typ{b: t.b, n: t.n, c: 1} }
+
+func (t typ) String() string {
+	var s string
+	switch {
+	case t.b == tVoid:
+		return "void"
+	case t.b == tSampler:
+		s = "sampler"
+	case t.isMatrix() || (t.arr > 0 && t.c > 1):
+		if t.n == t.c {
+			s = fmt.Sprintf("mat%d", t.n)
+		} else {
+			s = fmt.Sprintf("mat%dx%d", t.c, t.n)
+		}
+	case t.n == 1:
+		s = map[basic]string{tFloat: "float", tInt: "int", tBool: "bool"}[t.b]
+	default:
+		s = map[basic]string{tFloat: "vec", tInt: "ivec", tBool: "bvec"}[t.b] + fmt.Sprint(t.n)
+	}
+	if t.arr > 0 {
+		s += fmt.Sprintf("[%d]", t.arr)
+	}
+	return s
+}
+
+// enum returns the OpenGL constant for the type of one element of
+// t, as reported by GetActiveAttrib and GetActiveUniform.
+func (t typ) enum() gfx.Enum {
+	switch {
+	case t.b == tSampler:
+		return gfx.SAMPLER_2D
+	case t.c > 1:
+		mats := [5][5]gfx.Enum{
+			2: {2: gfx.FLOAT_MAT2, 3: gfx.FLOAT_MAT2x3, 4: gfx.FLOAT_MAT2x4},
+			3: {2: gfx.FLOAT_MAT3x2, 3: gfx.FLOAT_MAT3, 4: gfx.FLOAT_MAT3x4},
+			4: {2: gfx.FLOAT_MAT4x2, 3: gfx.FLOAT_MAT4x3, 4: gfx.FLOAT_MAT4},
+		}
+		return mats[t.c][t.n]
+	}
+	vecs := map[basic][5]gfx.Enum{
+		tFloat: {1: gfx.FLOAT, 2: gfx.FLOAT_VEC2, 3: gfx.FLOAT_VEC3, 4: gfx.FLOAT_VEC4},
+		tInt:   {1: gfx.INT, 2: gfx.INT_VEC2, 3: gfx.INT_VEC3, 4: gfx.INT_VEC4},
+		tBool:  {1: gfx.BOOL, 2: gfx.BOOL_VEC2, 3: gfx.BOOL_VEC3, 4: gfx.BOOL_VEC4},
+	}
+	return vecs[t.b][t.n]
+}
+
+// parseType converts a GLSL type name to a typ.
+func parseType(t glsl.Type) (typ, bool) {
+	name := t.Name
+	var r typ
+	switch {
+	case name == "void":
+		r = voidType
+	case name == "float":
+		r = floatType
+	case name == "int", name == "uint":
+		r = intType
+	case name == "bool":
+		r = boolType
+	case strings.HasPrefix(name, "sampler"):
+		r = typ{b: tSampler, n: 1, c: 1}
+	case len(name) == 4 && strings.HasPrefix(name, "vec"):
+		r = vecType(tFloat, int(name[3]-'0'))
+	case len(name) == 5 && (strings.HasPrefix(name, "ivec") || strings.HasPrefix(name, "uvec")):
+		r = vecType(tInt, int(name[4]-'0'))
+	case len(name) == 5 && strings.HasPrefix(name, "bvec"):
+		r = vecType(tBool, int(name[4]-'0'))
+	case len(name) == 4 && strings.HasPrefix(name, "mat"):
+		n := int(name[3] - '0')
+		r = typ{b: tFloat, n: n, c: n}
+	case len(name) == 6 && strings.HasPrefix(name, "mat") && name[4] == 'x':
+		r = typ{b: tFloat, n: int(name[5] - '0'), c: int(name[3] - '0')}
+	default:
+		return r, false
+	}
+	r.arr = t.ArrayLen
+	return r, true
+}
+
+// A value holds a non-array GLSL value. Matrices are stored in
+// column-major order.
+type value [16]float32
+
+func b2f(b bool) float32 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,200 @@
+package glsl
+
+// A File is a parsed shader.
+type File struct {
+	Decls []Decl
+}
+
+// A Decl is a top-level declaration: a *VarDecl, *BlockDecl or
+// *FuncDecl.
+type Decl interface {
+	declNode()
+}
+
+// A Type names a GLSL type, such as "vec4", with an optional array
+// length. ArrayLen is zero for non-array types.
+type Type struct {
+	Name     string
+	ArrayLen int
+}
+
+// Qualifiers modify a variable declaration.
+type Qualifiers struct {
+	Storage string         // "in", "out", "uniform", "const", or ""
+	Interp  string         // "smooth", "flat", "noperspective", or ""
+	Layout  map[string]int // layout(...) qualifiers; a bare name maps to -1
+}
+
+// A VarDecl declares one or more variables of the same type.
+type VarDecl struct {
+	Pos  Pos
+	Qual Qualifiers
+	Type Type
+	Vars []*Var
+}
+
+// A Var is a single variable in a declaration. Its array length,
+// if any, overrides the length in the declaration's Type.
+type Var struct {
+	Pos      Pos
+	Name     string
+	ArrayLen int
+	Init     Expr
+}
+
+// A BlockDecl declares an interface block, such as a uniform block.
+// Instance is empty if the block's members are declared at global
+// scope.
+type BlockDecl struct {
+	Pos      Pos
+	Qual     Qualifiers
+	Name     string
+	Members  []*VarDecl
+	Instance string
+}
+
+// A FuncDecl declares or defines a function. Body is nil for
+// prototypes.
+type FuncDecl struct {
+	Pos    Pos
+	Ret    Type
+	Name   string
+	Params []*Param
+	Body   *BlockStmt
+}
+
+// A Param is a function parameter.
+type Param struct {
+	Pos  Pos
+	Qual string // "in", "out", "inout" or ""
+	Type Type
+	Name string
+}
+
+func (*VarDecl) declNode()   {}
+func (*BlockDecl) declNode() {}
+func (*FuncDecl) declNode()  {}
+
+// An Expr is an expression.
+type Expr interface {
+	Position() Pos
+}
+
+type (
+	// An IdentExpr refers to a variable.
+	IdentExpr struct {
+		Pos  Pos
+		Name string
+	}
+	// A LitExpr is a numeric or boolean literal.
+	LitExpr struct {
+		Pos   Pos
+		Kind  Token // IntLit, FloatLit, or Ident for true/false
+		Value string
+	}
+	// A UnaryExpr is a prefix operator applied to X. Op is one of
+	// "-", "+", "!", "~", "++" or "--".
+	UnaryExpr struct {
+		Pos Pos
+		Op  string
+		X   Expr
+	}
+	// A PostfixExpr is X++ or X--.
+	PostfixExpr struct {
+		Pos Pos
+		Op  string
+		X   Expr
+	}
+	// A BinaryExpr is a binary operator, including assignment
+	// operators.
+	BinaryExpr struct {
+		Pos  Pos
+		Op   string
+		X, Y Expr
+	}
+	// A CondExpr is Cond ? X : Y.
+	CondExpr struct {
+		Pos  Pos
+		Cond Expr
+		X, Y Expr
+	}
+	// A CallExpr calls a function or constructs a value of a
+	// built-in type.
+	CallExpr struct {
+		Pos  Pos
+		Func string
+		Args []Expr
+	}
+	// A FieldExpr selects a swizzle or a block member.
+	FieldExpr struct {
+		Pos  Pos
+		X    Expr
+		Name string
+	}
+	// An IndexExpr is X[Index].
+	IndexExpr struct {
+		Pos   Pos
+		X     Expr
+		Index Expr
+	}
+)
+
+func (e *IdentExpr) Position() Pos   { return e.Pos }
+func (e *LitExpr) Position() Pos     { return e.Pos }
+func (e *UnaryExpr) Position() Pos   { return e.Pos }
+func (e *PostfixExpr) Position() Pos { return e.Pos }
+func (e *BinaryExpr) Position() Pos  { return e.Pos }
+func (e *CondExpr) Position() Pos    { return e.Pos }
+func (e *CallExpr) Position() Pos    { return e.Pos }
+func (e *FieldExpr) Position() Pos   { return e.Pos }
+func (e *IndexExpr) Position() Pos   { return e.Pos }
+
+// A Stmt is a statement.
+type Stmt interface {
+	stmtNode()
+}
+
+type (
+	BlockStmt struct {
+		Pos   Pos
+		Stmts []Stmt
+	}
+	DeclStmt struct {
+		Decl *VarDecl
+	}
+	ExprStmt struct {
+		X Expr
+	}
+	IfStmt struct {
+		Pos  Pos
+		Cond Expr
+		Then Stmt
+		Else Stmt // may be nil
+	}
+	// A ForStmt is a for or while loop. Init and Post are nil for
+	// while loops.
+	ForStmt struct {
+		Pos  Pos
+		Init Stmt
+		Cond Expr // may be nil
+		Post Expr
+		Body Stmt
+	}
+	ReturnStmt struct {
+		Pos    Pos
+		Result Expr // may be nil
+	}
+	// A BranchStmt is break, continue or discard.
+	BranchStmt struct {
+		Pos Pos
+		Tok string
+	}
+)
+
+func (*BlockStmt) stmtNode()  {}
+func (*DeclStmt) stmtNode()   {}
+func (*ExprStmt) stmtNode()   {}
+func (*IfStmt) stmtNode()     {}
+func (*ForStmt) stmtNode()    {}
+func (*ReturnStmt) stmtNode() {}
+func (*BranchStmt) stmtNode() {}
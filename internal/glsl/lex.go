@@ -0,0 +1,336 @@
+// Package glsl parses the subset of the OpenGL Shading Language
+// used by the tutorials. It understands enough of the language to
+// list a shader's inputs, outputs and uniforms, and to build the
+// syntax tree executed by the software renderer.
+package glsl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A Token is a lexical token of GLSL source.
+type Token int
+
+const (
+	EOF Token = iota
+	Ident
+	IntLit
+	FloatLit
+	Punct // operators and punctuation; the text is in Lexeme.Text
+)
+
+// A Pos is a position in a shader's source. Line numbers start
+// at 1. Source is the source string number, which is 0 unless a
+// #line directive sets it.
+type Pos struct {
+	Line, Col int
+	Source    int
+}
+
+func (p Pos) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Col)
+}
+
+// A Lexeme is a token along with its text and position.
+type Lexeme struct {
+	Tok  Token
+	Text string
+	Pos  Pos
+}
+
+// An Error is a syntax or semantic error in GLSL source. Its
+// message is formatted like the info logs of the Mesa drivers,
+// "source:line(col): error: msg", so that tools which parse driver
+// logs can locate the offending line.
+type Error struct {
+	Pos Pos
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d:%d(%d): error: %s", e.Pos.Source, e.Pos.Line, e.Pos.Col, e.Msg)
+}
+
+func errorf(pos Pos, format string, args ...interface{}) *Error {
+	return &Error{pos, fmt.Sprintf(format, args...)}
+}
+
+// Longest operators first, so that ">>=" is not split into ">" ">=".
+var puncts = []string{
+	"<<=", ">>=",
+	"++", "--", "<=", ">=", "==", "!=", "&&", "||", "^^",
+	"+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=", "<<", ">>",
+	"+", "-", "*", "/", "%", "<", ">", "=", "!", "~", "&", "|", "^",
+	"?", ":", ";", ",", ".", "(", ")", "[", "]", "{", "}",
+}
+
+type lexer struct {
+	src    string
+	off    int
+	line   int
+	col    int
+	source int
+	out    []Lexeme
+
+	defines map[string][]Lexeme
+	cond    []bool // stack of #if states; false while skipping
+}
+
+// Lex splits src into tokens. Comments are discarded. The
+// preprocessor directives #define, #undef, #ifdef, #ifndef, #else
+// and #endif are evaluated for simple object-like macros, and #line
+// sets the positions of the lines that follow it; #version,
+// #extension and #pragma are ignored.
+func Lex(src []byte) ([]Lexeme, error) {
+	l := &lexer{src: string(src), line: 1, col: 1, defines: make(map[string][]Lexeme)}
+	if err := l.run(); err != nil {
+		return nil, err
+	}
+	return l.out, nil
+}
+
+func (l *lexer) pos() Pos { return Pos{l.line, l.col, l.source} }
+
+func (l *lexer) advance(n int) {
+	for i := 0; i < n; i++ {
+		if l.src[l.off] == '\n' {
+			l.line++
+			l.col = 1
+		} else {
+			l.col++
+		}
+		l.off++
+	}
+}
+
+func (l *lexer) skipping() bool {
+	for _, c := range l.cond {
+		if !c {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *lexer) emit(lx Lexeme) {
+	if l.skipping() {
+		return
+	}
+	if lx.Tok == Ident {
+		if body, ok := l.defines[lx.Text]; ok {
+			for _, b := range body {
+				b.Pos = lx.Pos
+				l.out = append(l.out, b)
+			}
+			return
+		}
+	}
+	l.out = append(l.out, lx)
+}
+
+func (l *lexer) run() error {
+	atLineStart := true
+	for l.off < len(l.src) {
+		c := l.src[l.off]
+		switch {
+		case c == '\n':
+			atLineStart = true
+			l.advance(1)
+			continue
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			l.advance(1)
+			continue
+		case strings.HasPrefix(l.src[l.off:], "//"):
+			for l.off < len(l.src) && l.src[l.off] != '\n' {
+				l.advance(1)
+			}
+			continue
+		case strings.HasPrefix(l.src[l.off:], "/*"):
+			start := l.pos()
+			end := strings.Index(l.src[l.off+2:], "*/")
+			if end < 0 {
+				return errorf(start, "unterminated comment")
+			}
+			l.advance(end + 4)
+			continue
+		case c == '#' && atLineStart:
+			if err := l.directive(); err != nil {
+				return err
+			}
+			continue
+		}
+		atLineStart = false
+		start := l.pos()
+		switch {
+		case c == '_' || unicode.IsLetter(rune(c)):
+			n := l.off
+			for n < len(l.src) && (l.src[n] == '_' || unicode.IsLetter(rune(l.src[n])) || unicode.IsDigit(rune(l.src[n]))) {
+				n++
+			}
+			text := l.src[l.off:n]
+			l.advance(n - l.off)
+			l.emit(Lexeme{Ident, text, start})
+		case isDigit(c) || (c == '.' && l.off+1 < len(l.src) && isDigit(l.src[l.off+1])):
+			lx, err := l.number()
+			if err != nil {
+				return err
+			}
+			l.emit(lx)
+		default:
+			matched := false
+			for _, p := range puncts {
+				if strings.HasPrefix(l.src[l.off:], p) {
+					l.advance(len(p))
+					l.emit(Lexeme{Punct, p, start})
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return errorf(start, "unexpected character %q", c)
+			}
+		}
+	}
+	if len(l.cond) > 0 {
+		return errorf(l.pos(), "missing #endif")
+	}
+	l.out = append(l.out, Lexeme{EOF, "", l.pos()})
+	return nil
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+func (l *lexer) number() (Lexeme, error) {
+	start := l.pos()
+	n := l.off
+	float := false
+	if strings.HasPrefix(l.src[n:], "0x") || strings.HasPrefix(l.src[n:], "0X") {
+		n += 2
+		for n < len(l.src) && strings.IndexByte("0123456789abcdefABCDEF", l.src[n]) >= 0 {
+			n++
+		}
+	} else {
+		for n < len(l.src) && isDigit(l.src[n]) {
+			n++
+		}
+		if n < len(l.src) && l.src[n] == '.' {
+			float = true
+			n++
+			for n < len(l.src) && isDigit(l.src[n]) {
+				n++
+			}
+		}
+		if n < len(l.src) && (l.src[n] == 'e' || l.src[n] == 'E') {
+			float = true
+			n++
+			if n < len(l.src) && (l.src[n] == '+' || l.src[n] == '-') {
+				n++
+			}
+			for n < len(l.src) && isDigit(l.src[n]) {
+				n++
+			}
+		}
+	}
+	text := l.src[l.off:n]
+	if n < len(l.src) && (l.src[n] == 'f' || l.src[n] == 'F') {
+		float = true
+		n++
+	} else if n < len(l.src) && (l.src[n] == 'u' || l.src[n] == 'U') {
+		n++
+	}
+	l.advance(n - l.off)
+	if float {
+		if _, err := strconv.ParseFloat(text, 32); err != nil {
+			return Lexeme{}, errorf(start, "bad floating point literal %s", text)
+		}
+		return Lexeme{FloatLit, text, start}, nil
+	}
+	if _, err := strconv.ParseInt(text, 0, 64); err != nil {
+		return Lexeme{}, errorf(start, "bad integer literal %s", text)
+	}
+	return Lexeme{IntLit, text, start}, nil
+}
+
+// directive handles a preprocessor line.
+func (l *lexer) directive() error {
+	start := l.pos()
+	end := strings.IndexByte(l.src[l.off:], '\n')
+	if end < 0 {
+		end = len(l.src) - l.off
+	}
+	line := l.src[l.off+1 : l.off+end]
+	l.advance(end)
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+	switch fields[0] {
+	case "version", "extension", "pragma":
+	case "line":
+		if l.skipping() {
+			return nil
+		}
+		if len(fields) < 2 || len(fields) > 3 {
+			return errorf(start, "#line wants a line number and an optional source number")
+		}
+		var n [2]int
+		for i, f := range fields[1:] {
+			v, err := strconv.Atoi(f)
+			if err != nil || v < 0 {
+				return errorf(start, "bad number %q in #line", f)
+			}
+			n[i] = v
+		}
+		// The newline ending the directive starts line n[0].
+		l.line = n[0] - 1
+		if len(fields) == 3 {
+			l.source = n[1]
+		}
+	case "define":
+		if l.skipping() {
+			return nil
+		}
+		if len(fields) < 2 {
+			return errorf(start, "#define without a name")
+		}
+		name := fields[1]
+		if strings.Contains(name, "(") {
+			return errorf(start, "function-like macros are not supported")
+		}
+		body := strings.TrimSpace(line[strings.Index(line, name)+len(name):])
+		sub := &lexer{src: body, line: start.Line, col: 1, source: start.Source, defines: l.defines}
+		if err := sub.run(); err != nil {
+			return err
+		}
+		l.defines[name] = sub.out[:len(sub.out)-1]
+	case "undef":
+		if !l.skipping() && len(fields) > 1 {
+			delete(l.defines, fields[1])
+		}
+	case "ifdef", "ifndef":
+		if len(fields) < 2 {
+			return errorf(start, "#%s without a name", fields[0])
+		}
+		_, ok := l.defines[fields[1]]
+		l.cond = append(l.cond, ok == (fields[0] == "ifdef"))
+	case "else":
+		if len(l.cond) == 0 {
+			return errorf(start, "#else without #if")
+		}
+		l.cond[len(l.cond)-1] = !l.cond[len(l.cond)-1]
+	case "endif":
+		if len(l.cond) == 0 {
+			return errorf(start, "#endif without #if")
+		}
+		l.cond = l.cond[:len(l.cond)-1]
+	default:
+		if !l.skipping() {
+			return errorf(start, "unsupported preprocessor directive #%s", fields[0])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,524 @@
+package glsl
+
+import (
+	"strconv"
+)
+
+var builtinTypes = map[string]bool{
+	"void": true, "bool": true, "int": true, "uint": true, "float": true,
+	"vec2": true, "vec3": true, "vec4": true,
+	"ivec2": true, "ivec3": true, "ivec4": true,
+	"uvec2": true, "uvec3": true, "uvec4": true,
+	"bvec2": true, "bvec3": true, "bvec4": true,
+	"mat2": true, "mat3": true, "mat4": true,
+	"mat2x2": true, "mat3x3": true, "mat4x4": true,
+	"sampler1D": true, "sampler2D": true, "sampler3D": true, "samplerCube": true,
+}
+
+// IsType reports whether name is a built-in GLSL type.
+func IsType(name string) bool { return builtinTypes[name] }
+
+type parser struct {
+	toks []Lexeme
+	n    int
+}
+
+// Parse parses a shader.
+func Parse(src []byte) (*File, error) {
+	toks, err := Lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	return p.file()
+}
+
+// errors are propagated by panicking with an *Error, and recovered
+// at the top of the parser.
+func (p *parser) fail(pos Pos, format string, args ...interface{}) {
+	panic(errorf(pos, format, args...))
+}
+
+func (p *parser) peek() Lexeme { return p.toks[p.n] }
+func (p *parser) peekAt(i int) Lexeme {
+	if p.n+i < len(p.toks) {
+		return p.toks[p.n+i]
+	}
+	return p.toks[len(p.toks)-1]
+}
+
+func (p *parser) next() Lexeme {
+	t := p.toks[p.n]
+	if t.Tok != EOF {
+		p.n++
+	}
+	return t
+}
+
+func (p *parser) is(text string) bool {
+	t := p.peek()
+	return (t.Tok == Punct || t.Tok == Ident) && t.Text == text
+}
+
+func (p *parser) accept(text string) bool {
+	if p.is(text) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) Lexeme {
+	t := p.peek()
+	if !p.is(text) {
+		p.fail(t.Pos, "syntax error, unexpected %s, expecting '%s'", describe(t), text)
+	}
+	return p.next()
+}
+
+func (p *parser) ident() Lexeme {
+	t := p.peek()
+	if t.Tok != Ident {
+		p.fail(t.Pos, "syntax error, unexpected %s, expecting identifier", describe(t))
+	}
+	return p.next()
+}
+
+func describe(t Lexeme) string {
+	if t.Tok == EOF {
+		return "end of file"
+	}
+	return "'" + t.Text + "'"
+}
+
+func (p *parser) file() (f *File, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*Error)
+			if !ok {
+				panic(r)
+			}
+			err = e
+		}
+	}()
+	f = new(File)
+	for p.peek().Tok != EOF {
+		if d := p.decl(); d != nil {
+			f.Decls = append(f.Decls, d)
+		}
+	}
+	return f, nil
+}
+
+var storageQuals = map[string]string{
+	"in": "in", "out": "out", "uniform": "uniform", "const": "const",
+	"attribute": "in", "varying": "out",
+}
+
+var interpQuals = map[string]bool{"smooth": true, "flat": true, "noperspective": true}
+
+func (p *parser) qualifiers() Qualifiers {
+	var q Qualifiers
+	for {
+		t := p.peek()
+		if t.Tok != Ident {
+			return q
+		}
+		switch {
+		case t.Text == "layout":
+			p.next()
+			p.expect("(")
+			if q.Layout == nil {
+				q.Layout = make(map[string]int)
+			}
+			for !p.is(")") {
+				name := p.ident().Text
+				v := -1
+				if p.accept("=") {
+					v = p.intConst()
+				}
+				q.Layout[name] = v
+				if !p.accept(",") {
+					break
+				}
+			}
+			p.expect(")")
+		case storageQuals[t.Text] != "":
+			p.next()
+			q.Storage = storageQuals[t.Text]
+		case interpQuals[t.Text]:
+			p.next()
+			q.Interp = t.Text
+		case t.Text == "centroid", t.Text == "invariant",
+			t.Text == "highp", t.Text == "mediump", t.Text == "lowp":
+			p.next()
+		default:
+			return q
+		}
+	}
+}
+
+func (p *parser) intConst() int {
+	t := p.peek()
+	if t.Tok != IntLit {
+		p.fail(t.Pos, "expected integer constant, found %s", describe(t))
+	}
+	p.next()
+	n, _ := strconv.ParseInt(t.Text, 0, 64)
+	return int(n)
+}
+
+func (p *parser) typ() Type {
+	t := p.ident()
+	if !builtinTypes[t.Text] {
+		p.fail(t.Pos, "unknown type '%s'", t.Text)
+	}
+	typ := Type{Name: t.Text}
+	if p.accept("[") {
+		typ.ArrayLen = p.intConst()
+		p.expect("]")
+	}
+	return typ
+}
+
+func (p *parser) decl() Decl {
+	if p.accept(";") {
+		return nil
+	}
+	if p.accept("precision") {
+		for !p.accept(";") {
+			p.next()
+		}
+		return nil
+	}
+	pos := p.peek().Pos
+	q := p.qualifiers()
+	t := p.peek()
+	if t.Tok == Ident && !builtinTypes[t.Text] && p.peekAt(1).Text == "{" {
+		return p.block(pos, q)
+	}
+	if q.Storage != "" && p.is(";") {
+		// a lone layout qualifier, such as layout(std140) uniform;
+		p.next()
+		return nil
+	}
+	typ := p.typ()
+	name := p.ident()
+	if p.is("(") {
+		if q.Storage != "" || q.Interp != "" {
+			p.fail(pos, "qualifiers are not allowed on functions")
+		}
+		return p.function(pos, typ, name)
+	}
+	return p.varDecl(pos, q, typ, name)
+}
+
+func (p *parser) block(pos Pos, q Qualifiers) *BlockDecl {
+	b := &BlockDecl{Pos: pos, Qual: q, Name: p.ident().Text}
+	p.expect("{")
+	for !p.accept("}") {
+		mpos := p.peek().Pos
+		mq := p.qualifiers()
+		typ := p.typ()
+		name := p.ident()
+		b.Members = append(b.Members, p.varDecl(mpos, mq, typ, name))
+	}
+	if !p.is(";") {
+		b.Instance = p.ident().Text
+	}
+	p.expect(";")
+	return b
+}
+
+// varDecl parses the rest of a declaration after its first name.
+func (p *parser) varDecl(pos Pos, q Qualifiers, typ Type, name Lexeme) *VarDecl {
+	d := &VarDecl{Pos: pos, Qual: q, Type: typ}
+	for {
+		v := &Var{Pos: name.Pos, Name: name.Text, ArrayLen: typ.ArrayLen}
+		if p.accept("[") {
+			v.ArrayLen = p.intConst()
+			p.expect("]")
+		}
+		if p.accept("=") {
+			v.Init = p.assign()
+		}
+		d.Vars = append(d.Vars, v)
+		if !p.accept(",") {
+			break
+		}
+		name = p.ident()
+	}
+	p.expect(";")
+	return d
+}
+
+func (p *parser) function(pos Pos, ret Type, name Lexeme) *FuncDecl {
+	f := &FuncDecl{Pos: pos, Ret: ret, Name: name.Text}
+	p.expect("(")
+	if p.is("void") && p.peekAt(1).Text == ")" {
+		p.next()
+	}
+	for !p.is(")") {
+		prm := &Param{Pos: p.peek().Pos}
+		for {
+			if p.is("in") || p.is("out") || p.is("inout") {
+				prm.Qual = p.next().Text
+			} else if p.is("const") || p.is("highp") || p.is("mediump") || p.is("lowp") {
+				p.next()
+			} else {
+				break
+			}
+		}
+		prm.Type = p.typ()
+		if p.peek().Tok == Ident {
+			prm.Name = p.next().Text
+		}
+		if p.accept("[") {
+			prm.Type.ArrayLen = p.intConst()
+			p.expect("]")
+		}
+		f.Params = append(f.Params, prm)
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect(")")
+	if p.accept(";") {
+		return f
+	}
+	f.Body = p.blockStmt()
+	return f
+}
+
+func (p *parser) blockStmt() *BlockStmt {
+	b := &BlockStmt{Pos: p.expect("{").Pos}
+	for !p.accept("}") {
+		if p.peek().Tok == EOF {
+			p.fail(p.peek().Pos, "syntax error, unexpected end of file, expecting '}'")
+		}
+		if s := p.stmt(); s != nil {
+			b.Stmts = append(b.Stmts, s)
+		}
+	}
+	return b
+}
+
+// isDecl reports whether the upcoming tokens begin a declaration.
+func (p *parser) isDecl() bool {
+	t := p.peek()
+	if t.Tok != Ident {
+		return false
+	}
+	if storageQuals[t.Text] != "" || interpQuals[t.Text] ||
+		t.Text == "highp" || t.Text == "mediump" || t.Text == "lowp" {
+		return true
+	}
+	if !builtinTypes[t.Text] {
+		return false
+	}
+	next := p.peekAt(1)
+	return next.Tok == Ident || next.Text == "["
+}
+
+func (p *parser) stmt() Stmt {
+	t := p.peek()
+	switch {
+	case p.accept(";"):
+		return nil
+	case p.is("{"):
+		return p.blockStmt()
+	case p.accept("if"):
+		s := &IfStmt{Pos: t.Pos}
+		p.expect("(")
+		s.Cond = p.expr()
+		p.expect(")")
+		s.Then = p.stmt()
+		if p.accept("else") {
+			s.Else = p.stmt()
+		}
+		return s
+	case p.accept("for"):
+		s := &ForStmt{Pos: t.Pos}
+		p.expect("(")
+		if !p.accept(";") {
+			if p.isDecl() {
+				s.Init = p.declStmt()
+			} else {
+				s.Init = &ExprStmt{p.expr()}
+				p.expect(";")
+			}
+		}
+		if !p.is(";") {
+			s.Cond = p.expr()
+		}
+		p.expect(";")
+		if !p.is(")") {
+			s.Post = p.expr()
+		}
+		p.expect(")")
+		s.Body = p.stmt()
+		return s
+	case p.accept("while"):
+		s := &ForStmt{Pos: t.Pos}
+		p.expect("(")
+		s.Cond = p.expr()
+		p.expect(")")
+		s.Body = p.stmt()
+		return s
+	case p.accept("return"):
+		s := &ReturnStmt{Pos: t.Pos}
+		if !p.is(";") {
+			s.Result = p.expr()
+		}
+		p.expect(";")
+		return s
+	case p.is("break"), p.is("continue"), p.is("discard"):
+		p.next()
+		p.expect(";")
+		return &BranchStmt{t.Pos, t.Text}
+	case p.isDecl():
+		return p.declStmt()
+	}
+	x := p.expr()
+	p.expect(";")
+	return &ExprStmt{x}
+}
+
+func (p *parser) declStmt() Stmt {
+	pos := p.peek().Pos
+	q := p.qualifiers()
+	typ := p.typ()
+	name := p.ident()
+	return &DeclStmt{p.varDecl(pos, q, typ, name)}
+}
+
+func (p *parser) expr() Expr {
+	x := p.assign()
+	for p.is(",") {
+		t := p.next()
+		x = &BinaryExpr{t.Pos, ",", x, p.assign()}
+	}
+	return x
+}
+
+var assignOps = map[string]bool{
+	"=": true, "+=": true, "-=": true, "*=": true, "/=": true, "%=": true,
+	"<<=": true, ">>=": true, "&=": true, "|=": true, "^=": true,
+}
+
+func (p *parser) assign() Expr {
+	x := p.cond()
+	t := p.peek()
+	if t.Tok == Punct && assignOps[t.Text] {
+		p.next()
+		return &BinaryExpr{t.Pos, t.Text, x, p.assign()}
+	}
+	return x
+}
+
+func (p *parser) cond() Expr {
+	x := p.binary(0)
+	if p.is("?") {
+		t := p.next()
+		a := p.assign()
+		p.expect(":")
+		b := p.assign()
+		return &CondExpr{t.Pos, x, a, b}
+	}
+	return x
+}
+
+var binaryPrec = []map[string]bool{
+	{"||": true},
+	{"^^": true},
+	{"&&": true},
+	{"|": true},
+	{"^": true},
+	{"&": true},
+	{"==": true, "!=": true},
+	{"<": true, ">": true, "<=": true, ">=": true},
+	{"<<": true, ">>": true},
+	{"+": true, "-": true},
+	{"*": true, "/": true, "%": true},
+}
+
+func (p *parser) binary(prec int) Expr {
+	if prec == len(binaryPrec) {
+		return p.unary()
+	}
+	x := p.binary(prec + 1)
+	for {
+		t := p.peek()
+		if t.Tok != Punct || !binaryPrec[prec][t.Text] {
+			return x
+		}
+		p.next()
+		x = &BinaryExpr{t.Pos, t.Text, x, p.binary(prec + 1)}
+	}
+}
+
+func (p *parser) unary() Expr {
+	t := p.peek()
+	if t.Tok == Punct {
+		switch t.Text {
+		case "-", "+", "!", "~", "++", "--":
+			p.next()
+			return &UnaryExpr{t.Pos, t.Text, p.unary()}
+		}
+	}
+	return p.postfix()
+}
+
+func (p *parser) postfix() Expr {
+	x := p.primary()
+	for {
+		t := p.peek()
+		switch {
+		case p.accept("."):
+			x = &FieldExpr{t.Pos, x, p.ident().Text}
+		case p.accept("["):
+			x = &IndexExpr{t.Pos, x, p.expr()}
+			p.expect("]")
+		case p.is("++"), p.is("--"):
+			p.next()
+			x = &PostfixExpr{t.Pos, t.Text, x}
+		default:
+			return x
+		}
+	}
+}
+
+func (p *parser) primary() Expr {
+	t := p.next()
+	switch t.Tok {
+	case IntLit, FloatLit:
+		return &LitExpr{t.Pos, t.Tok, t.Text}
+	case Ident:
+		if t.Text == "true" || t.Text == "false" {
+			return &LitExpr{t.Pos, Ident, t.Text}
+		}
+		if p.accept("(") {
+			c := &CallExpr{Pos: t.Pos, Func: t.Text}
+			if p.is("void") && p.peekAt(1).Text == ")" {
+				p.next()
+			}
+			for !p.is(")") {
+				c.Args = append(c.Args, p.assign())
+				if !p.accept(",") {
+					break
+				}
+			}
+			p.expect(")")
+			return c
+		}
+		return &IdentExpr{t.Pos, t.Text}
+	case Punct:
+		if t.Text == "(" {
+			x := p.expr()
+			p.expect(")")
+			return x
+		}
+	}
+	p.fail(t.Pos, "syntax error, unexpected %s", describe(t))
+	return nil
+}
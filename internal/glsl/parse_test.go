@@ -0,0 +1,453 @@
+package glsl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// lexemes returns the text of the tokens of src, separated by
+// spaces, with the position of each token after an @ if pos is set.
+func lexemes(src string, pos bool) (string, error) {
+	toks, err := Lex([]byte(src))
+	if err != nil {
+		return "", err
+	}
+	var s []string
+	for _, t := range toks[:len(toks)-1] {
+		text := t.Text
+		if pos {
+			text += fmt.Sprintf("@%d:%d:%d", t.Pos.Source, t.Pos.Line, t.Pos.Col)
+		}
+		s = append(s, text)
+	}
+	return strings.Join(s, " "), nil
+}
+
+func TestLex(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+	}{
+		{"operators", "a>>=b<<c++-->=!=^^", "a >>= b << c ++ -- >= != ^^"},
+		{"numbers", "1 0x1F 1.5 .5 2. 1e3 1.5e-2 2.0f 3u", "1 0x1F 1.5 .5 2. 1e3 1.5e-2 2.0 3"},
+		{"comments", "a // b\nc /* d\ne */ f", "a c f"},
+		{"directives", "#version 150\n#extension GL_foo : enable\n#pragma x\n  #  pragma y\na", "a"},
+		{"define", "#define N 3\n#define V vec2(N, N)\nV", "vec2 ( 3 , 3 )"},
+		{"undef", "#define N 3\n#undef N\nN", "N"},
+		{"ifdef", "#define A\n#ifdef A\na\n#else\nb\n#endif\n#ifndef A\nc\n#else\nd\n#endif", "a d"},
+		{"nested skipping", "#ifdef A\n#ifdef B\nb\n#endif\n#define C 1\n#bogus\n#else\nC\n#endif", "C"},
+		{"hash inside a line", "a # b", ""},
+	}
+	for _, tt := range tests {
+		got, err := lexemes(tt.src, false)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("%s: no error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		} else if got != tt.want {
+			t.Errorf("%s: lexed %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLexPositions(t *testing.T) {
+	src := "a\n  b\n#line 10 2\nc /* x\n*/ d\n#define M e f\nM"
+	want := "a@0:1:1 b@0:2:3 c@2:10:1 d@2:11:4 e@2:13:1 f@2:13:1"
+	got, err := lexemes(src, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("positions %s, want %s", got, want)
+	}
+}
+
+func TestLexErrors(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		{"a\n  /* x", "0:2(3): error: unterminated comment"},
+		{"a @", "0:1(3): error: unexpected character '@'"},
+		{"#ifdef A\n", "0:2(1): error: missing #endif"},
+		{"0x", "0:1(1): error: bad integer literal 0x"},
+		{"1e", "0:1(1): error: bad floating point literal 1e"},
+		{"#line\n", "0:1(1): error: #line wants a line number and an optional source number"},
+		{"#line 1 2 3\n", "0:1(1): error: #line wants a line number and an optional source number"},
+		{"#line x\n", `0:1(1): error: bad number "x" in #line`},
+		{"#line 5 -1\n", `0:1(1): error: bad number "-1" in #line`},
+		{"#define\n", "0:1(1): error: #define without a name"},
+		{"#define F(x) x\n", "0:1(1): error: function-like macros are not supported"},
+		{"#ifdef\n", "0:1(1): error: #ifdef without a name"},
+		{"#else\n", "0:1(1): error: #else without #if"},
+		{"#endif\n", "0:1(1): error: #endif without #if"},
+		{"#if 1\n", "0:1(1): error: unsupported preprocessor directive #if"},
+		{"#line 7 3\n@", "3:7(1): error: unexpected character '@'"},
+	}
+	for _, tt := range tests {
+		_, err := Lex([]byte(tt.src))
+		if err == nil {
+			t.Errorf("%q: no error", tt.src)
+		} else if err.Error() != tt.want {
+			t.Errorf("%q: error %q, want %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+// decl describes a top-level declaration on one line.
+func decl(d Decl) string {
+	switch d := d.(type) {
+	case *VarDecl:
+		return varDecl(d)
+	case *BlockDecl:
+		var members []string
+		for _, m := range d.Members {
+			members = append(members, varDecl(m))
+		}
+		s := quals(d.Qual) + "block " + d.Name + " { " + strings.Join(members, "; ") + " }"
+		if d.Instance != "" {
+			s += " " + d.Instance
+		}
+		return s
+	case *FuncDecl:
+		var params []string
+		for _, p := range d.Params {
+			s := typ(p.Type)
+			if p.Qual != "" {
+				s = p.Qual + " " + s
+			}
+			if p.Name != "" {
+				s += " " + p.Name
+			}
+			params = append(params, s)
+		}
+		s := fmt.Sprintf("func %s %s(%s)", typ(d.Ret), d.Name, strings.Join(params, ", "))
+		if d.Body != nil {
+			s += fmt.Sprintf(" { %d statements }", len(d.Body.Stmts))
+		}
+		return s
+	}
+	return fmt.Sprintf("%T", d)
+}
+
+func quals(q Qualifiers) string {
+	var s []string
+	if len(q.Layout) > 0 {
+		var l []string
+		for k, v := range q.Layout {
+			if v < 0 {
+				l = append(l, k)
+			} else {
+				l = append(l, fmt.Sprintf("%s=%d", k, v))
+			}
+		}
+		sort.Strings(l)
+		s = append(s, "layout("+strings.Join(l, ",")+")")
+	}
+	for _, q := range []string{q.Interp, q.Storage} {
+		if q != "" {
+			s = append(s, q)
+		}
+	}
+	if len(s) == 0 {
+		return ""
+	}
+	return strings.Join(s, " ") + " "
+}
+
+func typ(t Type) string {
+	if t.ArrayLen > 0 {
+		return fmt.Sprintf("%s[%d]", t.Name, t.ArrayLen)
+	}
+	return t.Name
+}
+
+func varDecl(d *VarDecl) string {
+	var vars []string
+	for _, v := range d.Vars {
+		s := v.Name
+		if v.ArrayLen > 0 {
+			s += fmt.Sprintf("[%d]", v.ArrayLen)
+		}
+		if v.Init != nil {
+			s += " = " + expr(v.Init)
+		}
+		vars = append(vars, s)
+	}
+	return quals(d.Qual) + typ(d.Type) + " " + strings.Join(vars, ", ")
+}
+
+// expr writes e with every operation in parentheses, to show how
+// it was grouped.
+func expr(e Expr) string {
+	switch e := e.(type) {
+	case *IdentExpr:
+		return e.Name
+	case *LitExpr:
+		return e.Value
+	case *UnaryExpr:
+		return "(" + e.Op + expr(e.X) + ")"
+	case *PostfixExpr:
+		return "(" + expr(e.X) + e.Op + ")"
+	case *BinaryExpr:
+		return "(" + expr(e.X) + " " + e.Op + " " + expr(e.Y) + ")"
+	case *CondExpr:
+		return "(" + expr(e.Cond) + " ? " + expr(e.X) + " : " + expr(e.Y) + ")"
+	case *CallExpr:
+		var args []string
+		for _, a := range e.Args {
+			args = append(args, expr(a))
+		}
+		return e.Func + "(" + strings.Join(args, ", ") + ")"
+	case *FieldExpr:
+		return expr(e.X) + "." + e.Name
+	case *IndexExpr:
+		return expr(e.X) + "[" + expr(e.Index) + "]"
+	}
+	return fmt.Sprintf("%T", e)
+}
+
+// stmt writes s on one line, with its expressions grouped as by
+// expr.
+func stmt(s Stmt) string {
+	switch s := s.(type) {
+	case *BlockStmt:
+		var list []string
+		for _, s := range s.Stmts {
+			list = append(list, stmt(s))
+		}
+		return "{ " + strings.Join(list, " ") + " }"
+	case *DeclStmt:
+		return varDecl(s.Decl) + ";"
+	case *ExprStmt:
+		return expr(s.X) + ";"
+	case *IfStmt:
+		str := "if " + expr(s.Cond) + " " + stmt(s.Then)
+		if s.Else != nil {
+			str += " else " + stmt(s.Else)
+		}
+		return str
+	case *ForStmt:
+		var parts []string
+		for _, p := range []interface{}{s.Init, s.Cond, s.Post} {
+			switch p := p.(type) {
+			case Stmt:
+				parts = append(parts, strings.TrimSuffix(stmt(p), ";"))
+			case Expr:
+				parts = append(parts, expr(p))
+			default:
+				parts = append(parts, "")
+			}
+		}
+		return "for " + strings.Join(parts, "; ") + " " + stmt(s.Body)
+	case *ReturnStmt:
+		if s.Result == nil {
+			return "return;"
+		}
+		return "return " + expr(s.Result) + ";"
+	case *BranchStmt:
+		return s.Tok + ";"
+	}
+	return fmt.Sprintf("%T", s)
+}
+
+func TestParseDecls(t *testing.T) {
+	src := `#version 150
+precision highp float;
+layout(location = 0) in vec4 position;
+smooth out vec4 theColor;
+flat in int id;
+attribute vec3 normal;
+varying vec2 uv;
+centroid in vec2 st;
+uniform mat4 bones[3], world;
+uniform vec3[2] pair, other[4];
+uniform vec2 scale = vec2(0.5, 0.5);
+const float pi = 3.14159, tau = 2.0 * pi;
+layout(std140) uniform;
+layout(std140, binding = 2) uniform Camera {
+	mat4 cameraToClip;
+	highp vec3 eye;
+};
+uniform Light { vec4 color; } light;
+vec4 shade(in vec3 n, const out float x, inout vec2 y[2]);
+float f(void);
+void g(float) {}
+void main() { gl_Position = position; theColor = vec4(1); }
+;
+`
+	want := []string{
+		"layout(location=0) in vec4 position",
+		"smooth out vec4 theColor",
+		"flat in int id",
+		"in vec3 normal",
+		"out vec2 uv",
+		"in vec2 st",
+		"uniform mat4 bones[3], world",
+		"uniform vec3[2] pair[2], other[4]",
+		"uniform vec2 scale = vec2(0.5, 0.5)",
+		"const float pi = 3.14159, tau = (2.0 * pi)",
+		"layout(binding=2,std140) uniform block Camera { mat4 cameraToClip; vec3 eye }",
+		"uniform block Light { vec4 color } light",
+		"func vec4 shade(in vec3 n, out float x, inout vec2[2] y)",
+		"func float f()",
+		"func void g(float) { 0 statements }",
+		"func void main() { 2 statements }",
+	}
+	f, err := Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, d := range f.Decls {
+		got = append(got, decl(d))
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("parsed\n\t%s\nwant\n\t%s", strings.Join(got, "\n\t"), strings.Join(want, "\n\t"))
+	}
+}
+
+func TestParseExpr(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		{"a = b + c * d", "(a = (b + (c * d)))"},
+		{"a = b = c", "(a = (b = c))"},
+		{"a += b -= 1", "(a += (b -= 1))"},
+		{"a - b - c", "((a - b) - c)"},
+		{"a || b && c", "(a || (b && c))"},
+		{"a ^^ b || c", "((a ^^ b) || c)"},
+		{"a | b ^ c & d", "(a | (b ^ (c & d)))"},
+		{"a == b < c", "(a == (b < c))"},
+		{"a << 1 + 2", "(a << (1 + 2))"},
+		{"a % b / c", "((a % b) / c)"},
+		{"-a * !b", "((-a) * (!b))"},
+		{"- -a", "(-(-a))"},
+		{"++a.x", "(++a.x)"},
+		{"a[i]++", "(a[i]++)"},
+		{"(a + b) * c", "((a + b) * c)"},
+		{"a ? b : c ? d : e", "(a ? b : (c ? d : e))"},
+		{"a ? b = 1 : c", "(a ? (b = 1) : c)"},
+		{"x = a < b ? a : b", "(x = ((a < b) ? a : b))"},
+		{"a, b = c", "(a , (b = c))"},
+		{"m[1][2]", "m[1][2]"},
+		{"v.xyz.x", "v.xyz.x"},
+		{"f(a, b + 1).x", "f(a, (b + 1)).x"},
+		{"f(void)", "f()"},
+		{"vec4(1.5, true, false, 2u)", "vec4(1.5, true, false, 2)"},
+		{"light.color * texture(tex, uv)", "(light.color * texture(tex, uv))"},
+	}
+	for _, tt := range tests {
+		f, err := Parse([]byte("void main() { " + tt.src + "; }"))
+		if err != nil {
+			t.Errorf("%s: %v", tt.src, err)
+			continue
+		}
+		body := f.Decls[0].(*FuncDecl).Body.Stmts
+		if got := stmt(body[0]); len(body) != 1 || got != tt.want+";" {
+			t.Errorf("%s: parsed %s, want %s", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestParseStmts(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		{"vec3 a, b = vec3(1);", "vec3 a, b = vec3(1);"},
+		{"const float k[2] = x;", "const float k[2] = x;"},
+		{"float k[2];", "float k[2];"},
+		{"a;;b;", "a; b;"},
+		{"{ a; { b; } }", "{ a; { b; } }"},
+		{"if (a) b; else if (c) d; else e;", "if a b; else if c d; else e;"},
+		{"if (a) if (b) c; else d;", "if a if b c; else d;"},
+		{"for (int i = 0; i < 4; i++) a;", "for int i = 0; (i < 4); (i++) a;"},
+		{"for (i = 0; ; ) { break; }", "for (i = 0); ;  { break; }"},
+		{"for (;;) continue;", "for ; ;  continue;"},
+		{"while (a < b) a++;", "for ; (a < b);  (a++);"},
+		{"return;", "return;"},
+		{"return a + b;", "return (a + b);"},
+		{"discard;", "discard;"},
+	}
+	for _, tt := range tests {
+		f, err := Parse([]byte("void main() { " + tt.src + " }"))
+		if err != nil {
+			t.Errorf("%s: %v", tt.src, err)
+			continue
+		}
+		var got []string
+		for _, s := range f.Decls[0].(*FuncDecl).Body.Stmts {
+			got = append(got, stmt(s))
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("%s: parsed %s, want %s", tt.src, strings.Join(got, " "), tt.want)
+		}
+	}
+}
+
+func TestParsePositions(t *testing.T) {
+	src := "uniform vec2 offset;\n#line 20 1\nvoid main() {\n\tgl_Position = vec4(offset, 0, 1);\n}\n"
+	f, err := Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := f.Decls[0].(*VarDecl)
+	main := f.Decls[1].(*FuncDecl)
+	assign := main.Body.Stmts[0].(*ExprStmt).X.(*BinaryExpr)
+	call := assign.Y.(*CallExpr)
+	tests := []struct {
+		name      string
+		pos, want Pos
+	}{
+		{"uniform", u.Pos, Pos{1, 1, 0}},
+		{"offset", u.Vars[0].Pos, Pos{1, 14, 0}},
+		{"main", main.Pos, Pos{20, 1, 1}},
+		{"body", main.Body.Pos, Pos{20, 13, 1}},
+		{"=", assign.Pos, Pos{21, 14, 1}},
+		{"vec4", call.Pos, Pos{21, 16, 1}},
+		{"offset argument", call.Args[0].Position(), Pos{21, 21, 1}},
+	}
+	for _, tt := range tests {
+		if tt.pos != tt.want {
+			t.Errorf("%s is at %+v, want %+v", tt.name, tt.pos, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		{"uniform vec2 offset", "0:1(20): error: syntax error, unexpected end of file, expecting ';'"},
+		{"uniform foo x;", "0:1(9): error: unknown type 'foo'"},
+		{"uniform vec2 1;", "0:1(14): error: syntax error, unexpected '1', expecting identifier"},
+		{"uniform float x[n];", "0:1(17): error: expected integer constant, found 'n'"},
+		{"layout(location = x) in vec4 p;", "0:1(19): error: expected integer constant, found 'x'"},
+		{"uniform void f() {}", "0:1(1): error: qualifiers are not allowed on functions"},
+		{"uniform B { vec4 c; }", "0:1(22): error: syntax error, unexpected end of file, expecting identifier"},
+		{"void main() {", "0:1(14): error: syntax error, unexpected end of file, expecting '}'"},
+		{"void main() { a = ; }", "0:1(19): error: syntax error, unexpected ';'"},
+		{"void main() { a = (b; }", "0:1(21): error: syntax error, unexpected ';', expecting ')'"},
+		{"void main() { f(a b); }", "0:1(19): error: syntax error, unexpected 'b', expecting ')'"},
+		{"void main() { a ? b; }", "0:1(20): error: syntax error, unexpected ';', expecting ':'"},
+		{"void main() { if a; }", "0:1(18): error: syntax error, unexpected 'a', expecting '('"},
+		{"void main() { for (;;) }", "0:1(24): error: syntax error, unexpected '}'"},
+		{"void main() { break }", "0:1(21): error: syntax error, unexpected '}', expecting ';'"},
+		{"void main() { a[1; }", "0:1(18): error: syntax error, unexpected ';', expecting ']'"},
+		{"void main() { a.1; }", "0:1(16): error: syntax error, unexpected '.1', expecting ';'"},
+		{"void f(in foo x) {}", "0:1(11): error: unknown type 'foo'"},
+		{"#line 4 2\nvoid main() { x = ; }", "2:4(19): error: syntax error, unexpected ';'"},
+	}
+	for _, tt := range tests {
+		_, err := Parse([]byte(tt.src))
+		if err == nil {
+			t.Errorf("%q: no error", tt.src)
+		} else if err.Error() != tt.want {
+			t.Errorf("%q: error %q, want %q", tt.src, err, tt.want)
+		}
+	}
+}
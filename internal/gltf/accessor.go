@@ -0,0 +1,190 @@
+package gltf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Component types of accessors.
+const (
+	typeByte   = 5120
+	typeUbyte  = 5121
+	typeShort  = 5122
+	typeUshort = 5123
+	typeUint   = 5125
+	typeFloat  = 5126
+)
+
+var componentSizes = map[int]int{
+	typeByte:   1,
+	typeUbyte:  1,
+	typeShort:  2,
+	typeUshort: 2,
+	typeUint:   4,
+	typeFloat:  4,
+}
+
+// Matrix types are left out: their columns are padded to 4 bytes,
+// and no supported attribute uses them.
+var elementSizes = map[string]int{
+	"SCALAR": 1,
+	"VEC2":   2,
+	"VEC3":   3,
+	"VEC4":   4,
+}
+
+// floats reads accessor i as float32 values, n per element.
+// Normalized integers are mapped to [0, 1] or [-1, 1]; others keep
+// their value.
+func (d *decoder) floats(i int) (v []float32, n int, err error) {
+	raw, n, err := d.accessor(i)
+	if err != nil {
+		return nil, 0, err
+	}
+	a := d.doc.Accessors[i]
+	v = make([]float32, len(raw))
+	for j, x := range raw {
+		if a.Normalized {
+			switch a.ComponentType {
+			case typeByte:
+				x = math.Max(x/127, -1)
+			case typeUbyte:
+				x /= 255
+			case typeShort:
+				x = math.Max(x/32767, -1)
+			case typeUshort:
+				x /= 65535
+			}
+		}
+		v[j] = float32(x)
+	}
+	return v, n, nil
+}
+
+// indices reads accessor i as vertex indices.
+func (d *decoder) indices(i int) ([]uint32, error) {
+	raw, n, err := d.accessor(i)
+	if err != nil {
+		return nil, err
+	}
+	switch t := d.doc.Accessors[i].ComponentType; {
+	case n != 1:
+		return nil, fmt.Errorf("accessor %d: indices are not scalars", i)
+	case t != typeUbyte && t != typeUshort && t != typeUint:
+		return nil, fmt.Errorf("accessor %d: indices have component type %d", i, t)
+	}
+	idx := make([]uint32, len(raw))
+	for j, x := range raw {
+		idx[j] = uint32(x)
+	}
+	return idx, nil
+}
+
+// accessor reads the values of accessor i, n per element. The
+// elements of a sparse accessor are read from its buffer view, or
+// are zeros if it has none, and then the ones it lists are replaced.
+func (d *decoder) accessor(i int) (v []float64, n int, err error) {
+	if i < 0 || i >= len(d.doc.Accessors) {
+		return nil, 0, fmt.Errorf("no accessor %d", i)
+	}
+	a := d.doc.Accessors[i]
+	if _, ok := componentSizes[a.ComponentType]; !ok {
+		return nil, 0, fmt.Errorf("accessor %d: unknown component type %d", i, a.ComponentType)
+	}
+	n, ok := elementSizes[a.Type]
+	if !ok {
+		return nil, 0, fmt.Errorf("accessor %d: unsupported type %q", i, a.Type)
+	}
+	v = make([]float64, a.Count*n)
+	// An accessor without a view is all zeros.
+	if a.BufferView != nil {
+		if err := d.read(v, n, a.ComponentType, *a.BufferView, a.ByteOffset); err != nil {
+			return nil, 0, fmt.Errorf("accessor %d: %v", i, err)
+		}
+	}
+	if a.Sparse != nil {
+		if err := d.sparse(v, n, a.ComponentType, a.Sparse); err != nil {
+			return nil, 0, fmt.Errorf("accessor %d: sparse: %v", i, err)
+		}
+	}
+	return v, n, nil
+}
+
+// sparse replaces the elements of v that s lists. The elements have
+// n components of type typ.
+func (d *decoder) sparse(v []float64, n, typ int, s *sparseAccessor) error {
+	switch t := s.Indices.ComponentType; {
+	case s.Count < 1:
+		return fmt.Errorf("count is %d", s.Count)
+	case t != typeUbyte && t != typeUshort && t != typeUint:
+		return fmt.Errorf("indices have component type %d", t)
+	}
+	idx := make([]float64, s.Count)
+	if err := d.read(idx, 1, s.Indices.ComponentType, s.Indices.BufferView, s.Indices.ByteOffset); err != nil {
+		return fmt.Errorf("indices: %v", err)
+	}
+	values := make([]float64, s.Count*n)
+	if err := d.read(values, n, typ, s.Values.BufferView, s.Values.ByteOffset); err != nil {
+		return fmt.Errorf("values: %v", err)
+	}
+	for j, x := range idx {
+		e := int(x)
+		if e >= len(v)/n {
+			return fmt.Errorf("index %d is %d, but there are only %d elements", j, e, len(v)/n)
+		}
+		copy(v[e*n:e*n+n], values[j*n:j*n+n])
+	}
+	return nil
+}
+
+// read fills v with elements of n components of type typ, starting
+// offset bytes into buffer view i.
+func (d *decoder) read(v []float64, n, typ, i, offset int) error {
+	if i < 0 || i >= len(d.doc.BufferViews) {
+		return fmt.Errorf("no buffer view %d", i)
+	}
+	view := d.doc.BufferViews[i]
+	if view.Buffer < 0 || view.Buffer >= len(d.buffers) {
+		return fmt.Errorf("no buffer %d", view.Buffer)
+	}
+	buf := d.buffers[view.Buffer]
+	if view.ByteOffset < 0 || view.ByteLength < 0 || view.ByteOffset+view.ByteLength > len(buf) {
+		return fmt.Errorf("buffer view %d is outside its buffer", i)
+	}
+	buf = buf[view.ByteOffset : view.ByteOffset+view.ByteLength]
+	size := componentSizes[typ]
+	stride := view.ByteStride
+	if stride == 0 {
+		stride = n * size
+	}
+	count := len(v) / n
+	if count > 0 {
+		if end := offset + (count-1)*stride + n*size; offset < 0 || end > len(buf) {
+			return fmt.Errorf("%d elements do not fit in buffer view %d", count, i)
+		}
+	}
+	le := binary.LittleEndian
+	for e := 0; e < count; e++ {
+		for c := 0; c < n; c++ {
+			b := buf[offset+e*stride+c*size:]
+			var x float64
+			switch typ {
+			case typeByte:
+				x = float64(int8(b[0]))
+			case typeUbyte:
+				x = float64(b[0])
+			case typeShort:
+				x = float64(int16(le.Uint16(b)))
+			case typeUshort:
+				x = float64(le.Uint16(b))
+			case typeUint:
+				x = float64(le.Uint32(b))
+			case typeFloat:
+				x = float64(math.Float32frombits(le.Uint32(b)))
+			}
+			v[e*n+c] = x
+		}
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package gltf
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+// An Animation moves nodes of a model over time.
+type Animation struct {
+	Name     string
+	Channels []Channel
+}
+
+// A Path is the property of a node that a channel animates.
+type Path int
+
+const (
+	Translation Path = iota
+	Rotation
+	Scale
+)
+
+var paths = map[string]Path{
+	"translation": Translation,
+	"rotation":    Rotation,
+	"scale":       Scale,
+}
+
+// An Interpolation says how a channel moves between key frames.
+type Interpolation int
+
+const (
+	Linear      Interpolation = iota // rotations are slerped
+	Step                             // hold each key until the next
+	CubicSpline                      // Hermite spline with tangents
+)
+
+var interpolations = map[string]Interpolation{
+	"":            Linear,
+	"LINEAR":      Linear,
+	"STEP":        Step,
+	"CUBICSPLINE": CubicSpline,
+}
+
+// A Channel animates one property of one node. Times holds the time
+// of each key frame in seconds, in increasing order. Values holds 3
+// numbers per key for a translation or scale, and a quaternion
+// (x, y, z, w) for a rotation. For cubic splines, each key has an
+// in-tangent, a value and an out-tangent, in that order.
+type Channel struct {
+	Node          int
+	Path          Path
+	Interpolation Interpolation
+	Times         []float32
+	Values        []float32
+}
+
+// Duration returns the time of the last key frame.
+func (a *Animation) Duration() time.Duration {
+	var end float32
+	for _, c := range a.Channels {
+		if n := len(c.Times); n > 0 && c.Times[n-1] > end {
+			end = c.Times[n-1]
+		}
+	}
+	return time.Duration(float64(end) * float64(time.Second))
+}
+
+// Apply sets the nodes of m to their state at time t. Before the
+// first key frame of a channel and after its last, the node holds
+// still. Callers that want the animation to loop should pass t
+// modulo the animation's duration.
+func (a *Animation) Apply(m *Model, t time.Duration) {
+	secs := float32(t.Seconds())
+	for i := range a.Channels {
+		c := &a.Channels[i]
+		n := &m.Nodes[c.Node]
+		v := c.sample(secs)
+		switch c.Path {
+		case Translation:
+			copy(n.Translation[:], v)
+		case Scale:
+			copy(n.Scale[:], v)
+		case Rotation:
+			n.Rotation = quat(v).Normalize()
+		}
+	}
+}
+
+func (c *Channel) width() int {
+	if c.Path == Rotation {
+		return 4
+	}
+	return 3
+}
+
+// key returns the value of key frame k. For cubic splines, part 0
+// is the in-tangent, 1 the value and 2 the out-tangent; otherwise,
+// part must be 1.
+func (c *Channel) key(k, part int) []float32 {
+	n := c.width()
+	if c.Interpolation == CubicSpline {
+		k = 3*k + part
+	}
+	return c.Values[k*n : k*n+n]
+}
+
+func (c *Channel) sample(t float32) []float32 {
+	last := len(c.Times) - 1
+	switch {
+	case t <= c.Times[0]:
+		return c.key(0, 1)
+	case t >= c.Times[last]:
+		return c.key(last, 1)
+	}
+	// The key frame at or before t.
+	k := sort.Search(len(c.Times), func(i int) bool { return c.Times[i] > t }) - 1
+	dt := c.Times[k+1] - c.Times[k]
+	u := (t - c.Times[k]) / dt
+	out := make([]float32, c.width())
+	switch {
+	case c.Interpolation == Step:
+		copy(out, c.key(k, 1))
+	case c.Interpolation == CubicSpline:
+		u2, u3 := u*u, u*u*u
+		p0, m0 := c.key(k, 1), c.key(k, 2)
+		p1, m1 := c.key(k+1, 1), c.key(k+1, 0)
+		for i := range out {
+			out[i] = (2*u3-3*u2+1)*p0[i] + (u3-2*u2+u)*dt*m0[i] +
+				(-2*u3+3*u2)*p1[i] + (u3-u2)*dt*m1[i]
+		}
+	case c.Path == Rotation:
+		q := quat(c.key(k, 1)).Slerp(quat(c.key(k+1, 1)), u)
+		copy(out, []float32{q.X, q.Y, q.Z, q.W})
+	default:
+		a, b := c.key(k, 1), c.key(k+1, 1)
+		for i := range out {
+			out[i] = vmath.Lerp(a[i], b[i], u)
+		}
+	}
+	return out
+}
+
+// quat converts a glTF quaternion, which is stored as (x, y, z, w).
+func quat(v []float32) vmath.Quat {
+	return vmath.Quat{W: v[3], X: v[0], Y: v[1], Z: v[2]}
+}
+
+// animation reads animation i. Channels that animate morph target
+// weights, or no node, are left out.
+func (d *decoder) animation(i int, m *Model) (Animation, error) {
+	doc := d.doc.Animations[i]
+	a := Animation{Name: doc.Name}
+	for j, ch := range doc.Channels {
+		path, ok := paths[ch.Target.Path]
+		if ch.Target.Path == "weights" || ch.Target.Node == nil {
+			continue
+		}
+		if !ok {
+			return a, fmt.Errorf("channel %d: unknown path %q", j, ch.Target.Path)
+		}
+		c := Channel{Node: *ch.Target.Node, Path: path}
+		if c.Node < 0 || c.Node >= len(m.Nodes) {
+			return a, fmt.Errorf("channel %d: no node %d", j, c.Node)
+		}
+		if m.Nodes[c.Node].Matrix != nil {
+			return a, fmt.Errorf("channel %d: node %d has a matrix, and cannot be animated", j, c.Node)
+		}
+		if ch.Sampler < 0 || ch.Sampler >= len(doc.Samplers) {
+			return a, fmt.Errorf("channel %d: no sampler %d", j, ch.Sampler)
+		}
+		s := doc.Samplers[ch.Sampler]
+		if c.Interpolation, ok = interpolations[s.Interpolation]; !ok {
+			return a, fmt.Errorf("sampler %d: unknown interpolation %q", ch.Sampler, s.Interpolation)
+		}
+		if err := d.keyFrames(&c, s.Input, s.Output); err != nil {
+			return a, fmt.Errorf("sampler %d: %v", ch.Sampler, err)
+		}
+		a.Channels = append(a.Channels, c)
+	}
+	return a, nil
+}
+
+func (d *decoder) keyFrames(c *Channel, input, output int) error {
+	times, n, err := d.floats(input)
+	if err != nil {
+		return err
+	}
+	if n != 1 || len(times) == 0 {
+		return fmt.Errorf("input must have at least one scalar")
+	}
+	for k := 1; k < len(times); k++ {
+		if times[k] <= times[k-1] {
+			return fmt.Errorf("input times do not increase at key %d", k)
+		}
+	}
+	values, n, err := d.floats(output)
+	if err != nil {
+		return err
+	}
+	want := len(times) * c.width()
+	if c.Interpolation == CubicSpline {
+		want *= 3
+	}
+	if n != c.width() || len(values) != want {
+		return fmt.Errorf("output has %d values of %d components, want %d of %d",
+			len(values)/n, n, want/c.width(), c.width())
+	}
+	c.Times, c.Values = times, values
+	return nil
+}
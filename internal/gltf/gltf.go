@@ -0,0 +1,460 @@
+// Package gltf reads models in the glTF 2.0 format: their meshes,
+// the hierarchy of nodes that places them, base color materials,
+// and animations that move the nodes.
+//
+// Models may be .gltf files, whose buffers are separate files or
+// base64 data URIs, or binary .glb files. Extensions, skins, morph
+// targets, cameras and the metallic-roughness parts of materials
+// are not supported; a model that requires an extension is
+// rejected, and the rest are ignored.
+package gltf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/droyo/gltut/internal/mesh"
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+// A Model is a glTF asset.
+type Model struct {
+	Meshes     []Mesh
+	Nodes      []Node
+	Scenes     []Scene
+	Scene      int // the scene to show, or -1 if the file does not say
+	Animations []Animation
+}
+
+// A Mesh holds the primitives of a glTF mesh as submeshes of one
+// mesh.Data. The vertices of all primitives share the layout of
+// the attributes "position", "normal", "texCoord" and "color",
+// leaving out those that no primitive has; primitives without an
+// attribute get zeros. Each submesh has its own base vertex and
+// names its material in Data.Materials.
+//
+// Front faces are counter-clockwise, as in every glTF file.
+type Mesh struct {
+	Name string
+	Data *mesh.Data
+}
+
+// A Node is a transform in the node hierarchy, optionally holding a
+// mesh. Its transform is Matrix, if it is set, or else the product
+// of the translation, rotation and scale, applied in reverse order.
+// Only nodes without a Matrix can be animated.
+type Node struct {
+	Name     string
+	Children []int
+	Mesh     int // index in Model.Meshes, or -1
+
+	Matrix      *vmath.Mat4
+	Translation vmath.Vec3
+	Rotation    vmath.Quat
+	Scale       vmath.Vec3
+}
+
+// Transform returns the node's transform, relative to its parent.
+func (n *Node) Transform() vmath.Mat4 {
+	if n.Matrix != nil {
+		return *n.Matrix
+	}
+	return vmath.Translate(n.Translation).Mul(n.Rotation.Mat4()).Mul(vmath.Scale(n.Scale))
+}
+
+// A Scene is a set of root nodes.
+type Scene struct {
+	Name  string
+	Nodes []int
+}
+
+// Walk calls fn for every node of scene, parents before children.
+// While fn runs, the node's transform, combined with those of its
+// ancestors, is on top of stack.
+func (m *Model) Walk(scene int, stack *vmath.MatrixStack, fn func(n *Node, stack *vmath.MatrixStack)) {
+	var walk func(i int)
+	walk = func(i int) {
+		n := &m.Nodes[i]
+		stack.Push()
+		stack.Mul(n.Transform())
+		fn(n, stack)
+		for _, c := range n.Children {
+			walk(c)
+		}
+		stack.Pop()
+	}
+	for _, i := range m.Scenes[scene].Nodes {
+		walk(i)
+	}
+}
+
+// ReadFile reads a model from the named .gltf or .glb file. Buffers
+// and images it refers to are looked up in the same directory.
+func ReadFile(name string) (*Model, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	dir := filepath.Dir(name)
+	m, err := Read(f, func(uri string) (io.ReadCloser, error) {
+		return os.Open(filepath.Join(dir, filepath.FromSlash(uri)))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return m, nil
+}
+
+// Read reads a model in glTF JSON or binary form. Buffers given by a
+// URI other than a data URI are read with open; if open is nil,
+// such models are rejected.
+func Read(r io.Reader, open func(uri string) (io.ReadCloser, error)) (*Model, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var bin []byte
+	if bytes.HasPrefix(b, []byte(glbMagic)) {
+		if b, bin, err = splitGLB(b); err != nil {
+			return nil, err
+		}
+	}
+	var doc document
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("gltf: %v", err)
+	}
+	if !strings.HasPrefix(doc.Asset.Version, "2.") {
+		return nil, fmt.Errorf("gltf: unsupported version %q", doc.Asset.Version)
+	}
+	if len(doc.ExtensionsRequired) > 0 {
+		return nil, fmt.Errorf("gltf: unsupported extensions %s", strings.Join(doc.ExtensionsRequired, ", "))
+	}
+	d := &decoder{doc: &doc}
+	if err := d.loadBuffers(bin, open); err != nil {
+		return nil, err
+	}
+	return d.model()
+}
+
+const (
+	glbMagic     = "glTF"
+	glbChunkJSON = 0x4E4F534A
+	glbChunkBIN  = 0x004E4942
+)
+
+// splitGLB returns the JSON and binary chunks of a .glb file.
+func splitGLB(b []byte) (js, bin []byte, err error) {
+	le := binary.LittleEndian
+	if len(b) < 12 {
+		return nil, nil, fmt.Errorf("gltf: short glb header")
+	}
+	if v := le.Uint32(b[4:]); v != 2 {
+		return nil, nil, fmt.Errorf("gltf: unsupported glb version %d", v)
+	}
+	if n := le.Uint32(b[8:]); int(n) > len(b) {
+		return nil, nil, fmt.Errorf("gltf: glb is %d bytes, but its header says %d", len(b), n)
+	}
+	rest := b[12:]
+	for len(rest) >= 8 {
+		n, typ := int(le.Uint32(rest)), le.Uint32(rest[4:])
+		if n > len(rest)-8 {
+			return nil, nil, fmt.Errorf("gltf: glb chunk of %d bytes is truncated", n)
+		}
+		chunk := rest[8 : 8+n]
+		switch {
+		case typ == glbChunkJSON && js == nil:
+			js = chunk
+		case typ == glbChunkBIN && bin == nil:
+			bin = chunk
+		}
+		rest = rest[8+n:]
+	}
+	if js == nil {
+		return nil, nil, fmt.Errorf("gltf: glb has no JSON chunk")
+	}
+	return js, bin, nil
+}
+
+// A decoder turns a parsed document and its buffers into a Model.
+type decoder struct {
+	doc     *document
+	buffers [][]byte
+}
+
+func (d *decoder) loadBuffers(bin []byte, open func(string) (io.ReadCloser, error)) error {
+	for i, buf := range d.doc.Buffers {
+		var (
+			b   []byte
+			err error
+		)
+		switch {
+		case buf.URI == "" && i == 0 && bin != nil:
+			b = bin
+		case buf.URI == "":
+			err = fmt.Errorf("no data")
+		case strings.HasPrefix(buf.URI, "data:"):
+			b, err = decodeDataURI(buf.URI)
+		case open == nil:
+			err = fmt.Errorf("cannot open %q", buf.URI)
+		default:
+			b, err = readURI(buf.URI, open)
+		}
+		if err == nil && len(b) < buf.ByteLength {
+			err = fmt.Errorf("has %d bytes, want %d", len(b), buf.ByteLength)
+		}
+		if err != nil {
+			return fmt.Errorf("gltf: buffer %d: %v", i, err)
+		}
+		d.buffers = append(d.buffers, b[:buf.ByteLength])
+	}
+	return nil
+}
+
+func readURI(uri string, open func(string) (io.ReadCloser, error)) ([]byte, error) {
+	// Relative URIs may escape characters, such as spaces.
+	if s, err := url.PathUnescape(uri); err == nil {
+		uri = s
+	}
+	f, err := open(uri)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
+
+// decodeDataURI decodes a base64 data URI.
+func decodeDataURI(uri string) ([]byte, error) {
+	i := strings.IndexByte(uri, ',')
+	if i < 0 || !strings.HasSuffix(uri[:i], ";base64") {
+		return nil, fmt.Errorf("data URI is not base64")
+	}
+	return base64.StdEncoding.DecodeString(uri[i+1:])
+}
+
+func (d *decoder) model() (*Model, error) {
+	doc := d.doc
+	m := &Model{Scene: -1}
+	materials, err := d.materials()
+	if err != nil {
+		return nil, err
+	}
+	for i := range doc.Meshes {
+		data, err := d.mesh(i, materials)
+		if err != nil {
+			return nil, fmt.Errorf("gltf: mesh %d: %v", i, err)
+		}
+		m.Meshes = append(m.Meshes, Mesh{Name: doc.Meshes[i].Name, Data: data})
+	}
+	if err := d.nodes(m); err != nil {
+		return nil, err
+	}
+	for i, s := range doc.Scenes {
+		for _, n := range s.Nodes {
+			if n < 0 || n >= len(m.Nodes) {
+				return nil, fmt.Errorf("gltf: scene %d: no node %d", i, n)
+			}
+		}
+		m.Scenes = append(m.Scenes, Scene{Name: s.Name, Nodes: s.Nodes})
+	}
+	if doc.Scene != nil {
+		if *doc.Scene < 0 || *doc.Scene >= len(m.Scenes) {
+			return nil, fmt.Errorf("gltf: no scene %d", *doc.Scene)
+		}
+		m.Scene = *doc.Scene
+	}
+	for i := range doc.Animations {
+		a, err := d.animation(i, m)
+		if err != nil {
+			return nil, fmt.Errorf("gltf: animation %d: %v", i, err)
+		}
+		m.Animations = append(m.Animations, a)
+	}
+	return m, nil
+}
+
+func (d *decoder) nodes(m *Model) error {
+	doc := d.doc
+	parent := make([]int, len(doc.Nodes))
+	for i := range parent {
+		parent[i] = -1
+	}
+	for i, n := range doc.Nodes {
+		node := Node{
+			Name:     n.Name,
+			Children: n.Children,
+			Mesh:     -1,
+			Rotation: vmath.IdentQuat(),
+			Scale:    vmath.Vec3{1, 1, 1},
+		}
+		if n.Mesh != nil {
+			if *n.Mesh < 0 || *n.Mesh >= len(m.Meshes) {
+				return fmt.Errorf("gltf: node %d: no mesh %d", i, *n.Mesh)
+			}
+			node.Mesh = *n.Mesh
+		}
+		for _, c := range n.Children {
+			if c < 0 || c >= len(doc.Nodes) {
+				return fmt.Errorf("gltf: node %d: no child node %d", i, c)
+			}
+			if parent[c] >= 0 || c == i {
+				return fmt.Errorf("gltf: node %d: child %d already has a parent", i, c)
+			}
+			parent[c] = i
+		}
+		var err error
+		switch {
+		case n.Matrix != nil:
+			if len(n.Matrix) != 16 {
+				err = fmt.Errorf("matrix has %d values", len(n.Matrix))
+				break
+			}
+			var mat vmath.Mat4
+			copy(mat[:], n.Matrix)
+			node.Matrix = &mat
+		default:
+			err = setVec(node.Translation[:], n.Translation, "translation")
+			if err == nil {
+				err = setVec(node.Scale[:], n.Scale, "scale")
+			}
+			if err == nil && n.Rotation != nil {
+				var q [4]float32
+				err = setVec(q[:], n.Rotation, "rotation")
+				node.Rotation = vmath.Quat{W: q[3], X: q[0], Y: q[1], Z: q[2]}
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("gltf: node %d: %v", i, err)
+		}
+		m.Nodes = append(m.Nodes, node)
+	}
+	// Every node has at most one parent, so a cycle has no root.
+	for i := range parent {
+		seen := 0
+		for p := parent[i]; p >= 0; p = parent[p] {
+			if seen++; seen > len(parent) {
+				return fmt.Errorf("gltf: node %d is its own ancestor", i)
+			}
+		}
+	}
+	return nil
+}
+
+// setVec copies v into dst if it is set.
+func setVec(dst, v []float32, what string) error {
+	if v == nil {
+		return nil
+	}
+	if len(v) != len(dst) {
+		return fmt.Errorf("%s has %d values, want %d", what, len(v), len(dst))
+	}
+	copy(dst, v)
+	return nil
+}
+
+// document is the JSON part of a glTF file, less the properties
+// this package ignores.
+type document struct {
+	Asset struct {
+		Version string `json:"version"`
+	} `json:"asset"`
+	ExtensionsRequired []string `json:"extensionsRequired"`
+
+	Scene  *int `json:"scene"`
+	Scenes []struct {
+		Name  string `json:"name"`
+		Nodes []int  `json:"nodes"`
+	} `json:"scenes"`
+	Nodes []struct {
+		Name        string    `json:"name"`
+		Children    []int     `json:"children"`
+		Mesh        *int      `json:"mesh"`
+		Matrix      []float32 `json:"matrix"`
+		Translation []float32 `json:"translation"`
+		Rotation    []float32 `json:"rotation"`
+		Scale       []float32 `json:"scale"`
+	} `json:"nodes"`
+	Meshes []struct {
+		Name       string `json:"name"`
+		Primitives []struct {
+			Attributes map[string]int `json:"attributes"`
+			Indices    *int           `json:"indices"`
+			Material   *int           `json:"material"`
+			Mode       *int           `json:"mode"`
+		} `json:"primitives"`
+	} `json:"meshes"`
+	Materials []struct {
+		Name string `json:"name"`
+		PBR  struct {
+			BaseColorFactor  []float32 `json:"baseColorFactor"`
+			BaseColorTexture *struct {
+				Index int `json:"index"`
+			} `json:"baseColorTexture"`
+		} `json:"pbrMetallicRoughness"`
+	} `json:"materials"`
+	Textures []struct {
+		Source *int `json:"source"`
+	} `json:"textures"`
+	Images []struct {
+		URI string `json:"uri"`
+	} `json:"images"`
+	Accessors []struct {
+		BufferView    *int            `json:"bufferView"`
+		ByteOffset    int             `json:"byteOffset"`
+		ComponentType int             `json:"componentType"`
+		Normalized    bool            `json:"normalized"`
+		Count         int             `json:"count"`
+		Type          string          `json:"type"`
+		Sparse        *sparseAccessor `json:"sparse"`
+	} `json:"accessors"`
+	BufferViews []struct {
+		Buffer     int `json:"buffer"`
+		ByteOffset int `json:"byteOffset"`
+		ByteLength int `json:"byteLength"`
+		ByteStride int `json:"byteStride"`
+	} `json:"bufferViews"`
+	Buffers []struct {
+		URI        string `json:"uri"`
+		ByteLength int    `json:"byteLength"`
+	} `json:"buffers"`
+	Animations []struct {
+		Name     string `json:"name"`
+		Channels []struct {
+			Sampler int `json:"sampler"`
+			Target  struct {
+				Node *int   `json:"node"`
+				Path string `json:"path"`
+			} `json:"target"`
+		} `json:"channels"`
+		Samplers []struct {
+			Input         int    `json:"input"`
+			Output        int    `json:"output"`
+			Interpolation string `json:"interpolation"`
+		} `json:"samplers"`
+	} `json:"animations"`
+}
+
+// sparseAccessor lists the elements of an accessor that differ from
+// its buffer view, or from zero.
+type sparseAccessor struct {
+	Count   int `json:"count"`
+	Indices struct {
+		BufferView    int `json:"bufferView"`
+		ByteOffset    int `json:"byteOffset"`
+		ComponentType int `json:"componentType"`
+	} `json:"indices"`
+	Values struct {
+		BufferView int `json:"bufferView"`
+		ByteOffset int `json:"byteOffset"`
+	} `json:"values"`
+}
@@ -0,0 +1,482 @@
+package gltf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/mesh"
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+// A fixture builds a test model: a buffer, its buffer views, and a
+// glTF document around them.
+type fixture struct {
+	buf   bytes.Buffer
+	views []string
+}
+
+// view adds a buffer view holding data, written in little-endian
+// order, and returns its index. A stride of 0 is left out.
+func (f *fixture) view(stride int, data ...interface{}) int {
+	off := f.buf.Len()
+	for _, x := range data {
+		binary.Write(&f.buf, binary.LittleEndian, x)
+	}
+	n := f.buf.Len() - off
+	for f.buf.Len()%4 != 0 {
+		f.buf.WriteByte(0)
+	}
+	v := fmt.Sprintf(`{"buffer": 0, "byteOffset": %d, "byteLength": %d`, off, n)
+	if stride > 0 {
+		v += fmt.Sprintf(`, "byteStride": %d`, stride)
+	}
+	f.views = append(f.views, v+"}")
+	return len(f.views) - 1
+}
+
+// json returns a glTF document with the given top-level properties
+// and the buffer of f, at uri if it is not empty.
+func (f *fixture) json(props, uri string) string {
+	buffer := fmt.Sprintf(`{"byteLength": %d}`, f.buf.Len())
+	if uri != "" {
+		buffer = fmt.Sprintf(`{"uri": %q, "byteLength": %d}`, uri, f.buf.Len())
+	}
+	return fmt.Sprintf(`{"asset": {"version": "2.0"}, "bufferViews": [%s], "buffers": [%s], %s}`,
+		strings.Join(f.views, ", "), buffer, props)
+}
+
+func (f *fixture) dataURI() string {
+	return "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(f.buf.Bytes())
+}
+
+// glb returns a binary glTF file whose BIN chunk is the buffer of f.
+func (f *fixture) glb(props string) []byte {
+	js := []byte(f.json(props, ""))
+	for len(js)%4 != 0 {
+		js = append(js, ' ')
+	}
+	var b bytes.Buffer
+	le := binary.LittleEndian
+	b.WriteString(glbMagic)
+	binary.Write(&b, le, []uint32{2, uint32(12 + 8 + len(js) + 8 + f.buf.Len())})
+	binary.Write(&b, le, []uint32{uint32(len(js)), glbChunkJSON})
+	b.Write(js)
+	binary.Write(&b, le, []uint32{uint32(f.buf.Len()), glbChunkBIN})
+	b.Write(f.buf.Bytes())
+	return b.Bytes()
+}
+
+func near(a, b []float32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(float64(a[i]-b[i])) > 1e-5 {
+			return false
+		}
+	}
+	return true
+}
+
+// triangle returns a fixture and the properties of a model with one
+// red triangle, with RGB vertex colors and 16 bit indices.
+func triangle() (*fixture, string) {
+	f := new(fixture)
+	f.view(0, []float32{0, 0, 0, 1, 0, 0, 0, 1, 0})
+	f.view(0, []uint8{255, 0, 0, 0, 255, 0, 0, 0, 255})
+	f.view(0, []uint16{0, 1, 2})
+	return f, `"scene": 0,
+		"scenes": [{"nodes": [0]}],
+		"nodes": [{"name": "tri", "mesh": 0}],
+		"meshes": [{"name": "tri", "primitives": [
+			{"attributes": {"POSITION": 0, "COLOR_0": 1}, "indices": 2, "material": 0}
+		]}],
+		"materials": [{"name": "red", "pbrMetallicRoughness": {"baseColorFactor": [1, 0, 0, 0.5]}}],
+		"accessors": [
+			{"bufferView": 0, "componentType": 5126, "count": 3, "type": "VEC3"},
+			{"bufferView": 1, "componentType": 5121, "normalized": true, "count": 3, "type": "VEC3"},
+			{"bufferView": 2, "componentType": 5123, "count": 3, "type": "SCALAR"}
+		]`
+}
+
+func TestRead(t *testing.T) {
+	f, props := triangle()
+	external := func(uri string) (io.ReadCloser, error) {
+		if uri != "tri angle.bin" {
+			return nil, fmt.Errorf("no file %q", uri)
+		}
+		return ioutil.NopCloser(bytes.NewReader(f.buf.Bytes())), nil
+	}
+	tests := []struct {
+		name string
+		file []byte
+		open func(string) (io.ReadCloser, error)
+	}{
+		{"gltf with a data URI", []byte(f.json(props, f.dataURI())), nil},
+		{"gltf with an external buffer", []byte(f.json(props, "tri%20angle.bin")), external},
+		{"glb", f.glb(props), nil},
+	}
+	for _, tt := range tests {
+		m, err := Read(bytes.NewReader(tt.file), tt.open)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if m.Scene != 0 || len(m.Meshes) != 1 || m.Meshes[0].Name != "tri" || m.Nodes[0].Mesh != 0 {
+			t.Errorf("%s: read %+v", tt.name, m)
+			continue
+		}
+		d := m.Meshes[0].Data
+		layout := mesh.Interleaved(mesh.Float("position", 3), mesh.Float("color", 4))
+		if !reflect.DeepEqual(d.Layout, layout) {
+			t.Errorf("%s: layout %+v, want %+v", tt.name, d.Layout, layout)
+		}
+		// The RGB colors get an alpha of 1.
+		vertices := []float32{
+			0, 0, 0, 1, 0, 0, 1,
+			1, 0, 0, 0, 1, 0, 1,
+			0, 1, 0, 0, 0, 1, 1,
+		}
+		if !reflect.DeepEqual(d.Vertices, vertices) {
+			t.Errorf("%s: vertices %v, want %v", tt.name, d.Vertices, vertices)
+		}
+		if want := []uint16{0, 1, 2}; !reflect.DeepEqual(d.Indices, want) {
+			t.Errorf("%s: indices %v, want %v", tt.name, d.Indices, want)
+		}
+		sub := mesh.Submesh{Material: "red", Mode: gfx.TRIANGLES, Indexed: true, Count: 3}
+		if len(d.Submeshes) != 1 || d.Submeshes[0] != sub {
+			t.Errorf("%s: submeshes %+v, want %+v", tt.name, d.Submeshes, sub)
+		}
+		red := &mesh.Material{Diffuse: vmath.Vec3{1, 0, 0}, Alpha: 0.5}
+		if !reflect.DeepEqual(d.Materials, map[string]*mesh.Material{"red": red}) {
+			t.Errorf("%s: materials %v", tt.name, d.Materials)
+		}
+	}
+}
+
+func TestAccessors(t *testing.T) {
+	f := new(fixture)
+	tight := f.view(0, []float32{1, 2, 3, 4, 5, 6})
+	// Positions and normals, interleaved.
+	interleaved := f.view(24, []float32{1, 2, 3, 10, 20, 30, 4, 5, 6, 40, 50, 60})
+	// RGBA colors, padded to 8 bytes.
+	colors := f.view(8, []uint8{255, 0, 51, 255, 9, 9, 9, 9, 0, 255, 0, 102})
+	bytes := f.view(0, []int8{-128, -127, 0, 127})
+	shorts := f.view(0, []int16{-32768, 32767, -1})
+	ushorts := f.view(0, []uint16{0, 65535, 13107})
+	sparseIdx := f.view(0, []uint16{1, 3})
+	sparseVal := f.view(0, []float32{7, 8, 9, 10, 11, 12})
+	sparseOne := f.view(0, []uint8{0})
+	tests := []struct {
+		name     string
+		accessor string
+		want     []float32
+		n        int
+	}{
+		{"tightly packed",
+			fmt.Sprintf(`{"bufferView": %d, "componentType": 5126, "count": 2, "type": "VEC3"}`, tight),
+			[]float32{1, 2, 3, 4, 5, 6}, 3},
+		{"byte offset",
+			fmt.Sprintf(`{"bufferView": %d, "byteOffset": 12, "componentType": 5126, "count": 1, "type": "VEC3"}`, tight),
+			[]float32{4, 5, 6}, 3},
+		{"stride",
+			fmt.Sprintf(`{"bufferView": %d, "componentType": 5126, "count": 2, "type": "VEC3"}`, interleaved),
+			[]float32{1, 2, 3, 4, 5, 6}, 3},
+		{"stride and offset",
+			fmt.Sprintf(`{"bufferView": %d, "byteOffset": 12, "componentType": 5126, "count": 2, "type": "VEC3"}`, interleaved),
+			[]float32{10, 20, 30, 40, 50, 60}, 3},
+		{"normalized ubyte with stride",
+			fmt.Sprintf(`{"bufferView": %d, "componentType": 5121, "normalized": true, "count": 2, "type": "VEC4"}`, colors),
+			[]float32{1, 0, 0.2, 1, 0, 1, 0, 0.4}, 4},
+		{"ubyte",
+			fmt.Sprintf(`{"bufferView": %d, "componentType": 5121, "count": 2, "type": "VEC4"}`, colors),
+			[]float32{255, 0, 51, 255, 0, 255, 0, 102}, 4},
+		{"normalized byte",
+			fmt.Sprintf(`{"bufferView": %d, "componentType": 5120, "normalized": true, "count": 4, "type": "SCALAR"}`, bytes),
+			[]float32{-1, -1, 0, 1}, 1},
+		{"normalized short",
+			fmt.Sprintf(`{"bufferView": %d, "componentType": 5122, "normalized": true, "count": 3, "type": "SCALAR"}`, shorts),
+			[]float32{-1, 1, -1.0 / 32767}, 1},
+		{"normalized ushort",
+			fmt.Sprintf(`{"bufferView": %d, "componentType": 5123, "normalized": true, "count": 3, "type": "SCALAR"}`, ushorts),
+			[]float32{0, 1, 0.2}, 1},
+		{"no buffer view",
+			`{"componentType": 5126, "count": 2, "type": "VEC2"}`,
+			[]float32{0, 0, 0, 0}, 2},
+		{"sparse over zeros",
+			fmt.Sprintf(`{"componentType": 5126, "count": 4, "type": "VEC3", "sparse": {"count": 2,
+				"indices": {"bufferView": %d, "componentType": 5123},
+				"values": {"bufferView": %d}}}`, sparseIdx, sparseVal),
+			[]float32{0, 0, 0, 7, 8, 9, 0, 0, 0, 10, 11, 12}, 3},
+		{"sparse over a buffer view",
+			fmt.Sprintf(`{"bufferView": %d, "componentType": 5126, "count": 2, "type": "VEC3", "sparse": {"count": 1,
+				"indices": {"bufferView": %d, "componentType": 5121},
+				"values": {"bufferView": %d, "byteOffset": 12}}}`, tight, sparseOne, sparseVal),
+			[]float32{10, 11, 12, 4, 5, 6}, 3},
+	}
+	var accessors []string
+	for _, tt := range tests {
+		accessors = append(accessors, tt.accessor)
+	}
+	var doc document
+	if err := json.Unmarshal([]byte(f.json(`"accessors": [`+strings.Join(accessors, ",")+`]`, f.dataURI())), &doc); err != nil {
+		t.Fatal(err)
+	}
+	d := &decoder{doc: &doc}
+	if err := d.loadBuffers(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	for i, tt := range tests {
+		v, n, err := d.floats(i)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		} else if n != tt.n || !near(v, tt.want) {
+			t.Errorf("%s: read %v, %d per element; want %v, %d", tt.name, v, n, tt.want, tt.n)
+		}
+	}
+}
+
+func TestAccessorErrors(t *testing.T) {
+	f := new(fixture)
+	f.view(0, []float32{1, 2, 3, 4, 5, 6})
+	f.view(0, []uint16{5})
+	tests := []struct {
+		accessor, want string
+	}{
+		{`{"bufferView": 0, "componentType": 5126, "count": 3, "type": "VEC3"}`,
+			"accessor 0: 3 elements do not fit in buffer view 0"},
+		{`{"bufferView": 0, "byteOffset": 4, "componentType": 5126, "count": 2, "type": "VEC3"}`,
+			"accessor 0: 2 elements do not fit in buffer view 0"},
+		{`{"bufferView": 2, "componentType": 5126, "count": 1, "type": "VEC3"}`,
+			"accessor 0: no buffer view 2"},
+		{`{"bufferView": 0, "componentType": 5124, "count": 1, "type": "SCALAR"}`,
+			"accessor 0: unknown component type 5124"},
+		{`{"bufferView": 0, "componentType": 5126, "count": 1, "type": "MAT4"}`,
+			`accessor 0: unsupported type "MAT4"`},
+		{`{"componentType": 5126, "count": 2, "type": "VEC3", "sparse": {"count": 1,
+			"indices": {"bufferView": 1, "componentType": 5123}, "values": {"bufferView": 0}}}`,
+			"accessor 0: sparse: index 0 is 5, but there are only 2 elements"},
+		{`{"componentType": 5126, "count": 2, "type": "VEC3", "sparse": {"count": 1,
+			"indices": {"bufferView": 0, "componentType": 5126}, "values": {"bufferView": 0}}}`,
+			"accessor 0: sparse: indices have component type 5126"},
+		{`{"componentType": 5126, "count": 2, "type": "VEC3", "sparse": {"count": 2,
+			"indices": {"bufferView": 1, "componentType": 5123}, "values": {"bufferView": 0}}}`,
+			"accessor 0: sparse: indices: 2 elements do not fit in buffer view 1"},
+	}
+	for _, tt := range tests {
+		var doc document
+		if err := json.Unmarshal([]byte(f.json(`"accessors": [`+tt.accessor+`]`, f.dataURI())), &doc); err != nil {
+			t.Fatal(err)
+		}
+		d := &decoder{doc: &doc}
+		if err := d.loadBuffers(nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		_, _, err := d.floats(0)
+		if err == nil {
+			t.Errorf("%s: no error", tt.accessor)
+		} else if err.Error() != tt.want {
+			t.Errorf("error %q, want %q", err, tt.want)
+		}
+	}
+}
+
+func TestNodes(t *testing.T) {
+	f, _ := triangle()
+	m, err := Read(strings.NewReader(f.json(`
+		"scenes": [{"nodes": [0, 3]}],
+		"nodes": [
+			{"name": "trs", "children": [1, 2],
+				"translation": [1, 2, 3], "rotation": [0, 0, 0.70710678, 0.70710678], "scale": [2, 2, 2]},
+			{"name": "matrix", "matrix": [1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 5, 6, 7, 1]},
+			{"name": "default"},
+			{"name": "root"}
+		]`, f.dataURI())), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Scene != -1 {
+		t.Errorf("scene is %d without a scene property, want -1", m.Scene)
+	}
+	if m.Nodes[0].Matrix != nil || m.Nodes[1].Matrix == nil {
+		t.Errorf("TRS node has matrix %v, matrix node has %v", m.Nodes[0].Matrix, m.Nodes[1].Matrix)
+	}
+	// The point (1, 0, 0) in each node's space, seen from the root
+	// of the scene.
+	want := map[string]vmath.Vec3{
+		// scaled to (2, 0, 0), turned to (0, 2, 0), moved by (1, 2, 3)
+		"trs": {1, 4, 3},
+		// moved by (5, 6, 7) to (6, 6, 7), then as trs
+		"matrix":  {1 - 12, 2 + 12, 3 + 14},
+		"default": {1, 4, 3},
+		"root":    {1, 0, 0},
+	}
+	var order []string
+	m.Walk(0, new(vmath.MatrixStack), func(n *Node, stack *vmath.MatrixStack) {
+		order = append(order, n.Name)
+		p := stack.Top().MulVec(vmath.Vec4{1, 0, 0, 1})
+		if w := want[n.Name]; !near(p[:3], w[:]) {
+			t.Errorf("%s: (1, 0, 0) is at %v, want %v", n.Name, p, w)
+		}
+	})
+	if got := strings.Join(order, " "); got != "trs matrix default root" {
+		t.Errorf("walked %s, want parents before children", got)
+	}
+}
+
+func TestSample(t *testing.T) {
+	s, c := float32(math.Sin(math.Pi/8)), float32(math.Cos(math.Pi/8))
+	tests := []struct {
+		name string
+		ch   Channel
+		t    float32
+		want []float32
+	}{
+		{"linear", Channel{Path: Translation, Interpolation: Linear,
+			Times: []float32{1, 3}, Values: []float32{0, 0, 0, 4, 2, -2}},
+			1.5, []float32{1, 0.5, -0.5}},
+		{"before the first key", Channel{Path: Translation, Interpolation: Linear,
+			Times: []float32{1, 3}, Values: []float32{0, 0, 0, 4, 2, -2}},
+			0, []float32{0, 0, 0}},
+		{"after the last key", Channel{Path: Scale, Interpolation: Linear,
+			Times: []float32{1, 3}, Values: []float32{1, 1, 1, 4, 2, -2}},
+			5, []float32{4, 2, -2}},
+		{"step", Channel{Path: Translation, Interpolation: Step,
+			Times: []float32{0, 1, 2}, Values: []float32{0, 0, 0, 1, 1, 1, 2, 2, 2}},
+			1.9, []float32{1, 1, 1}},
+		{"step on a key", Channel{Path: Translation, Interpolation: Step,
+			Times: []float32{0, 1, 2}, Values: []float32{0, 0, 0, 1, 1, 1, 2, 2, 2}},
+			1, []float32{1, 1, 1}},
+		{"slerp", Channel{Path: Rotation, Interpolation: Linear,
+			Times: []float32{0, 1}, Values: []float32{0, 0, 0, 1, 0, 0, 0.70710678, 0.70710678}},
+			0.5, []float32{0, 0, s, c}},
+		// The tangents are per second, and are scaled by the 2
+		// seconds between the keys: 0.125*2*1 + 0.5*2 = 1.25.
+		// Unscaled, they would give 1.125.
+		{"cubic spline", Channel{Path: Translation, Interpolation: CubicSpline,
+			Times: []float32{0, 2},
+			Values: []float32{
+				9, 9, 9, 0, 0, 0, 1, 0, 0,
+				0, 0, 0, 2, 0, 0, 9, 9, 9,
+			}},
+			1, []float32{1.25, 0, 0}},
+		{"cubic spline at a key", Channel{Path: Translation, Interpolation: CubicSpline,
+			Times: []float32{0, 2},
+			Values: []float32{
+				9, 9, 9, 0, 0, 0, 1, 0, 0,
+				0, 0, 0, 2, 0, 0, 9, 9, 9,
+			}},
+			2, []float32{2, 0, 0}},
+	}
+	for _, tt := range tests {
+		if got := tt.ch.sample(tt.t); !near(got, tt.want) {
+			t.Errorf("%s: at %v got %v, want %v", tt.name, tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestAnimation(t *testing.T) {
+	f := new(fixture)
+	f.view(0, []float32{0, 2})
+	f.view(0, []float32{
+		9, 9, 9, 0, 0, 0, 1, 0, 0,
+		0, 0, 0, 2, 0, 0, 9, 9, 9,
+	})
+	f.view(0, []float32{0, 0, 0, 1, 0, 0, 0.70710678, 0.70710678})
+	m, err := Read(strings.NewReader(f.json(`
+		"nodes": [{"name": "moved"}],
+		"accessors": [
+			{"bufferView": 0, "componentType": 5126, "count": 2, "type": "SCALAR"},
+			{"bufferView": 1, "componentType": 5126, "count": 6, "type": "VEC3"},
+			{"bufferView": 2, "componentType": 5126, "count": 2, "type": "VEC4"}
+		],
+		"animations": [{"name": "go", "channels": [
+			{"sampler": 0, "target": {"node": 0, "path": "translation"}},
+			{"sampler": 1, "target": {"node": 0, "path": "rotation"}},
+			{"sampler": 1, "target": {"node": 0, "path": "weights"}}
+		], "samplers": [
+			{"input": 0, "output": 1, "interpolation": "CUBICSPLINE"},
+			{"input": 0, "output": 2}
+		]}]`, f.dataURI())), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := m.Animations[0]
+	if a.Name != "go" || len(a.Channels) != 2 {
+		t.Fatalf("read animation %+v, want 2 channels", a)
+	}
+	if d := a.Duration(); d != 2*time.Second {
+		t.Errorf("duration is %v, want 2s", d)
+	}
+	a.Apply(m, time.Second)
+	n := m.Nodes[0]
+	if !near(n.Translation[:], []float32{1.25, 0, 0}) {
+		t.Errorf("translation at 1s is %v, want (1.25, 0, 0)", n.Translation)
+	}
+	s, c := float32(math.Sin(math.Pi/8)), float32(math.Cos(math.Pi/8))
+	if q := n.Rotation; !near([]float32{q.X, q.Y, q.Z, q.W}, []float32{0, 0, s, c}) {
+		t.Errorf("rotation at 1s is %v, want 45 degrees about Z", q)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	f, props := triangle()
+	uri := f.dataURI()
+	glb := f.glb(props)
+	badVersion := append([]byte(nil), glb...)
+	badVersion[4] = 1
+	truncated := glb[:len(glb)-4]
+	binary.LittleEndian.PutUint32(truncated[8:], uint32(len(truncated)))
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{"version 1", strings.Replace(f.json(props, uri), `"2.0"`, `"1.0"`, 1),
+			`gltf: unsupported version "1.0"`},
+		{"required extension", f.json(props+`, "extensionsRequired": ["KHR_draco_mesh_compression"]`, uri),
+			"gltf: unsupported extensions KHR_draco_mesh_compression"},
+		{"glb version 1", string(badVersion),
+			"gltf: unsupported glb version 1"},
+		{"truncated glb chunk", string(truncated),
+			fmt.Sprintf("gltf: glb chunk of %d bytes is truncated", f.buf.Len())},
+		{"external buffer without open", f.json(props, "tri.bin"),
+			`gltf: buffer 0: cannot open "tri.bin"`},
+		{"short buffer", strings.Replace(f.json(props, uri), fmt.Sprintf(`"byteLength": %d`, f.buf.Len()), `"byteLength": 100`, 1),
+			fmt.Sprintf("gltf: buffer 0: has %d bytes, want 100", f.buf.Len())},
+		{"bad data URI", f.json(props, "data:application/octet-stream,abc"),
+			"gltf: buffer 0: data URI is not base64"},
+		{"node cycle", strings.Replace(f.json(props, uri), `{"name": "tri", "mesh": 0}`, `{"name": "tri", "mesh": 0, "children": [0]}`, 1),
+			"gltf: node 0: child 0 already has a parent"},
+		{"short matrix", strings.Replace(f.json(props, uri), `"mesh": 0}`, `"mesh": 0, "matrix": [1, 0, 0]}`, 1),
+			"gltf: node 0: matrix has 3 values"},
+		{"no mesh", strings.Replace(f.json(props, uri), `"mesh": 0}`, `"mesh": 1}`, 1),
+			"gltf: node 0: no mesh 1"},
+		{"no material", strings.Replace(f.json(props, uri), `"material": 0`, `"material": 1`, 1),
+			"gltf: mesh 0: primitive 0: no material 1"},
+		{"animated matrix", strings.Replace(f.json(props, uri), `"mesh": 0}`,
+			`"mesh": 0, "matrix": [1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1]}],
+			"animations": [{"channels": [{"sampler": 0, "target": {"node": 0, "path": "scale"}}],
+				"samplers": [{"input": 2, "output": 0}]}`, 1),
+			"gltf: animation 0: channel 0: node 0 has a matrix, and cannot be animated"},
+	}
+	for _, tt := range tests {
+		_, err := Read(strings.NewReader(tt.file), nil)
+		if err == nil {
+			t.Errorf("%s: no error", tt.name)
+		} else if err.Error() != tt.want {
+			t.Errorf("%s: error %q, want %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package gltf
+
+import (
+	"fmt"
+
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/mesh"
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+var modes = map[int]gfx.Enum{
+	0: gfx.POINTS,
+	1: gfx.LINES,
+	2: gfx.LINE_LOOP,
+	3: gfx.LINE_STRIP,
+	4: gfx.TRIANGLES,
+	5: gfx.TRIANGLE_STRIP,
+	6: gfx.TRIANGLE_FAN,
+}
+
+// A vertex attribute that this package reads, in the order they are
+// interleaved.
+type attribute struct {
+	gltf, name string
+	size       int
+}
+
+var attributes = []attribute{
+	{"POSITION", "position", 3},
+	{"NORMAL", "normal", 3},
+	{"TEXCOORD_0", "texCoord", 2},
+	{"COLOR_0", "color", 4},
+}
+
+// materialSet holds the materials of a model, keyed by name.
+type materialSet struct {
+	byName  map[string]*mesh.Material
+	byIndex []string
+}
+
+// materials reads the base colors of the model's materials. Each is
+// keyed by its name, or by "material" and its index if it has no
+// name or shares it with an earlier material.
+func (d *decoder) materials() (*materialSet, error) {
+	set := &materialSet{byName: make(map[string]*mesh.Material)}
+	for i, m := range d.doc.Materials {
+		mat := &mesh.Material{Diffuse: vmath.Vec3{1, 1, 1}, Alpha: 1}
+		if f := m.PBR.BaseColorFactor; f != nil {
+			if len(f) != 4 {
+				return nil, fmt.Errorf("gltf: material %d: base color has %d values", i, len(f))
+			}
+			mat.Diffuse, mat.Alpha = vmath.Vec3{f[0], f[1], f[2]}, f[3]
+		}
+		if t := m.PBR.BaseColorTexture; t != nil {
+			uri, err := d.textureURI(t.Index)
+			if err != nil {
+				return nil, fmt.Errorf("gltf: material %d: %v", i, err)
+			}
+			mat.Texture = uri
+		}
+		key := m.Name
+		if _, dup := set.byName[key]; dup || key == "" {
+			key = fmt.Sprintf("material%d", i)
+		}
+		set.byName[key] = mat
+		set.byIndex = append(set.byIndex, key)
+	}
+	return set, nil
+}
+
+// textureURI returns the URI of the image of texture i, or "" if
+// the image is embedded in a buffer.
+func (d *decoder) textureURI(i int) (string, error) {
+	if i < 0 || i >= len(d.doc.Textures) {
+		return "", fmt.Errorf("no texture %d", i)
+	}
+	src := d.doc.Textures[i].Source
+	if src == nil {
+		return "", nil
+	}
+	if *src < 0 || *src >= len(d.doc.Images) {
+		return "", fmt.Errorf("texture %d: no image %d", i, *src)
+	}
+	return d.doc.Images[*src].URI, nil
+}
+
+// mesh converts the primitives of mesh i.
+func (d *decoder) mesh(i int, materials *materialSet) (*mesh.Data, error) {
+	prims := d.doc.Meshes[i].Primitives
+	if len(prims) == 0 {
+		return nil, fmt.Errorf("no primitives")
+	}
+	var used []attribute
+	for _, a := range attributes {
+		for _, p := range prims {
+			if _, ok := p.Attributes[a.gltf]; ok {
+				used = append(used, a)
+				break
+			}
+		}
+	}
+	var layout []mesh.Attrib
+	stride := 0
+	for _, a := range used {
+		layout = append(layout, mesh.Float(a.name, a.size))
+		stride += a.size
+	}
+	data := &mesh.Data{Layout: mesh.Interleaved(layout...)}
+
+	var (
+		vertices []float32
+		indices  []uint32
+		wide     bool
+	)
+	for j, p := range prims {
+		s, pv, pi, err := d.primitive(p.Attributes, p.Indices, p.Mode, used, stride)
+		if err != nil {
+			return nil, fmt.Errorf("primitive %d: %v", j, err)
+		}
+		base := len(vertices) / stride
+		if s.Indexed {
+			s.First, s.BaseVertex = len(indices), base
+			indices = append(indices, pi...)
+		} else {
+			s.First = base
+		}
+		wide = wide || len(pv)/stride > 1<<16
+		vertices = append(vertices, pv...)
+		if p.Material != nil {
+			if *p.Material < 0 || *p.Material >= len(materials.byIndex) {
+				return nil, fmt.Errorf("primitive %d: no material %d", j, *p.Material)
+			}
+			s.Material = materials.byIndex[*p.Material]
+		}
+		data.Submeshes = append(data.Submeshes, s)
+	}
+	data.Vertices = vertices
+	// Each primitive's indices start from its own base vertex, so
+	// they only need to be wide if one primitive is large.
+	switch {
+	case indices == nil:
+	case wide:
+		data.Indices = indices
+	default:
+		idx := make([]uint16, len(indices))
+		for i, v := range indices {
+			idx[i] = uint16(v)
+		}
+		data.Indices = idx
+	}
+	if len(materials.byName) > 0 {
+		data.Materials = materials.byName
+	}
+	return data, nil
+}
+
+// primitive reads the vertices and indices of a primitive. Its
+// vertices are interleaved with the given attributes.
+func (d *decoder) primitive(attrs map[string]int, indices, mode *int, used []attribute, stride int) (mesh.Submesh, []float32, []uint32, error) {
+	s := mesh.Submesh{Mode: gfx.TRIANGLES}
+	if mode != nil {
+		m, ok := modes[*mode]
+		if !ok {
+			return s, nil, nil, fmt.Errorf("unknown mode %d", *mode)
+		}
+		s.Mode = m
+	}
+	if _, ok := attrs["POSITION"]; !ok {
+		return s, nil, nil, fmt.Errorf("no POSITION attribute")
+	}
+	count := -1
+	var vertices []float32
+	off := 0
+	for _, a := range used {
+		acc, ok := attrs[a.gltf]
+		if !ok {
+			off += a.size
+			continue
+		}
+		v, n, err := d.floats(acc)
+		if err != nil {
+			return s, nil, nil, fmt.Errorf("%s: %v", a.gltf, err)
+		}
+		// Colors may be RGB; alpha defaults to 1.
+		if n != a.size && !(a.gltf == "COLOR_0" && n == 3) {
+			return s, nil, nil, fmt.Errorf("%s has %d components, want %d", a.gltf, n, a.size)
+		}
+		if count < 0 {
+			count = len(v) / n
+			vertices = make([]float32, count*stride)
+		} else if len(v)/n != count {
+			return s, nil, nil, fmt.Errorf("%s has %d elements, POSITION has %d", a.gltf, len(v)/n, count)
+		}
+		for e := 0; e < count; e++ {
+			dst := vertices[e*stride+off : e*stride+off+a.size]
+			copy(dst, v[e*n:e*n+n])
+			if n < a.size {
+				dst[3] = 1
+			}
+		}
+		off += a.size
+	}
+	if indices == nil {
+		s.Count = count
+		return s, vertices, nil, nil
+	}
+	idx, err := d.indices(*indices)
+	if err != nil {
+		return s, nil, nil, err
+	}
+	for _, v := range idx {
+		if int(v) >= count {
+			return s, nil, nil, fmt.Errorf("index %d is out of range for %d vertices", v, count)
+		}
+	}
+	s.Indexed, s.Count = true, len(idx)
+	return s, vertices, idx, nil
+}
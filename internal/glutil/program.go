@@ -0,0 +1,327 @@
+// Package glutil contains helpers shared by the tutorials for the
+// repetitive parts of OpenGL setup.
+package glutil
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/shader"
+)
+
+// A Stage is a programmable stage of the OpenGL pipeline.
+type Stage int
+
+const (
+	Vertex Stage = iota
+	Geometry
+	Fragment
+)
+
+func (s Stage) String() string {
+	switch s {
+	case Vertex:
+		return "vertex"
+	case Geometry:
+		return "geometry"
+	case Fragment:
+		return "fragment"
+	}
+	return "stage(" + strconv.Itoa(int(s)) + ")"
+}
+
+func (s Stage) shaderType() gfx.Enum {
+	switch s {
+	case Vertex:
+		return gfx.VERTEX_SHADER
+	case Geometry:
+		return gfx.GEOMETRY_SHADER
+	case Fragment:
+		return gfx.FRAGMENT_SHADER
+	}
+	panic("glutil: unknown shader stage " + s.String())
+}
+
+// StageOf returns the stage of a shader file from its extension:
+// .vert, .geom or .frag.
+func StageOf(name string) (Stage, bool) {
+	switch path.Ext(name) {
+	case ".vert":
+		return Vertex, true
+	case ".geom":
+		return Geometry, true
+	case ".frag":
+		return Fragment, true
+	}
+	return 0, false
+}
+
+type source struct {
+	stage Stage
+	name  string // file to read the text from, if any
+	fsys  fs.FS
+	text  []byte
+	pp    *shader.Source // the preprocessed file, once read
+}
+
+// A Builder collects the GLSL source for each stage of a program
+// and compiles and links them together in a rendering context.
+type Builder struct {
+	ctx      gfx.Context
+	sources  []source
+	defines  []shader.Define
+	bindings []binding
+	err      error // first error adding a file
+}
+
+type binding struct {
+	name string
+	loc  gfx.Attrib
+}
+
+// NewProgram returns an empty Builder for a program in ctx.
+func NewProgram(ctx gfx.Context) *Builder {
+	return &Builder{ctx: ctx}
+}
+
+// Stage adds GLSL source code for the given stage.
+func (b *Builder) Stage(s Stage, src []byte) *Builder {
+	b.sources = append(b.sources, source{stage: s, text: src})
+	return b
+}
+
+// Vertex adds a vertex shader to the program.
+func (b *Builder) Vertex(src []byte) *Builder { return b.Stage(Vertex, src) }
+
+// Geometry adds a geometry shader to the program.
+func (b *Builder) Geometry(src []byte) *Builder { return b.Stage(Geometry, src) }
+
+// Fragment adds a fragment shader to the program.
+func (b *Builder) Fragment(src []byte) *Builder { return b.Stage(Fragment, src) }
+
+// Files adds the named shader files from fsys to the program, each
+// to the stage given by its extension, as reported by StageOf. The
+// files are read and preprocessed by Link, with shader.Preprocess,
+// so they may include other files, and a file missing from fsys is
+// looked for in shader.Common. If a file cannot be read, Link
+// returns the error.
+func (b *Builder) Files(fsys fs.FS, names ...string) *Builder {
+	for _, name := range names {
+		stage, ok := StageOf(name)
+		if !ok {
+			if b.err == nil {
+				b.err = fmt.Errorf("glutil: %s: unknown shader stage; want .vert, .geom or .frag", name)
+			}
+			continue
+		}
+		b.sources = append(b.sources, source{stage: stage, name: name, fsys: fsys})
+	}
+	return b
+}
+
+// Define defines a macro with the given value in every file added
+// with Files, as described by shader.Define. Stages given as source
+// code are left as they are.
+func (b *Builder) Define(name string, value interface{}) *Builder {
+	b.defines = append(b.defines, shader.Define{Name: name, Value: value})
+	return b
+}
+
+// BindAttrib gives the vertex shader input with the given name the
+// location loc, as gfx.Context.BindAttribLocation does, so that the
+// location is known before the program is linked.
+func (b *Builder) BindAttrib(name string, loc gfx.Attrib) *Builder {
+	b.bindings = append(b.bindings, binding{name, loc})
+	return b
+}
+
+// Link compiles every stage and links them into a program. All
+// stages are compiled before any error is returned, so that the
+// returned *BuildError describes every broken stage at once. The
+// shader objects are released before Link returns; the caller is
+// responsible for calling Delete on the returned Program.
+func (b *Builder) Link() (*Program, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if len(b.sources) == 0 {
+		return nil, fmt.Errorf("glutil: program has no shader stages")
+	}
+	for i := range b.sources {
+		src := &b.sources[i]
+		if src.fsys == nil {
+			continue
+		}
+		pp, err := shader.Preprocess(src.fsys, src.name, b.defines...)
+		if err != nil {
+			return nil, err
+		}
+		src.pp, src.text = pp, pp.Text
+	}
+	ctx := b.ctx
+	prog := ctx.CreateProgram()
+	var shaders, attached []gfx.Shader
+	ok := false
+	defer func() {
+		for _, sh := range attached {
+			ctx.DetachShader(prog, sh)
+		}
+		for _, sh := range shaders {
+			ctx.DeleteShader(sh)
+		}
+		if !ok {
+			ctx.DeleteProgram(prog)
+		}
+	}()
+
+	var failed BuildError
+	for _, src := range b.sources {
+		sh := ctx.CreateShader(src.stage.shaderType())
+		shaders = append(shaders, sh)
+		ctx.ShaderSource(sh, src.text)
+		if err := ctx.CompileShader(sh); err != nil {
+			failed.Stages = append(failed.Stages, newStageError(src, err))
+			continue
+		}
+		ctx.AttachShader(prog, sh)
+		attached = append(attached, sh)
+	}
+	if len(failed.Stages) > 0 {
+		return nil, &failed
+	}
+	for _, a := range b.bindings {
+		ctx.BindAttribLocation(prog, a.loc, a.name)
+	}
+	if err := ctx.LinkProgram(prog); err != nil {
+		failed.Link = err.Error()
+		return nil, &failed
+	}
+	ok = true
+	return &Program{ID: prog, ctx: ctx}, nil
+}
+
+// A Program is a linked GLSL program.
+type Program struct {
+	ID  gfx.Program
+	ctx gfx.Context
+}
+
+// Use installs the program as part of the current rendering state.
+func (p *Program) Use() {
+	p.ctx.UseProgram(p.ID)
+}
+
+// Delete releases the program. It is safe to call Delete more than
+// once.
+func (p *Program) Delete() {
+	if p.ID != 0 {
+		p.ctx.DeleteProgram(p.ID)
+		p.ID = 0
+	}
+}
+
+// A BuildError is returned by Link when one or more stages fail
+// to compile, or when the program fails to link.
+type BuildError struct {
+	Stages []*StageError
+	Link   string // info log from the linker, if linking failed
+}
+
+func (e *BuildError) Error() string {
+	var buf bytes.Buffer
+	for i, s := range e.Stages {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(s.Error())
+	}
+	if e.Link != "" {
+		buf.WriteString("link failed: ")
+		buf.WriteString(strings.TrimSpace(e.Link))
+	}
+	return buf.String()
+}
+
+// A StageError holds the info log of a shader that failed to
+// compile, along with the source lines the log refers to.
+type StageError struct {
+	Stage Stage
+	Name  string // file the source was read from, if any
+	Log   string
+	Lines []SourceLine
+}
+
+// A SourceLine is a line of GLSL source referenced by an info log.
+// File is the file it is in, if the source was read from files.
+type SourceLine struct {
+	File   string
+	Number int
+	Text   string
+}
+
+func (e *StageError) Error() string {
+	var buf bytes.Buffer
+	if e.Name != "" {
+		fmt.Fprintf(&buf, "%s shader %s: %s", e.Stage, e.Name, strings.TrimSpace(e.Log))
+	} else {
+		fmt.Fprintf(&buf, "%s shader: %s", e.Stage, strings.TrimSpace(e.Log))
+	}
+	for _, l := range e.Lines {
+		if l.File != "" {
+			fmt.Fprintf(&buf, "\n\t%s:%d| %s", l.File, l.Number, l.Text)
+		} else {
+			fmt.Fprintf(&buf, "\n\t%4d| %s", l.Number, l.Text)
+		}
+	}
+	return buf.String()
+}
+
+// Drivers disagree on how to format line numbers in info logs.
+// Mesa writes "0:12(7): error", NVIDIA "0(12) : error" and
+// AMD/Intel "ERROR: 0:12: ". The first number is the source string
+// number, which is set by the #line directives that
+// shader.Preprocess writes.
+var logLine = regexp.MustCompile(`(?m)^\s*(?:ERROR:\s*|WARNING:\s*)?(\d+)[:(](\d+)`)
+
+// newStageError collects the lines an info log refers to. For
+// preprocessed files, the log's source string numbers are replaced
+// with the names of the files they stand for.
+func newStageError(src source, err error) *StageError {
+	e := &StageError{Stage: src.stage, Name: src.name}
+	log := err.Error()
+	lines := strings.Split(string(src.text), "\n")
+	seen := make(map[SourceLine]bool)
+	var buf strings.Builder
+	last := 0
+	for _, m := range logLine.FindAllStringSubmatchIndex(log, -1) {
+		file, _ := strconv.Atoi(log[m[2]:m[3]])
+		n, _ := strconv.Atoi(log[m[4]:m[5]])
+		l := SourceLine{Number: n}
+		var ok bool
+		if src.pp != nil {
+			if l.Text, ok = src.pp.Line(file, n); ok {
+				l.File = src.pp.Files[file]
+				buf.WriteString(log[last:m[2]])
+				buf.WriteString(l.File)
+				last = m[3]
+			}
+		} else if ok = n >= 1 && n <= len(lines); ok {
+			l.Text = lines[n-1]
+		}
+		l.Text = strings.TrimRight(l.Text, "\r")
+		if !ok || seen[l] {
+			continue
+		}
+		seen[l] = true
+		e.Lines = append(e.Lines, l)
+	}
+	buf.WriteString(log[last:])
+	e.Log = buf.String()
+	return e
+}
@@ -0,0 +1,104 @@
+package glutil
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/droyo/gltut/internal/shader"
+)
+
+func TestStageErrorRaw(t *testing.T) {
+	src := source{
+		stage: Fragment,
+		text: []byte("#version 330\n" +
+			"out vec4 color;\n" +
+			"void main() {\n" +
+			"\tcolor = vec4(bogus);\r\n" +
+			"}\n"),
+	}
+	line4 := []SourceLine{{Number: 4, Text: "\tcolor = vec4(bogus);"}}
+	tests := []struct {
+		driver string
+		log    string
+		want   []SourceLine
+	}{
+		{"Mesa", "0:4(15): error: `bogus' undeclared\n", line4},
+		{"NVIDIA", "0(4) : error C1008: undefined variable \"bogus\"\n", line4},
+		{"AMD/Intel", "ERROR: 0:4: 'bogus' : undeclared identifier\nERROR: 1 compilation errors.\n", line4},
+		{"warning", "WARNING: 0:2: unused\n", []SourceLine{{Number: 2, Text: "out vec4 color;"}}},
+		{"repeated", "0:4(15): error: a\n0:4(20): error: b\n", line4},
+		{"out of range", "0:40(1): error: nonsense\n", nil},
+		{"no position", "error: something went wrong\n", nil},
+	}
+	for _, tt := range tests {
+		e := newStageError(src, errors.New(tt.log))
+		if !reflect.DeepEqual(e.Lines, tt.want) {
+			t.Errorf("%s: lines %q, want %q", tt.driver, e.Lines, tt.want)
+		}
+		if e.Log != tt.log {
+			t.Errorf("%s: log rewritten to %q", tt.driver, e.Log)
+		}
+	}
+}
+
+func TestStageErrorPreprocessed(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.frag": {Data: []byte("#version 330\n" +
+			"#include \"lib.glsl\"\n" +
+			"out vec4 color;\n" +
+			"void main() { color = tint(); }\n")},
+		"lib.glsl": {Data: []byte("// tint\n" +
+			"vec4 tint() { return vec4(bogus); }\n")},
+	}
+	pp, err := shader.Preprocess(fsys, "main.frag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(pp.Files, []string{"main.frag", "lib.glsl"}) {
+		t.Fatalf("files %q, want main.frag, lib.glsl", pp.Files)
+	}
+	src := source{stage: Fragment, name: "main.frag", text: pp.Text, pp: pp}
+	lib := SourceLine{File: "lib.glsl", Number: 2, Text: "vec4 tint() { return vec4(bogus); }"}
+	main := SourceLine{File: "main.frag", Number: 4, Text: "void main() { color = tint(); }"}
+	tests := []struct {
+		driver string
+		log    string
+		want   string
+		lines  []SourceLine
+	}{
+		{
+			driver: "Mesa",
+			log:    "1:2(27): error: `bogus' undeclared\n0:4(23): error: no function tint\n",
+			want:   "lib.glsl:2(27): error: `bogus' undeclared\nmain.frag:4(23): error: no function tint\n",
+			lines:  []SourceLine{lib, main},
+		},
+		{
+			driver: "NVIDIA",
+			log:    "1(2) : error C1008: undefined variable \"bogus\"\n",
+			want:   "lib.glsl(2) : error C1008: undefined variable \"bogus\"\n",
+			lines:  []SourceLine{lib},
+		},
+		{
+			driver: "AMD/Intel",
+			log:    "ERROR: 1:2: 'bogus' : undeclared identifier\nERROR: 1 compilation errors.\n",
+			want:   "ERROR: lib.glsl:2: 'bogus' : undeclared identifier\nERROR: 1 compilation errors.\n",
+			lines:  []SourceLine{lib},
+		},
+		{
+			driver: "unknown file",
+			log:    "7:2(1): error: nonsense\n",
+			want:   "7:2(1): error: nonsense\n",
+		},
+	}
+	for _, tt := range tests {
+		e := newStageError(src, errors.New(tt.log))
+		if e.Log != tt.want {
+			t.Errorf("%s: log %q, want %q", tt.driver, e.Log, tt.want)
+		}
+		if !reflect.DeepEqual(e.Lines, tt.lines) {
+			t.Errorf("%s: lines %q, want %q", tt.driver, e.Lines, tt.lines)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package glutil
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/droyo/gltut/internal/gfx"
+)
+
+// A Type is the type of a GLSL attribute or uniform, such as
+// gfx.FLOAT_VEC3.
+type Type gfx.Enum
+
+type typeInfo struct {
+	name  string
+	kind  gfx.Enum // type of one component: FLOAT, INT, UNSIGNED_INT or BOOL
+	count int      // number of components
+}
+
+var types = map[Type]typeInfo{
+	Type(gfx.FLOAT):             {"float", gfx.FLOAT, 1},
+	Type(gfx.FLOAT_VEC2):        {"vec2", gfx.FLOAT, 2},
+	Type(gfx.FLOAT_VEC3):        {"vec3", gfx.FLOAT, 3},
+	Type(gfx.FLOAT_VEC4):        {"vec4", gfx.FLOAT, 4},
+	Type(gfx.INT):               {"int", gfx.INT, 1},
+	Type(gfx.INT_VEC2):          {"ivec2", gfx.INT, 2},
+	Type(gfx.INT_VEC3):          {"ivec3", gfx.INT, 3},
+	Type(gfx.INT_VEC4):          {"ivec4", gfx.INT, 4},
+	Type(gfx.UNSIGNED_INT):      {"uint", gfx.UNSIGNED_INT, 1},
+	Type(gfx.UNSIGNED_INT_VEC2): {"uvec2", gfx.UNSIGNED_INT, 2},
+	Type(gfx.UNSIGNED_INT_VEC3): {"uvec3", gfx.UNSIGNED_INT, 3},
+	Type(gfx.UNSIGNED_INT_VEC4): {"uvec4", gfx.UNSIGNED_INT, 4},
+	Type(gfx.BOOL):              {"bool", gfx.BOOL, 1},
+	Type(gfx.BOOL_VEC2):         {"bvec2", gfx.BOOL, 2},
+	Type(gfx.BOOL_VEC3):         {"bvec3", gfx.BOOL, 3},
+	Type(gfx.BOOL_VEC4):         {"bvec4", gfx.BOOL, 4},
+	Type(gfx.FLOAT_MAT2):        {"mat2", gfx.FLOAT, 4},
+	Type(gfx.FLOAT_MAT3):        {"mat3", gfx.FLOAT, 9},
+	Type(gfx.FLOAT_MAT4):        {"mat4", gfx.FLOAT, 16},
+	Type(gfx.FLOAT_MAT2x3):      {"mat2x3", gfx.FLOAT, 6},
+	Type(gfx.FLOAT_MAT2x4):      {"mat2x4", gfx.FLOAT, 8},
+	Type(gfx.FLOAT_MAT3x2):      {"mat3x2", gfx.FLOAT, 6},
+	Type(gfx.FLOAT_MAT3x4):      {"mat3x4", gfx.FLOAT, 12},
+	Type(gfx.FLOAT_MAT4x2):      {"mat4x2", gfx.FLOAT, 8},
+	Type(gfx.FLOAT_MAT4x3):      {"mat4x3", gfx.FLOAT, 12},
+	Type(gfx.SAMPLER_2D):        {"sampler2D", gfx.INT, 1},
+}
+
+// String returns the GLSL name of t.
+func (t Type) String() string {
+	if info, ok := types[t]; ok {
+		return info.name
+	}
+	return fmt.Sprintf("type(0x%x)", uint32(t))
+}
+
+// Components returns the number of numbers in a value of type t: 3
+// for a vec3, 16 for a mat4.
+func (t Type) Components() int { return types[t].count }
+
+// Kind returns the type of each component of t: gfx.FLOAT,
+// gfx.INT, gfx.UNSIGNED_INT or gfx.BOOL. Samplers are set as ints.
+func (t Type) Kind() gfx.Enum { return types[t].kind }
+
+// IsMatrix reports whether t is a matrix type.
+func (t Type) IsMatrix() bool { return strings.HasPrefix(t.String(), "mat") }
+
+// A Variable is an active attribute or uniform of a linked program.
+// Arrays are named by their first element, as in "lights[0]", and
+// their Size is the number of elements; other variables have Size
+// 1. Uniforms in a uniform block have no location, and their
+// Location is -1.
+type Variable struct {
+	Name     string
+	Type     Type
+	Size     int
+	Location int
+}
+
+// An Interface lists the active attributes and uniforms of a linked
+// program, in the order the driver reports them. Variables that the
+// shaders declare but never use may be left out by the driver.
+type Interface struct {
+	Attribs  []Variable
+	Uniforms []Variable
+}
+
+// Inspect returns the interface of the linked program p.
+func Inspect(ctx gfx.Context, p gfx.Program) *Interface {
+	in := new(Interface)
+	for i, n := 0, ctx.GetProgramiv(p, gfx.ACTIVE_ATTRIBUTES); i < n; i++ {
+		name, size, typ := ctx.GetActiveAttrib(p, i)
+		v := Variable{Name: name, Type: Type(typ), Size: size, Location: -1}
+		if loc, err := ctx.GetAttribLocation(p, name); err == nil {
+			v.Location = int(loc)
+		}
+		in.Attribs = append(in.Attribs, v)
+	}
+	for i, n := 0, ctx.GetProgramiv(p, gfx.ACTIVE_UNIFORMS); i < n; i++ {
+		name, size, typ := ctx.GetActiveUniform(p, i)
+		v := Variable{Name: name, Type: Type(typ), Size: size, Location: -1}
+		if loc, err := ctx.GetUniformLocation(p, name); err == nil {
+			v.Location = int(loc)
+		}
+		in.Uniforms = append(in.Uniforms, v)
+	}
+	return in
+}
+
+// Interface returns the interface of the program.
+func (p *Program) Interface() *Interface { return Inspect(p.ctx, p.ID) }
+
+// Attrib returns the active attribute with the given name.
+func (in *Interface) Attrib(name string) (Variable, bool) {
+	return lookup(in.Attribs, name)
+}
+
+// Uniform returns the active uniform with the given name. An element
+// of an array, as in "lights[2]", is returned as a Variable of the
+// element's type, whose Size is the number of elements from it to
+// the end of the array. Past the first element, its Location is -1.
+func (in *Interface) Uniform(name string) (Variable, bool) {
+	return lookup(in.Uniforms, name)
+}
+
+func lookup(vars []Variable, name string) (Variable, bool) {
+	base, index := name, 0
+	if i := strings.IndexByte(name, '['); i >= 0 && strings.HasSuffix(name, "]") {
+		n, err := fmt.Sscanf(name[i:], "[%d]", &index)
+		if n != 1 || err != nil || index < 0 {
+			return Variable{}, false
+		}
+		base = name[:i]
+	}
+	for _, v := range vars {
+		vbase := strings.TrimSuffix(v.Name, "[0]")
+		if vbase != base || (index > 0 && index >= v.Size) {
+			continue
+		}
+		if base != name {
+			v.Name, v.Size = name, v.Size-index
+			if index > 0 {
+				// Only the first element's location is known.
+				v.Location = -1
+			}
+		}
+		return v, true
+	}
+	return Variable{}, false
+}
@@ -0,0 +1,106 @@
+package glutil
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/gfx/soft"
+)
+
+const reflectVS = `#version 150
+in vec4 position;
+in vec3 normal;
+uniform mat4 bones[3];
+uniform vec2 offset;
+layout(std140) uniform Camera { mat4 cameraToClip; };
+out vec3 n;
+void main() {
+	gl_Position = cameraToClip * bones[1] * (position + vec4(offset, 0, 0));
+	n = normal;
+}
+`
+
+const reflectFS = `#version 150
+in vec3 n;
+out vec4 color;
+uniform ivec2 cell;
+void main() { color = vec4(n.xy + vec2(cell), 0, 1); }
+`
+
+func TestInspect(t *testing.T) {
+	ctx := soft.New(1, 1)
+	p, err := NewProgram(ctx).
+		Vertex([]byte(reflectVS)).
+		Fragment([]byte(reflectFS)).
+		BindAttrib("normal", 3).
+		Link()
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := p.Interface()
+	loc := func(name string) int {
+		u, err := ctx.GetUniformLocation(p.ID, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return int(u)
+	}
+	tests := []struct {
+		name string
+		find func(string) (Variable, bool)
+		want Variable
+		ok   bool
+	}{
+		{"normal", in.Attrib, Variable{"normal", Type(gfx.FLOAT_VEC3), 1, 3}, true},
+		{"position", in.Attrib, Variable{"position", Type(gfx.FLOAT_VEC4), 1, 0}, true},
+		{"offset", in.Uniform, Variable{"offset", Type(gfx.FLOAT_VEC2), 1, loc("offset")}, true},
+		{"cell", in.Uniform, Variable{"cell", Type(gfx.INT_VEC2), 1, loc("cell")}, true},
+		// Arrays are named by their first element.
+		{"bones", in.Uniform, Variable{"bones[0]", Type(gfx.FLOAT_MAT4), 3, loc("bones")}, true},
+		{"bones[0]", in.Uniform, Variable{"bones[0]", Type(gfx.FLOAT_MAT4), 3, loc("bones")}, true},
+		{"bones[2]", in.Uniform, Variable{"bones[2]", Type(gfx.FLOAT_MAT4), 1, -1}, true},
+		{"bones[3]", in.Uniform, Variable{}, false},
+		{"bones[-1]", in.Uniform, Variable{}, false},
+		{"bones[x]", in.Uniform, Variable{}, false},
+		{"offset[0]", in.Uniform, Variable{"offset[0]", Type(gfx.FLOAT_VEC2), 1, loc("offset")}, true},
+		// Members of a block have no location.
+		{"cameraToClip", in.Uniform, Variable{"cameraToClip", Type(gfx.FLOAT_MAT4), 1, -1}, true},
+		{"Camera", in.Uniform, Variable{}, false},
+		{"position", in.Uniform, Variable{}, false},
+	}
+	for _, tt := range tests {
+		v, ok := tt.find(tt.name)
+		if ok != tt.ok || v != tt.want {
+			t.Errorf("%s: found %+v, %v; want %+v, %v", tt.name, v, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestType(t *testing.T) {
+	tests := []struct {
+		typ        gfx.Enum
+		name       string
+		components int
+		kind       gfx.Enum
+		matrix     bool
+	}{
+		{gfx.FLOAT, "float", 1, gfx.FLOAT, false},
+		{gfx.FLOAT_VEC3, "vec3", 3, gfx.FLOAT, false},
+		{gfx.INT_VEC2, "ivec2", 2, gfx.INT, false},
+		{gfx.UNSIGNED_INT_VEC4, "uvec4", 4, gfx.UNSIGNED_INT, false},
+		{gfx.BOOL, "bool", 1, gfx.BOOL, false},
+		{gfx.FLOAT_MAT4, "mat4", 16, gfx.FLOAT, true},
+		{gfx.FLOAT_MAT2x3, "mat2x3", 6, gfx.FLOAT, true},
+		{gfx.SAMPLER_2D, "sampler2D", 1, gfx.INT, false},
+		{0x1234, "type(0x1234)", 0, 0, false},
+	}
+	for _, tt := range tests {
+		typ := Type(tt.typ)
+		got := []interface{}{typ.String(), typ.Components(), typ.Kind(), typ.IsMatrix()}
+		want := []interface{}{tt.name, tt.components, tt.kind, tt.matrix}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("type 0x%x: name, components, kind and matrix are %v, want %v", tt.typ, got, want)
+		}
+	}
+}
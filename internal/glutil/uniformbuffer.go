@@ -0,0 +1,139 @@
+package glutil
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/std140"
+)
+
+// A UniformBuffer holds the contents of a uniform block, laid out
+// by the std140 rules from a Go struct. The buffer is bound to a
+// binding point, and any number of programs can read it by binding
+// their copy of the block to the same point, so that data shared
+// by several programs, such as a projection matrix, is uploaded
+// once for all of them.
+//
+// The GLSL block must be declared with layout(std140), with members
+// matching the fields of the struct in order; see std140.Of for how
+// Go types map to GLSL types.
+type UniformBuffer struct {
+	ID      gfx.Buffer
+	Binding int
+
+	ctx    gfx.Context
+	typ    reflect.Type
+	layout *std140.Type
+	data   []byte // contents of the buffer, as last uploaded
+}
+
+// NewUniformBuffer creates a buffer holding v, which must be a
+// struct or a pointer to one, and binds it to the uniform buffer
+// binding point binding. The caller is responsible for calling
+// Delete on the returned UniformBuffer.
+func NewUniformBuffer(ctx gfx.Context, binding int, v interface{}) (*UniformBuffer, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("glutil: uniform buffer of %T; need a struct", v)
+	}
+	layout, err := std140.Of(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, layout.Size())
+	std140.Put(data, layout, rv)
+
+	u := &UniformBuffer{
+		Binding: binding,
+		ctx:     ctx,
+		typ:     rv.Type(),
+		layout:  layout,
+		data:    data,
+	}
+	u.ID = ctx.GenBuffers(1)[0]
+	ctx.BindBuffer(gfx.UNIFORM_BUFFER, u.ID)
+	if err := ctx.BufferData(gfx.UNIFORM_BUFFER, data, gfx.DYNAMIC_DRAW); err != nil {
+		u.Delete()
+		return nil, err
+	}
+	ctx.BindBufferBase(gfx.UNIFORM_BUFFER, binding, u.ID)
+	return u, nil
+}
+
+// Bind connects the uniform block named block in p to the buffer.
+// It fails if p has no such block, or if the block's size does not
+// match the Go struct, which usually means the block is missing
+// layout(std140) or its members differ from the struct's fields.
+func (u *UniformBuffer) Bind(p *Program, block string) error {
+	idx, err := u.ctx.GetUniformBlockIndex(p.ID, block)
+	if err != nil {
+		return fmt.Errorf("glutil: %v", err)
+	}
+	// Drivers may round the size of a block up to a multiple
+	// of a vec4.
+	size := u.ctx.GetActiveUniformBlockiv(p.ID, idx, gfx.UNIFORM_BLOCK_DATA_SIZE)
+	if size < u.layout.Size() || size-u.layout.Size() >= 16 {
+		return fmt.Errorf("glutil: uniform block %s of program %d is %d bytes, but %s is %d bytes in the std140 layout",
+			block, p.ID, size, u.typ, u.layout.Size())
+	}
+	u.ctx.UniformBlockBinding(p.ID, idx, u.Binding)
+	return nil
+}
+
+// Update sets the contents of the buffer to v, which must have the
+// same type as the value passed to NewUniformBuffer. Only the
+// top-level fields that changed are uploaded, as one BufferSubData
+// call for each run of adjacent changed fields.
+func (u *UniformBuffer) Update(v interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Type() != u.typ {
+		return fmt.Errorf("glutil: cannot update uniform buffer of %s with %T", u.typ, v)
+	}
+	next := make([]byte, len(u.data))
+	std140.Put(next, u.layout, rv)
+
+	bound := false
+	for _, r := range u.dirty(next) {
+		if !bound {
+			u.ctx.BindBuffer(gfx.UNIFORM_BUFFER, u.ID)
+			bound = true
+		}
+		if err := u.ctx.BufferSubData(gfx.UNIFORM_BUFFER, r[0], next[r[0]:r[1]]); err != nil {
+			return err
+		}
+	}
+	u.data = next
+	return nil
+}
+
+// dirty returns the byte ranges of the fields that differ between
+// the uploaded contents and next. A range spans the padding between
+// two dirty fields, so that they are uploaded together.
+func (u *UniformBuffer) dirty(next []byte) [][2]int {
+	var ranges [][2]int
+	prev := -1 // index of the last field, if it was dirty
+	for i, f := range u.layout.Fields {
+		start, end := f.Offset, f.Offset+f.Type.Size()
+		if bytes.Equal(u.data[start:end], next[start:end]) {
+			continue
+		}
+		if prev == i-1 && len(ranges) > 0 {
+			ranges[len(ranges)-1][1] = end
+		} else {
+			ranges = append(ranges, [2]int{start, end})
+		}
+		prev = i
+	}
+	return ranges
+}
+
+// Delete releases the buffer. It is safe to call Delete more than
+// once.
+func (u *UniformBuffer) Delete() {
+	if u.ID != 0 {
+		u.ctx.DeleteBuffers([]gfx.Buffer{u.ID})
+		u.ID = 0
+	}
+}
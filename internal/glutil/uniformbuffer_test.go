@@ -0,0 +1,97 @@
+package glutil
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/gfx/soft"
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+// recorder records the byte range of every BufferSubData call.
+type recorder struct {
+	*soft.Context
+	ranges [][2]int
+}
+
+func (r *recorder) BufferSubData(target gfx.Enum, offset int, data interface{}) error {
+	r.ranges = append(r.ranges, [2]int{offset, offset + len(data.([]byte))})
+	return r.Context.BufferSubData(target, offset, data)
+}
+
+// globals is laid out as
+//
+//	mat4  Proj;   //  0..64
+//	vec3  Tint;   // 64..76
+//	float Scale;  // 76..80
+//	bool  Flag;   // 80..84
+//	vec2  Off;    // 88..96
+type globals struct {
+	Proj  vmath.Mat4
+	Tint  [3]float32
+	Scale float32
+	Flag  bool
+	Off   [2]float32
+}
+
+func TestUniformBufferUpdate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(g *globals)
+		want   [][2]int
+	}{
+		{"nothing", func(g *globals) {}, nil},
+		{"one element of mat4", func(g *globals) { g.Proj[5] = 2 }, [][2]int{{0, 64}}},
+		{"last field", func(g *globals) { g.Off[1] = 1 }, [][2]int{{88, 96}}},
+		{"separate fields", func(g *globals) { g.Scale, g.Off[0] = 2, 3 }, [][2]int{{76, 80}, {88, 96}}},
+		{"adjacent fields", func(g *globals) { g.Tint[0], g.Scale, g.Flag = 1, 2, true }, [][2]int{{64, 84}}},
+		{"adjacent across padding", func(g *globals) { g.Flag, g.Off[0] = true, 1 }, [][2]int{{80, 96}}},
+		{"everything", func(g *globals) {
+			g.Proj[0], g.Tint[2], g.Scale, g.Flag, g.Off[1] = 2, 1, 2, true, 1
+		}, [][2]int{{0, 96}}},
+	}
+	for _, tt := range tests {
+		r := &recorder{Context: soft.New(1, 1)}
+		g := globals{Proj: vmath.Ident4(), Scale: 1}
+		u, err := NewUniformBuffer(r, 0, &g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tt.change(&g)
+		if err := u.Update(&g); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(r.ranges, tt.want) {
+			t.Errorf("%s: uploaded %v, want %v", tt.name, r.ranges, tt.want)
+		}
+
+		// The second update has nothing new to upload.
+		r.ranges = nil
+		if err := u.Update(g); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if len(r.ranges) != 0 {
+			t.Errorf("%s: repeated Update uploaded %v", tt.name, r.ranges)
+		}
+		u.Delete()
+	}
+}
+
+func TestUniformBufferErrors(t *testing.T) {
+	ctx := soft.New(1, 1)
+	if _, err := NewUniformBuffer(ctx, 0, 3); err == nil {
+		t.Error("NewUniformBuffer of an int succeeded")
+	}
+	if _, err := NewUniformBuffer(ctx, 0, struct{ X float64 }{}); err == nil {
+		t.Error("NewUniformBuffer of a float64 field succeeded")
+	}
+	u, err := NewUniformBuffer(ctx, 0, globals{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer u.Delete()
+	if err := u.Update(struct{ X float32 }{}); err == nil {
+		t.Error("Update with a different type succeeded")
+	}
+}
@@ -0,0 +1,104 @@
+// Package golden compares rendered frames against reference
+// ("golden") images stored as PNG files.
+package golden
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+)
+
+// Compare compares got against want pixel by pixel. A pixel differs
+// if any of its channels differ by more than tolerance, on a scale of
+// 0 to 255. Compare returns the number of differing pixels, and an
+// image of want in grey with the differing pixels marked in red.
+// It returns an error if the images are not the same size.
+func Compare(got, want image.Image, tolerance int) (int, *image.RGBA, error) {
+	gb, wb := got.Bounds(), want.Bounds()
+	if gb.Dx() != wb.Dx() || gb.Dy() != wb.Dy() {
+		return 0, nil, fmt.Errorf("image is %dx%d, want %dx%d", gb.Dx(), gb.Dy(), wb.Dx(), wb.Dy())
+	}
+	bad := 0
+	diff := image.NewRGBA(image.Rect(0, 0, wb.Dx(), wb.Dy()))
+	for y := 0; y < wb.Dy(); y++ {
+		for x := 0; x < wb.Dx(); x++ {
+			g := color.NRGBAModel.Convert(got.At(gb.Min.X+x, gb.Min.Y+y)).(color.NRGBA)
+			w := color.NRGBAModel.Convert(want.At(wb.Min.X+x, wb.Min.Y+y)).(color.NRGBA)
+			if differs(g, w, tolerance) {
+				bad++
+				diff.SetRGBA(x, y, color.RGBA{255, 0, 0, 255})
+				continue
+			}
+			// dim the matching pixels so that the differences stand out
+			v := uint8((uint(w.R) + uint(w.G) + uint(w.B)) / 3 / 4)
+			diff.SetRGBA(x, y, color.RGBA{v, v, v, 255})
+		}
+	}
+	return bad, diff, nil
+}
+
+func differs(a, b color.NRGBA, tolerance int) bool {
+	return absDiff(a.R, b.R) > tolerance ||
+		absDiff(a.G, b.G) > tolerance ||
+		absDiff(a.B, b.B) > tolerance ||
+		absDiff(a.A, b.A) > tolerance
+}
+
+func absDiff(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
+
+// Load reads a PNG image from the named file.
+func Load(name string) (image.Image, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return img, nil
+}
+
+// Save writes img to the named file as a PNG image.
+func Save(name string, img image.Image) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	return f.Close()
+}
+
+// Side returns an image with a, b and diff placed side by side, for
+// viewing a failed comparison at a glance.
+func Side(a, b, diff image.Image) *image.RGBA {
+	ab, bb, db := a.Bounds(), b.Bounds(), diff.Bounds()
+	h := ab.Dy()
+	if bb.Dy() > h {
+		h = bb.Dy()
+	}
+	if db.Dy() > h {
+		h = db.Dy()
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, ab.Dx()+bb.Dx()+db.Dx(), h))
+	draw.Draw(dst, dst.Bounds(), image.Black, image.ZP, draw.Src)
+	x := 0
+	for _, img := range []image.Image{a, b, diff} {
+		r := img.Bounds()
+		draw.Draw(dst, image.Rect(x, 0, x+r.Dx(), r.Dy()), img, r.Min, draw.Over)
+		x += r.Dx()
+	}
+	return dst
+}
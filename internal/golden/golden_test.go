@@ -0,0 +1,29 @@
+package golden_test
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/droyo/gltut/internal/golden"
+	"github.com/droyo/gltut/internal/tutorial"
+	_ "github.com/droyo/gltut/internal/tutorial/all"
+)
+
+var update = flag.Bool("update", false, "overwrite the golden images with the current output")
+
+const dir = "../../testdata/golden"
+
+func TestTutorials(t *testing.T) {
+	list := tutorial.All()
+	if len(list) == 0 {
+		t.Fatal("no tutorials registered")
+	}
+	for _, tut := range list {
+		tut := tut
+		t.Run(tut.ID(), func(t *testing.T) {
+			if err := golden.Check(tut, dir, golden.Tolerance, *update); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
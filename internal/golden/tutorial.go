@@ -0,0 +1,62 @@
+package golden
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/droyo/gltut/internal/tutorial"
+)
+
+// Every tutorial is drawn at this size, this long after it starts.
+// Changing these invalidates every golden image.
+const (
+	Width   = 250
+	Height  = 250
+	Elapsed = 1500 * time.Millisecond
+)
+
+// Tolerance is the default largest allowed difference in a color
+// channel, from 0 to 255.
+const Tolerance = 2
+
+// Path returns the name of the golden image for t in dir.
+func Path(dir string, t *tutorial.Tutorial) string {
+	return filepath.Join(dir, strings.Replace(t.ID(), "/", "-", -1)+".png")
+}
+
+// Check draws t with the software renderer and compares the frame
+// against its golden image in dir. If they differ, Check writes an
+// image with the new frame, the golden image and the differing
+// pixels side by side next to the golden image, with the extension
+// .diff.png, and returns an error naming it. If update is true,
+// Check overwrites the golden image with the new frame instead.
+func Check(t *tutorial.Tutorial, dir string, tolerance int, update bool) error {
+	name := Path(dir, t)
+	diffName := strings.TrimSuffix(name, ".png") + ".diff.png"
+	got, err := t.Image(Width, Height, Elapsed)
+	if err != nil {
+		return err
+	}
+	if update {
+		return Save(name, got)
+	}
+	want, err := Load(name)
+	if err != nil {
+		return err
+	}
+	bad, diff, err := Compare(got, want, tolerance)
+	if err != nil {
+		return err
+	}
+	if bad == 0 {
+		os.Remove(diffName)
+		return nil
+	}
+	if err := Save(diffName, Side(got, want, diff)); err != nil {
+		return err
+	}
+	return fmt.Errorf("%d pixels differ; see %s", bad, diffName)
+}
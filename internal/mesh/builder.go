@@ -0,0 +1,126 @@
+package mesh
+
+import (
+	"math"
+
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+// A ColorVertex is a vertex of a Builder. It feeds the "position"
+// and "color" inputs of a program.
+type ColorVertex struct {
+	Position vmath.Vec3 `gl:"position"`
+	Color    vmath.Vec4 `gl:"color"`
+}
+
+// colorLayout is the layout of a []ColorVertex, read from its tags.
+var colorLayout = func() Layout {
+	l, err := LayoutOf(ColorVertex{})
+	if err != nil {
+		panic(err)
+	}
+	return l
+}()
+
+// A Builder collects the triangles of solid-colored objects for
+// the tutorials that come before lighting. Each vertex is shaded by
+// how much its normal faces Light, so that the sides of an object
+// can be told apart.
+//
+// A Builder can hold several meshes in one set of buffers: End
+// finishes a mesh as a named submesh whose indices count from its
+// own first vertex, to be drawn with DrawElementsBaseVertex.
+type Builder struct {
+	Light vmath.Vec3 // unit vector pointing towards the light
+
+	Vertices  []ColorVertex
+	Indices   []uint16
+	Submeshes []Submesh
+
+	normals []vmath.Vec3
+	base    int // first vertex of the current submesh
+	first   int // first index of the current submesh
+}
+
+// Vertex adds a vertex with the given position, normal and color,
+// and returns its index in the current submesh.
+func (b *Builder) Vertex(p, n, color vmath.Vec3) uint16 {
+	shade := 0.55 + 0.45*float32(math.Max(0, float64(n.Dot(b.Light))))
+	c := color.Mul(shade)
+	b.Vertices = append(b.Vertices, ColorVertex{p, vmath.Vec4{c[0], c[1], c[2], 1}})
+	b.normals = append(b.normals, n)
+	return uint16(len(b.Vertices) - 1 - b.base)
+}
+
+// Tri adds a triangle, winding it so that its front faces the way
+// the normals of its vertices point. Front faces are clockwise, to
+// match the tutorials' FrontFace(CW).
+func (b *Builder) Tri(i, j, k uint16) {
+	at := func(i uint16) vmath.Vec3 { return b.Vertices[b.base+int(i)].Position }
+	n := func(i uint16) vmath.Vec3 { return b.normals[b.base+int(i)] }
+	out := n(i).Add(n(j)).Add(n(k))
+	ccw := at(j).Sub(at(i)).Cross(at(k).Sub(at(i)))
+	if ccw.Dot(out) > 0 {
+		j, k = k, j
+	}
+	b.Indices = append(b.Indices, i, j, k)
+}
+
+// Quad adds the quadrilateral i, j, k, l as two triangles.
+func (b *Builder) Quad(i, j, k, l uint16) {
+	b.Tri(i, j, k)
+	b.Tri(i, k, l)
+}
+
+// Box adds a box with the given center and size, with a separate
+// set of vertices for each face.
+func (b *Builder) Box(center, size, color vmath.Vec3) {
+	scale := func(v vmath.Vec3) vmath.Vec3 {
+		return vmath.Vec3{v[0] * size[0], v[1] * size[1], v[2] * size[2]}
+	}
+	axes := [3]vmath.Vec3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+	for i, n := range axes {
+		u, v := axes[(i+1)%3], axes[(i+2)%3]
+		for _, n := range []vmath.Vec3{n, n.Neg()} {
+			c := center.Add(scale(n).Mul(0.5))
+			p := func(su, sv float32) uint16 {
+				d := scale(u).Mul(su / 2).Add(scale(v).Mul(sv / 2))
+				return b.Vertex(c.Add(d), n, color)
+			}
+			b.Quad(p(-1, -1), p(1, -1), p(1, 1), p(-1, 1))
+		}
+	}
+}
+
+// End finishes the current submesh, giving it name, adds it to
+// Submeshes and returns it. Vertices added afterwards start a new
+// submesh.
+func (b *Builder) End(name string) Submesh {
+	s := Submesh{
+		Name:       name,
+		Mode:       gfx.TRIANGLES,
+		Indexed:    true,
+		IndexType:  gfx.Uint16,
+		First:      b.first,
+		Count:      len(b.Indices) - b.first,
+		BaseVertex: b.base,
+	}
+	b.Submeshes = append(b.Submeshes, s)
+	b.base, b.first = len(b.Vertices), len(b.Indices)
+	return s
+}
+
+// Data returns the submeshes built so far as mesh data, ending the
+// current submesh first, without a name, if it has any triangles.
+func (b *Builder) Data() *Data {
+	if len(b.Indices) > b.first {
+		b.End("")
+	}
+	return &Data{
+		Layout:    colorLayout,
+		Vertices:  b.Vertices,
+		Indices:   b.Indices,
+		Submeshes: b.Submeshes,
+	}
+}
@@ -0,0 +1,63 @@
+package mesh
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+func TestBuilder(t *testing.T) {
+	b := &Builder{Light: vmath.Vec3{0, 1, 0}}
+	red := vmath.Vec3{1, 0, 0}
+	b.Box(vmath.Vec3{1, 2, 3}, vmath.Vec3{2, 4, 6}, red)
+	box := b.End("box")
+	up := vmath.Vec3{0, 1, 0}
+	b.Tri(
+		b.Vertex(vmath.Vec3{0, 0, 0}, up, red),
+		b.Vertex(vmath.Vec3{1, 0, 0}, up, red),
+		b.Vertex(vmath.Vec3{0, 0, 1}, up, red))
+	d := b.Data()
+
+	if box.Name != "box" || box.First != 0 || box.Count != 36 || box.BaseVertex != 0 {
+		t.Errorf("box submesh is %+v, want box with 36 indices from 0", box)
+	}
+	if want := Interleaved(Float("position", 3), Float("color", 4)); !reflect.DeepEqual(d.Layout, want) {
+		t.Errorf("Data has layout %+v, want %+v", d.Layout, want)
+	}
+	if len(d.Submeshes) != 2 {
+		t.Fatalf("Data has %d submeshes, want 2", len(d.Submeshes))
+	}
+	if tri := d.Submeshes[1]; tri.First != 36 || tri.Count != 3 || tri.BaseVertex != 24 {
+		t.Errorf("triangle submesh is %+v, want 3 indices from 36, base vertex 24", tri)
+	}
+	if got := b.Indices[36:]; got[0] > 2 || got[1] > 2 || got[2] > 2 {
+		t.Errorf("triangle indices %v are not relative to its base vertex", got)
+	}
+
+	for _, s := range d.Submeshes {
+		for i := s.First; i < s.First+s.Count; i += 3 {
+			var p [3]vmath.Vec3
+			var out vmath.Vec3
+			for k := range p {
+				v := s.BaseVertex + int(b.Indices[i+k])
+				p[k] = b.Vertices[v].Position
+				out = out.Add(b.normals[v])
+			}
+			// Clockwise seen from outside.
+			if n := p[1].Sub(p[0]).Cross(p[2].Sub(p[0])); n.Dot(out) >= 0 {
+				t.Errorf("triangle %v is not clockwise seen along %v", p, out)
+			}
+		}
+	}
+
+	for i, v := range b.Vertices {
+		want := float32(0.55)
+		if b.normals[i] == up {
+			want = 1
+		}
+		if v.Color != (vmath.Vec4{want, 0, 0, 1}) {
+			t.Errorf("vertex %d with normal %v has color %v, want red shaded by %v", i, b.normals[i], v.Color, want)
+		}
+	}
+}
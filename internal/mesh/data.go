@@ -0,0 +1,142 @@
+// Package mesh holds vertex data and the commands that draw it,
+// and uploads them to vertex and index buffers. Meshes can be read
+// from the XML format of the original arcsynthesis tutorials, and
+// from Wavefront OBJ files.
+package mesh
+
+import (
+	"fmt"
+
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+// An Attrib describes one vertex attribute in a vertex buffer.
+type Attrib struct {
+	// Name is the name of the vertex shader input the attribute
+	// feeds. If it is empty, the attribute feeds input Index.
+	Name  string
+	Index int
+
+	Type       gfx.Type // type of each component
+	Size       int      // number of components, 1 to 4
+	Normalized bool     // integers are mapped to [0, 1] or [-1, 1]
+
+	Offset int // offset of the first vertex's attribute, in bytes
+	Stride int // bytes from one vertex to the next; 0 if tightly packed
+}
+
+// Float returns a float attribute with size components, feeding
+// the named input. Its offset is set by Interleaved or Sequential.
+func Float(name string, size int) Attrib {
+	return Attrib{Name: name, Type: gfx.Float32, Size: size}
+}
+
+func (a Attrib) String() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return fmt.Sprintf("attribute %d", a.Index)
+}
+
+// size returns the number of bytes of one vertex's attribute.
+func (a Attrib) size() int { return a.Size * a.Type.Size() }
+
+// A Layout describes where each attribute is in a vertex buffer.
+type Layout []Attrib
+
+// Interleaved lays out the attributes one after another within each
+// vertex, each aligned to 4 bytes, so that the data of a vertex is
+// contiguous.
+func Interleaved(attribs ...Attrib) Layout {
+	l := make(Layout, len(attribs))
+	stride := 0
+	for i, a := range attribs {
+		a.Offset = stride
+		stride += align4(a.size())
+		l[i] = a
+	}
+	for i := range l {
+		l[i].Stride = stride
+	}
+	return l
+}
+
+// Sequential lays out the attributes of n vertices one array after
+// another: every vertex's first attribute, then every vertex's
+// second, and so on. Most of the early tutorials store their
+// positions and colors this way.
+func Sequential(n int, attribs ...Attrib) Layout {
+	l := make(Layout, len(attribs))
+	off := 0
+	for i, a := range attribs {
+		a.Offset, a.Stride = off, 0
+		off += align4(n * a.size())
+		l[i] = a
+	}
+	return l
+}
+
+func align4(n int) int { return (n + 3) &^ 3 }
+
+// A Submesh is a draw command for part of a mesh. If it is indexed,
+// it draws Count indices starting from index First, with BaseVertex
+// added to each; otherwise, it draws Count vertices starting from
+// vertex First.
+type Submesh struct {
+	Name       string
+	Material   string // key in Data.Materials, if any
+	Mode       gfx.Enum
+	Indexed    bool
+	IndexType  gfx.Type // if zero, set from the type of Data.Indices
+	First      int
+	Count      int
+	BaseVertex int
+}
+
+// Data is a mesh in memory.
+//
+// Vertices holds the vertex data, laid out as described by Layout,
+// as a []byte or a slice of numbers such as a []float32. Indices
+// holds the index arrays of the indexed submeshes, if any. When it
+// is a []uint8, []uint16 or []uint32, submeshes that leave their
+// IndexType unset take it from the type of the slice.
+//
+// VAOs names subsets of the attributes, by index. Drawing a mesh
+// through a named VAO feeds only those attributes to the program,
+// so that one mesh can serve programs that want, say, colors or
+// normals but not both.
+//
+// Materials holds the materials named by the submeshes.
+type Data struct {
+	Layout    Layout
+	Vertices  interface{}
+	Indices   interface{}
+	Submeshes []Submesh
+	VAOs      map[string][]int
+	Materials map[string]*Material
+}
+
+// A Material describes the surface of a submesh. Colors are linear
+// RGB, from 0 to 1.
+type Material struct {
+	Ambient   vmath.Vec3
+	Diffuse   vmath.Vec3
+	Specular  vmath.Vec3
+	Shininess float32 // specular exponent
+	Alpha     float32 // opacity; 1 is opaque
+	Texture   string  // file name of the diffuse texture, if any
+}
+
+// indexType returns the index type of a slice of indices.
+func indexType(indices interface{}) (gfx.Type, bool) {
+	switch indices.(type) {
+	case []uint8:
+		return gfx.Uint8, true
+	case []uint16:
+		return gfx.Uint16, true
+	case []uint32:
+		return gfx.Uint32, true
+	}
+	return 0, false
+}
@@ -0,0 +1,118 @@
+package mesh
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/droyo/gltut/internal/gfx"
+)
+
+var componentTypes = map[reflect.Kind]gfx.Type{
+	reflect.Float32: gfx.Float32,
+	reflect.Int8:    gfx.Int8,
+	reflect.Uint8:   gfx.Uint8,
+	reflect.Int16:   gfx.Int16,
+	reflect.Uint16:  gfx.Uint16,
+	reflect.Int32:   gfx.Int32,
+	reflect.Uint32:  gfx.Uint32,
+}
+
+// LayoutOf returns the layout of a vertex struct, so that a slice
+// of them can be used as the vertices of a mesh. vertex may be a
+// struct, a pointer to one, or a slice of them.
+//
+// Each field with a gl tag is an attribute feeding the vertex shader
+// input named by the tag. The field must be a number or an array of
+// 1 to 4 numbers, of type float32 or a fixed-size integer of at most
+// 32 bits. The option "normalized" maps integers to [0, 1] or
+// [-1, 1]. For example,
+//
+//	type vertex struct {
+//		Pos   vmath.Vec3 `gl:"position"`
+//		Color [4]uint8   `gl:"color,normalized"`
+//	}
+//
+// Fields without a tag are kept in the buffer, but not fed to the
+// program. The struct must not have padding between fields; use
+// blank fields to align them instead.
+func LayoutOf(vertex interface{}) (Layout, error) {
+	t := reflect.TypeOf(vertex)
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("mesh: vertex type %T is not a struct", vertex)
+	}
+	if n := binary.Size(reflect.Zero(t).Interface()); n != int(t.Size()) {
+		return nil, fmt.Errorf("mesh: vertex type %v has padding or fields of variable size", t)
+	}
+	var layout Layout
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("gl")
+		if !ok || tag == "-" {
+			continue
+		}
+		a, err := fieldAttrib(f, tag)
+		if err != nil {
+			return nil, fmt.Errorf("mesh: %v.%s: %v", t, f.Name, err)
+		}
+		a.Stride = int(t.Size())
+		layout = append(layout, a)
+	}
+	if len(layout) == 0 {
+		return nil, fmt.Errorf("mesh: vertex type %v has no fields with a gl tag", t)
+	}
+	return layout, nil
+}
+
+func fieldAttrib(f reflect.StructField, tag string) (Attrib, error) {
+	opts := strings.Split(tag, ",")
+	a := Attrib{Name: opts[0], Offset: int(f.Offset), Size: 1}
+	if a.Name == "" {
+		return a, fmt.Errorf("gl tag has no input name")
+	}
+	for _, opt := range opts[1:] {
+		switch opt {
+		case "normalized":
+			a.Normalized = true
+		default:
+			return a, fmt.Errorf("unknown gl tag option %q", opt)
+		}
+	}
+	ft := f.Type
+	if ft.Kind() == reflect.Array {
+		a.Size = ft.Len()
+		ft = ft.Elem()
+	}
+	typ, ok := componentTypes[ft.Kind()]
+	if !ok || a.Size < 1 || a.Size > 4 {
+		return a, fmt.Errorf("type %v is not a number or an array of 1 to 4 numbers", f.Type)
+	}
+	if a.Normalized && typ == gfx.Float32 {
+		return a, fmt.Errorf("float attributes cannot be normalized")
+	}
+	a.Type = typ
+	return a, nil
+}
+
+// describe names the attribute a of vertices in errors: by the
+// struct field it was read from, if vertices is a slice of vertex
+// structs, or else by the input it feeds.
+func describe(vertices interface{}, a Attrib) string {
+	t := reflect.TypeOf(vertices)
+	if t == nil || t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Struct {
+		return a.String()
+	}
+	t = t.Elem()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("gl"), ",")[0]
+		if name == a.Name && int(f.Offset) == a.Offset {
+			return fmt.Sprintf("%v.%s", t, f.Name)
+		}
+	}
+	return a.String()
+}
@@ -0,0 +1,278 @@
+package mesh
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/glutil"
+)
+
+// A Mesh is mesh data uploaded to a rendering context. It owns a
+// vertex buffer, an index buffer if the mesh has indexed submeshes,
+// and a vertex array object for the whole mesh and for each of its
+// named VAOs, all recorded once by New. Call Close to release them.
+type Mesh struct {
+	ctx       gfx.Context
+	buffers   []gfx.Buffer
+	vao       gfx.VertexArray
+	named     map[string]gfx.VertexArray
+	submeshes []Submesh
+}
+
+// New uploads d to ctx. Attributes with a name feed the vertex
+// shader input of that name in prog, and it is an error if prog
+// has no such input, or if the input is not a float, vector or
+// scalar, with room for the attribute's components; the others feed
+// the input at their index. prog may be nil if no attribute has a
+// name.
+//
+// The mesh keeps the locations it finds, so it should only be drawn
+// with prog, or with programs whose inputs have the same locations.
+func New(ctx gfx.Context, prog *glutil.Program, d *Data) (*Mesh, error) {
+	layout, err := resolve(ctx, prog, d)
+	if err != nil {
+		return nil, err
+	}
+	submeshes, err := submeshes(d)
+	if err != nil {
+		return nil, err
+	}
+	m := &Mesh{
+		ctx:       ctx,
+		named:     make(map[string]gfx.VertexArray),
+		submeshes: submeshes,
+	}
+	n := 1
+	if d.Indices != nil {
+		n = 2
+	}
+	m.buffers = ctx.GenBuffers(n)
+	ctx.BindBuffer(gfx.ARRAY_BUFFER, m.buffers[0])
+	err = ctx.BufferData(gfx.ARRAY_BUFFER, d.Vertices, gfx.STATIC_DRAW)
+	if err == nil && n == 2 {
+		ctx.BindBuffer(gfx.ELEMENT_ARRAY_BUFFER, m.buffers[1])
+		err = ctx.BufferData(gfx.ELEMENT_ARRAY_BUFFER, d.Indices, gfx.STATIC_DRAW)
+	}
+	ctx.BindBuffer(gfx.ARRAY_BUFFER, 0)
+	if err != nil {
+		ctx.DeleteBuffers(m.buffers)
+		return nil, fmt.Errorf("mesh: %v", err)
+	}
+
+	m.vao = m.vertexArray(layout)
+
+	// Sorted, so that names are assigned in the same order
+	// every time.
+	var names []string
+	for name := range d.VAOs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var subset Layout
+		for _, index := range d.VAOs[name] {
+			a, ok := find(layout, index)
+			if !ok {
+				m.Close()
+				return nil, fmt.Errorf("mesh: VAO %q: no attribute with index %d", name, index)
+			}
+			subset = append(subset, a)
+		}
+		m.named[name] = m.vertexArray(subset)
+	}
+	ctx.BindVertexArray(0)
+	return m, nil
+}
+
+// resolve returns a copy of the layout of d with the index of each
+// named attribute set to the location of its input in prog.
+func resolve(ctx gfx.Context, prog *glutil.Program, d *Data) (Layout, error) {
+	layout := d.Layout
+	if len(layout) == 0 {
+		return nil, fmt.Errorf("mesh: no attributes")
+	}
+	resolved := make(Layout, len(layout))
+	for i, a := range layout {
+		if a.Name != "" {
+			if prog == nil {
+				return nil, fmt.Errorf("mesh: attribute %q needs a program to find its location", a.Name)
+			}
+			loc, err := ctx.GetAttribLocation(prog.ID, a.Name)
+			if err != nil {
+				return nil, fmt.Errorf("mesh: vertex input %q: %v", a.Name, err)
+			}
+			if v, ok := input(ctx, prog.ID, a.Name); ok {
+				if err := fits(a, v); err != nil {
+					return nil, fmt.Errorf("mesh: %s: %v", describe(d.Vertices, a), err)
+				}
+			}
+			a.Index = int(loc)
+		}
+		if a.Size < 1 || a.Size > 4 {
+			return nil, fmt.Errorf("mesh: %s has invalid size %d", a, a.Size)
+		}
+		resolved[i] = a
+	}
+	return resolved, nil
+}
+
+// input returns the active vertex shader input of p with the given
+// name. It does not use glutil.Inspect, which looks up the location
+// of every uniform, so that the lookups are not mistaken for the
+// caller's by a checking context.
+func input(ctx gfx.Context, p gfx.Program, name string) (glutil.Variable, bool) {
+	for i, n := 0, ctx.GetProgramiv(p, gfx.ACTIVE_ATTRIBUTES); i < n; i++ {
+		if vname, size, typ := ctx.GetActiveAttrib(p, i); vname == name {
+			return glutil.Variable{Name: name, Type: glutil.Type(typ), Size: size, Location: -1}, true
+		}
+	}
+	return glutil.Variable{}, false
+}
+
+// fits returns an error if a cannot feed the vertex shader input v.
+// VertexAttribPointer hands the shader floats, so v must be a float
+// vector or scalar. It may have more components than a, which are
+// filled in from (0, 0, 0, 1), but not fewer.
+func fits(a Attrib, v glutil.Variable) error {
+	if v.Type.Kind() != gfx.FLOAT || v.Type.IsMatrix() {
+		return fmt.Errorf("vertex input %s has type %s, but attributes are read as floats", v.Name, v.Type)
+	}
+	if n := v.Type.Components(); a.Size > n {
+		return fmt.Errorf("%d components do not fit in vertex input %s of type %s", a.Size, v.Name, v.Type)
+	}
+	return nil
+}
+
+func find(layout Layout, index int) (Attrib, bool) {
+	for _, a := range layout {
+		if a.Index == index {
+			return a, true
+		}
+	}
+	return Attrib{}, false
+}
+
+// submeshes returns the submeshes of d with their index types set,
+// checking that each indexed submesh is within the index data.
+func submeshes(d *Data) ([]Submesh, error) {
+	if len(d.Submeshes) == 0 {
+		return nil, fmt.Errorf("mesh: no submeshes")
+	}
+	seen := make(map[string]bool)
+	list := make([]Submesh, len(d.Submeshes))
+	for i, s := range d.Submeshes {
+		if s.Name != "" {
+			if seen[s.Name] {
+				return nil, fmt.Errorf("mesh: submesh %q is defined more than once", s.Name)
+			}
+			seen[s.Name] = true
+		}
+		if s.Indexed {
+			if d.Indices == nil {
+				return nil, fmt.Errorf("mesh: submesh %q is indexed, but there are no indices", s.Name)
+			}
+			if s.IndexType == 0 {
+				t, ok := indexType(d.Indices)
+				if !ok {
+					return nil, fmt.Errorf("mesh: submesh %q needs an index type for indices of type %T",
+						s.Name, d.Indices)
+				}
+				s.IndexType = t
+			}
+			n := binary.Size(d.Indices) / s.IndexType.Size()
+			if s.First < 0 || s.Count < 0 || s.First+s.Count > n {
+				return nil, fmt.Errorf("mesh: submesh %q draws indices [%d, %d) of %d",
+					s.Name, s.First, s.First+s.Count, n)
+			}
+		}
+		list[i] = s
+	}
+	return list, nil
+}
+
+// vertexArray records a vertex array that reads the attributes of
+// layout.
+func (m *Mesh) vertexArray(layout Layout) gfx.VertexArray {
+	ctx := m.ctx
+	vao := ctx.GenVertexArrays(1)[0]
+	ctx.BindVertexArray(vao)
+	ctx.BindBuffer(gfx.ARRAY_BUFFER, m.buffers[0])
+	for _, a := range layout {
+		ctx.EnableVertexAttribArray(gfx.Attrib(a.Index))
+		ctx.VertexAttribPointer(gfx.Attrib(a.Index), a.Size, a.Type, a.Normalized, a.Stride, uintptr(a.Offset))
+	}
+	if len(m.buffers) > 1 {
+		ctx.BindBuffer(gfx.ELEMENT_ARRAY_BUFFER, m.buffers[1])
+	}
+	ctx.BindBuffer(gfx.ARRAY_BUFFER, 0)
+	return vao
+}
+
+// Draw draws every submesh with the current program.
+func (m *Mesh) Draw() {
+	m.ctx.BindVertexArray(m.vao)
+	for _, s := range m.submeshes {
+		m.draw(s)
+	}
+	m.ctx.BindVertexArray(0)
+}
+
+// DrawSubmesh draws the named submesh with the current program.
+func (m *Mesh) DrawSubmesh(name string) error {
+	for _, s := range m.submeshes {
+		if s.Name == name {
+			m.ctx.BindVertexArray(m.vao)
+			m.draw(s)
+			m.ctx.BindVertexArray(0)
+			return nil
+		}
+	}
+	return fmt.Errorf("mesh: no submesh named %q", name)
+}
+
+// DrawVAO is like Draw, but feeds the program only the attributes
+// of the named VAO.
+func (m *Mesh) DrawVAO(name string) error {
+	vao, ok := m.named[name]
+	if !ok {
+		return fmt.Errorf("mesh: no VAO named %q", name)
+	}
+	m.ctx.BindVertexArray(vao)
+	for _, s := range m.submeshes {
+		m.draw(s)
+	}
+	m.ctx.BindVertexArray(0)
+	return nil
+}
+
+func (m *Mesh) draw(s Submesh) {
+	switch {
+	case !s.Indexed:
+		m.ctx.DrawArrays(s.Mode, s.First, s.Count)
+	case s.BaseVertex != 0:
+		m.ctx.DrawElementsBaseVertex(s.Mode, s.Count, s.IndexType,
+			uintptr(s.First*s.IndexType.Size()), s.BaseVertex)
+	default:
+		m.ctx.DrawElements(s.Mode, s.Count, s.IndexType, uintptr(s.First*s.IndexType.Size()))
+	}
+}
+
+// Close releases the mesh's buffers and vertex arrays. It is safe
+// to call Close more than once, or on a nil Mesh.
+func (m *Mesh) Close() {
+	if m == nil || m.buffers == nil {
+		return
+	}
+	vaos := []gfx.VertexArray{}
+	if m.vao != 0 {
+		vaos = append(vaos, m.vao)
+	}
+	for _, vao := range m.named {
+		vaos = append(vaos, vao)
+	}
+	m.ctx.DeleteVertexArrays(vaos)
+	m.ctx.DeleteBuffers(m.buffers)
+	m.buffers, m.named, m.vao = nil, nil, 0
+}
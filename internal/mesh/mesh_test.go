@@ -0,0 +1,90 @@
+package mesh
+
+import (
+	"testing"
+
+	"github.com/droyo/gltut/internal/gfx/soft"
+	"github.com/droyo/gltut/internal/glutil"
+)
+
+const inputsVS = `#version 150
+in vec2 position;
+in vec4 color;
+in ivec2 cell;
+out vec4 c;
+void main() {
+	gl_Position = vec4(position + vec2(cell), 0, 1);
+	c = color;
+}
+`
+
+const inputsFS = `#version 150
+in vec4 c;
+out vec4 o;
+void main() { o = c; }
+`
+
+type (
+	flatVertex struct {
+		Pos   [2]float32 `gl:"position"`
+		Color [4]uint8   `gl:"color,normalized"`
+	}
+	deepVertex struct {
+		Pos   [3]float32 `gl:"position"`
+		Color [4]uint8   `gl:"color,normalized"`
+	}
+	cellVertex struct {
+		Pos  [2]float32 `gl:"position"`
+		Cell [2]int32   `gl:"cell"`
+	}
+)
+
+func TestNewInputs(t *testing.T) {
+	ctx := soft.New(1, 1)
+	prog, err := glutil.NewProgram(ctx).Vertex([]byte(inputsVS)).Fragment([]byte(inputsFS)).Link()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer prog.Delete()
+	data := func(vertices interface{}) *Data {
+		layout, err := LayoutOf(vertices)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &Data{Layout: layout, Vertices: vertices, Submeshes: []Submesh{{Count: 1}}}
+	}
+	tests := []struct {
+		name string
+		data *Data
+		want string
+	}{
+		{"matching struct", data([]flatVertex{{}}), ""},
+		// Inputs have room for fewer components.
+		{"fewer components", &Data{
+			Layout:    Interleaved(Float("position", 1), Float("color", 3)),
+			Vertices:  make([]float32, 4),
+			Submeshes: []Submesh{{Count: 1}},
+		}, ""},
+		{"too many components", data([]deepVertex{{}}),
+			"mesh: mesh.deepVertex.Pos: 3 components do not fit in vertex input position of type vec2"},
+		{"integer input", data([]cellVertex{{}}),
+			"mesh: mesh.cellVertex.Cell: vertex input cell has type ivec2, but attributes are read as floats"},
+		{"no struct", &Data{
+			Layout:    Interleaved(Float("position", 4)),
+			Vertices:  make([]float32, 4),
+			Submeshes: []Submesh{{Count: 1}},
+		}, "mesh: position: 4 components do not fit in vertex input position of type vec2"},
+	}
+	for _, tt := range tests {
+		m, err := New(ctx, prog, tt.data)
+		switch {
+		case err == nil && tt.want == "":
+			m.Close()
+		case err == nil:
+			m.Close()
+			t.Errorf("%s: no error, want %q", tt.name, tt.want)
+		case err.Error() != tt.want:
+			t.Errorf("%s: error %q, want %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,443 @@
+package mesh
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+// ReadOBJFile reads a mesh from the named Wavefront OBJ file. Its
+// material libraries are read from the same directory.
+func ReadOBJFile(name string) (*Data, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	dir := filepath.Dir(name)
+	d, err := ReadOBJ(f, func(lib string) (io.ReadCloser, error) {
+		return os.Open(filepath.Join(dir, lib))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return d, nil
+}
+
+// ReadOBJ reads a mesh in the Wavefront OBJ format. Material
+// libraries named by mtllib statements are opened with open; if
+// open is nil, they are skipped, and the mesh has no materials.
+//
+// Each distinct combination of position, texture coordinate and
+// normal becomes one vertex, with the attributes "position",
+// "normal" and "texCoord", interleaved in that order. The normal
+// and texture coordinate are left out if no face uses them, and are
+// zero for faces that do not. Polygons are split into triangles
+// around their first vertex, so they should be convex. Faces keep
+// the winding of the file, which makes front faces counter-clockwise
+// by convention.
+//
+// The faces of each group or object are a submesh with the group's
+// name, or "default" before the first group. A group that uses
+// several materials is split into submeshes named "group/material".
+// The indices are a []uint16, or a []uint32 if there are too many
+// vertices. Points, lines, and statements the reader does not
+// understand are ignored.
+func ReadOBJ(r io.Reader, open func(name string) (io.ReadCloser, error)) (*Data, error) {
+	o := &objReader{
+		name:   "default",
+		verts:  make(map[[3]int]int),
+		groups: make(map[objKey]*objGroup),
+	}
+	if open != nil {
+		o.materials = make(map[string]*Material)
+	}
+	err := scanStatements(r, func(line int, f []string) error {
+		err := o.statement(f, open)
+		if _, ok := err.(*lineError); err != nil && !ok {
+			return &lineError{line: line, err: err}
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return o.data()
+}
+
+// A lineError is a problem with one statement of an OBJ file, or of
+// the named MTL file.
+type lineError struct {
+	file string
+	line int
+	err  error
+}
+
+func (e *lineError) Error() string {
+	if e.file != "" {
+		return fmt.Sprintf("mesh: %s line %d: %v", e.file, e.line, e.err)
+	}
+	return fmt.Sprintf("mesh: line %d: %v", e.line, e.err)
+}
+
+type objKey struct{ name, material string }
+
+// An objGroup collects the triangles of one submesh.
+type objGroup struct {
+	objKey
+	indices []int
+}
+
+type objReader struct {
+	pos  []vmath.Vec3
+	tex  [][2]float32
+	norm []vmath.Vec3
+
+	// Each vertex is a tuple of indices into pos, tex and norm,
+	// with -1 for a missing texture coordinate or normal.
+	verts  map[[3]int]int
+	tuples [][3]int
+
+	name, material string
+	order          []*objGroup
+	groups         map[objKey]*objGroup
+	materials      map[string]*Material
+}
+
+func (o *objReader) statement(f []string, open func(string) (io.ReadCloser, error)) error {
+	switch f[0] {
+	case "v":
+		v, err := parseFloats(f[1:], 3, 7)
+		if err != nil {
+			return err
+		}
+		// A fourth value is a weight, and three more are a color;
+		// neither is used.
+		o.pos = append(o.pos, vmath.Vec3{v[0], v[1], v[2]})
+	case "vt":
+		v, err := parseFloats(f[1:], 1, 3)
+		if err != nil {
+			return err
+		}
+		v = append(v, 0)
+		o.tex = append(o.tex, [2]float32{v[0], v[1]})
+	case "vn":
+		v, err := parseFloats(f[1:], 3, 3)
+		if err != nil {
+			return err
+		}
+		o.norm = append(o.norm, vmath.Vec3{v[0], v[1], v[2]})
+	case "f":
+		return o.face(f[1:])
+	case "g", "o":
+		o.name = strings.Join(f[1:], " ")
+		if o.name == "" {
+			o.name = "default"
+		}
+	case "usemtl":
+		if len(f) != 2 {
+			return fmt.Errorf("usemtl needs one material name")
+		}
+		o.material = f[1]
+	case "mtllib":
+		if open == nil {
+			return nil
+		}
+		for _, lib := range f[1:] {
+			if err := o.readMTL(lib, open); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (o *objReader) face(refs []string) error {
+	if len(refs) < 3 {
+		return fmt.Errorf("face has %d vertices", len(refs))
+	}
+	vs := make([]int, len(refs))
+	for i, ref := range refs {
+		v, err := o.vertex(ref)
+		if err != nil {
+			return err
+		}
+		vs[i] = v
+	}
+	key := objKey{o.name, o.material}
+	g := o.groups[key]
+	if g == nil {
+		g = &objGroup{objKey: key}
+		o.groups[key] = g
+		o.order = append(o.order, g)
+	}
+	for i := 1; i+1 < len(vs); i++ {
+		g.indices = append(g.indices, vs[0], vs[i], vs[i+1])
+	}
+	return nil
+}
+
+// vertex returns the vertex for a face's reference of the form v,
+// v/t, v//n or v/t/n.
+func (o *objReader) vertex(ref string) (int, error) {
+	parts := strings.Split(ref, "/")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid vertex %q", ref)
+	}
+	tuple := [3]int{-1, -1, -1}
+	counts := [3]int{len(o.pos), len(o.tex), len(o.norm)}
+	for i, s := range parts {
+		if s == "" && i > 0 {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid vertex %q", ref)
+		}
+		// Negative indices count back from the latest element.
+		if n < 0 {
+			n += counts[i] + 1
+		}
+		if n < 1 || n > counts[i] {
+			return 0, fmt.Errorf("vertex %q refers to element %s of %d", ref, s, counts[i])
+		}
+		tuple[i] = n - 1
+	}
+	v, ok := o.verts[tuple]
+	if !ok {
+		v = len(o.tuples)
+		o.verts[tuple] = v
+		o.tuples = append(o.tuples, tuple)
+	}
+	return v, nil
+}
+
+func (o *objReader) data() (*Data, error) {
+	if len(o.order) == 0 {
+		return nil, fmt.Errorf("mesh: no faces")
+	}
+	var hasTex, hasNorm bool
+	for _, t := range o.tuples {
+		hasTex = hasTex || t[1] >= 0
+		hasNorm = hasNorm || t[2] >= 0
+	}
+	attribs := []Attrib{Float("position", 3)}
+	if hasNorm {
+		attribs = append(attribs, Float("normal", 3))
+	}
+	if hasTex {
+		attribs = append(attribs, Float("texCoord", 2))
+	}
+	d := &Data{Layout: Interleaved(attribs...)}
+
+	var vertices []float32
+	for _, t := range o.tuples {
+		vertices = append(vertices, o.pos[t[0]][:]...)
+		if hasNorm {
+			var n vmath.Vec3
+			if t[2] >= 0 {
+				n = o.norm[t[2]]
+			}
+			vertices = append(vertices, n[:]...)
+		}
+		if hasTex {
+			var uv [2]float32
+			if t[1] >= 0 {
+				uv = o.tex[t[1]]
+			}
+			vertices = append(vertices, uv[:]...)
+		}
+	}
+	d.Vertices = vertices
+
+	uses := make(map[string]int)
+	for _, g := range o.order {
+		uses[g.name]++
+	}
+	var indices []int
+	for _, g := range o.order {
+		s := Submesh{
+			Name:     g.name,
+			Material: g.material,
+			Mode:     gfx.TRIANGLES,
+			Indexed:  true,
+			First:    len(indices),
+			Count:    len(g.indices),
+		}
+		if uses[g.name] > 1 && g.material != "" {
+			s.Name += "/" + g.material
+		}
+		if o.materials != nil && g.material != "" && o.materials[g.material] == nil {
+			return nil, fmt.Errorf("mesh: group %q uses undefined material %q", g.name, g.material)
+		}
+		indices = append(indices, g.indices...)
+		d.Submeshes = append(d.Submeshes, s)
+	}
+	if len(o.tuples) <= 1<<16 {
+		idx := make([]uint16, len(indices))
+		for i, v := range indices {
+			idx[i] = uint16(v)
+		}
+		d.Indices = idx
+	} else {
+		idx := make([]uint32, len(indices))
+		for i, v := range indices {
+			idx[i] = uint32(v)
+		}
+		d.Indices = idx
+	}
+	if len(o.materials) > 0 {
+		d.Materials = o.materials
+	}
+	return d, nil
+}
+
+// readMTL reads the materials of a material library. Colors that a
+// material leaves out take the defaults of the MTL format.
+func (o *objReader) readMTL(name string, open func(string) (io.ReadCloser, error)) error {
+	f, err := open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var m *Material
+	return scanStatements(f, func(line int, fields []string) error {
+		err := func() error {
+			if fields[0] == "newmtl" {
+				if len(fields) != 2 {
+					return fmt.Errorf("newmtl needs one material name")
+				}
+				m = &Material{
+					Ambient:  vmath.Vec3{0.2, 0.2, 0.2},
+					Diffuse:  vmath.Vec3{0.8, 0.8, 0.8},
+					Specular: vmath.Vec3{1, 1, 1},
+					Alpha:    1,
+				}
+				o.materials[fields[1]] = m
+				return nil
+			}
+			if m == nil {
+				return fmt.Errorf("%s before newmtl", fields[0])
+			}
+			return m.statement(fields)
+		}()
+		if err != nil {
+			return &lineError{name, line, err}
+		}
+		return nil
+	})
+}
+
+func (m *Material) statement(f []string) error {
+	var err error
+	switch f[0] {
+	case "Ka":
+		m.Ambient, err = parseColor(f[1:])
+	case "Kd":
+		m.Diffuse, err = parseColor(f[1:])
+	case "Ks":
+		m.Specular, err = parseColor(f[1:])
+	case "Ns", "d", "Tr":
+		var v []float32
+		if v, err = parseFloats(f[1:], 1, 1); err != nil {
+			return err
+		}
+		switch f[0] {
+		case "Ns":
+			m.Shininess = v[0]
+		case "d":
+			m.Alpha = v[0]
+		case "Tr":
+			m.Alpha = 1 - v[0]
+		}
+	case "map_Kd":
+		// Options come before the file name.
+		if len(f) < 2 {
+			return fmt.Errorf("map_Kd needs a file name")
+		}
+		m.Texture = f[len(f)-1]
+	}
+	return err
+}
+
+// parseColor parses an RGB color, or a single value for a gray.
+func parseColor(f []string) (vmath.Vec3, error) {
+	v, err := parseFloats(f, 1, 3)
+	if err != nil {
+		return vmath.Vec3{}, err
+	}
+	switch len(v) {
+	case 1:
+		return vmath.Vec3{v[0], v[0], v[0]}, nil
+	case 3:
+		return vmath.Vec3{v[0], v[1], v[2]}, nil
+	}
+	return vmath.Vec3{}, fmt.Errorf("color has %d values", len(v))
+}
+
+// parseFloats parses between min and max numbers.
+func parseFloats(f []string, min, max int) ([]float32, error) {
+	if len(f) < min || len(f) > max {
+		if min == max {
+			return nil, fmt.Errorf("want %d numbers, have %d", min, len(f))
+		}
+		return nil, fmt.Errorf("want %d to %d numbers, have %d", min, max, len(f))
+	}
+	v := make([]float32, len(f))
+	for i, s := range f {
+		x, err := strconv.ParseFloat(s, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", s)
+		}
+		v[i] = float32(x)
+	}
+	return v, nil
+}
+
+// scanStatements calls fn with the fields of each statement in an
+// OBJ or MTL file, and the line it starts on. Comments and blank
+// lines are skipped, and lines ending in a backslash are joined to
+// the next.
+func scanStatements(r io.Reader, fn func(line int, fields []string) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	var stmt string
+	n, start := 0, 0
+	flush := func() error {
+		fields := strings.Fields(stmt)
+		stmt = ""
+		if len(fields) == 0 {
+			return nil
+		}
+		return fn(start, fields)
+	}
+	for sc.Scan() {
+		n++
+		text := sc.Text()
+		if stmt == "" {
+			start = n
+		}
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		if strings.HasSuffix(text, "\\") {
+			stmt += strings.TrimSuffix(text, "\\") + " "
+			continue
+		}
+		stmt += text
+		if err := flush(); err != nil {
+			return err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("mesh: %v", err)
+	}
+	return flush()
+}
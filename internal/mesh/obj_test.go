@@ -0,0 +1,342 @@
+package mesh
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+func TestReadOBJ(t *testing.T) {
+	tri := func(name string, first, count int) Submesh {
+		return Submesh{Name: name, Mode: gfx.TRIANGLES, Indexed: true, First: first, Count: count}
+	}
+	tests := []struct {
+		name      string
+		obj       string
+		attribs   []string // names of the layout's attributes
+		vertices  []float32
+		indices   []uint16
+		submeshes []Submesh
+	}{
+		{
+			name: "fan-triangulated pentagon",
+			obj: `v 0 0 0
+				v 1 0 0
+				v 2 1 0
+				v 1 2 0
+				v 0 1 0
+				f 1 2 3 4 5`,
+			attribs:   []string{"position"},
+			vertices:  []float32{0, 0, 0, 1, 0, 0, 2, 1, 0, 1, 2, 0, 0, 1, 0},
+			indices:   []uint16{0, 1, 2, 0, 2, 3, 0, 3, 4},
+			submeshes: []Submesh{tri("default", 0, 9)},
+		},
+		{
+			name: "negative indices",
+			obj: `v 0 0 0
+				v 1 0 0
+				v 0 1 0
+				f -3 -2 -1
+				v 1 1 0
+				f -3 -1 -2`,
+			attribs:   []string{"position"},
+			vertices:  []float32{0, 0, 0, 1, 0, 0, 0, 1, 0, 1, 1, 0},
+			indices:   []uint16{0, 1, 2, 1, 3, 2},
+			submeshes: []Submesh{tri("default", 0, 6)},
+		},
+		{
+			name: "positions and normals",
+			obj: `v 0 0 0
+				v 1 0 0
+				v 0 1 0
+				vn 0 0 1
+				vn 0 0 -1
+				f 1//1 2//1 3//1
+				f 1//2 3//2 2//2`,
+			attribs: []string{"position", "normal"},
+			vertices: []float32{
+				0, 0, 0, 0, 0, 1,
+				1, 0, 0, 0, 0, 1,
+				0, 1, 0, 0, 0, 1,
+				0, 0, 0, 0, 0, -1,
+				0, 1, 0, 0, 0, -1,
+				1, 0, 0, 0, 0, -1,
+			},
+			indices:   []uint16{0, 1, 2, 3, 4, 5},
+			submeshes: []Submesh{tri("default", 0, 6)},
+		},
+		{
+			name: "positions and texture coordinates",
+			obj: `v 0 0 0
+				v 1 0 0
+				v 0 1 0
+				vt 0 0
+				vt 1 0
+				vt 0.5
+				f 1/1 2/2 3/3
+				f 1/1 3/3 2/-1`,
+			attribs: []string{"position", "texCoord"},
+			vertices: []float32{
+				0, 0, 0, 0, 0,
+				1, 0, 0, 1, 0,
+				0, 1, 0, 0.5, 0,
+				1, 0, 0, 0.5, 0,
+			},
+			indices:   []uint16{0, 1, 2, 0, 2, 3},
+			submeshes: []Submesh{tri("default", 0, 6)},
+		},
+		{
+			name: "faces without normals get zero",
+			obj: `v 0 0 0
+				v 1 0 0
+				v 0 1 0
+				vn 0 0 1
+				f 1//1 2//1 3//1
+				f 1 3 2`,
+			attribs: []string{"position", "normal"},
+			vertices: []float32{
+				0, 0, 0, 0, 0, 1,
+				1, 0, 0, 0, 0, 1,
+				0, 1, 0, 0, 0, 1,
+				0, 0, 0, 0, 0, 0,
+				0, 1, 0, 0, 0, 0,
+				1, 0, 0, 0, 0, 0,
+			},
+			indices:   []uint16{0, 1, 2, 3, 4, 5},
+			submeshes: []Submesh{tri("default", 0, 6)},
+		},
+		{
+			name: "groups and materials",
+			obj: `v 0 0 0
+				v 1 0 0
+				v 0 1 0
+				f 1 2 3
+				g roof
+				usemtl red
+				f 1 2 3
+				usemtl blue
+				f 3 2 1
+				o walls # still blue
+				f 1 3 2
+				g roof
+				usemtl red
+				f 2 3 1`,
+			attribs:  []string{"position"},
+			vertices: []float32{0, 0, 0, 1, 0, 0, 0, 1, 0},
+			indices:  []uint16{0, 1, 2, 0, 1, 2, 1, 2, 0, 2, 1, 0, 0, 2, 1},
+			submeshes: []Submesh{
+				tri("default", 0, 3),
+				{Name: "roof/red", Material: "red", Mode: gfx.TRIANGLES, Indexed: true, First: 3, Count: 6},
+				{Name: "roof/blue", Material: "blue", Mode: gfx.TRIANGLES, Indexed: true, First: 9, Count: 3},
+				{Name: "walls", Material: "blue", Mode: gfx.TRIANGLES, Indexed: true, First: 12, Count: 3},
+			},
+		},
+		{
+			name: "comments and continued lines",
+			obj: `# a triangle
+				v 0 0 0 # the origin
+				v 1 0 0 1
+				v 0 1 0 1 0.5 0.5 0.5
+				f 1 \
+				  2 3`,
+			attribs:   []string{"position"},
+			vertices:  []float32{0, 0, 0, 1, 0, 0, 0, 1, 0},
+			indices:   []uint16{0, 1, 2},
+			submeshes: []Submesh{tri("default", 0, 3)},
+		},
+	}
+	for _, tt := range tests {
+		d, err := ReadOBJ(strings.NewReader(tt.obj), nil)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		var names []string
+		for _, a := range d.Layout {
+			names = append(names, a.Name)
+		}
+		if !reflect.DeepEqual(names, tt.attribs) {
+			t.Errorf("%s: attributes %v, want %v", tt.name, names, tt.attribs)
+		}
+		if !reflect.DeepEqual(d.Vertices, tt.vertices) {
+			t.Errorf("%s: vertices\n\t%v\nwant\n\t%v", tt.name, d.Vertices, tt.vertices)
+		}
+		if !reflect.DeepEqual(d.Indices, tt.indices) {
+			t.Errorf("%s: indices %v, want %v", tt.name, d.Indices, tt.indices)
+		}
+		if !reflect.DeepEqual(d.Submeshes, tt.submeshes) {
+			t.Errorf("%s: submeshes\n\t%+v\nwant\n\t%+v", tt.name, d.Submeshes, tt.submeshes)
+		}
+		if d.Materials != nil {
+			t.Errorf("%s: read materials without a way to open them", tt.name)
+		}
+	}
+}
+
+func TestReadOBJMaterials(t *testing.T) {
+	const obj = `mtllib walls.mtl roof.mtl
+		v 0 0 0
+		v 1 0 0
+		v 0 1 0
+		usemtl brick
+		f 1 2 3
+		usemtl plain
+		f 1 3 2
+		usemtl glass
+		f 2 1 3`
+	libs := map[string]string{
+		"walls.mtl": `# walls
+			newmtl brick
+			Ka 0.5
+			Kd 0.6 0.2 0.1
+			Ks 0 0 0
+			Ns 10
+			map_Kd -s 2 2 1 brick.png
+			newmtl plain`,
+		"roof.mtl": `newmtl glass
+			Kd 0.1 0.2 0.3
+			Tr 0.75`,
+	}
+	var opened []string
+	open := func(name string) (io.ReadCloser, error) {
+		opened = append(opened, name)
+		s, ok := libs[name]
+		if !ok {
+			return nil, fmt.Errorf("%s not found", name)
+		}
+		return ioutil.NopCloser(strings.NewReader(s)), nil
+	}
+	d, err := ReadOBJ(strings.NewReader(obj), open)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"walls.mtl", "roof.mtl"}; !reflect.DeepEqual(opened, want) {
+		t.Errorf("opened %v, want %v", opened, want)
+	}
+	want := map[string]*Material{
+		"brick": {
+			Ambient:   vmath.Vec3{0.5, 0.5, 0.5},
+			Diffuse:   vmath.Vec3{0.6, 0.2, 0.1},
+			Specular:  vmath.Vec3{0, 0, 0},
+			Shininess: 10,
+			Alpha:     1,
+			Texture:   "brick.png",
+		},
+		// The defaults of the MTL format
+		"plain": {
+			Ambient:  vmath.Vec3{0.2, 0.2, 0.2},
+			Diffuse:  vmath.Vec3{0.8, 0.8, 0.8},
+			Specular: vmath.Vec3{1, 1, 1},
+			Alpha:    1,
+		},
+		"glass": {
+			Ambient:  vmath.Vec3{0.2, 0.2, 0.2},
+			Diffuse:  vmath.Vec3{0.1, 0.2, 0.3},
+			Specular: vmath.Vec3{1, 1, 1},
+			Alpha:    0.25,
+		},
+	}
+	if !reflect.DeepEqual(d.Materials, want) {
+		for name, m := range d.Materials {
+			t.Logf("%s: %+v", name, m)
+		}
+		t.Errorf("materials differ")
+	}
+	for i, name := range []string{"brick", "plain", "glass"} {
+		if s := d.Submeshes[i]; s.Name != "default/"+name || s.Material != name {
+			t.Errorf("submesh %d is %q with material %q, want default/%s", i, s.Name, s.Material, name)
+		}
+	}
+}
+
+// TestReadOBJIndexType checks that the indices switch from 16 to 32
+// bits when there are more vertices than 16 bits can number.
+func TestReadOBJIndexType(t *testing.T) {
+	for _, n := range []int{1 << 16, 1<<16 + 1} {
+		var b strings.Builder
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(&b, "v %d 0 0\n", i)
+		}
+		for i := 1; i+2 <= n; i += 3 {
+			fmt.Fprintf(&b, "f %d %d %d\n", i, i+1, i+2)
+		}
+		fmt.Fprintf(&b, "f %d %d %d\n", n-2, n-1, n)
+		d, err := ReadOBJ(strings.NewReader(b.String()), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var last uint32
+		switch idx := d.Indices.(type) {
+		case []uint16:
+			if n > 1<<16 {
+				t.Errorf("%d vertices have 16 bit indices", n)
+			}
+			last = uint32(idx[len(idx)-1])
+		case []uint32:
+			if n <= 1<<16 {
+				t.Errorf("%d vertices have 32 bit indices", n)
+			}
+			last = idx[len(idx)-1]
+		default:
+			t.Fatalf("indices are a %T", d.Indices)
+		}
+		if last != uint32(n-1) {
+			t.Errorf("%d vertices: last index is %d, want %d", n, last, n-1)
+		}
+	}
+}
+
+func TestReadOBJErrors(t *testing.T) {
+	const tri = "v 0 0 0\nv 1 0 0\nv 0 1 0\n"
+	open := func(name string) (io.ReadCloser, error) {
+		switch name {
+		case "ok.mtl":
+			return ioutil.NopCloser(strings.NewReader("newmtl red\nKd 1 0 0\n")), nil
+		case "bad.mtl":
+			return ioutil.NopCloser(strings.NewReader("# no material yet\nKd 1 0 0\n")), nil
+		}
+		return nil, fmt.Errorf("%s not found", name)
+	}
+	tests := []struct {
+		name, obj, want string
+	}{
+		{"no faces", tri,
+			"mesh: no faces"},
+		{"short vertex", "v 1 2\n",
+			"mesh: line 1: want 3 to 7 numbers, have 2"},
+		{"bad number", "v 1 2 x\n",
+			`mesh: line 1: invalid number "x"`},
+		{"two-sided face", tri + "f 1 2\n",
+			"mesh: line 4: face has 2 vertices"},
+		{"index past the end", tri + "f 1 2 4\n",
+			`mesh: line 4: vertex "4" refers to element 4 of 3`},
+		{"index zero", tri + "f 0 1 2\n",
+			`mesh: line 4: vertex "0" refers to element 0 of 3`},
+		{"negative index past the start", tri + "f 1 2 -4\n",
+			`mesh: line 4: vertex "-4" refers to element -4 of 3`},
+		{"missing normal", tri + "f 1//1 2//1 3//1\n",
+			`mesh: line 4: vertex "1//1" refers to element 1 of 0`},
+		{"bad reference", tri + "f 1/x 2 3\n",
+			`mesh: line 4: invalid vertex "1/x"`},
+		{"undefined material", "mtllib ok.mtl\n" + tri + "usemtl green\nf 1 2 3\n",
+			`mesh: group "default" uses undefined material "green"`},
+		{"missing library", "mtllib gone.mtl\n" + tri + "f 1 2 3\n",
+			"mesh: line 1: gone.mtl not found"},
+		{"statement before newmtl", "mtllib bad.mtl\n" + tri + "f 1 2 3\n",
+			"mesh: bad.mtl line 2: Kd before newmtl"},
+	}
+	for _, tt := range tests {
+		_, err := ReadOBJ(strings.NewReader(tt.obj), open)
+		if err == nil {
+			t.Errorf("%s: no error", tt.name)
+		} else if err.Error() != tt.want {
+			t.Errorf("%s: error %q, want %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+package mesh
+
+import (
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/droyo/gltut/internal/gfx"
+)
+
+// The XML format of the arcsynthesis tutorials looks like this:
+//
+//	<mesh xmlns="http://www.arcsynthesis.com/gltut/mesh">
+//		<attribute index="0" type="float" size="3">
+//			0.5 0.5 0.5
+//			...
+//		</attribute>
+//		<vao name="color">
+//			<source attrib="0"/>
+//			<source attrib="1"/>
+//		</vao>
+//		<indices cmd="triangles" type="ushort">0 1 2 ...</indices>
+//		<arrays cmd="tri-fan" start="0" count="10"/>
+//	</mesh>
+//
+// Every attribute must have the same number of vertices. The
+// commands are drawn in the order they appear.
+type xmlMesh struct {
+	XMLName  xml.Name     `xml:"mesh"`
+	Attribs  []xmlAttrib  `xml:"attribute"`
+	VAOs     []xmlVAO     `xml:"vao"`
+	Commands []xmlCommand `xml:",any"`
+}
+
+type xmlAttrib struct {
+	Index    string `xml:"index,attr"`
+	Type     string `xml:"type,attr"`
+	Size     string `xml:"size,attr"`
+	Integral string `xml:"integral,attr"`
+	Data     string `xml:",chardata"`
+}
+
+type xmlVAO struct {
+	Name    string `xml:"name,attr"`
+	Sources []struct {
+		Attrib string `xml:"attrib,attr"`
+	} `xml:"source"`
+}
+
+// An xmlCommand is an <indices> or <arrays> element.
+type xmlCommand struct {
+	XMLName xml.Name
+	Cmd     string `xml:"cmd,attr"`
+	Type    string `xml:"type,attr"`
+	Start   string `xml:"start,attr"`
+	Count   string `xml:"count,attr"`
+	Restart string `xml:"primitive-restart,attr"`
+	Data    string `xml:",chardata"`
+}
+
+var modes = map[string]gfx.Enum{
+	"points":     gfx.POINTS,
+	"lines":      gfx.LINES,
+	"line-loop":  gfx.LINE_LOOP,
+	"line-strip": gfx.LINE_STRIP,
+	"triangles":  gfx.TRIANGLES,
+	"tri-strip":  gfx.TRIANGLE_STRIP,
+	"tri-fan":    gfx.TRIANGLE_FAN,
+}
+
+// attribTypes maps the type names of the XML format to component
+// types. The "norm-" prefix marks normalized integers.
+var attribTypes = map[string]gfx.Type{
+	"float":  gfx.Float32,
+	"int":    gfx.Int32,
+	"uint":   gfx.Uint32,
+	"short":  gfx.Int16,
+	"ushort": gfx.Uint16,
+	"byte":   gfx.Int8,
+	"ubyte":  gfx.Uint8,
+}
+
+var indexTypes = map[string]gfx.Type{
+	"uint":   gfx.Uint32,
+	"ushort": gfx.Uint16,
+	"ubyte":  gfx.Uint8,
+}
+
+// A parseError describes a problem with one element of a mesh file.
+type parseError struct {
+	elem string
+	msg  string
+}
+
+func (e *parseError) Error() string {
+	return "mesh: <" + e.elem + ">: " + e.msg
+}
+
+func errorf(elem, format string, args ...interface{}) error {
+	return &parseError{elem, fmt.Sprintf(format, args...)}
+}
+
+// ReadXMLFile reads a mesh from the named file in the arcsynthesis
+// XML format.
+func ReadXMLFile(name string) (*Data, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	d, err := ReadXML(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return d, nil
+}
+
+// ReadXML reads a mesh in the arcsynthesis XML format. The
+// attributes are interleaved in the order of their indices, each
+// aligned to 4 bytes.
+func ReadXML(r io.Reader) (*Data, error) {
+	var m xmlMesh
+	if err := xml.NewDecoder(r).Decode(&m); err != nil {
+		return nil, fmt.Errorf("mesh: %v", err)
+	}
+	if len(m.Attribs) == 0 {
+		return nil, errorf("mesh", "no attributes")
+	}
+	d := new(Data)
+	values := make(map[int][]float64)
+	nverts := -1
+	var attribs []Attrib
+	for _, x := range m.Attribs {
+		a, v, err := parseAttrib(x)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := values[a.Index]; dup {
+			return nil, errorf("attribute", "index %d is used more than once", a.Index)
+		}
+		n := len(v) / a.Size
+		if nverts >= 0 && n != nverts {
+			return nil, errorf("attribute", "index %d has %d vertices; attribute %d has %d",
+				a.Index, n, attribs[0].Index, nverts)
+		}
+		nverts = n
+		values[a.Index] = v
+		attribs = append(attribs, a)
+	}
+	sort.Slice(attribs, func(i, j int) bool { return attribs[i].Index < attribs[j].Index })
+	d.Layout = Interleaved(attribs...)
+	stride := d.Layout[0].Stride
+	vertices := make([]byte, nverts*stride)
+	for _, a := range d.Layout {
+		v := values[a.Index]
+		for i := 0; i < nverts; i++ {
+			for c := 0; c < a.Size; c++ {
+				putValue(vertices[i*stride+a.Offset+c*a.Type.Size():], a.Type, v[i*a.Size+c])
+			}
+		}
+	}
+	d.Vertices = vertices
+
+	for _, x := range m.VAOs {
+		if err := d.parseVAO(x); err != nil {
+			return nil, err
+		}
+	}
+	var indices []byte
+	for _, x := range m.Commands {
+		if err := d.parseCommand(x, nverts, &indices); err != nil {
+			return nil, err
+		}
+	}
+	if len(d.Submeshes) == 0 {
+		return nil, errorf("mesh", "no <indices> or <arrays> commands")
+	}
+	if len(indices) > 0 {
+		d.Indices = indices
+	}
+	return d, nil
+}
+
+func parseAttrib(x xmlAttrib) (Attrib, []float64, error) {
+	var a Attrib
+	index, err := strconv.Atoi(x.Index)
+	if err != nil || index < 0 || index >= 16 {
+		return a, nil, errorf("attribute", "invalid index %q", x.Index)
+	}
+	a.Index = index
+	name := x.Type
+	if strings.HasPrefix(name, "norm-") {
+		a.Normalized = true
+		name = strings.TrimPrefix(name, "norm-")
+	}
+	t, ok := attribTypes[name]
+	if !ok || (a.Normalized && t == gfx.Float32) {
+		return a, nil, errorf("attribute", "index %d has unsupported type %q", index, x.Type)
+	}
+	a.Type = t
+	size, err := strconv.Atoi(x.Size)
+	if err != nil || size < 1 || size > 4 {
+		return a, nil, errorf("attribute", "index %d has invalid size %q", index, x.Size)
+	}
+	a.Size = size
+	if x.Integral == "true" {
+		return a, nil, errorf("attribute", "index %d: integral attributes are not supported", index)
+	}
+	v, err := parseValues(x.Data, t)
+	if err != nil {
+		return a, nil, errorf("attribute", "index %d: %v", index, err)
+	}
+	if len(v) == 0 || len(v)%size != 0 {
+		return a, nil, errorf("attribute", "index %d has %d values, which is not a multiple of its size %d",
+			index, len(v), size)
+	}
+	return a, v, nil
+}
+
+// parseValues parses whitespace-separated numbers of type t. Every
+// type fits in a float64 without loss.
+func parseValues(s string, t gfx.Type) ([]float64, error) {
+	fields := strings.Fields(s)
+	v := make([]float64, len(fields))
+	for i, f := range fields {
+		var err error
+		switch t {
+		case gfx.Float32:
+			v[i], err = strconv.ParseFloat(f, 32)
+		case gfx.Int8, gfx.Int16, gfx.Int32:
+			var n int64
+			n, err = strconv.ParseInt(f, 10, 8*t.Size())
+			v[i] = float64(n)
+		default:
+			var n uint64
+			n, err = strconv.ParseUint(f, 10, 8*t.Size())
+			v[i] = float64(n)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("value %d: %v", i, err.(*strconv.NumError).Err)
+		}
+	}
+	return v, nil
+}
+
+func putValue(dst []byte, t gfx.Type, v float64) {
+	switch t {
+	case gfx.Float32:
+		binary.LittleEndian.PutUint32(dst, math.Float32bits(float32(v)))
+	case gfx.Int8:
+		dst[0] = byte(int8(v))
+	case gfx.Uint8:
+		dst[0] = uint8(v)
+	case gfx.Int16:
+		binary.LittleEndian.PutUint16(dst, uint16(int16(v)))
+	case gfx.Uint16:
+		binary.LittleEndian.PutUint16(dst, uint16(v))
+	case gfx.Int32:
+		binary.LittleEndian.PutUint32(dst, uint32(int32(v)))
+	case gfx.Uint32:
+		binary.LittleEndian.PutUint32(dst, uint32(v))
+	}
+}
+
+func (d *Data) parseVAO(x xmlVAO) error {
+	if x.Name == "" {
+		return errorf("vao", "missing name")
+	}
+	if _, dup := d.VAOs[x.Name]; dup {
+		return errorf("vao", "%q is defined more than once", x.Name)
+	}
+	var attribs []int
+	for _, src := range x.Sources {
+		index, err := strconv.Atoi(src.Attrib)
+		if err != nil {
+			return errorf("vao", "%q: invalid attribute %q", x.Name, src.Attrib)
+		}
+		if _, ok := find(d.Layout, index); !ok {
+			return errorf("vao", "%q: no attribute with index %d", x.Name, index)
+		}
+		attribs = append(attribs, index)
+	}
+	if d.VAOs == nil {
+		d.VAOs = make(map[string][]int)
+	}
+	d.VAOs[x.Name] = attribs
+	return nil
+}
+
+// parseCommand adds the submesh of an <indices> or <arrays> element.
+// Index arrays are appended to indices.
+func (d *Data) parseCommand(x xmlCommand, nverts int, indices *[]byte) error {
+	elem := x.XMLName.Local
+	if elem != "indices" && elem != "arrays" {
+		return errorf(elem, "unknown element")
+	}
+	mode, ok := modes[x.Cmd]
+	if !ok {
+		return errorf(elem, "unknown command %q", x.Cmd)
+	}
+	c := Submesh{Mode: mode}
+	if elem == "arrays" {
+		start, err1 := strconv.Atoi(x.Start)
+		count, err2 := strconv.Atoi(x.Count)
+		if err1 != nil || err2 != nil || start < 0 || count <= 0 || start+count > nverts {
+			return errorf(elem, "invalid range start=%q count=%q for %d vertices", x.Start, x.Count, nverts)
+		}
+		c.First, c.Count = start, count
+		d.Submeshes = append(d.Submeshes, c)
+		return nil
+	}
+
+	if x.Restart != "" {
+		return errorf(elem, "primitive restart is not supported")
+	}
+	t, ok := indexTypes[x.Type]
+	if !ok {
+		return errorf(elem, "unsupported index type %q", x.Type)
+	}
+	v, err := parseValues(x.Data, t)
+	if err != nil {
+		return errorf(elem, "%v", err)
+	}
+	if len(v) == 0 {
+		return errorf(elem, "no indices")
+	}
+	// Keep every index array aligned, whatever the type of the one
+	// before it, so that its offset is a whole number of indices.
+	for len(*indices)%4 != 0 {
+		*indices = append(*indices, 0)
+	}
+	c.Indexed, c.IndexType, c.First, c.Count = true, t, len(*indices)/t.Size(), len(v)
+	buf := make([]byte, len(v)*t.Size())
+	for i, n := range v {
+		if int(n) >= nverts {
+			return errorf(elem, "index %d is %d, but there are only %d vertices", i, int(n), nverts)
+		}
+		putValue(buf[i*t.Size():], t, n)
+	}
+	*indices = append(*indices, buf...)
+	d.Submeshes = append(d.Submeshes, c)
+	return nil
+}
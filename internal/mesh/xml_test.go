@@ -0,0 +1,155 @@
+package mesh
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/droyo/gltut/internal/gfx"
+)
+
+const xmlQuad = `<?xml version="1.0" encoding="UTF-8"?>
+<mesh xmlns="http://www.arcsynthesis.com/gltut/mesh">
+	<attribute index="2" type="ushort" size="3">
+		1 2 3  4 5 6  7 8 9  65535 0 1
+	</attribute>
+	<attribute index="0" type="float" size="3">
+		-1 -1 0  1 -1 0  1 1 0  -1 1 0
+	</attribute>
+	<attribute index="1" type="norm-ubyte" size="4">
+		255 0 0 255  0 255 0 255  0 0 255 255  128 128 128 0
+	</attribute>
+	<vao name="color">
+		<source attrib="0"/>
+		<source attrib="1"/>
+	</vao>
+	<vao name="flat">
+		<source attrib="0"/>
+	</vao>
+	<indices cmd="triangles" type="ushort">0 1 2 0 2 3</indices>
+	<arrays cmd="tri-fan" start="0" count="4"/>
+	<indices cmd="triangles" type="ubyte">3 2 1</indices>
+	<indices cmd="lines" type="ushort">0 3</indices>
+</mesh>
+`
+
+func TestReadXML(t *testing.T) {
+	d, err := ReadXML(strings.NewReader(xmlQuad))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Attributes are sorted by index and aligned to 4 bytes, so
+	// the 6 bytes of attribute 2 take up 8.
+	wantLayout := Layout{
+		{Index: 0, Type: gfx.Float32, Size: 3, Offset: 0, Stride: 24},
+		{Index: 1, Type: gfx.Uint8, Size: 4, Normalized: true, Offset: 12, Stride: 24},
+		{Index: 2, Type: gfx.Uint16, Size: 3, Offset: 16, Stride: 24},
+	}
+	if !reflect.DeepEqual(d.Layout, wantLayout) {
+		t.Errorf("layout is\n\t%+v\nwant\n\t%+v", d.Layout, wantLayout)
+	}
+
+	v, ok := d.Vertices.([]byte)
+	if !ok || len(v) != 4*24 {
+		t.Fatalf("vertices are %T of length %d, want 96 bytes", d.Vertices, len(v))
+	}
+	f32 := func(off int) float32 { return math.Float32frombits(binary.LittleEndian.Uint32(v[off:])) }
+	u16 := func(off int) uint16 { return binary.LittleEndian.Uint16(v[off:]) }
+	if got := [3]float32{f32(48), f32(52), f32(56)}; got != [3]float32{1, 1, 0} {
+		t.Errorf("position of vertex 2 is %v, want [1 1 0]", got)
+	}
+	if got := v[3*24+12 : 3*24+16]; !reflect.DeepEqual(got, []byte{128, 128, 128, 0}) {
+		t.Errorf("color of vertex 3 is %v, want [128 128 128 0]", got)
+	}
+	if got := [3]uint16{u16(3*24 + 16), u16(3*24 + 18), u16(3*24 + 20)}; got != [3]uint16{65535, 0, 1} {
+		t.Errorf("attribute 2 of vertex 3 is %v, want [65535 0 1]", got)
+	}
+
+	wantVAOs := map[string][]int{"color": {0, 1}, "flat": {0}}
+	if !reflect.DeepEqual(d.VAOs, wantVAOs) {
+		t.Errorf("VAOs are %v, want %v", d.VAOs, wantVAOs)
+	}
+
+	// Each index array starts on a 4 byte boundary, so the ushort
+	// indices after the three ubyte ones skip a byte of padding.
+	wantSubmeshes := []Submesh{
+		{Mode: gfx.TRIANGLES, Indexed: true, IndexType: gfx.Uint16, First: 0, Count: 6},
+		{Mode: gfx.TRIANGLE_FAN, First: 0, Count: 4},
+		{Mode: gfx.TRIANGLES, Indexed: true, IndexType: gfx.Uint8, First: 12, Count: 3},
+		{Mode: gfx.LINES, Indexed: true, IndexType: gfx.Uint16, First: 8, Count: 2},
+	}
+	if !reflect.DeepEqual(d.Submeshes, wantSubmeshes) {
+		t.Errorf("submeshes are\n\t%+v\nwant\n\t%+v", d.Submeshes, wantSubmeshes)
+	}
+	wantIndices := []byte{0, 0, 1, 0, 2, 0, 0, 0, 2, 0, 3, 0, 3, 2, 1, 0, 0, 0, 3, 0}
+	if !reflect.DeepEqual(d.Indices, wantIndices) {
+		t.Errorf("indices are %v, want %v", d.Indices, wantIndices)
+	}
+}
+
+func TestReadXMLArraysOnly(t *testing.T) {
+	d, err := ReadXML(strings.NewReader(`<mesh>
+		<attribute index="0" type="float" size="2">0 0 1 0 0 1</attribute>
+		<arrays cmd="triangles" start="0" count="3"/>
+	</mesh>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Indices != nil {
+		t.Errorf("indices are %v, want none", d.Indices)
+	}
+	if want := []Submesh{{Mode: gfx.TRIANGLES, Count: 3}}; !reflect.DeepEqual(d.Submeshes, want) {
+		t.Errorf("submeshes are %+v, want %+v", d.Submeshes, want)
+	}
+}
+
+func TestReadXMLErrors(t *testing.T) {
+	const pos = `<attribute index="0" type="float" size="2">0 0 1 0 0 1</attribute>`
+	const tri = `<arrays cmd="triangles" start="0" count="3"/>`
+	tests := []struct {
+		name, body, want string
+	}{
+		{"no attributes", tri,
+			"mesh: <mesh>: no attributes"},
+		{"no commands", pos,
+			"mesh: <mesh>: no <indices> or <arrays> commands"},
+		{"invalid index", `<attribute index="16" type="float" size="2">0 0</attribute>` + tri,
+			`mesh: <attribute>: invalid index "16"`},
+		{"duplicate index", pos + pos + tri,
+			"mesh: <attribute>: index 0 is used more than once"},
+		{"mismatched counts", pos + `<attribute index="1" type="ubyte" size="4">1 2 3 4 5 6 7 8</attribute>` + tri,
+			"mesh: <attribute>: index 1 has 2 vertices; attribute 0 has 3"},
+		{"partial vertex", `<attribute index="0" type="float" size="3">0 0 1 0</attribute>` + tri,
+			"mesh: <attribute>: index 0 has 4 values, which is not a multiple of its size 3"},
+		{"normalized float", `<attribute index="0" type="norm-float" size="1">0</attribute>` + tri,
+			`mesh: <attribute>: index 0 has unsupported type "norm-float"`},
+		{"out of range value", `<attribute index="0" type="ubyte" size="1">0 256 1</attribute>` + tri,
+			"mesh: <attribute>: index 0: value 1: value out of range"},
+		{"invalid size", `<attribute index="0" type="float" size="5">0 0 0 0 0</attribute>` + tri,
+			`mesh: <attribute>: index 0 has invalid size "5"`},
+		{"vao of unknown attribute", pos + `<vao name="color"><source attrib="1"/></vao>` + tri,
+			`mesh: <vao>: "color": no attribute with index 1`},
+		{"vao without name", pos + `<vao><source attrib="0"/></vao>` + tri,
+			"mesh: <vao>: missing name"},
+		{"unknown command", pos + `<arrays cmd="quads" start="0" count="3"/>`,
+			`mesh: <arrays>: unknown command "quads"`},
+		{"arrays past the end", pos + `<arrays cmd="triangles" start="1" count="3"/>`,
+			`mesh: <arrays>: invalid range start="1" count="3" for 3 vertices`},
+		{"index past the end", pos + `<indices cmd="triangles" type="ushort">0 1 3</indices>`,
+			"mesh: <indices>: index 2 is 3, but there are only 3 vertices"},
+		{"float indices", pos + `<indices cmd="triangles" type="float">0 1 2</indices>`,
+			`mesh: <indices>: unsupported index type "float"`},
+		{"unknown element", pos + tri + `<normals/>`,
+			"mesh: <normals>: unknown element"},
+	}
+	for _, tt := range tests {
+		_, err := ReadXML(strings.NewReader("<mesh>" + tt.body + "</mesh>"))
+		if err == nil {
+			t.Errorf("%s: no error", tt.name)
+		} else if err.Error() != tt.want {
+			t.Errorf("%s: error %q, want %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+#version 150
+
+smooth in vec4 theColor;
+out vec4 outColor;
+
+void main() {
+	outColor = theColor;
+}
@@ -0,0 +1,213 @@
+package shader
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A Source is a shader ready to compile, made from one or more
+// files. Its #line directives number each line by the file it came
+// from, so that a line the driver's info log calls line n of source
+// string i is line n of Files[i].
+type Source struct {
+	Text  []byte
+	Files []string
+	lines [][]string
+}
+
+// Line returns the text of the given line of file number i.
+func (s *Source) Line(i, line int) (string, bool) {
+	if i < 0 || i >= len(s.lines) || line < 1 || line > len(s.lines[i]) {
+		return "", false
+	}
+	return s.lines[i][line-1], true
+}
+
+// A Define is a macro for Preprocess to define. Its Value is a
+// number or a bool, which is written as a GLSL literal of the same
+// type, or a string, which is copied as is.
+type Define struct {
+	Name  string
+	Value interface{}
+}
+
+var ident = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func (d Define) line() (string, error) {
+	if !ident.MatchString(d.Name) || strings.HasPrefix(d.Name, "GL_") || strings.Contains(d.Name, "__") {
+		return "", fmt.Errorf("shader: bad macro name %q", d.Name)
+	}
+	var v string
+	switch x := d.Value.(type) {
+	case string:
+		if strings.ContainsAny(x, "\r\n") {
+			return "", fmt.Errorf("shader: macro %s spans lines", d.Name)
+		}
+		v = x
+	case bool:
+		v = strconv.FormatBool(x)
+	case int, int8, int16, int32, int64:
+		v = fmt.Sprint(x)
+	case uint, uint8, uint16, uint32, uint64:
+		v = fmt.Sprint(x) + "u"
+	case float32:
+		v = float(float64(x), 32)
+	case float64:
+		v = float(x, 64)
+	default:
+		return "", fmt.Errorf("shader: macro %s has unsupported type %T", d.Name, d.Value)
+	}
+	return "#define " + d.Name + " " + v, nil
+}
+
+// float formats f so that GLSL reads it as a float.
+func float(f float64, bits int) string {
+	s := strconv.FormatFloat(f, 'g', -1, bits)
+	if !strings.ContainsAny(s, ".eIN") {
+		s += ".0"
+	}
+	return s
+}
+
+var (
+	includeDirective = regexp.MustCompile(`^\s*#\s*include\s*"([^"]*)"\s*(?://.*)?$`)
+	versionDirective = regexp.MustCompile(`^\s*#\s*version\b`)
+)
+
+// Preprocess reads the named shader file from fsys, or else from
+// Common, and prepares it for compiling.
+//
+// A line of the form
+//
+//	#include "name"
+//
+// is replaced by the named file, found relative to the including
+// file, or else in Common. A file is included at most once, so a
+// snippet may include what it uses without guarding against being
+// included twice.
+//
+// The defines are written just after the #version directive, or at
+// the start if there is none. The rest of the preprocessing is left
+// to the driver.
+func Preprocess(fsys fs.FS, name string, defines ...Define) (*Source, error) {
+	p := &preprocessor{src: new(Source), seen: make(map[string]bool)}
+	for _, d := range defines {
+		line, err := d.line()
+		if err != nil {
+			return nil, err
+		}
+		p.defines = append(p.defines, line)
+	}
+	fsys, name, data, err := find(fsys, ".", name)
+	if err != nil {
+		return nil, fmt.Errorf("shader: %v", err)
+	}
+	if err := p.file(fsys, name, data); err != nil {
+		return nil, err
+	}
+	p.src.Text = p.out.Bytes()
+	return p.src, nil
+}
+
+// find reads the file with the given name, relative to dir, from
+// fsys, or else from Common. It returns the file system it was found
+// in and its name there.
+func find(fsys fs.FS, dir, name string) (fs.FS, string, []byte, error) {
+	rel := path.Join(dir, name)
+	data, err := fs.ReadFile(fsys, rel)
+	if err == nil || fsys == Common {
+		return fsys, rel, data, err
+	}
+	lib := path.Join("common", name)
+	if data, err := fs.ReadFile(Common, lib); err == nil {
+		return Common, lib, data, nil
+	}
+	return nil, "", nil, err
+}
+
+type preprocessor struct {
+	src     *Source
+	defines []string
+	out     bytes.Buffer
+	seen    map[string]bool // files read so far
+	stack   []string        // files being read, outermost first
+}
+
+// file writes a file to the output, with the files it includes.
+func (p *preprocessor) file(fsys fs.FS, name string, data []byte) error {
+	n := len(p.src.Files)
+	main := n == 0
+	p.src.Files = append(p.src.Files, name)
+	text := strings.TrimSuffix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	lines := strings.Split(text, "\n")
+	p.src.lines = append(p.src.lines, lines)
+	p.seen[name] = true
+	p.stack = append(p.stack, name)
+	defer func() { p.stack = p.stack[:len(p.stack)-1] }()
+
+	if main {
+		hasVersion := false
+		for _, line := range lines {
+			hasVersion = hasVersion || versionDirective.MatchString(line)
+		}
+		if !hasVersion {
+			p.writeDefines(1, n)
+		}
+	} else {
+		fmt.Fprintf(&p.out, "#line 1 %d\n", n)
+	}
+	for i, line := range lines {
+		num := i + 1
+		if m := includeDirective.FindStringSubmatch(line); m != nil {
+			if err := p.include(fsys, name, num, m[1]); err != nil {
+				return err
+			}
+			fmt.Fprintf(&p.out, "#line %d %d\n", num+1, n)
+			continue
+		}
+		p.out.WriteString(line)
+		p.out.WriteByte('\n')
+		if versionDirective.MatchString(line) {
+			if !main {
+				return fmt.Errorf("shader: %s:%d: #version in an included file", name, num)
+			}
+			p.writeDefines(num+1, n)
+		}
+	}
+	return nil
+}
+
+// include writes the file included from line num of the named file.
+func (p *preprocessor) include(fsys fs.FS, from string, num int, name string) error {
+	fsys, name, data, err := find(fsys, path.Dir(from), name)
+	if err != nil {
+		return fmt.Errorf("shader: %s:%d: %v", from, num, err)
+	}
+	for _, s := range p.stack {
+		if s == name {
+			return fmt.Errorf("shader: %s:%d: %s includes itself", from, num, name)
+		}
+	}
+	if p.seen[name] {
+		return nil
+	}
+	return p.file(fsys, name, data)
+}
+
+// writeDefines writes the defines, followed by a #line directive
+// that numbers the next line as line next of file n.
+func (p *preprocessor) writeDefines(next, n int) {
+	if len(p.defines) == 0 {
+		return
+	}
+	for _, d := range p.defines {
+		p.out.WriteString(d)
+		p.out.WriteByte('\n')
+	}
+	fmt.Fprintf(&p.out, "#line %d %d\n", next, n)
+}
@@ -0,0 +1,205 @@
+package shader
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// files is a tree of shaders to preprocess. Each line of a snippet
+// says where it is, so that the tests can check that the #line
+// directives point back to it.
+var files = fstest.MapFS{
+	"main.frag": {Data: []byte(`// main.frag:1
+#version 150
+#include "a.glsl"
+#include "sub/b.glsl" // main.frag:4
+void main() {} // main.frag:5
+`)},
+	"a.glsl": {Data: []byte("float a; // a.glsl:1\n")},
+	"sub/b.glsl": {Data: []byte(`#include "c.glsl"
+#include "../a.glsl"
+float b; // sub/b.glsl:3
+`)},
+	"sub/c.glsl": {Data: []byte("float c; // sub/c.glsl:1")},
+	"crlf.frag":  {Data: []byte("#version 150\r\n#include \"a.glsl\"\r\nvoid main() {}\r\n")},
+	"plain.frag": {Data: []byte("void main() {}\n")},
+
+	"self.frag":  {Data: []byte("#include \"self.frag\"\n")},
+	"cycle.frag": {Data: []byte("#include \"x.glsl\"\n")},
+	"x.glsl":     {Data: []byte("float x;\n#include \"y.glsl\"\n")},
+	"y.glsl":     {Data: []byte("#include \"x.glsl\"\n")},
+
+	"version.frag":  {Data: []byte("#include \"v.glsl\"\n")},
+	"v.glsl":        {Data: []byte("#version 150\n")},
+	"common.frag":   {Data: []byte("#include \"color.frag\"\n")},
+	"missing.frag":  {Data: []byte("\n#include \"none.glsl\"\n")},
+	"nested/f.frag": {Data: []byte("#include \"sub/c.glsl\"\n")},
+}
+
+func TestPreprocess(t *testing.T) {
+	color, err := common.ReadFile("common/color.frag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		defines []Define
+		text    string
+		files   []string
+	}{
+		{"main.frag", nil, `// main.frag:1
+#version 150
+#line 1 1
+float a; // a.glsl:1
+#line 4 0
+#line 1 2
+#line 1 3
+float c; // sub/c.glsl:1
+#line 2 2
+#line 3 2
+float b; // sub/b.glsl:3
+#line 5 0
+void main() {} // main.frag:5
+`, []string{"main.frag", "a.glsl", "sub/b.glsl", "sub/c.glsl"}},
+
+		{"main.frag", []Define{{"N", 3}, {"F", float32(2)}, {"U", uint(4)}, {"B", true}, {"S", "vec3(1)"}, {"G", 1e-7}},
+			`// main.frag:1
+#version 150
+#define N 3
+#define F 2.0
+#define U 4u
+#define B true
+#define S vec3(1)
+#define G 1e-07
+#line 3 0
+#line 1 1
+float a; // a.glsl:1
+#line 4 0
+#line 1 2
+#line 1 3
+float c; // sub/c.glsl:1
+#line 2 2
+#line 3 2
+float b; // sub/b.glsl:3
+#line 5 0
+void main() {} // main.frag:5
+`, []string{"main.frag", "a.glsl", "sub/b.glsl", "sub/c.glsl"}},
+
+		{"crlf.frag", []Define{{"N", 1}}, `#version 150
+#define N 1
+#line 2 0
+#line 1 1
+float a; // a.glsl:1
+#line 3 0
+void main() {}
+`, []string{"crlf.frag", "a.glsl"}},
+
+		// Without a #version, the defines come first.
+		{"plain.frag", []Define{{"N", 1}}, `#define N 1
+#line 1 0
+void main() {}
+`, []string{"plain.frag"}},
+
+		// Files not in the tree are read from Common.
+		{"color.frag", nil, string(color), []string{"common/color.frag"}},
+	}
+	for _, tt := range tests {
+		src, err := Preprocess(files, tt.name, tt.defines...)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if string(src.Text) != tt.text {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.name, src.Text, tt.text)
+		}
+		if strings.Join(src.Files, " ") != strings.Join(tt.files, " ") {
+			t.Errorf("%s: read files %q, want %q", tt.name, src.Files, tt.files)
+		}
+	}
+}
+
+func TestPreprocessLocalFirst(t *testing.T) {
+	fsys := fstest.MapFS{"color.frag": {Data: []byte("void main() {}\n")}}
+	src, err := Preprocess(fsys, "color.frag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src.Files[0] != "color.frag" || string(src.Text) != "void main() {}\n" {
+		t.Errorf("read %q as %q, want the local color.frag", src.Files, src.Text)
+	}
+}
+
+var lineDirective = regexp.MustCompile(`^#line (\d+) (\d+)$`)
+
+// TestSourceLine follows the #line directives of the output the way
+// a compiler would, and checks that Line gives back each line as it
+// is in the file it came from.
+func TestSourceLine(t *testing.T) {
+	src, err := Preprocess(files, "main.frag", Define{"N", 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, line := 0, 1
+	checked := 0
+	for _, text := range strings.Split(strings.TrimSuffix(string(src.Text), "\n"), "\n") {
+		if m := lineDirective.FindStringSubmatch(text); m != nil {
+			line, _ = strconv.Atoi(m[1])
+			file, _ = strconv.Atoi(m[2])
+			continue
+		}
+		got, ok := src.Line(file, line)
+		if strings.HasPrefix(text, "#define") {
+			// Defines are not in any file, and take the place
+			// of the lines after #version.
+		} else if !ok || got != text {
+			t.Errorf("Line(%d, %d) is %q, %v; want %q", file, line, got, ok, text)
+		} else if i := strings.Index(text, "// "); i >= 0 {
+			want := src.Files[file] + ":" + strconv.Itoa(line)
+			if where := text[i+3:]; where != want {
+				t.Errorf("line %q is numbered %s", text, want)
+			}
+			checked++
+		}
+		line++
+	}
+	if checked != 5 {
+		t.Errorf("checked %d marked lines, want 5", checked)
+	}
+	for _, bad := range [][2]int{{-1, 1}, {4, 1}, {0, 0}, {0, 6}, {3, 2}} {
+		if text, ok := src.Line(bad[0], bad[1]); ok {
+			t.Errorf("Line(%d, %d) is %q, want none", bad[0], bad[1], text)
+		}
+	}
+}
+
+func TestPreprocessErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		defines []Define
+		want    string
+	}{
+		{"self.frag", nil, "shader: self.frag:1: self.frag includes itself"},
+		{"cycle.frag", nil, "shader: y.glsl:1: x.glsl includes itself"},
+		{"version.frag", nil, "shader: v.glsl:1: #version in an included file"},
+		{"common.frag", nil, "shader: common/color.frag:1: #version in an included file"},
+		{"missing.frag", nil, "shader: missing.frag:2: open none.glsl: file does not exist"},
+		{"nested/f.frag", nil, "shader: nested/f.frag:1: open nested/sub/c.glsl: file does not exist"},
+		{"none.frag", nil, "shader: open none.frag: file does not exist"},
+		{"plain.frag", []Define{{"GL_FOO", 1}}, `shader: bad macro name "GL_FOO"`},
+		{"plain.frag", []Define{{"A__B", 1}}, `shader: bad macro name "A__B"`},
+		{"plain.frag", []Define{{"1N", 1}}, `shader: bad macro name "1N"`},
+		{"plain.frag", []Define{{"N", "1\n2"}}, "shader: macro N spans lines"},
+		{"plain.frag", []Define{{"N", []int{1}}}, "shader: macro N has unsupported type []int"},
+	}
+	for _, tt := range tests {
+		_, err := Preprocess(files, tt.name, tt.defines...)
+		if err == nil {
+			t.Errorf("%s: no error", tt.name)
+		} else if err.Error() != tt.want {
+			t.Errorf("%s: error %q, want %q", tt.name, err, tt.want)
+		}
+	}
+}
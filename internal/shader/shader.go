@@ -0,0 +1,175 @@
+// Package shader finds the GLSL source files of the tutorials.
+//
+// Each tutorial embeds its shader files, so that a built binary runs
+// from anywhere. When the tutorial's source directory is on disk, as
+// it is under go run or in a binary built from a checkout, the files
+// are read from there instead, so that they can be edited without a
+// rebuild and watched for changes while the tutorial runs. Binaries
+// built with -trimpath always use the embedded copies.
+//
+// Shaders and snippets shared by several tutorials are in Common, and
+// Preprocess puts a shader together from its files.
+package shader
+
+import (
+	"bytes"
+	"embed"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Files holds the shader files of one package. It implements fs.FS.
+type Files struct {
+	embedded fs.FS
+	dir      string
+
+	mu     sync.Mutex
+	read   map[string]version // files read from dir
+	pinned map[string][]byte
+}
+
+// A version is the state of a file on disk when it was read.
+type version struct {
+	mod  time.Time
+	size int64
+	data []byte
+}
+
+func (v version) same(fi fs.FileInfo) bool {
+	return v.mod.Equal(fi.ModTime()) && v.size == fi.Size()
+}
+
+// Local returns the shader files of the calling package, whose
+// embedded copies are in embedded. It is meant to initialize a
+// package-level variable:
+//
+//	//go:embed tutorial.vert tutorial.frag
+//	var files embed.FS
+//
+//	var shaders = shader.Local(files)
+func Local(embedded fs.FS) *Files {
+	f := &Files{embedded: embedded, read: make(map[string]version)}
+	// The caller's file name is only absolute if the source
+	// directory was on disk when it was built.
+	_, file, _, ok := runtime.Caller(1)
+	if ok && filepath.IsAbs(file) {
+		dir := filepath.Dir(file)
+		if fi, err := os.Stat(dir); err == nil && fi.IsDir() {
+			f.dir = dir
+		}
+	}
+	return f
+}
+
+//go:embed common
+var common embed.FS
+
+// Common holds the shader files shared by the tutorials, in the
+// common directory of this package. Preprocess looks here for files
+// that a tutorial does not have, by the name common/name.
+var Common = Local(common)
+
+// Dir returns the directory that files are read from, or "" if only
+// the embedded copies are used.
+func (f *Files) Dir() string { return f.dir }
+
+func (f *Files) path(name string) string {
+	return filepath.Join(f.dir, filepath.FromSlash(name))
+}
+
+// Open opens the named file from disk, if it is there, or else from
+// the embedded copies.
+func (f *Files) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if f.dir == "" {
+		return f.embedded.Open(name)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if data, ok := f.pinned[name]; ok {
+		return newFile(name, data, time.Time{}), nil
+	}
+	fi, err := os.Stat(f.path(name))
+	if err == nil {
+		var data []byte
+		if data, err = os.ReadFile(f.path(name)); err == nil {
+			f.read[name] = version{fi.ModTime(), fi.Size(), data}
+			return newFile(name, data, fi.ModTime()), nil
+		}
+	}
+	delete(f.read, name)
+	return f.embedded.Open(name)
+}
+
+// Changed returns the names of the files read from disk that have
+// been modified or removed since they were last opened, in order.
+// A file stays changed until it is opened again.
+func (f *Files) Changed() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var names []string
+	for name, v := range f.read {
+		if fi, err := os.Stat(f.path(name)); err != nil || !v.same(fi) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Snapshot returns the contents of the files read from disk, as they
+// were when they were last opened.
+func (f *Files) Snapshot() map[string][]byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	snap := make(map[string][]byte, len(f.read))
+	for name, v := range f.read {
+		snap[name] = v.data
+	}
+	return snap
+}
+
+// Pin makes Open return the contents in snap, as returned by
+// Snapshot, for the files it holds, so that a program can be built
+// again from sources that have since changed on disk. Pin(nil)
+// returns to reading the disk. Opening a pinned file does not count
+// as opening it for Changed.
+func (f *Files) Pin(snap map[string][]byte) {
+	f.mu.Lock()
+	f.pinned = snap
+	f.mu.Unlock()
+}
+
+// A file is an open file whose contents are in memory.
+type file struct {
+	*bytes.Reader
+	info fileInfo
+}
+
+func newFile(name string, data []byte, mod time.Time) *file {
+	return &file{bytes.NewReader(data), fileInfo{path.Base(name), int64(len(data)), mod}}
+}
+
+func (f *file) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *file) Close() error               { return nil }
+
+type fileInfo struct {
+	name string
+	size int64
+	mod  time.Time
+}
+
+func (fi fileInfo) Name() string       { return fi.name }
+func (fi fileInfo) Size() int64        { return fi.size }
+func (fi fileInfo) Mode() fs.FileMode  { return 0444 }
+func (fi fileInfo) ModTime() time.Time { return fi.mod }
+func (fi fileInfo) IsDir() bool        { return false }
+func (fi fileInfo) Sys() interface{}   { return nil }
@@ -0,0 +1,194 @@
+// Package shape generates meshes of simple solids: boxes, planes,
+// spheres, cylinders, cones, tori and capsules.
+//
+// Every shape is centered on the origin, and those with an axis
+// stand along Y. Triangles are wound clockwise when seen from
+// outside, to match the tutorials' gl.FrontFace(gl.CW), and every
+// vertex has an outward normal and texture coordinates from 0 to 1.
+// Vertices are repeated along texture seams and sharp edges, so a
+// closed shape is watertight in its positions, not its indices.
+//
+// Shapes with fewer slices, stacks or divisions than they need to
+// be solid are given the minimum instead.
+package shape
+
+import (
+	"math"
+
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/mesh"
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+// A Vertex is a vertex of a shape.
+type Vertex struct {
+	Position vmath.Vec3
+	Normal   vmath.Vec3
+	TexCoord [2]float32
+}
+
+// A Shape is a list of triangles, three indices each.
+type Shape struct {
+	Vertices []Vertex
+	Indices  []uint32
+}
+
+// Data returns the shape as mesh data with one indexed submesh of
+// triangles. Its layout feeds the vertex inputs "position",
+// "normal" and "texCoord"; a program that lacks some of them can
+// still draw the shape if they are removed from the layout.
+func (s *Shape) Data() *mesh.Data {
+	d := &mesh.Data{
+		Layout: mesh.Interleaved(
+			mesh.Float("position", 3),
+			mesh.Float("normal", 3),
+			mesh.Float("texCoord", 2),
+		),
+		Vertices: s.Vertices,
+		Submeshes: []mesh.Submesh{
+			{Mode: gfx.TRIANGLES, Indexed: true, Count: len(s.Indices)},
+		},
+	}
+	if len(s.Vertices) > 1<<16 {
+		d.Indices = s.Indices
+		return d
+	}
+	idx := make([]uint16, len(s.Indices))
+	for i, v := range s.Indices {
+		idx[i] = uint16(v)
+	}
+	d.Indices = idx
+	return d
+}
+
+// Build adds the triangles of s to b in a solid color, moved by m.
+// m moves the normals too, so it may rotate, translate and scale
+// evenly, but not stretch the shape.
+func (s *Shape) Build(b *mesh.Builder, m vmath.Mat4, color vmath.Vec3) {
+	rot := m.Mat3()
+	idx := make([]uint16, len(s.Vertices))
+	for i, v := range s.Vertices {
+		p := m.MulVec(v.Position.Vec4(1))
+		n := rot.MulVec(v.Normal).Normalize()
+		idx[i] = b.Vertex(vmath.Vec3{p[0], p[1], p[2]}, n, color)
+	}
+	for i := 0; i+2 < len(s.Indices); i += 3 {
+		b.Tri(idx[s.Indices[i]], idx[s.Indices[i+1]], idx[s.Indices[i+2]])
+	}
+}
+
+func (s *Shape) add(v Vertex) uint32 {
+	s.Vertices = append(s.Vertices, v)
+	return uint32(len(s.Vertices) - 1)
+}
+
+func (s *Shape) tri(i, j, k uint32) {
+	s.Indices = append(s.Indices, i, j, k)
+}
+
+// grid adds a surface of cols by rows quads. f gives the vertex at
+// (u, v), from (0, 0) to (1, 1), which is also its texture
+// coordinate. The surface must face the direction of ∂f/∂u × ∂f/∂v.
+func (s *Shape) grid(cols, rows int, f func(u, v float32) Vertex) {
+	base := uint32(len(s.Vertices))
+	for r := 0; r <= rows; r++ {
+		for c := 0; c <= cols; c++ {
+			u, v := float32(c)/float32(cols), float32(r)/float32(rows)
+			p := f(u, v)
+			p.TexCoord = [2]float32{u, v}
+			s.add(p)
+		}
+	}
+	at := func(c, r int) uint32 { return base + uint32(r*(cols+1)+c) }
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			s.tri(at(c, r), at(c, r+1), at(c+1, r+1))
+			s.tri(at(c, r), at(c+1, r+1), at(c+1, r))
+		}
+	}
+}
+
+// A ring is a point of a profile that lathe turns around the Y
+// axis: a circle of radius r at height y, whose normals lean out
+// by nr and up by ny.
+type ring struct {
+	r, y   float32
+	nr, ny float32
+}
+
+// lathe adds the surface swept by turning a profile around the Y
+// axis in the given number of slices. The profile must keep the
+// outside of the shape on its right as it goes, with radius to the
+// right and height up: up the outer wall, inward across a top.
+// Rings of radius 0 close the surface at a point, and a ring that
+// repeats the previous one's position starts a sharp edge.
+//
+// Texture coordinates go around the axis in u, starting from +Z,
+// and along the profile in v, by distance.
+func (s *Shape) lathe(profile []ring, slices int) {
+	sin := make([]float32, slices+1)
+	cos := make([]float32, slices+1)
+	for i := range sin {
+		// The last slice meets the first exactly.
+		a := 2 * math.Pi * float64(i%slices) / float64(slices)
+		sin[i], cos[i] = float32(math.Sin(a)), float32(math.Cos(a))
+	}
+	dist := make([]float32, len(profile))
+	for j := 1; j < len(profile); j++ {
+		a, b := profile[j-1], profile[j]
+		dist[j] = dist[j-1] + float32(math.Hypot(float64(b.r-a.r), float64(b.y-a.y)))
+	}
+	length := dist[len(dist)-1]
+
+	base := uint32(len(s.Vertices))
+	for j, p := range profile {
+		for i := range sin {
+			s.add(Vertex{
+				Position: vmath.Vec3{p.r * sin[i], p.y, p.r * cos[i]},
+				Normal:   vmath.Vec3{p.nr * sin[i], p.ny, p.nr * cos[i]},
+				TexCoord: [2]float32{float32(i) / float32(slices), dist[j] / length},
+			})
+		}
+	}
+	at := func(i, j int) uint32 { return base + uint32(j*(slices+1)+i) }
+	for j := 0; j+1 < len(profile); j++ {
+		a, b := profile[j], profile[j+1]
+		if a.r == b.r && a.y == b.y {
+			continue
+		}
+		for i := 0; i < slices; i++ {
+			if b.r != 0 {
+				s.tri(at(i, j), at(i, j+1), at(i+1, j+1))
+			}
+			if a.r != 0 {
+				s.tri(at(i, j), at(i+1, j+1), at(i+1, j))
+			}
+		}
+	}
+}
+
+// arc returns the rings of a circular arc of the given radius,
+// centered at height y on the axis, from angle a0 to a1 in the
+// given number of steps. Angles are from the +Y axis, so the arc
+// from π to 0 is a half circle going up.
+func arc(radius, y float32, a0, a1 float64, steps int) []ring {
+	var rings []ring
+	for j := 0; j <= steps; j++ {
+		a := a0 + (a1-a0)*float64(j)/float64(steps)
+		sin, cos := float32(math.Sin(a)), float32(math.Cos(a))
+		// Keep the poles on the axis, so that lathe closes them.
+		if a == 0 || a == math.Pi {
+			sin = 0
+		}
+		rings = append(rings, ring{radius * sin, y + radius*cos, sin, cos})
+	}
+	return rings
+}
+
+// atLeast returns n, or min if n is less.
+func atLeast(n, min int) int {
+	if n < min {
+		return min
+	}
+	return n
+}
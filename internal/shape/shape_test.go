@@ -0,0 +1,184 @@
+package shape
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/droyo/gltut/internal/mesh"
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+func origin(vmath.Vec3) vmath.Vec3 { return vmath.Vec3{} }
+
+// shapes lists a shape of each kind, whether it is closed, and a
+// function giving a point inside the shape that a surface point p
+// faces away from.
+var shapes = []struct {
+	name   string
+	shape  *Shape
+	closed bool
+	inside func(p vmath.Vec3) vmath.Vec3
+}{
+	{"cube", Cube(2), true, origin},
+	{"box", Box(vmath.Vec3{1, 2, 3}), true, origin},
+	{"plane", Plane(2, 3, 4, 5), false, func(p vmath.Vec3) vmath.Vec3 { return p.Sub(vmath.Vec3{0, 1, 0}) }},
+	{"sphere", Sphere(1.5, 16, 8), true, origin},
+	{"minimal sphere", Sphere(1, 0, 0), true, origin},
+	{"icosahedron", Icosphere(1, 0), true, origin},
+	{"icosphere", Icosphere(2, 3), true, origin},
+	{"cylinder", Cylinder(1, 2, 12, 3), true, origin},
+	{"cone", Cone(1, 2, 12, 2), true, origin},
+	{"capsule", Capsule(0.5, 2, 12, 4), true, func(p vmath.Vec3) vmath.Vec3 {
+		return vmath.Vec3{0, float32(math.Max(-1, math.Min(1, float64(p[1])))), 0}
+	}},
+	{"flat capsule", Capsule(0.5, 0, 12, 4), true, origin},
+	{"torus", Torus(2, 0.5, 24, 12), true, func(p vmath.Vec3) vmath.Vec3 {
+		return vmath.Vec3{p[0], 0, p[2]}.Normalize().Mul(2)
+	}},
+}
+
+// triangles calls fn with the vertices of each triangle of s.
+func triangles(s *Shape, fn func(a, b, c Vertex)) {
+	for i := 0; i+2 < len(s.Indices); i += 3 {
+		fn(s.Vertices[s.Indices[i]], s.Vertices[s.Indices[i+1]], s.Vertices[s.Indices[i+2]])
+	}
+}
+
+func TestWinding(t *testing.T) {
+	for _, tt := range shapes {
+		if len(tt.shape.Indices)%3 != 0 {
+			t.Errorf("%s: %d indices is not a whole number of triangles", tt.name, len(tt.shape.Indices))
+			continue
+		}
+		bad := 0
+		triangles(tt.shape, func(a, b, c Vertex) {
+			cross := b.Position.Sub(a.Position).Cross(c.Position.Sub(a.Position))
+			n := a.Normal.Add(b.Normal).Add(c.Normal)
+			if cross.Len() < 1e-7 || cross.Dot(n) >= 0 {
+				bad++
+			}
+		})
+		if bad > 0 {
+			t.Errorf("%s: %d of %d triangles are degenerate or not clockwise", tt.name, bad, len(tt.shape.Indices)/3)
+		}
+	}
+}
+
+// weld returns an id for the position of each vertex of s, equal
+// for vertices at the same position.
+func weld(s *Shape) []int {
+	var ids []int
+	var seen []vmath.Vec3
+	for _, v := range s.Vertices {
+		id := -1
+		for j, p := range seen {
+			if v.Position.Sub(p).Len() < 1e-5 {
+				id = j
+				break
+			}
+		}
+		if id < 0 {
+			seen = append(seen, v.Position)
+			id = len(seen) - 1
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestClosed(t *testing.T) {
+	for _, tt := range shapes {
+		s := tt.shape
+		ids := weld(s)
+		edges := make(map[[2]int]int)
+		for i := 0; i+2 < len(s.Indices); i += 3 {
+			for j := 0; j < 3; j++ {
+				a, b := ids[s.Indices[i+j]], ids[s.Indices[i+(j+1)%3]]
+				edges[[2]int{a, b}]++
+			}
+		}
+		open := 0
+		for e, n := range edges {
+			if n != 1 || edges[[2]int{e[1], e[0]}] != 1 {
+				open++
+			}
+		}
+		if tt.closed && open > 0 {
+			t.Errorf("%s: %d of %d edges are not matched by exactly one reverse edge", tt.name, open, len(edges))
+		}
+		if !tt.closed && open == 0 {
+			t.Errorf("%s: has no boundary, want an open surface", tt.name)
+		}
+	}
+}
+
+func TestNormals(t *testing.T) {
+	for _, tt := range shapes {
+		for _, v := range tt.shape.Vertices {
+			if math.Abs(float64(v.Normal.Len()-1)) > 1e-4 {
+				t.Errorf("%s: normal %v at %v has length %v", tt.name, v.Normal, v.Position, v.Normal.Len())
+				break
+			}
+			if v.Position.Sub(tt.inside(v.Position)).Dot(v.Normal) <= 0 {
+				t.Errorf("%s: normal %v at %v points inwards", tt.name, v.Normal, v.Position)
+				break
+			}
+		}
+	}
+}
+
+func TestMinimum(t *testing.T) {
+	tests := []struct {
+		name       string
+		got, least *Shape
+	}{
+		{"plane", Plane(1, 1, 0, -1), Plane(1, 1, 1, 1)},
+		{"sphere", Sphere(1, 0, 1), Sphere(1, 3, 2)},
+		{"cylinder", Cylinder(1, 1, 2, 0), Cylinder(1, 1, 3, 1)},
+		{"cone", Cone(1, 1, -4, 0), Cone(1, 1, 3, 1)},
+		{"torus", Torus(2, 1, 1, 2), Torus(2, 1, 3, 3)},
+		{"capsule", Capsule(1, 1, 0, 0), Capsule(1, 1, 3, 1)},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.least) {
+			t.Errorf("%s: too few divisions gave %d vertices and %d indices, want the minimum's %d and %d",
+				tt.name, len(tt.got.Vertices), len(tt.got.Indices), len(tt.least.Vertices), len(tt.least.Indices))
+		}
+	}
+}
+
+func TestData(t *testing.T) {
+	if _, ok := Sphere(1, 16, 8).Data().Indices.([]uint16); !ok {
+		t.Error("small shape does not use 16-bit indices")
+	}
+	if _, ok := Sphere(1, 400, 200).Data().Indices.([]uint32); !ok {
+		t.Error("shape of more than 65536 vertices does not use 32-bit indices")
+	}
+}
+
+func TestBuild(t *testing.T) {
+	s := Torus(2, 0.5, 12, 6)
+	b := &mesh.Builder{Light: vmath.Vec3{0, 0, 1}}
+	// Stand the torus around Z, and move it up.
+	m := vmath.Translate(vmath.Vec3{0, 3, 0}).Mul(vmath.RotateX(math.Pi / 2))
+	s.Build(b, m, vmath.Vec3{1, 1, 1})
+	b.End("torus")
+	if len(b.Vertices) != len(s.Vertices) || !reflect.DeepEqual(b.Indices, s.Data().Indices) {
+		t.Fatalf("built %d vertices and indices %v, want the shape's %d and %v",
+			len(b.Vertices), b.Indices, len(s.Vertices), s.Indices)
+	}
+	for i, v := range b.Vertices {
+		p, n := s.Vertices[i].Position, s.Vertices[i].Normal
+		want := vmath.Vec3{p[0], 3 - p[2], p[1]}
+		if v.Position.Sub(want).Len() > 1e-5 {
+			t.Errorf("vertex %v moved to %v, want %v", p, v.Position, want)
+		}
+		// Shading by the light along Z shows the normal was turned
+		// with the shape.
+		shade := 0.55 + 0.45*float32(math.Max(0, float64(n[1])))
+		if math.Abs(float64(v.Color[0]-shade)) > 1e-5 {
+			t.Errorf("vertex %v with normal %v has shade %v, want %v", p, n, v.Color[0], shade)
+		}
+	}
+}
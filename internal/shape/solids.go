@@ -0,0 +1,232 @@
+package shape
+
+import (
+	"math"
+
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+// Cube returns a cube with edges of the given length.
+func Cube(size float32) *Shape {
+	return Box(vmath.Vec3{size, size, size})
+}
+
+// Box returns a box with the given width, height and depth. Each
+// face has its own vertices and the whole texture.
+func Box(size vmath.Vec3) *Shape {
+	s := new(Shape)
+	axes := [3]vmath.Vec3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+	scale := func(v vmath.Vec3) vmath.Vec3 {
+		return vmath.Vec3{v[0] * size[0], v[1] * size[1], v[2] * size[2]}
+	}
+	for i, n := range axes {
+		// u × v = n; swapping them turns the face around.
+		u, v := axes[(i+1)%3], axes[(i+2)%3]
+		for _, n := range []vmath.Vec3{n, n.Neg()} {
+			center := scale(n).Mul(0.5)
+			du, dv := scale(u), scale(v)
+			s.grid(1, 1, func(x, y float32) Vertex {
+				p := center.Add(du.Mul(x - 0.5)).Add(dv.Mul(y - 0.5))
+				return Vertex{Position: p, Normal: n}
+			})
+			u, v = v, u
+		}
+	}
+	return s
+}
+
+// Plane returns a flat rectangle in the XZ plane, facing +Y, cut
+// into a grid of cols by rows squares. Texture coordinates run
+// along +X in u, and along -Z in v.
+func Plane(width, depth float32, cols, rows int) *Shape {
+	s := new(Shape)
+	cols, rows = atLeast(cols, 1), atLeast(rows, 1)
+	s.grid(cols, rows, func(u, v float32) Vertex {
+		return Vertex{
+			Position: vmath.Vec3{width * (u - 0.5), 0, depth * (0.5 - v)},
+			Normal:   vmath.Vec3{0, 1, 0},
+		}
+	})
+	return s
+}
+
+// Sphere returns a sphere cut into slices around the Y axis and
+// stacks from pole to pole. At least 3 slices and 2 stacks are
+// used.
+func Sphere(radius float32, slices, stacks int) *Shape {
+	s := new(Shape)
+	slices, stacks = atLeast(slices, 3), atLeast(stacks, 2)
+	s.lathe(arc(radius, 0, math.Pi, 0, stacks), slices)
+	return s
+}
+
+// Cylinder returns a capped cylinder cut into slices around the Y
+// axis, with its side cut into stacks.
+func Cylinder(radius, height float32, slices, stacks int) *Shape {
+	s := new(Shape)
+	slices, stacks = atLeast(slices, 3), atLeast(stacks, 1)
+	y := height / 2
+	profile := []ring{{0, -y, 0, -1}, {radius, -y, 0, -1}}
+	for j := 0; j <= stacks; j++ {
+		profile = append(profile, ring{radius, -y + height*float32(j)/float32(stacks), 1, 0})
+	}
+	profile = append(profile, ring{radius, y, 0, 1}, ring{0, y, 0, 1})
+	s.lathe(profile, slices)
+	return s
+}
+
+// Cone returns a cone whose base of the given radius is capped,
+// cut into slices around the Y axis, with its side cut into stacks.
+func Cone(radius, height float32, slices, stacks int) *Shape {
+	s := new(Shape)
+	slices, stacks = atLeast(slices, 3), atLeast(stacks, 1)
+	y := height / 2
+	// The side's normal is perpendicular to its slope.
+	n := vmath.Vec3{height, radius, 0}.Normalize()
+	profile := []ring{{0, -y, 0, -1}, {radius, -y, 0, -1}}
+	for j := 0; j <= stacks; j++ {
+		t := float32(j) / float32(stacks)
+		profile = append(profile, ring{radius * (1 - t), -y + height*t, n[0], n[1]})
+	}
+	s.lathe(profile, slices)
+	return s
+}
+
+// Torus returns a ring around the Y axis. Its tube has radius minor,
+// and its center is a circle of radius major. It is cut into rings
+// around the Y axis, and each ring into sides around the tube.
+func Torus(major, minor float32, rings, sides int) *Shape {
+	s := new(Shape)
+	rings, sides = atLeast(rings, 3), atLeast(sides, 3)
+	var profile []ring
+	for j := 0; j <= sides; j++ {
+		// Start on the outside of the tube, and go up.
+		a := 2 * math.Pi * float64(j%sides) / float64(sides)
+		cos, sin := float32(math.Cos(a)), float32(math.Sin(a))
+		profile = append(profile, ring{major + minor*cos, minor * sin, cos, sin})
+	}
+	s.lathe(profile, rings)
+	return s
+}
+
+// Capsule returns a cylinder of the given height with a hemisphere
+// on each end, so that it is height+2*radius tall. It is cut into
+// slices around the Y axis, and each hemisphere into stacks.
+func Capsule(radius, height float32, slices, stacks int) *Shape {
+	s := new(Shape)
+	slices, stacks = atLeast(slices, 3), atLeast(stacks, 1)
+	y := height / 2
+	profile := arc(radius, -y, math.Pi, math.Pi/2, stacks)
+	profile = append(profile, arc(radius, y, math.Pi/2, 0, stacks)...)
+	s.lathe(profile, slices)
+	return s
+}
+
+// Icosphere returns a sphere made by splitting each triangle of an
+// icosahedron into four, the given number of times, and pushing the
+// new vertices out to the sphere. Its triangles are more even than
+// those of Sphere. Texture coordinates are mapped as on Sphere,
+// except that u goes past 1 on triangles that cross the seam, so
+// its textures should repeat.
+func Icosphere(radius float32, subdivisions int) *Shape {
+	t := float32((1 + math.Sqrt(5)) / 2)
+	points := []vmath.Vec3{
+		{-1, t, 0}, {1, t, 0}, {-1, -t, 0}, {1, -t, 0},
+		{0, -1, t}, {0, 1, t}, {0, -1, -t}, {0, 1, -t},
+		{t, 0, -1}, {t, 0, 1}, {-t, 0, -1}, {-t, 0, 1},
+	}
+	for i, p := range points {
+		points[i] = p.Normalize()
+	}
+	faces := [][3]int{
+		{0, 11, 5}, {0, 5, 1}, {0, 1, 7}, {0, 7, 10}, {0, 10, 11},
+		{1, 5, 9}, {5, 11, 4}, {11, 10, 2}, {10, 7, 6}, {7, 1, 8},
+		{3, 9, 4}, {3, 4, 2}, {3, 2, 6}, {3, 6, 8}, {3, 8, 9},
+		{4, 9, 5}, {2, 4, 11}, {6, 2, 10}, {8, 6, 7}, {9, 8, 1},
+	}
+	for n := 0; n < subdivisions; n++ {
+		mid := make(map[[2]int]int)
+		split := func(a, b int) int {
+			if a > b {
+				a, b = b, a
+			}
+			if m, ok := mid[[2]int{a, b}]; ok {
+				return m
+			}
+			points = append(points, points[a].Add(points[b]).Normalize())
+			mid[[2]int{a, b}] = len(points) - 1
+			return len(points) - 1
+		}
+		var next [][3]int
+		for _, f := range faces {
+			ab, bc, ca := split(f[0], f[1]), split(f[1], f[2]), split(f[2], f[0])
+			next = append(next,
+				[3]int{f[0], ab, ca}, [3]int{f[1], bc, ab},
+				[3]int{f[2], ca, bc}, [3]int{ab, bc, ca})
+		}
+		faces = next
+	}
+
+	// Vertices are shared unless a triangle crosses the texture
+	// seam, where its vertices past the seam need u beyond 1.
+	s := new(Shape)
+	type key struct {
+		point int
+		u     float32
+	}
+	index := make(map[key]uint32)
+	for _, f := range faces {
+		var u [3]float32
+		for i, p := range f {
+			u[i] = longitude(points[p])
+		}
+		if max3(u)-min3(u) > 0.5 {
+			for i := range u {
+				if u[i] < 0.5 {
+					u[i]++
+				}
+			}
+		}
+		var idx [3]uint32
+		for i, p := range f {
+			k := key{p, u[i]}
+			v, ok := index[k]
+			if !ok {
+				n := points[p]
+				lat := 0.5 + float32(math.Asin(float64(n[1])))/math.Pi
+				v = s.add(Vertex{
+					Position: n.Mul(radius),
+					Normal:   n,
+					TexCoord: [2]float32{u[i], lat},
+				})
+				index[k] = v
+			}
+			idx[i] = v
+		}
+		// Wind clockwise as seen from outside.
+		a, b, c := points[f[0]], points[f[1]], points[f[2]]
+		if b.Sub(a).Cross(c.Sub(a)).Dot(a.Add(b).Add(c)) > 0 {
+			idx[1], idx[2] = idx[2], idx[1]
+		}
+		s.tri(idx[0], idx[1], idx[2])
+	}
+	return s
+}
+
+// longitude returns the texture coordinate u of a point on the unit
+// sphere: 0 at +Z, increasing toward +X.
+func longitude(n vmath.Vec3) float32 {
+	u := float32(math.Atan2(float64(n[0]), float64(n[2])) / (2 * math.Pi))
+	if u < 0 {
+		u++
+	}
+	return u
+}
+
+func min3(v [3]float32) float32 {
+	return float32(math.Min(float64(v[0]), math.Min(float64(v[1]), float64(v[2]))))
+}
+
+func max3(v [3]float32) float32 {
+	return float32(math.Max(float64(v[0]), math.Max(float64(v[1]), float64(v[2]))))
+}
@@ -0,0 +1,151 @@
+package std140
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+var (
+	mat3Type = reflect.TypeOf(vmath.Mat3{})
+	mat4Type = reflect.TypeOf(vmath.Mat4{})
+)
+
+// Of returns the layout of the Go type t. Go types map to GLSL
+// types as follows:
+//
+//	float32, int32, uint32, bool   float, int, uint, bool
+//	[2]float32 ... [4]float32      vec2 ... vec4, and likewise for
+//	                               the other scalars; vmath.Vec3
+//	                               and vmath.Vec4 are vectors
+//	vmath.Mat3, vmath.Mat4         mat3, mat4
+//	[N]T, for any other length     T[N]
+//	struct                         a structure of its fields
+//
+// Padding is computed, so structs must not have padding fields of
+// their own. Other types, including int, float64, slices and
+// unexported struct fields, are rejected, since they have no GLSL
+// equivalent or would silently be left out.
+func Of(t reflect.Type) (*Type, error) {
+	switch t {
+	case mat3Type:
+		return NewMatrix(3, 3), nil
+	case mat4Type:
+		return NewMatrix(4, 4), nil
+	}
+	if s, ok := scalarOf(t); ok {
+		return NewScalar(s), nil
+	}
+	switch t.Kind() {
+	case reflect.Array:
+		if s, ok := scalarOf(t.Elem()); ok && t.Len() >= 2 && t.Len() <= 4 {
+			return NewVector(s, t.Len()), nil
+		}
+		if t.Len() == 0 {
+			return nil, fmt.Errorf("std140: %s: arrays must not be empty", t)
+		}
+		elem, err := Of(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return NewArray(elem, t.Len()), nil
+	case reflect.Struct:
+		var fields []Field
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				return nil, fmt.Errorf("std140: %s: field %s is unexported", t, f.Name)
+			}
+			ft, err := Of(f.Type)
+			if err != nil {
+				return nil, fmt.Errorf("%v (field %s of %s)", err, f.Name, t)
+			}
+			fields = append(fields, Field{Name: f.Name, Type: ft})
+		}
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("std140: %s has no fields", t)
+		}
+		return NewStruct(fields...), nil
+	}
+	return nil, fmt.Errorf("std140: %s has no GLSL equivalent", t)
+}
+
+func scalarOf(t reflect.Type) (Scalar, bool) {
+	switch t.Kind() {
+	case reflect.Float32:
+		return Float, true
+	case reflect.Int32:
+		return Int, true
+	case reflect.Uint32:
+		return Uint, true
+	case reflect.Bool:
+		return Bool, true
+	}
+	return 0, false
+}
+
+// Marshal returns the std140 representation of v, which must be
+// a struct or a pointer to one. The result is Of(v's type).Size()
+// bytes long, with zeros in the padding.
+func Marshal(v interface{}) ([]byte, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("std140: cannot marshal %T; need a struct", v)
+	}
+	t, err := Of(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, t.Size())
+	Put(buf, t, rv)
+	return buf, nil
+}
+
+// Put writes v, whose layout is t, to the start of dst. t must
+// have come from Of(v.Type()), and dst must hold at least t.Size()
+// bytes. Padding in dst is left as it was.
+func Put(dst []byte, t *Type, v reflect.Value) {
+	switch t.Kind {
+	case KindScalar:
+		putScalar(dst, t.Scalar, v)
+	case KindVector:
+		for i := 0; i < t.Rows; i++ {
+			putScalar(dst[4*i:], t.Scalar, v.Index(i))
+		}
+	case KindMatrix:
+		// vmath matrices are column-major, like GLSL
+		for c := 0; c < t.Cols; c++ {
+			for r := 0; r < t.Rows; r++ {
+				putScalar(dst[c*vec4+4*r:], Float, v.Index(c*t.Rows+r))
+			}
+		}
+	case KindArray:
+		for i := 0; i < t.Len; i++ {
+			Put(dst[i*t.Stride():], t.Elem, v.Index(i))
+		}
+	case KindStruct:
+		for i, f := range t.Fields {
+			Put(dst[f.Offset:], f.Type, v.Field(i))
+		}
+	}
+}
+
+func putScalar(dst []byte, s Scalar, v reflect.Value) {
+	var bits uint32
+	switch s {
+	case Float:
+		bits = math.Float32bits(float32(v.Float()))
+	case Int:
+		bits = uint32(int32(v.Int()))
+	case Uint:
+		bits = uint32(v.Uint())
+	case Bool:
+		if v.Bool() {
+			bits = 1
+		}
+	}
+	binary.LittleEndian.PutUint32(dst, bits)
+}
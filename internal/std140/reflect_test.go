@@ -0,0 +1,143 @@
+package std140
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/droyo/gltut/internal/vmath"
+)
+
+type light struct {
+	Color     vmath.Vec4
+	Position  vmath.Vec3
+	Intensity float32
+	On        bool
+}
+
+type block struct {
+	CameraToClip vmath.Mat4
+	Normal       vmath.Mat3
+	Scale        float32
+	Offset       [2]float32
+	Lights       [2]light
+	Weights      [5]float32
+	Count        int32
+	Mask         uint32
+}
+
+func TestOf(t *testing.T) {
+	bt, err := Of(reflect.TypeOf(block{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		offset int
+		typ    string
+	}{
+		{"CameraToClip", 0, "mat4"},
+		{"Normal", 64, "mat3"},
+		{"Scale", 112, "float"},
+		{"Offset", 120, "vec2"},
+		{"Lights", 128, "struct { vec4 Color; vec3 Position; float Intensity; bool On; }[2]"},
+		{"Weights", 224, "float[5]"},
+		{"Count", 304, "int"},
+		{"Mask", 308, "uint"},
+	}
+	if len(bt.Fields) != len(tests) {
+		t.Fatalf("%s has %d fields, want %d", bt, len(bt.Fields), len(tests))
+	}
+	for i, tt := range tests {
+		f := bt.Fields[i]
+		if f.Name != tt.name || f.Offset != tt.offset || f.Type.String() != tt.typ {
+			t.Errorf("field %d is %s %s at %d, want %s %s at %d", i,
+				f.Type, f.Name, f.Offset, tt.typ, tt.name, tt.offset)
+		}
+	}
+	if bt.Size() != 320 {
+		t.Errorf("size %d, want 320", bt.Size())
+	}
+	lt := bt.Fields[4].Type.Elem
+	if lt.Fields[2].Offset != 28 || lt.Fields[3].Offset != 32 || lt.Size() != 48 {
+		t.Errorf("light is %s, size %d; want Intensity at 28, On at 32, size 48", lt, lt.Size())
+	}
+}
+
+func TestOfErrors(t *testing.T) {
+	tests := []interface{}{
+		struct{ X int }{},
+		struct{ X float64 }{},
+		struct{ X []float32 }{},
+		struct{ x float32 }{},
+		struct{ _ float32 }{},
+		struct{}{},
+		struct{ A [0]float32 }{},
+		struct{ S struct{ X string } }{},
+	}
+	for _, v := range tests {
+		if _, err := Of(reflect.TypeOf(v)); err == nil {
+			t.Errorf("Of(%T) succeeded", v)
+		}
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	b := block{
+		CameraToClip: vmath.Translate(vmath.Vec3{1, 2, 3}),
+		Normal:       vmath.Mat3{1, 2, 3, 4, 5, 6, 7, 8, 9},
+		Scale:        0.5,
+		Offset:       [2]float32{6, 7},
+		Weights:      [5]float32{1, 2, 3},
+		Count:        -2,
+		Mask:         0xffffffff,
+	}
+	b.Lights[1] = light{Color: vmath.Vec4{1, 0, 0, 1}, Position: vmath.Vec3{9, 8, 7}, Intensity: 3, On: true}
+	buf, err := Marshal(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buf) != 320 {
+		t.Fatalf("Marshal returned %d bytes, want 320", len(buf))
+	}
+	word := func(off int) uint32 { return binary.LittleEndian.Uint32(buf[off:]) }
+	f := func(v float32) uint32 { return math.Float32bits(v) }
+	tests := []struct {
+		what string
+		off  int
+		want uint32
+	}{
+		{"CameraToClip translation x", 48, f(1)},
+		{"CameraToClip translation z", 56, f(3)},
+		{"CameraToClip[3][3]", 60, f(1)},
+		{"Normal[0][0]", 64, f(1)},
+		{"Normal[0][2]", 72, f(3)},
+		{"Normal column 0 padding", 76, 0},
+		{"Normal[1][0]", 80, f(4)},
+		{"Normal[2][0]", 96, f(7)},
+		{"Normal[2][2]", 104, f(9)},
+		{"Scale", 112, f(0.5)},
+		{"Offset[0]", 120, f(6)},
+		{"Offset[1]", 124, f(7)},
+		{"Lights[0].On", 128 + 32, 0},
+		{"Lights[1].Color.r", 128 + 48, f(1)},
+		{"Lights[1].Position.x", 128 + 48 + 16, f(9)},
+		{"Lights[1].Intensity", 128 + 48 + 28, f(3)},
+		{"Lights[1].On", 128 + 48 + 32, 1},
+		{"Weights[0]", 224, f(1)},
+		{"Weights[0] padding", 228, 0},
+		{"Weights[1]", 240, f(2)},
+		{"Weights[2]", 256, f(3)},
+		{"Count", 304, uint32(0xfffffffe)},
+		{"Mask", 308, 0xffffffff},
+	}
+	for _, tt := range tests {
+		if got := word(tt.off); got != tt.want {
+			t.Errorf("%s at %d = %#x, want %#x", tt.what, tt.off, got, tt.want)
+		}
+	}
+	if _, err := Marshal(3); err == nil {
+		t.Error("Marshal(3) succeeded")
+	}
+}
@@ -0,0 +1,222 @@
+// Package std140 lays out values in memory by the std140 rules of
+// the OpenGL specification, which fix the offset of every member of
+// a uniform block declared with layout(std140). A program that
+// follows the same rules can fill a uniform buffer without asking
+// the driver where each member goes.
+//
+// The rules, from section 2.11.4 of the OpenGL 3.2 specification:
+// scalars take 4 bytes; two-component vectors are aligned to 8
+// bytes and larger vectors to 16; the elements of an array, and the
+// columns of a matrix, are aligned to 16 bytes whatever their type;
+// a structure is aligned to 16 bytes, or to its most aligned member
+// if that is larger; and arrays and structures are padded at the end
+// to a multiple of their alignment.
+package std140
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Scalar is the type of a single component.
+type Scalar int
+
+const (
+	Float Scalar = iota
+	Int
+	Uint
+	Bool // stored as a 4-byte integer that is 0 or 1
+)
+
+func (s Scalar) String() string {
+	switch s {
+	case Float:
+		return "float"
+	case Int:
+		return "int"
+	case Uint:
+		return "uint"
+	case Bool:
+		return "bool"
+	}
+	return fmt.Sprintf("Scalar(%d)", int(s))
+}
+
+// prefix returns the prefix of the GLSL vector type name for s.
+func (s Scalar) prefix() string {
+	return map[Scalar]string{Float: "", Int: "i", Uint: "u", Bool: "b"}[s]
+}
+
+// Kind is the kind of a Type.
+type Kind int
+
+const (
+	KindScalar Kind = iota
+	KindVector
+	KindMatrix
+	KindArray
+	KindStruct
+)
+
+// A Type is a GLSL type that can be a member of a uniform block.
+// Build Types with the New functions, which fill in the layout.
+type Type struct {
+	Kind   Kind
+	Scalar Scalar  // component type of scalars, vectors and matrices
+	Rows   int     // components of a vector, or rows of a matrix
+	Cols   int     // columns of a matrix
+	Len    int     // length of an array
+	Elem   *Type   // element type of an array
+	Fields []Field // members of a structure
+
+	align, size int
+}
+
+// A Field is a member of a structure, at Offset bytes from its
+// start.
+type Field struct {
+	Name   string
+	Type   *Type
+	Offset int
+}
+
+// vec4 is the alignment that arrays, matrix columns and structures
+// are rounded up to.
+const vec4 = 16
+
+func roundUp(n, align int) int {
+	return (n + align - 1) / align * align
+}
+
+// NewScalar returns the scalar type s.
+func NewScalar(s Scalar) *Type {
+	return &Type{Kind: KindScalar, Scalar: s, Rows: 1, Cols: 1, align: 4, size: 4}
+}
+
+// NewVector returns a vector of n components of type s, where n is
+// 2, 3 or 4.
+func NewVector(s Scalar, n int) *Type {
+	if n < 2 || n > 4 {
+		panic(fmt.Sprintf("std140: vector of %d components", n))
+	}
+	align := 4 * n
+	if n == 3 {
+		align = vec4
+	}
+	return &Type{Kind: KindVector, Scalar: s, Rows: n, Cols: 1, align: align, size: 4 * n}
+}
+
+// NewMatrix returns a float matrix with cols columns and rows rows.
+// It is laid out as an array of cols column vectors.
+func NewMatrix(cols, rows int) *Type {
+	if cols < 2 || cols > 4 || rows < 2 || rows > 4 {
+		panic(fmt.Sprintf("std140: %dx%d matrix", cols, rows))
+	}
+	return &Type{Kind: KindMatrix, Scalar: Float, Rows: rows, Cols: cols, align: vec4, size: vec4 * cols}
+}
+
+// NewArray returns an array of n elements of type elem.
+func NewArray(elem *Type, n int) *Type {
+	if n < 1 {
+		panic(fmt.Sprintf("std140: array of length %d", n))
+	}
+	align := roundUp(elem.align, vec4)
+	t := &Type{Kind: KindArray, Len: n, Elem: elem, align: align}
+	t.size = n * t.Stride()
+	return t
+}
+
+// NewStruct returns a structure with the given members, in order.
+// The Offset of each field is ignored, and set in the returned
+// Type.
+func NewStruct(fields ...Field) *Type {
+	t := &Type{Kind: KindStruct, align: vec4}
+	off := 0
+	for _, f := range fields {
+		off = roundUp(off, f.Type.align)
+		f.Offset = off
+		off += f.Type.size
+		if f.Type.align > t.align {
+			t.align = f.Type.align
+		}
+		t.Fields = append(t.Fields, f)
+	}
+	t.size = roundUp(off, t.align)
+	return t
+}
+
+// Align returns the base alignment of t, in bytes.
+func (t *Type) Align() int { return t.align }
+
+// Size returns the number of bytes t occupies, including any padding
+// at the end of an array or structure. A member that follows a vec3
+// may start in its last 4 bytes.
+func (t *Type) Size() int { return t.size }
+
+// Stride returns the distance in bytes between the elements of an
+// array, or the columns of a matrix.
+func (t *Type) Stride() int {
+	switch t.Kind {
+	case KindArray:
+		return roundUp(t.Elem.size, t.align)
+	case KindMatrix:
+		return vec4
+	}
+	return 0
+}
+
+// Components calls fn with the offset and type of every component
+// of a value of type t that starts at offset base. Components are
+// visited in the order GLSL stores them: arrays element by element,
+// matrices column by column, and structures member by member.
+func (t *Type) Components(base int, fn func(off int, s Scalar)) {
+	switch t.Kind {
+	case KindScalar:
+		fn(base, t.Scalar)
+	case KindVector:
+		for i := 0; i < t.Rows; i++ {
+			fn(base+4*i, t.Scalar)
+		}
+	case KindMatrix:
+		for c := 0; c < t.Cols; c++ {
+			for r := 0; r < t.Rows; r++ {
+				fn(base+c*vec4+4*r, t.Scalar)
+			}
+		}
+	case KindArray:
+		for i := 0; i < t.Len; i++ {
+			t.Elem.Components(base+i*t.Stride(), fn)
+		}
+	case KindStruct:
+		for _, f := range t.Fields {
+			f.Type.Components(base+f.Offset, fn)
+		}
+	}
+}
+
+// String returns the GLSL name of t. Structures are written out
+// in full.
+func (t *Type) String() string {
+	switch t.Kind {
+	case KindScalar:
+		return t.Scalar.String()
+	case KindVector:
+		return fmt.Sprintf("%svec%d", t.Scalar.prefix(), t.Rows)
+	case KindMatrix:
+		if t.Rows == t.Cols {
+			return fmt.Sprintf("mat%d", t.Cols)
+		}
+		return fmt.Sprintf("mat%dx%d", t.Cols, t.Rows)
+	case KindArray:
+		return fmt.Sprintf("%s[%d]", t.Elem, t.Len)
+	case KindStruct:
+		var buf bytes.Buffer
+		buf.WriteString("struct {")
+		for _, f := range t.Fields {
+			fmt.Fprintf(&buf, " %s %s;", f.Type, f.Name)
+		}
+		buf.WriteString(" }")
+		return buf.String()
+	}
+	return "invalid"
+}
@@ -0,0 +1,205 @@
+package std140
+
+import (
+	"reflect"
+	"testing"
+)
+
+func field(name string, t *Type) Field { return Field{Name: name, Type: t} }
+
+func TestLayout(t *testing.T) {
+	float := NewScalar(Float)
+	tests := []struct {
+		name                string
+		typ                 *Type
+		align, size, stride int
+	}{
+		{"float", float, 4, 4, 0},
+		{"int", NewScalar(Int), 4, 4, 0},
+		{"bool", NewScalar(Bool), 4, 4, 0},
+		{"vec2", NewVector(Float, 2), 8, 8, 0},
+		{"vec3", NewVector(Float, 3), 16, 12, 0},
+		{"uvec3", NewVector(Uint, 3), 16, 12, 0},
+		{"vec4", NewVector(Float, 4), 16, 16, 0},
+		{"float[3]", NewArray(float, 3), 16, 48, 16},
+		{"vec2[2]", NewArray(NewVector(Float, 2), 2), 16, 32, 16},
+		{"vec3[2]", NewArray(NewVector(Float, 3), 2), 16, 32, 16},
+		{"mat2", NewMatrix(2, 2), 16, 32, 16},
+		{"mat3", NewMatrix(3, 3), 16, 48, 16},
+		{"mat4", NewMatrix(4, 4), 16, 64, 16},
+		{"mat2x3", NewMatrix(2, 3), 16, 32, 16},
+		{"mat4[2]", NewArray(NewMatrix(4, 4), 2), 16, 128, 64},
+		{"struct{float}", NewStruct(field("f", float)), 16, 16, 0},
+		{"struct{vec3, float}", NewStruct(field("v", NewVector(Float, 3)), field("f", float)), 16, 16, 0},
+		{"struct{struct{float}, float}", NewStruct(field("s", NewStruct(field("f", float))), field("f", float)), 16, 32, 0},
+		{"struct{vec2}[3]", NewArray(NewStruct(field("v", NewVector(Float, 2))), 3), 16, 48, 16},
+		{"struct{mat3, float}[2]", NewArray(NewStruct(field("m", NewMatrix(3, 3)), field("f", float)), 2), 16, 128, 64},
+	}
+	for _, tt := range tests {
+		if tt.typ.Align() != tt.align || tt.typ.Size() != tt.size || tt.typ.Stride() != tt.stride {
+			t.Errorf("%s: align %d, size %d, stride %d; want %d, %d, %d", tt.name,
+				tt.typ.Align(), tt.typ.Size(), tt.typ.Stride(), tt.align, tt.size, tt.stride)
+		}
+	}
+}
+
+func TestStructOffsets(t *testing.T) {
+	float, vec2, vec3 := NewScalar(Float), NewVector(Float, 2), NewVector(Float, 3)
+	tests := []struct {
+		name    string
+		fields  []Field
+		offsets []int
+		size    int
+	}{
+		{
+			name:    "float after vec3 shares its last word",
+			fields:  []Field{field("v", vec3), field("f", float)},
+			offsets: []int{0, 12},
+			size:    16,
+		},
+		{
+			name:    "vec3 aligned to 16",
+			fields:  []Field{field("f", float), field("v", vec3)},
+			offsets: []int{0, 16},
+			size:    32,
+		},
+		{
+			name:    "vec2 aligned to 8",
+			fields:  []Field{field("f", float), field("v", vec2), field("g", float)},
+			offsets: []int{0, 8, 16},
+			size:    32,
+		},
+		{
+			name:    "float array with 16-byte stride",
+			fields:  []Field{field("a", float), field("b", NewArray(float, 2)), field("c", float)},
+			offsets: []int{0, 16, 48},
+			size:    64,
+		},
+		{
+			name:    "vec2 array with 16-byte stride",
+			fields:  []Field{field("a", NewArray(vec2, 3)), field("b", vec2)},
+			offsets: []int{0, 48},
+			size:    64,
+		},
+		{
+			name:    "mat3 as three 16-byte columns",
+			fields:  []Field{field("a", float), field("m", NewMatrix(3, 3)), field("b", float)},
+			offsets: []int{0, 16, 64},
+			size:    80,
+		},
+		{
+			name:    "mat4",
+			fields:  []Field{field("m", NewMatrix(4, 4)), field("b", float)},
+			offsets: []int{0, 64},
+			size:    80,
+		},
+		{
+			name:    "nested struct rounded up to 16",
+			fields:  []Field{field("a", float), field("s", NewStruct(field("f", float))), field("b", float)},
+			offsets: []int{0, 16, 32},
+			size:    48,
+		},
+		{
+			name: "array of structs",
+			fields: []Field{
+				field("a", float),
+				field("s", NewArray(NewStruct(field("v", vec3), field("f", float)), 2)),
+				field("b", float),
+			},
+			offsets: []int{0, 16, 48},
+			size:    64,
+		},
+	}
+	for _, tt := range tests {
+		s := NewStruct(tt.fields...)
+		var offsets []int
+		for _, f := range s.Fields {
+			offsets = append(offsets, f.Offset)
+		}
+		if !reflect.DeepEqual(offsets, tt.offsets) || s.Size() != tt.size {
+			t.Errorf("%s: %s has offsets %v and size %d, want %v and %d",
+				tt.name, s, offsets, s.Size(), tt.offsets, tt.size)
+		}
+	}
+}
+
+// The example from the ARB_uniform_buffer_object specification.
+func TestSpecExample(t *testing.T) {
+	f := NewStruct(field("d", NewScalar(Int)), field("e", NewVector(Bool, 2)))
+	o := NewStruct(
+		field("j", NewVector(Uint, 3)),
+		field("k", NewVector(Float, 2)),
+		field("l", NewArray(NewScalar(Float), 2)),
+		field("m", NewVector(Float, 2)),
+		field("n", NewArray(NewMatrix(3, 3), 2)),
+	)
+	ex := NewStruct(
+		field("a", NewScalar(Float)),
+		field("b", NewVector(Float, 2)),
+		field("c", NewVector(Float, 3)),
+		field("f", f),
+		field("g", NewScalar(Float)),
+		field("h", NewArray(NewScalar(Float), 2)),
+		field("i", NewMatrix(2, 3)),
+		field("o", NewArray(o, 2)),
+	)
+	tests := []struct {
+		s       *Type
+		offsets []int
+		size    int
+	}{
+		{f, []int{0, 8}, 16},
+		{o, []int{0, 16, 32, 64, 80}, 176},
+		{ex, []int{0, 8, 16, 32, 48, 64, 96, 128}, 480},
+	}
+	for _, tt := range tests {
+		var offsets []int
+		for _, f := range tt.s.Fields {
+			offsets = append(offsets, f.Offset)
+		}
+		if !reflect.DeepEqual(offsets, tt.offsets) || tt.s.Size() != tt.size {
+			t.Errorf("%s has offsets %v and size %d, want %v and %d",
+				tt.s, offsets, tt.s.Size(), tt.offsets, tt.size)
+		}
+	}
+}
+
+func TestComponents(t *testing.T) {
+	tests := []struct {
+		typ  *Type
+		want []int
+	}{
+		{NewVector(Float, 3), []int{0, 4, 8}},
+		{NewArray(NewScalar(Float), 3), []int{0, 16, 32}},
+		{NewMatrix(3, 3), []int{0, 4, 8, 16, 20, 24, 32, 36, 40}},
+		{NewMatrix(2, 3), []int{0, 4, 8, 16, 20, 24}},
+		{NewStruct(field("v", NewVector(Float, 2)), field("a", NewArray(NewScalar(Int), 2))), []int{0, 4, 16, 32}},
+	}
+	for _, tt := range tests {
+		var got []int
+		tt.typ.Components(0, func(off int, s Scalar) { got = append(got, off) })
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: components at %v, want %v", tt.typ, got, tt.want)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		typ  *Type
+		want string
+	}{
+		{NewScalar(Bool), "bool"},
+		{NewVector(Int, 3), "ivec3"},
+		{NewMatrix(4, 4), "mat4"},
+		{NewMatrix(2, 3), "mat2x3"},
+		{NewArray(NewVector(Float, 2), 4), "vec2[4]"},
+		{NewArray(NewStruct(field("j", NewVector(Uint, 3)), field("n", NewArray(NewMatrix(3, 3), 2))), 2),
+			"struct { uvec3 j; mat3[2] n; }[2]"},
+	}
+	for _, tt := range tests {
+		if got := tt.typ.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
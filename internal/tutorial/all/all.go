@@ -0,0 +1,31 @@
+// Package all registers every tutorial. Commands that list or run
+// tutorials import it for its side effects.
+package all
+
+import (
+	_ "github.com/droyo/gltut/01-Hello-Triangle/hello-triangle"
+	_ "github.com/droyo/gltut/02-Playing-with-Colors/fragment-positions"
+	_ "github.com/droyo/gltut/02-Playing-with-Colors/vertex-attributes"
+	_ "github.com/droyo/gltut/03-Moving-Triangle/a-better-way"
+	_ "github.com/droyo/gltut/03-Moving-Triangle/moving-the-vertices"
+	_ "github.com/droyo/gltut/03-Moving-Triangle/multiple-shaders"
+	_ "github.com/droyo/gltut/03-Moving-Triangle/power-shaders"
+	_ "github.com/droyo/gltut/04-Objects-at-rest/aspect-ratio"
+	_ "github.com/droyo/gltut/04-Objects-at-rest/matrix-projection"
+	_ "github.com/droyo/gltut/04-Objects-at-rest/ortho-cube"
+	_ "github.com/droyo/gltut/04-Objects-at-rest/perspective-projection"
+	_ "github.com/droyo/gltut/05-Objects-in-depth/base-vertex"
+	_ "github.com/droyo/gltut/05-Objects-in-depth/depth-clamping"
+	_ "github.com/droyo/gltut/05-Objects-in-depth/overlap-depth"
+	_ "github.com/droyo/gltut/05-Objects-in-depth/overlap-no-depth"
+	_ "github.com/droyo/gltut/05-Objects-in-depth/vertex-clipping"
+	_ "github.com/droyo/gltut/06-Objects-in-motion/hierarchy"
+	_ "github.com/droyo/gltut/06-Objects-in-motion/rotation"
+	_ "github.com/droyo/gltut/06-Objects-in-motion/scale"
+	_ "github.com/droyo/gltut/06-Objects-in-motion/translation"
+	_ "github.com/droyo/gltut/07-World-in-Motion/world-scene"
+	_ "github.com/droyo/gltut/07-World-in-Motion/world-with-ubo"
+	_ "github.com/droyo/gltut/08-Getting-Oriented/camera-relative"
+	_ "github.com/droyo/gltut/08-Getting-Oriented/gimbal-lock"
+	_ "github.com/droyo/gltut/08-Getting-Oriented/interpolation"
+)
@@ -0,0 +1,70 @@
+package tutorial
+
+import (
+	"aqwari.net/exp/display"
+	"github.com/droyo/gltut/internal/gfx"
+)
+
+// A MouseApp is an App that also responds to the mouse. The runner
+// calls Mouse after every mouse event, with the state of the
+// pointer after the event.
+type MouseApp interface {
+	App
+	Mouse(ctx gfx.Context, m Mouse)
+}
+
+// Mouse describes the pointer after a mouse event.
+type Mouse struct {
+	X, Y   int // position, in pixels from the top left of the window
+	DX, DY int // movement since the previous event
+
+	// Buttons held down
+	Left, Middle, Right bool
+
+	// Wheel is 1 if the event was the wheel turning away from the
+	// user, -1 if it turned towards the user, and 0 otherwise.
+	Wheel int
+}
+
+// Dragging reports whether the pointer moved with a button held.
+func (m Mouse) Dragging() bool {
+	return (m.DX != 0 || m.DY != 0) && (m.Left || m.Middle || m.Right)
+}
+
+// update applies a display event to m. It reports false if ev is
+// not a mouse event.
+func (m *Mouse) update(ev interface{}) bool {
+	m.DX, m.DY, m.Wheel = 0, 0, 0
+	switch ev := ev.(type) {
+	case display.MouseMove:
+		m.move(ev.X, ev.Y)
+	case display.MouseButton:
+		m.X, m.Y = ev.X, ev.Y
+		switch ev.Button {
+		case display.ButtonLeft:
+			m.Left = ev.Down
+		case display.ButtonMiddle:
+			m.Middle = ev.Down
+		case display.ButtonRight:
+			m.Right = ev.Down
+		case display.WheelUp:
+			if ev.Down {
+				m.Wheel = 1
+			}
+		case display.WheelDown:
+			if ev.Down {
+				m.Wheel = -1
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// move records motion to x, y. Only motion events count as movement,
+// so pressing a button never looks like a drag.
+func (m *Mouse) move(x, y int) {
+	m.DX, m.DY = x-m.X, y-m.Y
+	m.X, m.Y = x, y
+}
@@ -0,0 +1,316 @@
+package tutorial
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"aqwari.net/exp/display"
+	"github.com/droyo/gltut/internal/capture"
+	"github.com/droyo/gltut/internal/shader"
+)
+
+// frameRate is how often animated tutorials are redrawn.
+const frameRate = 60
+
+// pollInterval is how often shader files are checked for changes.
+const pollInterval = time.Second / 2
+
+// maxInputs is the most inputs kept to replay when a tutorial is
+// reloaded. Updates are merged, so it limits the number of events.
+const maxInputs = 10000
+
+// Capture controls saving frames as PNG images.
+type Capture struct {
+	Dir    string // directory to write images to
+	Depth  bool   // save the depth buffer along with the color buffer
+	Frames int    // number of frames to save each time a tutorial starts
+}
+
+// A runner holds the state of a running tutorial.
+type runner struct {
+	t      *Tutorial
+	win    *Window
+	app    App
+	redraw bool // draw a frame even if the tutorial is not animated
+	shoot  bool // save the next frame
+	frame  int  // frames saved since the tutorial started
+	mouse  Mouse
+
+	watch  []*shader.Files     // shaders to rebuild the tutorial on
+	polled time.Time           // when the shaders were last checked
+	good   []map[string][]byte // the shaders app was built from
+	inputs []input             // everything passed to app since Init
+	lost   bool                // inputs were dropped for passing maxInputs
+}
+
+// An input is something the runner passed to a tutorial: the time
+// given to Update, or a display.KeyPress, display.Resize or Mouse.
+type input struct {
+	dt    time.Duration
+	event interface{}
+}
+
+// Run runs t in win until the user asks to quit or switch to another
+// tutorial. Every pending event is handled before each frame is
+// drawn. Animated tutorials are redrawn continuously, at the time
+// given by win.Clock; the others are redrawn only after an event.
+// The tutorial's OpenGL objects are deleted before Run returns,
+// whether or not it returns an error.
+//
+// Pressing C saves the next frame, as described by win.Capture.
+// Mouse events are passed on to tutorials that implement MouseApp.
+//
+// If win.Context records errors, as a check.Context does, they are
+// logged after every frame.
+//
+// If win.Reload is set and the tutorial's shader files, or those in
+// shader.Common, are on disk, they are checked for changes twice a
+// second, and the tutorial is rebuilt when they change, as described
+// by reload.
+func (t *Tutorial) Run(win *Window) (Action, error) {
+	ctx := win.Context
+	r := &runner{t: t, win: win, app: t.New(), redraw: true}
+	if win.Reload {
+		for _, f := range []*shader.Files{t.Shaders, shader.Common} {
+			if f != nil && f.Dir() != "" {
+				r.watch = append(r.watch, f)
+			}
+		}
+	}
+	if err := r.app.Init(ctx, win.Size.Width, win.Size.Height); err != nil {
+		return Quit, fmt.Errorf("%s: %v", t.ID(), err)
+	}
+	// A reload replaces r.app.
+	defer func() { r.app.Close(ctx) }()
+	r.snapshot()
+
+	ticker := time.NewTicker(time.Second / frameRate)
+	defer ticker.Stop()
+
+	last := win.Clock.Now()
+	for {
+	Events:
+		for {
+			select {
+			case ev := <-win.Event:
+				if a, ok := r.handle(ev); ok {
+					return a, nil
+				}
+			default:
+				break Events
+			}
+		}
+		if len(r.watch) > 0 && time.Since(r.polled) >= pollInterval {
+			r.polled = time.Now()
+			r.reload()
+		}
+		continuous := t.Animated || r.frame < win.Capture.Frames
+		if t.Animated {
+			now := win.Clock.Now()
+			r.app.Update(now - last)
+			r.record(input{dt: now - last})
+			last = now
+		}
+		if continuous || r.redraw {
+			r.app.Draw(ctx)
+			r.report()
+			if r.shoot || r.frame < win.Capture.Frames {
+				if err := r.save(); err != nil {
+					return Quit, err
+				}
+			}
+			win.Flip()
+			win.Clock.Tick()
+			r.redraw = false
+		}
+		// Watching the shaders means waking up without events.
+		if continuous || len(r.watch) > 0 {
+			<-ticker.C
+			win.CheckEvent()
+		} else {
+			win.WaitEvent()
+		}
+	}
+}
+
+// report logs the errors recorded by the window's context, if it
+// records any.
+func (r *runner) report() {
+	c, ok := r.win.Context.(interface{ Err() error })
+	if !ok {
+		return
+	}
+	if err := c.Err(); err != nil {
+		log.Printf("%s: %v", r.t.ID(), err)
+	}
+}
+
+// record adds in to the inputs of the running tutorial, if it may
+// need to be replayed. Consecutive updates are merged, which is why
+// App.Update must be additive in dt.
+//
+// Once there are more than maxInputs, they are all dropped, and no
+// more are recorded until the next reload, which starts the tutorial
+// over instead of replaying a partial history.
+func (r *runner) record(in input) {
+	if len(r.watch) == 0 || r.lost {
+		return
+	}
+	if n := len(r.inputs); in.event == nil && n > 0 && r.inputs[n-1].event == nil {
+		r.inputs[n-1].dt += in.dt
+		return
+	}
+	if len(r.inputs) >= maxInputs {
+		log.Printf("%s: too many inputs to replay; reloading will start over", r.t.ID())
+		r.inputs, r.lost = nil, true
+		return
+	}
+	r.inputs = append(r.inputs, in)
+}
+
+// reload rebuilds the tutorial if its shader files have changed. A
+// new instance of the tutorial is started and given every input the
+// running one has had, so that it picks up in the same state, at the
+// same point in its animation. If the inputs were dropped, as
+// described by record, the new instance starts from the beginning.
+//
+// If the new instance fails to start, as it does when the shaders
+// fail to compile, the error is logged. The failed instance may have
+// changed state that the running one needs, so the tutorial is
+// started again with the shaders the running one was built from,
+// which are known to work. The changed files are not tried again
+// until they change again.
+func (r *runner) reload() {
+	var changed []string
+	for _, f := range r.watch {
+		changed = append(changed, f.Changed()...)
+	}
+	if len(changed) == 0 {
+		return
+	}
+	ctx := r.win.Context
+	log.Printf("%s: reloading %s", r.t.ID(), strings.Join(changed, ", "))
+	app, err := r.start()
+	if err != nil {
+		log.Printf("%s: %v", r.t.ID(), err)
+		r.pin(r.good)
+		app, err = r.start()
+		r.pin(nil)
+		if err != nil {
+			log.Printf("%s: %v", r.t.ID(), err)
+			return
+		}
+	} else {
+		r.snapshot()
+	}
+	for _, in := range r.inputs {
+		switch ev := in.event.(type) {
+		case nil:
+			app.Update(in.dt)
+		case display.KeyPress:
+			app.Key(ctx, ev)
+		case display.Resize:
+			app.Resize(ctx, ev.Width, ev.Height)
+		case Mouse:
+			app.(MouseApp).Mouse(ctx, ev)
+		}
+	}
+	r.app.Close(ctx)
+	r.app = app
+	r.redraw = true
+	r.lost = false
+}
+
+// snapshot saves the watched shaders as they were last read, as
+// the ones the running instance was built from.
+func (r *runner) snapshot() {
+	r.good = r.good[:0]
+	for _, f := range r.watch {
+		r.good = append(r.good, f.Snapshot())
+	}
+}
+
+// pin makes the watched shaders read as in snap, as saved by
+// snapshot, or read from disk again if snap is nil.
+func (r *runner) pin(snap []map[string][]byte) {
+	for i, f := range r.watch {
+		if snap == nil {
+			f.Pin(nil)
+		} else {
+			f.Pin(snap[i])
+		}
+	}
+}
+
+// start returns a new instance of the tutorial, ready to draw.
+func (r *runner) start() (App, error) {
+	app := r.t.New()
+	if err := app.Init(r.win.Context, r.win.Size.Width, r.win.Size.Height); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+// handle handles a single window event. It reports whether the
+// tutorial should stop, and what to do next.
+func (r *runner) handle(ev interface{}) (Action, bool) {
+	ctx := r.win.Context
+	r.redraw = true
+	switch ev := ev.(type) {
+	case display.KeyPress:
+		if a, ok := KeyAction(ev); ok {
+			return a, true
+		}
+		switch {
+		case ev.Code == display.KeyC && ev.Down:
+			r.shoot = true
+		case r.t.Animated && ClockKey(r.win.Clock, ev):
+		default:
+			r.app.Key(ctx, ev)
+			r.record(input{event: ev})
+		}
+	case display.Resize:
+		r.win.Size = ev
+		r.app.Resize(ctx, ev.Width, ev.Height)
+		r.record(input{event: ev})
+	default:
+		if r.mouse.update(ev) {
+			if m, ok := r.app.(MouseApp); ok {
+				m.Mouse(ctx, r.mouse)
+				r.record(input{event: r.mouse})
+			}
+		}
+	}
+	return Quit, false
+}
+
+// save saves the frame that was just drawn. It must be called
+// before the window is flipped.
+func (r *runner) save() error {
+	win, c := r.win, r.win.Capture
+	name := func(suffix string) string {
+		if r.shoot {
+			return capture.Name(r.t.ID(), time.Now(), suffix)
+		}
+		return capture.FrameName(r.t.ID(), r.frame, suffix)
+	}
+	w, h := win.Size.Width, win.Size.Height
+	path, err := capture.Save(c.Dir, name(""), capture.Color(win.Context, w, h))
+	if err != nil {
+		return err
+	}
+	if c.Depth {
+		if _, err := capture.Save(c.Dir, name("-depth"), capture.Depth(win.Context, w, h)); err != nil {
+			return err
+		}
+	}
+	if r.shoot {
+		log.Printf("saved %s", path)
+		r.shoot = false
+	} else {
+		r.frame++
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+// Package tutorial keeps a registry of the tutorials, so that they
+// can be listed and run from a single command.
+package tutorial
+
+import (
+	"fmt"
+	"image"
+	"sort"
+	"strings"
+	"time"
+
+	"aqwari.net/exp/display"
+	"github.com/droyo/gltut/internal/clock"
+	"github.com/droyo/gltut/internal/gfx"
+	"github.com/droyo/gltut/internal/gfx/check"
+	"github.com/droyo/gltut/internal/gfx/soft"
+	"github.com/droyo/gltut/internal/shader"
+)
+
+// An Action tells the launcher what to do after a tutorial returns.
+type Action int
+
+const (
+	Quit Action = iota
+	Next
+	Prev
+)
+
+// A Window is the display window shared by every tutorial run from
+// the launcher. Size holds the most recent dimensions of the window,
+// so that the next tutorial can set up its viewport without waiting
+// for the window to be resized again. Tutorials draw to the window
+// through Context, and animated tutorials are drawn at the time
+// given by Clock. Capture says where saved frames are written. If
+// Reload is set, tutorials are rebuilt when their shader files change
+// on disk.
+type Window struct {
+	*display.Window
+	Size    display.Resize
+	Context gfx.Context
+	Clock   *clock.Control
+	Capture Capture
+	Reload  bool
+}
+
+// An App is a running tutorial. Init creates the OpenGL objects the
+// tutorial draws with, and Close deletes them; if Init fails, it must
+// clean up after itself, and Close is not called. Key is called for
+// every key press the runner does not handle itself, and Update is
+// called before every frame of an animated tutorial with the time
+// since the previous frame. Draw renders a complete frame, including
+// clearing the framebuffer.
+//
+// Update must be additive in dt: Update(a) followed by Update(b)
+// must leave the App as Update(a+b) would. When a tutorial is
+// reloaded, the updates between its other inputs are replayed as one.
+type App interface {
+	Init(ctx gfx.Context, width, height int) error
+	Resize(ctx gfx.Context, width, height int)
+	Key(ctx gfx.Context, ev display.KeyPress)
+	Update(dt time.Duration)
+	Draw(ctx gfx.Context)
+	Close(ctx gfx.Context)
+}
+
+// A Tutorial describes one of the arcsynthesis tutorials.
+type Tutorial struct {
+	Chapter int    // chapter number in the book
+	Section int    // order of the tutorial within its chapter
+	Name    string // short name, such as "depth-clamping"
+	Title   string // window title
+	URL     string // the arcsynthesis page this tutorial implements
+	Doc     string // one-line description
+
+	// New returns a new instance of the tutorial.
+	New func() App
+
+	// Shaders holds the tutorial's shader files, if it reads its
+	// shaders from files.
+	Shaders *shader.Files
+
+	// Animated tutorials are redrawn continuously. The others are
+	// only redrawn when something changes.
+	Animated bool
+}
+
+// ID returns the identifier used to select t on the command line,
+// such as "05/depth-clamping".
+func (t *Tutorial) ID() string {
+	return fmt.Sprintf("%02d/%s", t.Chapter, t.Name)
+}
+
+var registry []*Tutorial
+
+// Register adds t to the list of tutorials. It is meant to be
+// called from the init function of each tutorial's package.
+func Register(t *Tutorial) {
+	for _, old := range registry {
+		if old.ID() == t.ID() {
+			panic("tutorial: Register called twice for " + t.ID())
+		}
+	}
+	registry = append(registry, t)
+	sort.SliceStable(registry, func(i, j int) bool {
+		a, b := registry[i], registry[j]
+		if a.Chapter != b.Chapter {
+			return a.Chapter < b.Chapter
+		}
+		return a.Section < b.Section
+	})
+}
+
+// All returns every registered tutorial in the order they appear
+// in the book.
+func All() []*Tutorial {
+	return append([]*Tutorial(nil), registry...)
+}
+
+// Lookup finds a tutorial by its ID. The leading zero of the
+// chapter may be omitted, and the chapter may be left off entirely
+// if the name is unique.
+func Lookup(id string) (*Tutorial, error) {
+	var found []*Tutorial
+	for _, t := range registry {
+		switch {
+		case t.ID() == id,
+			fmt.Sprintf("%d/%s", t.Chapter, t.Name) == id,
+			!strings.Contains(id, "/") && t.Name == id:
+			found = append(found, t)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("no tutorial named %q", id)
+	case 1:
+		return found[0], nil
+	}
+	return nil, fmt.Errorf("%q is ambiguous; use the chapter/name form", id)
+}
+
+// KeyAction maps the keys shared by every tutorial to an Action.
+// Escape quits, and Page Down and Page Up switch to the next and
+// previous tutorial.
+func KeyAction(ev display.KeyPress) (Action, bool) {
+	switch {
+	case ev.Code == display.KeyEscape:
+		return Quit, true
+	case ev.Code == display.KeyPageDown && ev.Down:
+		return Next, true
+	case ev.Code == display.KeyPageUp && ev.Down:
+		return Prev, true
+	}
+	return Quit, false
+}
+
+// ClockKey handles the keys that control the animation clock, and
+// reports whether ev was one of them. P pauses and resumes, the
+// period key advances a paused animation by one frame, minus and
+// equals halve and double the speed, and 0 restores normal speed.
+func ClockKey(c *clock.Control, ev display.KeyPress) bool {
+	if !ev.Down {
+		return false
+	}
+	switch ev.Code {
+	case display.KeyP:
+		c.SetPaused(!c.Paused())
+	case display.KeyPeriod:
+		c.Step()
+	case display.KeyMinus:
+		c.SetScale(c.Scale() / 2)
+	case display.KeyEqual:
+		c.SetScale(c.Scale() * 2)
+	case display.Key0:
+		c.SetScale(1)
+	default:
+		return false
+	}
+	return true
+}
+
+// A Keymap holds a tutorial's own key bindings. Tutorials with more
+// than one or two keys use a Keymap in their Key method rather than
+// a switch, so the bindings read as a table.
+type Keymap map[display.Key]func()
+
+// Handle runs the binding for ev, if there is one, and reports
+// whether there was. Key releases are ignored.
+func (m Keymap) Handle(ev display.KeyPress) bool {
+	if !ev.Down {
+		return false
+	}
+	if fn, ok := m[ev.Code]; ok {
+		fn()
+		return true
+	}
+	return false
+}
+
+// Reset restores the OpenGL state the tutorials change to its
+// default values, so that one tutorial's settings do not leak into
+// the next.
+func Reset(ctx gfx.Context, width, height int) {
+	ctx.UseProgram(0)
+	ctx.BindVertexArray(0)
+	ctx.BindBuffer(gfx.ARRAY_BUFFER, 0)
+	ctx.BindBuffer(gfx.ELEMENT_ARRAY_BUFFER, 0)
+	ctx.Disable(gfx.CULL_FACE)
+	ctx.Disable(gfx.DEPTH_TEST)
+	ctx.Disable(gfx.DEPTH_CLAMP)
+	ctx.CullFace(gfx.BACK)
+	ctx.FrontFace(gfx.CCW)
+	ctx.DepthFunc(gfx.LESS)
+	ctx.DepthMask(true)
+	ctx.DepthRange(0, 1)
+	ctx.ClearColor(0, 0, 0, 0)
+	ctx.ClearDepth(1)
+	ctx.Viewport(0, 0, width, height)
+}
+
+// Render draws a single frame of t to ctx, as it appears once
+// elapsed time has passed since the tutorial started.
+func (t *Tutorial) Render(ctx gfx.Context, width, height int, elapsed time.Duration) error {
+	Reset(ctx, width, height)
+	app := t.New()
+	if err := app.Init(ctx, width, height); err != nil {
+		return fmt.Errorf("%s: %v", t.ID(), err)
+	}
+	defer app.Close(ctx)
+	app.Update(elapsed)
+	app.Draw(ctx)
+	return nil
+}
+
+// Image renders a single frame of t with the software renderer. It
+// does not need a window or an OpenGL driver. Misuse of the API, and
+// Go code that disagrees with the shaders' interfaces, as found by
+// package check, are reported as errors.
+func (t *Tutorial) Image(width, height int, elapsed time.Duration) (*image.RGBA, error) {
+	ctx := soft.New(width, height)
+	checked := check.New(ctx)
+	if err := t.Render(checked, width, height, elapsed); err != nil {
+		return nil, err
+	}
+	if err := t.drawErr(ctx, checked); err != nil {
+		return nil, err
+	}
+	return ctx.Image(), nil
+}
+
+// drawErr returns the mistakes found by the checks, or else the
+// first error recorded by the software renderer.
+func (t *Tutorial) drawErr(ctx *soft.Context, checked *check.Context) error {
+	for _, err := range []error{checked.Err(), ctx.Err()} {
+		if err != nil {
+			return fmt.Errorf("%s: %v", t.ID(), err)
+		}
+	}
+	return nil
+}
+
+// Frames renders n frames of t with the software renderer, with
+// the animation advancing by step between frames, and calls fn with
+// each one. The image passed to fn is not reused. Errors are
+// reported as by Image.
+func (t *Tutorial) Frames(width, height, n int, step time.Duration, fn func(i int, img *image.RGBA) error) error {
+	ctx := soft.New(width, height)
+	checked := check.New(ctx)
+	Reset(checked, width, height)
+	app := t.New()
+	if err := app.Init(checked, width, height); err != nil {
+		return fmt.Errorf("%s: %v", t.ID(), err)
+	}
+	defer app.Close(checked)
+
+	c := clock.NewFixed(step)
+	last := c.Now()
+	for i := 0; i < n; i++ {
+		now := c.Now()
+		app.Update(now - last)
+		last = now
+		app.Draw(checked)
+		if err := t.drawErr(ctx, checked); err != nil {
+			return err
+		}
+		if err := fn(i, ctx.Image()); err != nil {
+			return err
+		}
+		c.Tick()
+	}
+	return nil
+}
@@ -0,0 +1,359 @@
+package vmath
+
+// A Mat3 is a 3x3 matrix stored in column-major order.
+type Mat3 [9]float32
+
+// A Mat4 is a 4x4 matrix stored in column-major order; the element
+// at row r, column c is m[c*4+r].
+type Mat4 [16]float32
+
+// Ident3 returns the 3x3 identity matrix.
+func Ident3() Mat3 {
+	return Mat3{
+		1, 0, 0,
+		0, 1, 0,
+		0, 0, 1,
+	}
+}
+
+// Ident4 returns the 4x4 identity matrix.
+func Ident4() Mat4 {
+	return Mat4{
+		1, 0, 0, 0,
+		0, 1, 0, 0,
+		0, 0, 1, 0,
+		0, 0, 0, 1,
+	}
+}
+
+// At returns the element at row r, column c.
+func (m Mat3) At(r, c int) float32 { return m[c*3+r] }
+
+// Set sets the element at row r, column c.
+func (m *Mat3) Set(r, c int, v float32) { m[c*3+r] = v }
+
+// Mul returns the matrix product m × n.
+func (m Mat3) Mul(n Mat3) Mat3 {
+	var p Mat3
+	for c := 0; c < 3; c++ {
+		for r := 0; r < 3; r++ {
+			var sum float32
+			for k := 0; k < 3; k++ {
+				sum += m[k*3+r] * n[c*3+k]
+			}
+			p[c*3+r] = sum
+		}
+	}
+	return p
+}
+
+// MulVec returns the product of m and the column vector v.
+func (m Mat3) MulVec(v Vec3) Vec3 {
+	return Vec3{
+		m[0]*v[0] + m[3]*v[1] + m[6]*v[2],
+		m[1]*v[0] + m[4]*v[1] + m[7]*v[2],
+		m[2]*v[0] + m[5]*v[1] + m[8]*v[2],
+	}
+}
+
+// Transpose returns the transpose of m.
+func (m Mat3) Transpose() Mat3 {
+	return Mat3{
+		m[0], m[3], m[6],
+		m[1], m[4], m[7],
+		m[2], m[5], m[8],
+	}
+}
+
+// Det returns the determinant of m.
+func (m Mat3) Det() float32 {
+	return m[0]*(m[4]*m[8]-m[7]*m[5]) -
+		m[3]*(m[1]*m[8]-m[7]*m[2]) +
+		m[6]*(m[1]*m[5]-m[4]*m[2])
+}
+
+// Inverse returns the inverse of m. If m is singular, ok is false
+// and the returned matrix is undefined.
+func (m Mat3) Inverse() (inv Mat3, ok bool) {
+	det := m.Det()
+	if det == 0 {
+		return inv, false
+	}
+	d := 1 / det
+	inv = Mat3{
+		(m[4]*m[8] - m[7]*m[5]) * d,
+		(m[7]*m[2] - m[1]*m[8]) * d,
+		(m[1]*m[5] - m[4]*m[2]) * d,
+		(m[6]*m[5] - m[3]*m[8]) * d,
+		(m[0]*m[8] - m[6]*m[2]) * d,
+		(m[3]*m[2] - m[0]*m[5]) * d,
+		(m[3]*m[7] - m[6]*m[4]) * d,
+		(m[6]*m[1] - m[0]*m[7]) * d,
+		(m[0]*m[4] - m[3]*m[1]) * d,
+	}
+	return inv, true
+}
+
+// Mat4 embeds m in the upper-left corner of a 4x4 identity matrix.
+func (m Mat3) Mat4() Mat4 {
+	return Mat4{
+		m[0], m[1], m[2], 0,
+		m[3], m[4], m[5], 0,
+		m[6], m[7], m[8], 0,
+		0, 0, 0, 1,
+	}
+}
+
+// ColumnMajor returns the elements of m in column-major order.
+func (m Mat3) ColumnMajor() []float32 { return m[:] }
+
+// RowMajor returns the elements of m in row-major order.
+func (m Mat3) RowMajor() []float32 {
+	t := m.Transpose()
+	return t[:]
+}
+
+// At returns the element at row r, column c.
+func (m Mat4) At(r, c int) float32 { return m[c*4+r] }
+
+// Set sets the element at row r, column c.
+func (m *Mat4) Set(r, c int, v float32) { m[c*4+r] = v }
+
+// Col returns column c of m.
+func (m Mat4) Col(c int) Vec4 { return Vec4{m[c*4], m[c*4+1], m[c*4+2], m[c*4+3]} }
+
+// SetCol replaces column c of m.
+func (m *Mat4) SetCol(c int, v Vec4) { copy(m[c*4:c*4+4], v[:]) }
+
+// Mul returns the matrix product m × n. When transforming a vector,
+// n is applied first.
+func (m Mat4) Mul(n Mat4) Mat4 {
+	var p Mat4
+	for c := 0; c < 4; c++ {
+		for r := 0; r < 4; r++ {
+			var sum float32
+			for k := 0; k < 4; k++ {
+				sum += m[k*4+r] * n[c*4+k]
+			}
+			p[c*4+r] = sum
+		}
+	}
+	return p
+}
+
+// MulVec returns the product of m and the column vector v.
+func (m Mat4) MulVec(v Vec4) Vec4 {
+	var p Vec4
+	for r := 0; r < 4; r++ {
+		p[r] = m[r]*v[0] + m[4+r]*v[1] + m[8+r]*v[2] + m[12+r]*v[3]
+	}
+	return p
+}
+
+// Transpose returns the transpose of m.
+func (m Mat4) Transpose() Mat4 {
+	return Mat4{
+		m[0], m[4], m[8], m[12],
+		m[1], m[5], m[9], m[13],
+		m[2], m[6], m[10], m[14],
+		m[3], m[7], m[11], m[15],
+	}
+}
+
+// Mat3 returns the upper-left 3x3 corner of m.
+func (m Mat4) Mat3() Mat3 {
+	return Mat3{
+		m[0], m[1], m[2],
+		m[4], m[5], m[6],
+		m[8], m[9], m[10],
+	}
+}
+
+// Inverse returns the inverse of m. If m is singular, ok is false
+// and the returned matrix is undefined.
+func (m Mat4) Inverse() (inv Mat4, ok bool) {
+	inv[0] = m[5]*m[10]*m[15] - m[5]*m[11]*m[14] - m[9]*m[6]*m[15] +
+		m[9]*m[7]*m[14] + m[13]*m[6]*m[11] - m[13]*m[7]*m[10]
+	inv[4] = -m[4]*m[10]*m[15] + m[4]*m[11]*m[14] + m[8]*m[6]*m[15] -
+		m[8]*m[7]*m[14] - m[12]*m[6]*m[11] + m[12]*m[7]*m[10]
+	inv[8] = m[4]*m[9]*m[15] - m[4]*m[11]*m[13] - m[8]*m[5]*m[15] +
+		m[8]*m[7]*m[13] + m[12]*m[5]*m[11] - m[12]*m[7]*m[9]
+	inv[12] = -m[4]*m[9]*m[14] + m[4]*m[10]*m[13] + m[8]*m[5]*m[14] -
+		m[8]*m[6]*m[13] - m[12]*m[5]*m[10] + m[12]*m[6]*m[9]
+	inv[1] = -m[1]*m[10]*m[15] + m[1]*m[11]*m[14] + m[9]*m[2]*m[15] -
+		m[9]*m[3]*m[14] - m[13]*m[2]*m[11] + m[13]*m[3]*m[10]
+	inv[5] = m[0]*m[10]*m[15] - m[0]*m[11]*m[14] - m[8]*m[2]*m[15] +
+		m[8]*m[3]*m[14] + m[12]*m[2]*m[11] - m[12]*m[3]*m[10]
+	inv[9] = -m[0]*m[9]*m[15] + m[0]*m[11]*m[13] + m[8]*m[1]*m[15] -
+		m[8]*m[3]*m[13] - m[12]*m[1]*m[11] + m[12]*m[3]*m[9]
+	inv[13] = m[0]*m[9]*m[14] - m[0]*m[10]*m[13] - m[8]*m[1]*m[14] +
+		m[8]*m[2]*m[13] + m[12]*m[1]*m[10] - m[12]*m[2]*m[9]
+	inv[2] = m[1]*m[6]*m[15] - m[1]*m[7]*m[14] - m[5]*m[2]*m[15] +
+		m[5]*m[3]*m[14] + m[13]*m[2]*m[7] - m[13]*m[3]*m[6]
+	inv[6] = -m[0]*m[6]*m[15] + m[0]*m[7]*m[14] + m[4]*m[2]*m[15] -
+		m[4]*m[3]*m[14] - m[12]*m[2]*m[7] + m[12]*m[3]*m[6]
+	inv[10] = m[0]*m[5]*m[15] - m[0]*m[7]*m[13] - m[4]*m[1]*m[15] +
+		m[4]*m[3]*m[13] + m[12]*m[1]*m[7] - m[12]*m[3]*m[5]
+	inv[14] = -m[0]*m[5]*m[14] + m[0]*m[6]*m[13] + m[4]*m[1]*m[14] -
+		m[4]*m[2]*m[13] - m[12]*m[1]*m[6] + m[12]*m[2]*m[5]
+	inv[3] = -m[1]*m[6]*m[11] + m[1]*m[7]*m[10] + m[5]*m[2]*m[11] -
+		m[5]*m[3]*m[10] - m[9]*m[2]*m[7] + m[9]*m[3]*m[6]
+	inv[7] = m[0]*m[6]*m[11] - m[0]*m[7]*m[10] - m[4]*m[2]*m[11] +
+		m[4]*m[3]*m[10] + m[8]*m[2]*m[7] - m[8]*m[3]*m[6]
+	inv[11] = -m[0]*m[5]*m[11] + m[0]*m[7]*m[9] + m[4]*m[1]*m[11] -
+		m[4]*m[3]*m[9] - m[8]*m[1]*m[7] + m[8]*m[3]*m[5]
+	inv[15] = m[0]*m[5]*m[10] - m[0]*m[6]*m[9] - m[4]*m[1]*m[10] +
+		m[4]*m[2]*m[9] + m[8]*m[1]*m[6] - m[8]*m[2]*m[5]
+
+	det := m[0]*inv[0] + m[1]*inv[4] + m[2]*inv[8] + m[3]*inv[12]
+	if det == 0 {
+		return inv, false
+	}
+	d := 1 / det
+	for i := range inv {
+		inv[i] *= d
+	}
+	return inv, true
+}
+
+// ColumnMajor returns the elements of m in column-major order,
+// suitable for gl.UniformMatrix4fv with transpose set to false.
+func (m Mat4) ColumnMajor() []float32 { return m[:] }
+
+// RowMajor returns the elements of m in row-major order, suitable
+// for gl.UniformMatrix4fv with transpose set to true.
+func (m Mat4) RowMajor() []float32 {
+	t := m.Transpose()
+	return t[:]
+}
+
+// Translate returns a matrix that translates by v.
+func Translate(v Vec3) Mat4 {
+	m := Ident4()
+	m[12], m[13], m[14] = v[0], v[1], v[2]
+	return m
+}
+
+// Scale returns a matrix that scales each axis by the
+// corresponding component of v.
+func Scale(v Vec3) Mat4 {
+	return Mat4{
+		v[0], 0, 0, 0,
+		0, v[1], 0, 0,
+		0, 0, v[2], 0,
+		0, 0, 0, 1,
+	}
+}
+
+// Rotate returns a matrix that rotates counter-clockwise by angle
+// radians around axis. The axis does not need to be normalized.
+func Rotate(angle float32, axis Vec3) Mat4 {
+	return Rotate3(angle, axis).Mat4()
+}
+
+// Rotate3 is like Rotate, but returns a 3x3 matrix.
+func Rotate3(angle float32, axis Vec3) Mat3 {
+	a := axis.Normalize()
+	x, y, z := a[0], a[1], a[2]
+	c, s := cos(angle), sin(angle)
+	ic := 1 - c
+	return Mat3{
+		x*x*ic + c, x*y*ic + z*s, x*z*ic - y*s,
+		x*y*ic - z*s, y*y*ic + c, y*z*ic + x*s,
+		x*z*ic + y*s, y*z*ic - x*s, z*z*ic + c,
+	}
+}
+
+// RotateX returns a matrix that rotates by angle radians around
+// the X axis.
+func RotateX(angle float32) Mat4 {
+	c, s := cos(angle), sin(angle)
+	return Mat4{
+		1, 0, 0, 0,
+		0, c, s, 0,
+		0, -s, c, 0,
+		0, 0, 0, 1,
+	}
+}
+
+// RotateY returns a matrix that rotates by angle radians around
+// the Y axis.
+func RotateY(angle float32) Mat4 {
+	c, s := cos(angle), sin(angle)
+	return Mat4{
+		c, 0, -s, 0,
+		0, 1, 0, 0,
+		s, 0, c, 0,
+		0, 0, 0, 1,
+	}
+}
+
+// RotateZ returns a matrix that rotates by angle radians around
+// the Z axis.
+func RotateZ(angle float32) Mat4 {
+	c, s := cos(angle), sin(angle)
+	return Mat4{
+		c, s, 0, 0,
+		-s, c, 0, 0,
+		0, 0, 1, 0,
+		0, 0, 0, 1,
+	}
+}
+
+// Perspective returns a perspective projection matrix with a
+// vertical field of view of fovy radians. Camera space looks down
+// the negative Z axis, as in the tutorials.
+func Perspective(fovy, aspect, zNear, zFar float32) Mat4 {
+	f := 1 / tan(fovy/2)
+	return Mat4{
+		0:  f / aspect,
+		5:  f,
+		10: (zFar + zNear) / (zNear - zFar),
+		11: -1,
+		14: (2 * zFar * zNear) / (zNear - zFar),
+	}
+}
+
+// Frustum returns a perspective projection matrix for the view
+// volume bounded by the given planes. left, right, bottom and top
+// are measured on the near plane.
+func Frustum(left, right, bottom, top, zNear, zFar float32) Mat4 {
+	return Mat4{
+		0:  2 * zNear / (right - left),
+		5:  2 * zNear / (top - bottom),
+		8:  (right + left) / (right - left),
+		9:  (top + bottom) / (top - bottom),
+		10: (zFar + zNear) / (zNear - zFar),
+		11: -1,
+		14: (2 * zFar * zNear) / (zNear - zFar),
+	}
+}
+
+// Ortho returns an orthographic projection matrix for the given
+// view volume.
+func Ortho(left, right, bottom, top, zNear, zFar float32) Mat4 {
+	return Mat4{
+		0:  2 / (right - left),
+		5:  2 / (top - bottom),
+		10: -2 / (zFar - zNear),
+		12: -(right + left) / (right - left),
+		13: -(top + bottom) / (top - bottom),
+		14: -(zFar + zNear) / (zFar - zNear),
+		15: 1,
+	}
+}
+
+// LookAt returns a matrix that transforms world space into the
+// space of a camera at eye looking towards center, with up
+// pointing roughly upwards.
+func LookAt(eye, center, up Vec3) Mat4 {
+	f := center.Sub(eye).Normalize()
+	s := f.Cross(up).Normalize()
+	u := s.Cross(f)
+	return Mat4{
+		s[0], u[0], -f[0], 0,
+		s[1], u[1], -f[1], 0,
+		s[2], u[2], -f[2], 0,
+		-s.Dot(eye), -u.Dot(eye), f.Dot(eye), 1,
+	}
+}
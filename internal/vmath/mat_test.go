@@ -0,0 +1,309 @@
+package vmath
+
+import (
+	"math"
+	"testing"
+)
+
+const epsilon = 1e-5
+
+func near(a, b float32) bool {
+	return math.Abs(float64(a-b)) <= epsilon
+}
+
+func matNear(m, n Mat4) bool {
+	for i := range m {
+		if !near(m[i], n[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func vecNear(v, w Vec4) bool {
+	for i := range v {
+		if !near(v[i], w[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// project transforms p by m and performs the perspective divide.
+func project(m Mat4, p Vec3) Vec3 {
+	c := m.MulVec(Vec4{p[0], p[1], p[2], 1})
+	return Vec3{c[0] / c[3], c[1] / c[3], c[2] / c[3]}
+}
+
+func TestProjection(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Mat4
+		want Mat4
+	}{
+		{
+			name: "perspective",
+			m:    Perspective(math.Pi/2, 1, 1, 3),
+			want: Mat4{0: 1, 5: 1, 10: -2, 11: -1, 14: -3},
+		},
+		{
+			name: "perspective aspect",
+			m:    Perspective(math.Pi/2, 2, 1, 3),
+			want: Mat4{0: 0.5, 5: 1, 10: -2, 11: -1, 14: -3},
+		},
+		{
+			name: "symmetric frustum",
+			m:    Frustum(-1, 1, -1, 1, 1, 3),
+			want: Perspective(math.Pi/2, 1, 1, 3),
+		},
+		{
+			name: "off-center frustum",
+			m:    Frustum(0, 2, 0, 1, 1, 3),
+			want: Mat4{0: 1, 5: 2, 8: 1, 9: 1, 10: -2, 11: -1, 14: -3},
+		},
+		{
+			name: "ortho",
+			m:    Ortho(0, 4, 0, 2, 1, 3),
+			want: Mat4{0: 0.5, 5: 1, 10: -1, 12: -1, 13: -1, 14: -2, 15: 1},
+		},
+	}
+	for _, tt := range tests {
+		if !matNear(tt.m, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, tt.m, tt.want)
+		}
+	}
+}
+
+func TestProjectionCorners(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Mat4
+		p    Vec3
+		want Vec3
+	}{
+		{"perspective near", Perspective(math.Pi/2, 1, 1, 3), Vec3{1, 1, -1}, Vec3{1, 1, -1}},
+		{"perspective far", Perspective(math.Pi/2, 1, 1, 3), Vec3{-3, -3, -3}, Vec3{-1, -1, 1}},
+		{"frustum near", Frustum(0, 2, 0, 1, 1, 3), Vec3{2, 1, -1}, Vec3{1, 1, -1}},
+		{"frustum far", Frustum(0, 2, 0, 1, 1, 3), Vec3{0, 0, -3}, Vec3{-1, -1, 1}},
+		{"ortho near", Ortho(0, 4, 0, 2, 1, 3), Vec3{0, 0, -1}, Vec3{-1, -1, -1}},
+		{"ortho far", Ortho(0, 4, 0, 2, 1, 3), Vec3{4, 2, -3}, Vec3{1, 1, 1}},
+	}
+	for _, tt := range tests {
+		got := project(tt.m, tt.p)
+		if !vecNear(got.Vec4(1), tt.want.Vec4(1)) {
+			t.Errorf("%s: %v -> %v, want %v", tt.name, tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestLookAt(t *testing.T) {
+	tests := []struct {
+		name            string
+		eye, center, up Vec3
+		want            Mat4
+	}{
+		{
+			name:   "down -z",
+			eye:    Vec3{0, 0, 5},
+			center: Vec3{0, 0, 0},
+			up:     Vec3{0, 1, 0},
+			want:   Translate(Vec3{0, 0, -5}),
+		},
+		{
+			name:   "down -x",
+			eye:    Vec3{1, 0, 0},
+			center: Vec3{0, 0, 0},
+			up:     Vec3{0, 1, 0},
+			// World +z is camera -x, world -x is camera -z.
+			want: Mat4{
+				0, 0, 1, 0,
+				0, 1, 0, 0,
+				-1, 0, 0, 0,
+				0, 0, -1, 1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		m := LookAt(tt.eye, tt.center, tt.up)
+		if !matNear(m, tt.want) {
+			t.Errorf("%s: LookAt = %v, want %v", tt.name, m, tt.want)
+		}
+		if got := m.MulVec(tt.eye.Vec4(1)); !vecNear(got, Vec4{0, 0, 0, 1}) {
+			t.Errorf("%s: eye -> %v, want origin", tt.name, got)
+		}
+		d := tt.center.Sub(tt.eye).Len()
+		if got := m.MulVec(tt.center.Vec4(1)); !vecNear(got, Vec4{0, 0, -d, 1}) {
+			t.Errorf("%s: center -> %v, want (0, 0, %v)", tt.name, got, -d)
+		}
+	}
+}
+
+func TestTranspose(t *testing.T) {
+	m := Mat4{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	want := Mat4{1, 5, 9, 13, 2, 6, 10, 14, 3, 7, 11, 15, 4, 8, 12, 16}
+	if got := m.Transpose(); got != want {
+		t.Errorf("Transpose = %v, want %v", got, want)
+	}
+	if got := m.Transpose().Transpose(); got != m {
+		t.Errorf("Transpose twice = %v, want %v", got, m)
+	}
+}
+
+func TestMul(t *testing.T) {
+	a := Mat4{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	b := Mat4{-1, -2, -3, -4, 2, 3, 4, 5, 1, 0, -1, -2, 4, 5, 6, 7}
+	want := Mat4{
+		-90, -100, -110, -120,
+		118, 132, 146, 160,
+		-34, -36, -38, -40,
+		174, 196, 218, 240,
+	}
+	if got := a.Mul(b); got != want {
+		t.Errorf("a·b = %v, want %v", got, want)
+	}
+	if got := a.Mul(Ident4()); got != a {
+		t.Errorf("a·I = %v, want %v", got, a)
+	}
+	if got := Ident4().Mul(a); got != a {
+		t.Errorf("I·a = %v, want %v", got, a)
+	}
+
+	// The right-hand matrix applies to vertices first.
+	tr, sc := Translate(Vec3{1, 0, 0}), Scale(Vec3{2, 2, 2})
+	p := Vec4{1, 0, 0, 1}
+	if got := tr.Mul(sc).MulVec(p); got != (Vec4{3, 0, 0, 1}) {
+		t.Errorf("T·S·p = %v, want (3, 0, 0, 1)", got)
+	}
+	if got := sc.Mul(tr).MulVec(p); got != (Vec4{4, 0, 0, 1}) {
+		t.Errorf("S·T·p = %v, want (4, 0, 0, 1)", got)
+	}
+}
+
+func TestInverse(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Mat4
+		want Mat4
+	}{
+		{"identity", Ident4(), Ident4()},
+		{"translate", Translate(Vec3{1, 2, 3}), Translate(Vec3{-1, -2, -3})},
+		{"scale", Scale(Vec3{2, 4, 8}), Scale(Vec3{0.5, 0.25, 0.125})},
+		{"rotate", RotateZ(0.3), RotateZ(-0.3)},
+		{"perspective", Perspective(math.Pi/2, 1, 1, 3), Mat4{
+			0: 1, 5: 1, 11: -1.0 / 3, 14: -1, 15: 2.0 / 3,
+		}},
+	}
+	for _, tt := range tests {
+		inv, ok := tt.m.Inverse()
+		if !ok {
+			t.Errorf("%s: Inverse not ok", tt.name)
+			continue
+		}
+		if !matNear(inv, tt.want) {
+			t.Errorf("%s: Inverse = %v, want %v", tt.name, inv, tt.want)
+		}
+		if got := tt.m.Mul(inv); !matNear(got, Ident4()) {
+			t.Errorf("%s: m·m⁻¹ = %v, want identity", tt.name, got)
+		}
+	}
+
+	m := Translate(Vec3{1, 2, 3}).Mul(RotateY(1)).Mul(Scale(Vec3{2, 3, 4}))
+	inv, ok := m.Inverse()
+	if !ok || !matNear(inv.Mul(m), Ident4()) {
+		t.Errorf("compound: m⁻¹·m = %v, ok %v; want identity", inv.Mul(m), ok)
+	}
+
+	if _, ok := Scale(Vec3{1, 0, 1}).Inverse(); ok {
+		t.Error("Inverse of singular matrix reported ok")
+	}
+}
+
+func TestColumnMajor(t *testing.T) {
+	m := Translate(Vec3{1, 2, 3})
+	cm, rm := m.ColumnMajor(), m.RowMajor()
+	if cm[12] != 1 || cm[13] != 2 || cm[14] != 3 {
+		t.Errorf("ColumnMajor translation = %v, want 1, 2, 3 at 12..14", cm[12:15])
+	}
+	if rm[3] != 1 || rm[7] != 2 || rm[11] != 3 {
+		t.Errorf("RowMajor translation = %v, %v, %v, want 1, 2, 3", rm[3], rm[7], rm[11])
+	}
+
+	a := Mat4{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	cm, rm = a.ColumnMajor(), a.RowMajor()
+	for r := 0; r < 4; r++ {
+		for c := 0; c < 4; c++ {
+			if cm[c*4+r] != a.At(r, c) {
+				t.Errorf("ColumnMajor[%d] = %v, want At(%d, %d) = %v", c*4+r, cm[c*4+r], r, c, a.At(r, c))
+			}
+			if rm[r*4+c] != a.At(r, c) {
+				t.Errorf("RowMajor[%d] = %v, want At(%d, %d) = %v", r*4+c, rm[r*4+c], r, c, a.At(r, c))
+			}
+		}
+	}
+}
+
+func TestRotate(t *testing.T) {
+	const (
+		quarter = math.Pi / 2
+		half    = math.Pi
+	)
+	tests := []struct {
+		name string
+		m    Mat4
+		v    Vec3
+		want Vec3
+	}{
+		{"RotateX(π/2) y", RotateX(quarter), Vec3{0, 1, 0}, Vec3{0, 0, 1}},
+		{"RotateX(π/2) z", RotateX(quarter), Vec3{0, 0, 1}, Vec3{0, -1, 0}},
+		{"RotateX(π) y", RotateX(half), Vec3{0, 1, 0}, Vec3{0, -1, 0}},
+		{"RotateY(π/2) z", RotateY(quarter), Vec3{0, 0, 1}, Vec3{1, 0, 0}},
+		{"RotateY(π/2) x", RotateY(quarter), Vec3{1, 0, 0}, Vec3{0, 0, -1}},
+		{"RotateY(π) x", RotateY(half), Vec3{1, 0, 0}, Vec3{-1, 0, 0}},
+		{"RotateZ(π/2) x", RotateZ(quarter), Vec3{1, 0, 0}, Vec3{0, 1, 0}},
+		{"RotateZ(π/2) y", RotateZ(quarter), Vec3{0, 1, 0}, Vec3{-1, 0, 0}},
+		{"RotateZ(π) y", RotateZ(half), Vec3{0, 1, 0}, Vec3{0, -1, 0}},
+		{"Rotate(π/2, x)", Rotate(quarter, Vec3{2, 0, 0}), Vec3{0, 1, 0}, Vec3{0, 0, 1}},
+		{"Rotate(π/2, y)", Rotate(quarter, Vec3{0, 3, 0}), Vec3{0, 0, 1}, Vec3{1, 0, 0}},
+		{"Rotate(π/2, z)", Rotate(quarter, Vec3{0, 0, 1}), Vec3{1, 0, 0}, Vec3{0, 1, 0}},
+		// A third of a turn around the diagonal cycles the axes.
+		{"Rotate(2π/3, diag) x", Rotate(2*math.Pi/3, Vec3{1, 1, 1}), Vec3{1, 0, 0}, Vec3{0, 1, 0}},
+		{"Rotate(2π/3, diag) y", Rotate(2*math.Pi/3, Vec3{1, 1, 1}), Vec3{0, 1, 0}, Vec3{0, 0, 1}},
+		{"Rotate(2π/3, diag) z", Rotate(2*math.Pi/3, Vec3{1, 1, 1}), Vec3{0, 0, 1}, Vec3{1, 0, 0}},
+	}
+	for _, tt := range tests {
+		got := tt.m.MulVec(tt.v.Vec4(1))
+		if !vecNear(got, tt.want.Vec4(1)) {
+			t.Errorf("%s·%v = %v, want %v", tt.name, tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestRotateMatchesAxes(t *testing.T) {
+	for _, angle := range []float32{0.3, math.Pi / 2, math.Pi, -2} {
+		if got, want := Rotate(angle, Vec3{1, 0, 0}), RotateX(angle); !matNear(got, want) {
+			t.Errorf("Rotate(%v, x) = %v, want RotateX = %v", angle, got, want)
+		}
+		if got, want := Rotate(angle, Vec3{0, 1, 0}), RotateY(angle); !matNear(got, want) {
+			t.Errorf("Rotate(%v, y) = %v, want RotateY = %v", angle, got, want)
+		}
+		if got, want := Rotate(angle, Vec3{0, 0, 1}), RotateZ(angle); !matNear(got, want) {
+			t.Errorf("Rotate(%v, z) = %v, want RotateZ = %v", angle, got, want)
+		}
+	}
+}
+
+func TestRotateArbitraryAxis(t *testing.T) {
+	axis := Vec3{1, 1, 1}
+	for _, angle := range []float32{0.3, math.Pi / 2, 2, math.Pi} {
+		m := Rotate(angle, axis)
+		if want := AxisAngle(angle, axis).Mat4(); !matNear(m, want) {
+			t.Errorf("Rotate(%v, %v) = %v, want quaternion matrix %v", angle, axis, m, want)
+		}
+		if got := m.MulVec(axis.Vec4(1)); !vecNear(got, axis.Vec4(1)) {
+			t.Errorf("Rotate(%v, %v) moved the axis to %v", angle, axis, got)
+		}
+		if got := m.Mul(m.Transpose()); !matNear(got, Ident4()) {
+			t.Errorf("Rotate(%v, %v) is not orthonormal: m·mᵀ = %v", angle, axis, got)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package vmath
+
+import "math"
+
+// A Quat is a quaternion W + Xi + Yj + Zk. Unit quaternions
+// represent orientations.
+type Quat struct {
+	W, X, Y, Z float32
+}
+
+// IdentQuat returns the quaternion representing no rotation.
+func IdentQuat() Quat { return Quat{W: 1} }
+
+// AxisAngle returns a unit quaternion that rotates by angle radians
+// around axis.
+func AxisAngle(angle float32, axis Vec3) Quat {
+	a := axis.Normalize()
+	s := sin(angle / 2)
+	return Quat{cos(angle / 2), a[0] * s, a[1] * s, a[2] * s}
+}
+
+// Mul returns the Hamilton product q × p. The resulting rotation
+// applies p first, then q.
+func (q Quat) Mul(p Quat) Quat {
+	return Quat{
+		q.W*p.W - q.X*p.X - q.Y*p.Y - q.Z*p.Z,
+		q.W*p.X + q.X*p.W + q.Y*p.Z - q.Z*p.Y,
+		q.W*p.Y + q.Y*p.W + q.Z*p.X - q.X*p.Z,
+		q.W*p.Z + q.Z*p.W + q.X*p.Y - q.Y*p.X,
+	}
+}
+
+// Dot returns the 4-dimensional dot product of q and p.
+func (q Quat) Dot(p Quat) float32 {
+	return q.W*p.W + q.X*p.X + q.Y*p.Y + q.Z*p.Z
+}
+
+// Len returns the magnitude of q.
+func (q Quat) Len() float32 { return sqrt(q.Dot(q)) }
+
+// Normalize returns q scaled to unit length. The zero quaternion
+// is returned unchanged.
+func (q Quat) Normalize() Quat {
+	l := q.Len()
+	if l == 0 {
+		return q
+	}
+	return Quat{q.W / l, q.X / l, q.Y / l, q.Z / l}
+}
+
+// Conjugate returns the conjugate of q, which is its inverse if
+// q is a unit quaternion.
+func (q Quat) Conjugate() Quat { return Quat{q.W, -q.X, -q.Y, -q.Z} }
+
+// Rotate rotates v by the unit quaternion q.
+func (q Quat) Rotate(v Vec3) Vec3 {
+	p := q.Mul(Quat{0, v[0], v[1], v[2]}).Mul(q.Conjugate())
+	return Vec3{p.X, p.Y, p.Z}
+}
+
+// Mat3 returns the rotation matrix for the unit quaternion q.
+func (q Quat) Mat3() Mat3 {
+	w, x, y, z := q.W, q.X, q.Y, q.Z
+	return Mat3{
+		1 - 2*(y*y+z*z), 2 * (x*y + w*z), 2 * (x*z - w*y),
+		2 * (x*y - w*z), 1 - 2*(x*x+z*z), 2 * (y*z + w*x),
+		2 * (x*z + w*y), 2 * (y*z - w*x), 1 - 2*(x*x+y*y),
+	}
+}
+
+// Mat4 returns the rotation matrix for the unit quaternion q.
+func (q Quat) Mat4() Mat4 { return q.Mat3().Mat4() }
+
+func (q Quat) neg() Quat { return Quat{-q.W, -q.X, -q.Y, -q.Z} }
+
+// Lerp interpolates linearly between the unit quaternions q and p
+// and normalizes the result. The orientation moves along the same
+// path as with Slerp, but not at a constant rate: it is fastest
+// halfway between q and p. Lerp takes the shorter of the two paths
+// between q and p.
+func (q Quat) Lerp(p Quat, t float32) Quat {
+	if q.Dot(p) < 0 {
+		p = p.neg()
+	}
+	return Quat{
+		Lerp(q.W, p.W, t),
+		Lerp(q.X, p.X, t),
+		Lerp(q.Y, p.Y, t),
+		Lerp(q.Z, p.Z, t),
+	}.Normalize()
+}
+
+// Slerp interpolates spherically between the unit quaternions q
+// and p, so that the orientation turns at a constant rate as t goes
+// from 0 to 1. Like Lerp, it takes the shorter path.
+func (q Quat) Slerp(p Quat, t float32) Quat {
+	d := q.Dot(p)
+	if d < 0 {
+		p, d = p.neg(), -d
+	}
+	// Close enough that sin(theta) loses precision; the two
+	// methods agree here anyway.
+	if d > 0.9995 {
+		return q.Lerp(p, t)
+	}
+	theta := float32(math.Acos(float64(d)))
+	s := sin(theta)
+	a, b := sin((1-t)*theta)/s, sin(t*theta)/s
+	return Quat{
+		a*q.W + b*p.W,
+		a*q.X + b*p.X,
+		a*q.Y + b*p.Y,
+		a*q.Z + b*p.Z,
+	}
+}
@@ -0,0 +1,189 @@
+package vmath
+
+import (
+	"math"
+	"testing"
+)
+
+func quatNear(q, p Quat) bool {
+	return near(q.W, p.W) && near(q.X, p.X) && near(q.Y, p.Y) && near(q.Z, p.Z)
+}
+
+// sameRotation reports whether q and p represent the same rotation;
+// q and -q do.
+func sameRotation(q, p Quat) bool {
+	return quatNear(q, p) || quatNear(q, p.neg())
+}
+
+func vec3Near(v, w Vec3) bool {
+	return near(v[0], w[0]) && near(v[1], w[1]) && near(v[2], w[2])
+}
+
+func TestAxisAngle(t *testing.T) {
+	r := float32(math.Sqrt2 / 2)
+	tests := []struct {
+		angle float32
+		axis  Vec3
+		want  Quat
+	}{
+		{0, Vec3{0, 1, 0}, IdentQuat()},
+		{math.Pi / 2, Vec3{0, 0, 1}, Quat{r, 0, 0, r}},
+		{math.Pi / 2, Vec3{0, 0, 5}, Quat{r, 0, 0, r}},
+		{-math.Pi / 2, Vec3{1, 0, 0}, Quat{r, -r, 0, 0}},
+		{math.Pi, Vec3{1, 0, 0}, Quat{0, 1, 0, 0}},
+		{2 * math.Pi / 3, Vec3{1, 1, 1}, Quat{0.5, 0.5, 0.5, 0.5}},
+	}
+	for _, tt := range tests {
+		q := AxisAngle(tt.angle, tt.axis)
+		if !quatNear(q, tt.want) {
+			t.Errorf("AxisAngle(%v, %v) = %v, want %v", tt.angle, tt.axis, q, tt.want)
+		}
+		if !near(q.Len(), 1) {
+			t.Errorf("AxisAngle(%v, %v) has length %v", tt.angle, tt.axis, q.Len())
+		}
+	}
+}
+
+func TestQuatRotate(t *testing.T) {
+	tests := []struct {
+		q    Quat
+		v    Vec3
+		want Vec3
+	}{
+		{IdentQuat(), Vec3{1, 2, 3}, Vec3{1, 2, 3}},
+		{AxisAngle(math.Pi/2, Vec3{1, 0, 0}), Vec3{0, 1, 0}, Vec3{0, 0, 1}},
+		{AxisAngle(math.Pi/2, Vec3{0, 1, 0}), Vec3{0, 0, 1}, Vec3{1, 0, 0}},
+		{AxisAngle(math.Pi/2, Vec3{0, 0, 1}), Vec3{1, 0, 0}, Vec3{0, 1, 0}},
+		{AxisAngle(math.Pi, Vec3{0, 0, 1}), Vec3{1, 2, 3}, Vec3{-1, -2, 3}},
+		{AxisAngle(2*math.Pi/3, Vec3{1, 1, 1}), Vec3{1, 0, 0}, Vec3{0, 1, 0}},
+	}
+	for _, tt := range tests {
+		if got := tt.q.Rotate(tt.v); !vec3Near(got, tt.want) {
+			t.Errorf("%v.Rotate(%v) = %v, want %v", tt.q, tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestQuatMul(t *testing.T) {
+	i, j, k := Quat{0, 1, 0, 0}, Quat{0, 0, 1, 0}, Quat{0, 0, 0, 1}
+	tests := []struct {
+		name string
+		got  Quat
+		want Quat
+	}{
+		{"ij", i.Mul(j), k},
+		{"ji", j.Mul(i), k.neg()},
+		{"jk", j.Mul(k), i},
+		{"kj", k.Mul(j), i.neg()},
+		{"ki", k.Mul(i), j},
+		{"ii", i.Mul(i), Quat{W: -1}},
+		{"1q", IdentQuat().Mul(Quat{1, 2, 3, 4}), Quat{1, 2, 3, 4}},
+		{"q1", Quat{1, 2, 3, 4}.Mul(IdentQuat()), Quat{1, 2, 3, 4}},
+		{"qq*", Quat{1, 2, 3, 4}.Mul(Quat{1, 2, 3, 4}.Conjugate()), Quat{W: 30}},
+	}
+	for _, tt := range tests {
+		if !quatNear(tt.got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	// q.Mul(p) applies p first, so the order matters.
+	rx, rz := AxisAngle(math.Pi/2, Vec3{1, 0, 0}), AxisAngle(math.Pi/2, Vec3{0, 0, 1})
+	y := Vec3{0, 1, 0}
+	if got := rz.Mul(rx).Rotate(y); !vec3Near(got, Vec3{0, 0, 1}) {
+		t.Errorf("rz·rx rotates y to %v, want (0, 0, 1)", got)
+	}
+	if got := rx.Mul(rz).Rotate(y); !vec3Near(got, Vec3{-1, 0, 0}) {
+		t.Errorf("rx·rz rotates y to %v, want (-1, 0, 0)", got)
+	}
+
+	a, b := AxisAngle(0.3, Vec3{1, 2, 3}), AxisAngle(1.2, Vec3{-1, 0, 1})
+	if got, want := a.Mul(b).Mat4(), a.Mat4().Mul(b.Mat4()); !matNear(got, want) {
+		t.Errorf("(a·b).Mat4() = %v, want a.Mat4()·b.Mat4() = %v", got, want)
+	}
+}
+
+func TestQuatNormalize(t *testing.T) {
+	tests := []struct {
+		q, want Quat
+	}{
+		{Quat{W: 2}, IdentQuat()},
+		{Quat{1, 1, 1, 1}, Quat{0.5, 0.5, 0.5, 0.5}},
+		{Quat{0, 0, -3, 4}, Quat{0, 0, -0.6, 0.8}},
+		{Quat{}, Quat{}},
+	}
+	for _, tt := range tests {
+		if got := tt.q.Normalize(); !quatNear(got, tt.want) {
+			t.Errorf("%v.Normalize() = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestQuatMatrix(t *testing.T) {
+	axes := []Vec3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}, {1, 1, 1}, {-1, 2, 0.5}}
+	angles := []float32{0, 0.3, math.Pi / 2, 2, math.Pi, -1}
+	for _, axis := range axes {
+		for _, angle := range angles {
+			q := AxisAngle(angle, axis)
+			if got, want := q.Mat4(), Rotate(angle, axis); !matNear(got, want) {
+				t.Errorf("AxisAngle(%v, %v).Mat4() = %v, want %v", angle, axis, got, want)
+			}
+			if got, want := q.Mat3(), Rotate3(angle, axis); !matNear(got.Mat4(), want.Mat4()) {
+				t.Errorf("AxisAngle(%v, %v).Mat3() = %v, want %v", angle, axis, got, want)
+			}
+			v := Vec3{0.2, -3, 1}
+			if got, want := q.Rotate(v), q.Mat3().MulVec(v); !vec3Near(got, want) {
+				t.Errorf("AxisAngle(%v, %v).Rotate(%v) = %v, want %v", angle, axis, v, got, want)
+			}
+		}
+	}
+}
+
+func TestSlerp(t *testing.T) {
+	axis := Vec3{0, 1, 0}
+	q, p := IdentQuat(), AxisAngle(Radians(120), axis)
+	tests := []struct {
+		t    float32
+		want Quat
+	}{
+		{0, q},
+		{0.25, AxisAngle(Radians(30), axis)},
+		{0.5, AxisAngle(Radians(60), axis)},
+		{0.75, AxisAngle(Radians(90), axis)},
+		{1, p},
+	}
+	for _, tt := range tests {
+		if got := q.Slerp(p, tt.t); !quatNear(got, tt.want) {
+			t.Errorf("Slerp(%v) = %v, want %v", tt.t, got, tt.want)
+		}
+	}
+
+	// Lerp agrees at the midpoint, but lags behind before it.
+	if got, want := q.Lerp(p, 0.5), q.Slerp(p, 0.5); !quatNear(got, want) {
+		t.Errorf("Lerp(0.5) = %v, want Slerp(0.5) = %v", got, want)
+	}
+	if got := q.Lerp(p, 0.25); 2*math.Acos(float64(got.W)) >= float64(Radians(30))-0.01 {
+		t.Errorf("Lerp(0.25) = %v turns by %v, want less than 30°", got, Degrees(float32(2*math.Acos(float64(got.W)))))
+	}
+
+	// -p is the same orientation as p, and Slerp takes the same
+	// path to it.
+	for _, tt := range tests {
+		if got, want := q.Slerp(p.neg(), tt.t), q.Slerp(p, tt.t); !sameRotation(got, want) {
+			t.Errorf("Slerp(-p, %v) = %v, want %v", tt.t, got, want)
+		}
+	}
+
+	// Three quarters of a turn one way is a quarter turn the other,
+	// so the midpoint is an eighth of a turn backwards.
+	r := AxisAngle(3*math.Pi/2, Vec3{0, 0, 1})
+	if got, want := q.Slerp(r, 0.5), AxisAngle(-math.Pi/4, Vec3{0, 0, 1}); !sameRotation(got, want) {
+		t.Errorf("Slerp to 270° = %v, want %v", got, want)
+	}
+
+	// Orientations close enough for Slerp to fall back to Lerp.
+	e := AxisAngle(0.001, Vec3{0, 0, 1})
+	if got, want := q.Slerp(e, 0.5), AxisAngle(0.0005, Vec3{0, 0, 1}); !quatNear(got, want) {
+		t.Errorf("Slerp to nearby = %v, want %v", got, want)
+	}
+}
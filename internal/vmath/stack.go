@@ -0,0 +1,74 @@
+package vmath
+
+// A MatrixStack builds up a transform one step at a time, the way
+// the fixed-function OpenGL matrix stack did. Each method multiplies
+// the top of the stack on the right, so transforms apply to vertices
+// in the reverse of the order they are given. Push saves the top so
+// that a later Pop can undo everything done since; this makes it
+// easy to draw a hierarchy of objects, each positioned relative to
+// its parent. The zero value is a stack holding the identity matrix.
+type MatrixStack struct {
+	top   Mat4
+	saved []Mat4
+	init  bool
+}
+
+// NewMatrixStack returns a stack holding m.
+func NewMatrixStack(m Mat4) *MatrixStack {
+	return &MatrixStack{top: m, init: true}
+}
+
+// Top returns the matrix at the top of the stack.
+func (s *MatrixStack) Top() Mat4 {
+	if !s.init {
+		return Ident4()
+	}
+	return s.top
+}
+
+// Push saves a copy of the top of the stack.
+func (s *MatrixStack) Push() {
+	s.saved = append(s.saved, s.Top())
+}
+
+// Pop restores the top of the stack to what it was at the matching
+// Push. It panics if there is no matching Push.
+func (s *MatrixStack) Pop() {
+	n := len(s.saved)
+	if n == 0 {
+		panic("vmath: Pop of empty MatrixStack")
+	}
+	s.top, s.init = s.saved[n-1], true
+	s.saved = s.saved[:n-1]
+}
+
+// Depth returns the number of saved matrices.
+func (s *MatrixStack) Depth() int { return len(s.saved) }
+
+// Set replaces the top of the stack with m.
+func (s *MatrixStack) Set(m Mat4) { s.top, s.init = m, true }
+
+// Mul multiplies the top of the stack by m.
+func (s *MatrixStack) Mul(m Mat4) { s.Set(s.Top().Mul(m)) }
+
+// Translate multiplies the top of the stack by a translation.
+func (s *MatrixStack) Translate(v Vec3) { s.Mul(Translate(v)) }
+
+// Scale multiplies the top of the stack by a scale.
+func (s *MatrixStack) Scale(v Vec3) { s.Mul(Scale(v)) }
+
+// RotateX multiplies the top of the stack by a rotation of angle
+// radians around the X axis.
+func (s *MatrixStack) RotateX(angle float32) { s.Mul(RotateX(angle)) }
+
+// RotateY multiplies the top of the stack by a rotation of angle
+// radians around the Y axis.
+func (s *MatrixStack) RotateY(angle float32) { s.Mul(RotateY(angle)) }
+
+// RotateZ multiplies the top of the stack by a rotation of angle
+// radians around the Z axis.
+func (s *MatrixStack) RotateZ(angle float32) { s.Mul(RotateZ(angle)) }
+
+// Rotate multiplies the top of the stack by a rotation of angle
+// radians around axis.
+func (s *MatrixStack) Rotate(angle float32, axis Vec3) { s.Mul(Rotate(angle, axis)) }
@@ -0,0 +1,66 @@
+package vmath
+
+import "testing"
+
+func TestMatrixStack(t *testing.T) {
+	var s MatrixStack
+	if s.Top() != Ident4() || s.Depth() != 0 {
+		t.Fatalf("zero MatrixStack: Top = %v, Depth = %d; want identity, 0", s.Top(), s.Depth())
+	}
+
+	tr := Translate(Vec3{1, 2, 3})
+	sc := Scale(Vec3{2, 2, 2})
+	rz := RotateZ(0.5)
+
+	s.Translate(Vec3{1, 2, 3})
+	s.Push()
+	s.Scale(Vec3{2, 2, 2})
+	s.Push()
+	s.RotateZ(0.5)
+	if s.Depth() != 2 {
+		t.Errorf("Depth = %d, want 2", s.Depth())
+	}
+	if want := tr.Mul(sc).Mul(rz); !matNear(s.Top(), want) {
+		t.Errorf("Top = %v, want T·S·R = %v", s.Top(), want)
+	}
+
+	s.Pop()
+	if want := tr.Mul(sc); !matNear(s.Top(), want) {
+		t.Errorf("after Pop, Top = %v, want T·S = %v", s.Top(), want)
+	}
+	s.Pop()
+	if !matNear(s.Top(), tr) || s.Depth() != 0 {
+		t.Errorf("after second Pop, Top = %v, Depth = %d; want T, 0", s.Top(), s.Depth())
+	}
+
+	// Set replaces the top without disturbing saved matrices.
+	s.Push()
+	s.Set(sc)
+	s.Pop()
+	if !matNear(s.Top(), tr) {
+		t.Errorf("Pop after Set: Top = %v, want %v", s.Top(), tr)
+	}
+
+	// A Push on the zero value saves the identity.
+	var z MatrixStack
+	z.Push()
+	z.Translate(Vec3{1, 0, 0})
+	z.Pop()
+	if z.Top() != Ident4() {
+		t.Errorf("zero stack after Push/Translate/Pop: Top = %v, want identity", z.Top())
+	}
+
+	if got := NewMatrixStack(sc).Top(); got != sc {
+		t.Errorf("NewMatrixStack(m).Top() = %v, want %v", got, sc)
+	}
+}
+
+func TestMatrixStackPopEmpty(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Pop of empty MatrixStack did not panic")
+		}
+	}()
+	var s MatrixStack
+	s.Pop()
+}
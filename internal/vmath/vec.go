@@ -0,0 +1,83 @@
+// Package vmath provides the vector, matrix and quaternion types
+// used to position objects in the tutorials.
+//
+// Matrices are stored in column-major order, the layout OpenGL
+// expects when a matrix uniform is uploaded with transpose set
+// to false. Use ColumnMajor or RowMajor to be explicit about
+// which layout is handed to the driver.
+package vmath
+
+import "math"
+
+// Radians converts an angle in degrees to radians.
+func Radians(deg float32) float32 {
+	return deg * math.Pi / 180
+}
+
+// Degrees converts an angle in radians to degrees.
+func Degrees(rad float32) float32 {
+	return rad * 180 / math.Pi
+}
+
+// Lerp linearly interpolates between a and b.
+func Lerp(a, b, t float32) float32 {
+	return a + (b-a)*t
+}
+
+func sqrt(x float32) float32 { return float32(math.Sqrt(float64(x))) }
+func sin(x float32) float32  { return float32(math.Sin(float64(x))) }
+func cos(x float32) float32  { return float32(math.Cos(float64(x))) }
+func tan(x float32) float32  { return float32(math.Tan(float64(x))) }
+
+// A Vec3 is a 3-component vector.
+type Vec3 [3]float32
+
+// A Vec4 is a 4-component vector.
+type Vec4 [4]float32
+
+func (v Vec3) Add(u Vec3) Vec3    { return Vec3{v[0] + u[0], v[1] + u[1], v[2] + u[2]} }
+func (v Vec3) Sub(u Vec3) Vec3    { return Vec3{v[0] - u[0], v[1] - u[1], v[2] - u[2]} }
+func (v Vec3) Mul(s float32) Vec3 { return Vec3{v[0] * s, v[1] * s, v[2] * s} }
+func (v Vec3) Neg() Vec3          { return Vec3{-v[0], -v[1], -v[2]} }
+
+// Dot returns the dot product of v and u.
+func (v Vec3) Dot(u Vec3) float32 {
+	return v[0]*u[0] + v[1]*u[1] + v[2]*u[2]
+}
+
+// Cross returns the cross product v × u.
+func (v Vec3) Cross(u Vec3) Vec3 {
+	return Vec3{
+		v[1]*u[2] - v[2]*u[1],
+		v[2]*u[0] - v[0]*u[2],
+		v[0]*u[1] - v[1]*u[0],
+	}
+}
+
+// Len returns the length of v.
+func (v Vec3) Len() float32 { return sqrt(v.Dot(v)) }
+
+// Normalize returns a unit vector in the direction of v. The zero
+// vector is returned unchanged.
+func (v Vec3) Normalize() Vec3 {
+	l := v.Len()
+	if l == 0 {
+		return v
+	}
+	return v.Mul(1 / l)
+}
+
+// Vec4 extends v with the given w component.
+func (v Vec3) Vec4(w float32) Vec4 { return Vec4{v[0], v[1], v[2], w} }
+
+func (v Vec4) Add(u Vec4) Vec4    { return Vec4{v[0] + u[0], v[1] + u[1], v[2] + u[2], v[3] + u[3]} }
+func (v Vec4) Sub(u Vec4) Vec4    { return Vec4{v[0] - u[0], v[1] - u[1], v[2] - u[2], v[3] - u[3]} }
+func (v Vec4) Mul(s float32) Vec4 { return Vec4{v[0] * s, v[1] * s, v[2] * s, v[3] * s} }
+
+// Dot returns the dot product of v and u.
+func (v Vec4) Dot(u Vec4) float32 {
+	return v[0]*u[0] + v[1]*u[1] + v[2]*u[2] + v[3]*u[3]
+}
+
+// Vec3 drops the w component of v.
+func (v Vec4) Vec3() Vec3 { return Vec3{v[0], v[1], v[2]} }